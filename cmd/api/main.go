@@ -2,164 +2,296 @@ package main
 
 import (
 	"context"
-	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
-	"github.com/gorilla/mux"
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api"
+	apigrpc "github.com/gometeo/app/internal/api/grpc"
 	"github.com/gometeo/app/internal/api/handlers"
+	"github.com/gometeo/app/internal/auth"
 	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/cachepush"
+	"github.com/gometeo/app/internal/changefeed"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/chaos"
 	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/geocode"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/ipfilter"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/startup"
 	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/tracing"
 )
 
 func main() {
-	// Настройка логирования
-	logger := setupLogger()
-	logger.Info("Запуск Weather API сервиса...")
-
 	// Загрузка конфигурации
 	cfg := config.Load()
+
+	// Настройка логирования
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск Weather API сервиса...", buildinfo.LogArgs()...)
 	logger.Info("Конфигурация загружена",
 		"port", cfg.HTTPPort,
 		"redis", cfg.RedisAddr,
 		"cache_ttl", cfg.CacheTTL)
 
-	// 1. Подключение к Postgres
-	store, err := storage.New(cfg.DBDSN, logger)
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, "api")
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "tracing", shutdownTracing)
+
+	reporter, err := errreport.New(cfg, "api", logger)
 	if err != nil {
-		logger.Error("Не удалось подключиться к БД", "error", err)
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
 		os.Exit(1)
 	}
-	defer store.Close()
-	logger.Info("Успешное подключение к Postgres")
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "api")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	// 1. Подключение к Postgres
+	backoff := startup.BackoffFromConfig(cfg)
+	connectDB := func(context.Context) (*storage.WeatherStorage, error) {
+		return storage.New(cfg.DBDSN, logger)
+	}
+	store, err := startup.Wait(context.Background(), logger, "postgres", backoff, connectDB)
+	if err != nil {
+		if !cfg.StartupPartial {
+			logger.Error("Не удалось подключиться к БД", "error", err)
+			os.Exit(1)
+		}
+		logger.Warn("БД недоступна, API запускается в режиме только чтения из кэша", "error", err)
+	}
 
 	// 2. Подключение к Redis
-	redisCache, err := cache.New(
-		cfg.RedisAddr,
-		cfg.RedisPassword,
-		cfg.RedisDB,
-		cfg.CacheTTL,
-		logger,
-	)
+	redisCache, err := startup.Wait(context.Background(), logger, "redis", backoff,
+		func(context.Context) (*cache.WeatherCache, error) {
+			return cache.New(
+				cfg.RedisAddr,
+				cfg.RedisPassword,
+				cfg.RedisDB,
+				cfg.CacheTTL,
+				logger,
+			)
+		})
 	if err != nil {
 		logger.Error("Не удалось подключиться к Redis", "error", err)
 		os.Exit(1)
 	}
-	defer redisCache.Close()
-	logger.Info("Успешное подключение к Redis")
+	shutdown.Register(lifecycle.PhaseCloseCache, "redis", func(context.Context) error { return redisCache.Close() })
 
-	// 3. Настройка маршрутизатора
-	router := mux.NewRouter()
-	weatherHandler := handlers.NewWeatherHandler(store, redisCache, logger)
-
-	// API маршруты
-	api := router.PathPrefix("/api/v1").Subrouter()
-	
-	// Weather endpoints
-	api.HandleFunc("/weather/{city}", weatherHandler.GetWeather).Methods("GET")
-	api.HandleFunc("/weather/{city}", weatherHandler.UpdateWeather).Methods("PUT")
-	api.HandleFunc("/cities", weatherHandler.GetAllCities).Methods("GET")
-	
-	// Health check
-	api.HandleFunc("/health", weatherHandler.HealthCheck).Methods("GET")
-	
-	// Middleware
-	router.Use(loggingMiddleware(logger))
-	router.Use(contentTypeMiddleware)
+	// Внедрение сбоев для staging, в остальных окружениях nil
+	faults := chaos.New(cfg, logger)
+	redisCache.SetFaultInjector(faults)
 
-	// 4. Настройка HTTP сервера
-	server := &http.Server{
-		Addr:         ":" + cfg.HTTPPort,
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	geocoder := geocode.FromConfig(cfg, nil, redisCache, logger)
+	weatherHandler := handlers.NewWeatherHandler(nil, redisCache, logger)
+	weatherHandler.SetGeocoder(geocoder)
+	weatherGRPC := apigrpc.NewServer(redisCache, logger)
+	weatherGRPC.SetGeocoder(geocoder)
+
+	// Обновление кэша по событиям агрегатора, без ожидания истечения TTL
+	if cfg.CachePushEnabled {
+		kafkaConfig := sarama.NewConfig()
+		// TLS и SASL для управляемой Kafka
+		if err := bus.Secure(kafkaConfig, cfg); err != nil {
+			logger.Error("Ошибка настройки подключения к Kafka", "error", err)
+			os.Exit(1)
+		}
+		consumer, err := startup.Wait(context.Background(), logger, "kafka", backoff,
+			func(context.Context) (sarama.Consumer, error) {
+				return sarama.NewConsumer(cfg.KafkaBrokers, kafkaConfig)
+			})
+		if err != nil {
+			logger.Error("Ошибка подключения к Kafka", "error", err)
+			os.Exit(1)
+		}
+		subscriber := cachepush.NewSubscriber(consumer, cfg.KafkaUpdatesTopic, redisCache, logger)
+		// Те же события будят клиентов /weather/{city}/wait
+		feed := changefeed.NewHub()
+		subscriber.SetFeed(feed)
+		weatherHandler.SetFeed(feed)
+		weatherGRPC.SetFeed(feed)
+		pushCtx, stopPush := context.WithCancel(context.Background())
+		pushDone := make(chan struct{})
+		go func() {
+			defer close(pushDone)
+			if err := subscriber.Run(pushCtx); err != nil {
+				logger.Error("Подписка на обновления кэша остановлена", "error", err)
+			}
+		}()
+		shutdown.Register(lifecycle.PhaseStopIntake, "cache-push", func(ctx context.Context) error {
+			stopPush()
+			select {
+			case <-pushDone:
+			case <-ctx.Done():
+			}
+			return consumer.Close()
+		})
 	}
 
-	// 5. Graceful shutdown
-	stopChan := make(chan os.Signal, 1)
-	signal.Notify(stopChan, syscall.SIGINT, syscall.SIGTERM)
+	// API-ключи: счетчики запросов в Redis, периодически сохраняются в Postgres
+	accounts := account.New(cfg, redisCache, logger)
+	accountHandler := handlers.NewAccountHandler(accounts, logger)
+	tokens := auth.NewTokens(cfg)
+	accountHandler.SetTokens(tokens)
+	planRoles, err := auth.ParsePlanRoles(cfg.APIKeyPlanRoles)
+	if err != nil {
+		logger.Error("Ошибка настройки ролей тарифов", "error", err)
+		os.Exit(1)
+	}
+	accountHandler.SetPlanRoles(planRoles)
+	usageCtx, stopUsage := context.WithCancel(context.Background())
+	go accounts.Run(usageCtx, cfg.UsageFlushInterval)
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "usage-flush-loop", stopUsage)
+	shutdown.Register(lifecycle.PhaseFlush, "usage", accounts.Flush)
 
-	go func() {
-		logger.Info("Сервер запущен", "port", cfg.HTTPPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("Ошибка сервера", "error", err)
+	attachStore := func(store *storage.WeatherStorage) {
+		store.SetFaultInjector(faults)
+		shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, "postgres", store.Close)
+		if err := metrics.RegisterDBStats("postgres", store.Stats); err != nil {
+			logger.Warn("Метрики пула БД недоступны", "error", err)
 		}
-	}()
+		geocoder := geocode.FromConfig(cfg, store, redisCache, logger)
+		weatherHandler.SetGeocoder(geocoder)
+		weatherHandler.SetStore(store)
+		weatherGRPC.SetGeocoder(geocoder)
+		weatherGRPC.SetStore(store)
+		accounts.SetStore(store)
+	}
 
-	// Ожидание сигнала завершения
-	<-stopChan
-	logger.Info("Получен сигнал завершения...")
+	if store != nil {
+		attachStore(store)
+	} else {
+		// Частичный старт: продолжаем подключаться к БД в фоне
+		connectCtx, stopConnect := context.WithCancel(context.Background())
+		shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "postgres-connect", stopConnect)
+		go func() {
+			store, err := startup.Wait(connectCtx, logger, "postgres", backoff.Unbounded(), connectDB)
+			if err != nil {
+				return
+			}
+			if connectCtx.Err() != nil {
+				store.Close()
+				return
+			}
+			attachStore(store)
+			logger.Info("БД подключена, режим только чтения выключен")
+		}()
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// БД соседнего региона для сравнения свежести; во время его аварии подключаемся в фоне
+	var replicationHandler *handlers.ReplicationHandler
+	if cfg.ReplicaDBDSN != "" {
+		replicationHandler = handlers.NewReplicationHandler(weatherHandler, cfg.Region, cfg.ReplicationTolerance)
+		peerCtx, stopPeer := context.WithCancel(context.Background())
+		shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "replica-connect", stopPeer)
+		go func() {
+			peer, err := startup.Wait(peerCtx, logger, "postgres-replica", backoff.Unbounded(),
+				func(context.Context) (*storage.WeatherStorage, error) {
+					return storage.New(cfg.ReplicaDBDSN, logger)
+				})
+			if err != nil {
+				return
+			}
+			if peerCtx.Err() != nil {
+				peer.Close()
+				return
+			}
+			shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, "postgres-replica", peer.Close)
+			replicationHandler.SetPeer(peer)
+		}()
+	}
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("Ошибка при остановке сервера", "error", err)
-	} else {
-		logger.Info("Сервер остановлен")
+	// 3. Настройка маршрутизатора
+	checks := health.New("api", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	checks.RegisterDetailed("database", weatherHandler.StoreHealth)
+	checks.RegisterDetailed("redis", redisCache.MemoryInfo)
+	ipFilter, err := ipfilter.FromConfig(cfg)
+	if err != nil {
+		logger.Error("Неверные настройки подсетей доступа", "error", err)
+		os.Exit(1)
 	}
-}
+	router := api.NewRouter(cfg, api.Deps{
+		Weather:     weatherHandler,
+		Accounts:    accountHandler,
+		Tokens:      tokens,
+		Replication: replicationHandler,
+		Cache:       redisCache,
+		Checks:      checks,
+		Metrics:     metricsProvider.Handler(),
+		Reporter:    reporter,
+		IPFilter:    ipFilter,
+		Logger:      logger,
+	})
 
-func setupLogger() *slog.Logger {
-	opts := &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+	// 4. Настройка HTTP сервера
+	server := &http.Server{
+		Addr:         ":" + cfg.HTTPPort,
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
 	}
 
-	var handler slog.Handler = slog.NewTextHandler(os.Stdout, opts)
-	
-	// Для продакшена используем JSON формат
-	if os.Getenv("ENV") == "production" {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+	stopTLS, err := api.ConfigureServer(server, cfg, logger)
+	if err != nil {
+		logger.Error("Не удалось настроить TLS", "error", err)
+		os.Exit(1)
 	}
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "tls-reload", stopTLS)
 
-	return slog.New(handler)
-}
+	shutdown.Register(lifecycle.PhaseStopIntake, "http", server.Shutdown)
+	// Хуки фазы идут в обратном порядке: сначала закрываются /ws и /wait,
+	// иначе Shutdown ждал бы их до дедлайна фазы
+	shutdown.Register(lifecycle.PhaseStopIntake, "live-streams", weatherHandler.DrainStreams)
 
-// Middleware для логирования
-func loggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			
-			// Создаем ResponseWriter для отслеживания статуса
-			rw := &responseWriter{ResponseWriter: w, status: 200}
-			
-			next.ServeHTTP(rw, r)
-			
-			duration := time.Since(start)
-			
-			logger.Info("HTTP запрос",
-				"method", r.Method,
-				"path", r.URL.Path,
-				"status", rw.status,
-				"duration_ms", duration.Milliseconds(),
-				"user_agent", r.UserAgent(),
-				"remote_addr", r.RemoteAddr,
-			)
-		})
+	// pprof и статистика рантайма на внутреннем порту
+	debugCtx, stopDebug := context.WithCancel(context.Background())
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "debug", stopDebug)
+	debugserver.Serve(debugCtx, cfg, "api", logger)
+
+	// gRPC для внутренних сервисов рядом с HTTP
+	if cfg.GRPCPort != "" {
+		stopGRPC, err := weatherGRPC.Listen(":" + cfg.GRPCPort)
+		if err != nil {
+			logger.Error("Не удалось запустить gRPC-сервер", "error", err)
+			os.Exit(1)
+		}
+		shutdown.Register(lifecycle.PhaseStopIntake, "grpc", stopGRPC)
 	}
-}
 
-// Кастомный ResponseWriter для отслеживания статуса
-type responseWriter struct {
-	http.ResponseWriter
-	status int
-}
+	// 5. Запуск и graceful shutdown
+	serverCtx, serverFailed := context.WithCancel(context.Background())
+	go func() {
+		logger.Info("Сервер запущен", "port", cfg.HTTPPort, "tls", server.TLSConfig != nil)
+		if err := api.ListenAndServe(server); err != nil && err != http.ErrServerClosed {
+			logger.Error("Ошибка сервера", "error", err)
+			serverFailed()
+		}
+	}()
 
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.status = code
-	rw.ResponseWriter.WriteHeader(code)
+	// Ожидание сигнала завершения
+	shutdown.Wait(serverCtx)
+	shutdown.Shutdown()
 }
-
-// Middleware для установки Content-Type
-func contentTypeMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		next.ServeHTTP(w, r)
-	})
-}
\ No newline at end of file
@@ -1,94 +1,222 @@
 package main
 
 import (
-	"encoding/json"
-	"log/slog"
-	"math/rand"
+	"context"
+	"fmt"
+	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/codec"
+	"github.com/gometeo/app/internal/collector"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/geocode"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
 	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/provider"
+	"github.com/gometeo/app/internal/startup"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/tracing"
 )
 
-const (
-	topic         = "weather_data"
-	brokerAddress = "localhost:9092"
-)
+const workerGroup = "weather_collector_workers"
 
 func main() {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	logger.Info("Запуск Weather Collector...")
+	cfg := config.Load()
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск Weather Collector...", buildinfo.LogArgs()...)
+
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, "collector")
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "tracing", shutdownTracing)
+
+	reporter, err := errreport.New(cfg, "collector", logger)
+	if err != nil {
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "collector")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	runCtx, stop := context.WithCancel(context.Background())
+	checks := health.New("collector", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	// Остальные брокеры клиент Kafka находит по метаданным первого
+	checks.Register("kafka", health.TCPCheck(cfg.KafkaBrokers[0]))
+	metricsProvider.Serve(runCtx, cfg.MetricsAddr, logger, map[string]http.Handler{
+		"/health": checks.Handler(),
+		"/livez":  checks.LiveHandler(),
+		"/readyz": checks.ReadyHandler(),
+	})
+	debugserver.Serve(runCtx, cfg, "collector", logger)
 
 	// 1. Настройка Kafka Producer
 	config := sarama.NewConfig()
 	config.Producer.Return.Successes = true
 	// Важно для надежности: ждать подтверждения от Kafka, что сообщение записано
 	config.Producer.RequiredAcks = sarama.WaitForAll
+	// Партиция по хэшу ключа (города), а не случайная: порядок замеров города
+	// сохраняется. При изменении числа партиций города перераспределяются,
+	// поэтому партиции топика добавляются, когда агрегатор дочитал отставание.
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	// Сотни городов опрашиваются параллельно: сообщения уходят пачками,
+	// а не по одному запросу к брокеру на замер
+	config.Producer.Return.Errors = true
+	config.Producer.Flush.Messages = cfg.KafkaBatchSize
+	config.Producer.Flush.Frequency = cfg.KafkaLinger
+	if err := bus.Secure(config, cfg); err != nil {
+		logger.Error("Ошибка настройки подключения к Kafka", "error", err)
+		os.Exit(1)
+	}
 
-	producer, err := sarama.NewSyncProducer([]string{brokerAddress}, config)
+	asyncProducer, err := startup.Wait(context.Background(), logger, "kafka", startup.BackoffFromConfig(cfg),
+		func(context.Context) (sarama.AsyncProducer, error) {
+			return sarama.NewAsyncProducer(cfg.KafkaBrokers, config)
+		})
 	if err != nil {
 		logger.Error("Ошибка подключения к Kafka", "error", err)
 		os.Exit(1)
 	}
-	defer func() {
-		if err := producer.Close(); err != nil {
-			logger.Error("Ошибка при закрытии продюсера", "error", err)
-		}
-	}()
-
-	// 2. Канал для Graceful Shutdown (Ctrl+C)
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	// 3. Тикер для эмуляции CRON (каждые 3 секунды)
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
+	producer := bus.NewAsync(asyncProducer)
+	// Продюсер закрывается после остановки цикла сбора: Close отправляет
+	// накопленные пачки и ждет их подтверждения, чтобы не потерять сообщения
+	shutdown.Register(lifecycle.PhaseFlush, "kafka-producer", func(context.Context) error {
+		return producer.Close()
+	})
 
-	cities := []string{"Moscow", "London", "New York", "Berlin", "Tokyo"}
+	// 2. Цикл сбора в отдельной горутине, остановка через lifecycle
+	collectorDone := make(chan struct{})
+	shutdown.Register(lifecycle.PhaseStopIntake, "collector-loop", func(ctx context.Context) error {
+		stop()
+		select {
+		case <-collectorDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
 
-	logger.Info("Начинаем сбор данных...")
+	geocoder := geocode.FromConfig(cfg, nil, nil, logger)
+	geocoder.Seed(model.DefaultCities)
+	c := collector.New(producer, reporter, chaos.New(cfg, logger), geocoder, logger)
+	messageCodec, err := codec.FromConfig(cfg)
+	if err != nil {
+		logger.Error("Ошибка настройки формата сообщений", "error", err)
+		os.Exit(1)
+	}
+	c.SetCodec(messageCodec)
+	c.SetForecasts(cfg.KafkaForecastTopic, cfg.ForecastInterval, cfg.ForecastDays)
+	providers := provider.FromConfig(cfg, logger)
+	if len(providers) == 0 {
+		logger.Error("Провайдеры погоды не настроены: задайте ключ API, METNO_ENABLED " +
+			"или PROVIDER_EMULATOR=true для разработки")
+		os.Exit(1)
+	}
+	c.SetProviders(providers)
+	// Предохранители провайдеров в health: разомкнутый дает degraded
+	for _, p := range providers {
+		if check := provider.CircuitCheck(p); check != nil {
+			checks.RegisterOptional("provider:"+p.Name(), check)
+		}
+	}
+	// Зависший цикл сбора или отправки снимает готовность; /healthz и /status
+	// отдают подробности для оркестратора
+	c.SetStallTimeout(cfg.CollectorStallTimeout)
+	checks.Register("collector", func(context.Context) error {
+		if st := c.Status(); st.Status == collector.StatusStalled {
+			return fmt.Errorf("сбор не продвигается дольше %s", cfg.CollectorStallTimeout)
+		}
+		return nil
+	})
+	c.ServeStatus(runCtx, cfg.CollectorStatusAddr)
+	if cfg.CollectorMode == "worker" {
+		// Воркер без своего расписания: города и время опроса задает cmd/scheduler
+		consumerConfig := sarama.NewConfig()
+		consumerConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
+		if err := bus.Secure(consumerConfig, cfg); err != nil {
+			logger.Error("Ошибка настройки подключения к Kafka", "error", err)
+			os.Exit(1)
+		}
+		consumer, err := startup.Wait(context.Background(), logger, "kafka-consumer", startup.BackoffFromConfig(cfg),
+			func(context.Context) (sarama.ConsumerGroup, error) {
+				return sarama.NewConsumerGroup(cfg.KafkaBrokers, workerGroup, consumerConfig)
+			})
+		if err != nil {
+			logger.Error("Ошибка создания Kafka consumer", "error", err)
+			os.Exit(1)
+		}
+		shutdown.Register(lifecycle.PhaseDrain, "kafka-consumer", func(context.Context) error {
+			return consumer.Close()
+		})
 
-	for {
-		select {
-		case <-sigChan:
-			logger.Info("Получен сигнал завершения. Остановка...")
-			return
-		case <-ticker.C:
-			// Эмуляция получения данных от внешнего API
-			data := model.WeatherData{
-				City:      cities[rand.Intn(len(cities))],
-				Temp:      float64(rand.Intn(40)-10) + rand.Float64(), // Случайная темп.
-				Condition: "Cloudy",
-				Provider:  "OpenWeatherMap",
-				Timestamp: time.Now(),
+		go func() {
+			defer close(collectorDone)
+			logger.Info("Коллектор работает в режиме воркера", "topic", cfg.KafkaFetchTopic)
+			for {
+				if err := consumer.Consume(runCtx, []string{cfg.KafkaFetchTopic}, c.Worker()); err != nil {
+					logger.Error("Ошибка при чтении Kafka", "error", err)
+				}
+				if runCtx.Err() != nil {
+					return
+				}
 			}
-
-			// Сериализация
-			bytes, err := json.Marshal(data)
+		}()
+	} else {
+		// Города из настроек, иначе справочник в БД; пока она недоступна,
+		// опрашиваются города по умолчанию
+		c.SetCities(cfg.CollectorCities, cfg.CollectorCitiesFile, cfg.CollectorInterval)
+		c.SetCitiesRefresh(cfg.CollectorCitiesRefresh)
+		if err := c.SetSchedules(cfg.CollectorSchedules); err != nil {
+			logger.Error("Ошибка в расписании сбора", "error", err)
+			os.Exit(1)
+		}
+		connectCtx, stopConnect := context.WithCancel(context.Background())
+		shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "postgres-connect", stopConnect)
+		go func() {
+			store, err := startup.Wait(connectCtx, logger, "postgres", startup.BackoffFromConfig(cfg).Unbounded(),
+				func(context.Context) (*storage.WeatherStorage, error) {
+					return storage.New(cfg.DBDSN, logger)
+				})
 			if err != nil {
-				logger.Error("Ошибка JSON", "error", err)
-				continue
+				return
 			}
-
-			// Отправка в Kafka
-			msg := &sarama.ProducerMessage{
-				Topic: topic,
-				Value: sarama.ByteEncoder(bytes),
+			if connectCtx.Err() != nil {
+				store.Close()
+				return
 			}
+			shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, "postgres", store.Close)
+			c.SetCitySource(store)
+			logger.Info("БД подключена, города берутся из справочника")
+		}()
 
-			partition, offset, err := producer.SendMessage(msg)
-			if err != nil {
-				logger.Error("Не удалось отправить сообщение", "error", err)
-			} else {
-				logger.Info("Погода отправлена",
-					"city", data.City,
-					"temp", int(data.Temp),
-					"partition", partition,
-					"offset", offset)
-			}
-		}
+		go func() {
+			defer close(collectorDone)
+			c.Run(runCtx)
+		}()
 	}
+
+	shutdown.Wait(runCtx)
+	logger.Info("Остановка...")
+	shutdown.Shutdown()
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/export"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/startup"
+	"github.com/gometeo/app/internal/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func main() {
+	date := flag.String("date", "", "выгрузить один день (YYYY-MM-DD) и завершиться")
+	flag.Parse()
+
+	cfg := config.Load()
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск Weather Exporter...", buildinfo.LogArgs()...)
+
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	reporter, err := errreport.New(cfg, "exporter", logger)
+	if err != nil {
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "exporter")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	store, err := startup.Wait(context.Background(), logger, "postgres", startup.BackoffFromConfig(cfg),
+		func(context.Context) (*storage.WeatherStorage, error) {
+			return storage.New(cfg.DBDSN, logger)
+		})
+	if err != nil {
+		logger.Error("Не удалось подключиться к БД. Выход.", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, "postgres", store.Close)
+
+	exporter, err := export.New(cfg, store, logger)
+	if err != nil {
+		logger.Error("Ошибка настройки выгрузки", "error", err)
+		os.Exit(1)
+	}
+
+	// Разовая выгрузка для дозаполнения пропущенных дней
+	if *date != "" {
+		day, err := time.Parse(time.DateOnly, *date)
+		if err != nil {
+			logger.Error("Некорректная дата", "date", *date, "error", err)
+			os.Exit(1)
+		}
+		err = exporter.ExportDay(context.Background(), day)
+		shutdown.Shutdown()
+		if err != nil {
+			logger.Error("Ошибка выгрузки", "date", *date, "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	runCtx, stop := context.WithCancel(context.Background())
+	checks := health.New("exporter", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	checks.Register("database", store.Ping)
+	checks.Register("s3", exporter.Ping)
+	metricsProvider.Serve(runCtx, cfg.MetricsAddr, logger, map[string]http.Handler{
+		"/health": checks.Handler(),
+		"/livez":  checks.LiveHandler(),
+		"/readyz": checks.ReadyHandler(),
+	})
+	debugserver.Serve(runCtx, cfg, "exporter", logger)
+
+	runs, _ := metrics.Meter("github.com/gometeo/app/cmd/exporter").Int64Counter(
+		"exporter.runs",
+		metric.WithDescription("Количество ежедневных выгрузок по результату"))
+
+	done := make(chan struct{})
+	shutdown.Register(lifecycle.PhaseStopIntake, "export-loop", func(ctx context.Context) error {
+		stop()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	go func() {
+		defer close(done)
+		run(runCtx, cfg, exporter, logger, reporter, runs)
+	}()
+
+	shutdown.Wait(runCtx)
+	logger.Info("Остановка сервиса...")
+	shutdown.Shutdown()
+}
+
+// run раз в сутки в cfg.ExportAt выгружает прошедшие сутки до отмены ctx
+func run(ctx context.Context, cfg *config.Config, exporter *export.Exporter, logger *slog.Logger, reporter errreport.Reporter, runs metric.Int64Counter) {
+	for {
+		next, err := export.NextRun(time.Now(), cfg.ExportAt)
+		if err != nil {
+			logger.Error("Ошибка расписания выгрузки", "error", err)
+			return
+		}
+		logger.Info("Следующая выгрузка", "at", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		day := next.AddDate(0, 0, -1)
+		result := "ok"
+		if err := exporter.ExportDay(ctx, day); err != nil {
+			result = "failed"
+			logger.ErrorContext(ctx, "Ошибка выгрузки", "date", day.Format(time.DateOnly), "error", err)
+			reporter.CaptureError(ctx, err, map[string]string{"date": day.Format(time.DateOnly), "stage": "export"})
+		}
+		runs.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+	}
+}
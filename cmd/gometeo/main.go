@@ -0,0 +1,300 @@
+// Монолит: API, коллектор и агрегатор в одном процессе. Вместо Kafka
+// сообщения идут через шину в памяти (bus.Memory), поэтому нужны только
+// Postgres и Redis. Подходит для демо, edge-установок и локальной разработки;
+// сообщения в очереди теряются при перезапуске.
+//
+// С флагом --dev (или DEV_MODE=true) внешние зависимости не нужны вовсе: данные
+// хранятся в файле SQLite (DEV_DB_PATH), Redis поднимается в памяти процесса,
+// а без настроенных провайдеров замеры выдает эмулятор.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/aggregator"
+	"github.com/gometeo/app/internal/api"
+	"github.com/gometeo/app/internal/api/handlers"
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/collector"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/dlq"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/geocode"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/ipfilter"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/provider"
+	"github.com/gometeo/app/internal/startup"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/storage/sqlite"
+	"github.com/gometeo/app/internal/tracing"
+	"github.com/gometeo/app/internal/webhook"
+)
+
+// busBuffer — емкость очереди топика в памяти
+const busBuffer = 1024
+
+// Store — все операции с БД, нужные частям монолита; реализуется
+// storage.WeatherStorage и sqlite.WeatherStorage
+type Store interface {
+	handlers.Store
+	aggregator.Store
+	geocode.Store
+	account.Store
+	Close()
+	SetFaultInjector(faults *chaos.Injector)
+	Stats() sql.DBStats
+}
+
+var (
+	_ Store = (*storage.WeatherStorage)(nil)
+	_ Store = (*sqlite.WeatherStorage)(nil)
+)
+
+func main() {
+	cfg := config.Load()
+	flag.BoolVar(&cfg.DevMode, "dev", cfg.DevMode, "режим разработки: SQLite вместо Postgres, Redis в памяти процесса, эмулятор погоды")
+	flag.Parse()
+	if cfg.DevMode {
+		cfg.ProviderEmulator = true
+	}
+
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск GoMeteo в режиме монолита...", append(buildinfo.LogArgs(), "dev", cfg.DevMode)...)
+
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, "gometeo")
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "tracing", shutdownTracing)
+
+	reporter, err := errreport.New(cfg, "gometeo", logger)
+	if err != nil {
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "gometeo")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	// 1. БД и Redis; в режиме разработки — SQLite и кэш в памяти
+	backoff := startup.BackoffFromConfig(cfg)
+	dbName := "postgres"
+	if cfg.DevMode {
+		dbName = "sqlite"
+	}
+	store, err := startup.Wait(context.Background(), logger, dbName, backoff,
+		func(context.Context) (Store, error) {
+			if cfg.DevMode {
+				return sqlite.New(cfg.DevDBPath, logger)
+			}
+			return storage.New(cfg.DBDSN, logger)
+		})
+	if err != nil {
+		logger.Error("Не удалось подключиться к БД. Выход.", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, dbName, store.Close)
+	if err := metrics.RegisterDBStats(dbName, store.Stats); err != nil {
+		logger.Warn("Метрики пула БД недоступны", "error", err)
+	}
+
+	redisCache, err := startup.Wait(context.Background(), logger, "redis", backoff,
+		func(context.Context) (*cache.WeatherCache, error) {
+			if cfg.DevMode {
+				return cache.NewInMemory(cfg.CacheTTL, logger)
+			}
+			return cache.New(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB, cfg.CacheTTL, logger)
+		})
+	if err != nil {
+		logger.Error("Не удалось подключиться к Redis", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseCloseCache, "redis", func(context.Context) error { return redisCache.Close() })
+
+	faults := chaos.New(cfg, logger)
+	store.SetFaultInjector(faults)
+	redisCache.SetFaultInjector(faults)
+
+	geocoder := geocode.FromConfig(cfg, store, redisCache, logger)
+	geocoder.Seed(model.DefaultCities)
+
+	// 2. Шина в памяти вместо Kafka; закрывается после остановки всех участников
+	messages := bus.NewMemory(busBuffer)
+	shutdown.Register(lifecycle.PhaseFlush, "bus", func(context.Context) error { return messages.Close() })
+
+	// 3. Агрегатор. DLQ пишется в ту же шину: без Kafka разбирать ее некому,
+	// но паника одного сообщения по-прежнему не останавливает обработку
+	handler := aggregator.NewHandler(cfg, store, dlq.NewPublisher(messages, cfg.KafkaDLQTopic), reporter, faults, logger)
+	webhooks := webhook.NewDispatcher(cfg, store, logger)
+	shutdown.Register(lifecycle.PhaseFlush, "webhooks", webhooks.Close)
+	handler.SetWebhooks(webhooks)
+	aggCtx, stopAggregator := context.WithCancel(context.Background())
+	aggWG := &sync.WaitGroup{}
+	aggWG.Add(1)
+	go func() {
+		defer aggWG.Done()
+		for {
+			if err := messages.Consume(aggCtx, []string{aggregator.Topic, cfg.KafkaForecastTopic}, handler); err != nil {
+				logger.Error("Ошибка при чтении шины", "error", err)
+			}
+			if aggCtx.Err() != nil {
+				return
+			}
+		}
+	}()
+	shutdown.Register(lifecycle.PhaseDrain, "aggregator", func(ctx context.Context) error {
+		stopAggregator()
+		done := make(chan struct{})
+		go func() {
+			aggWG.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	// 4. Коллектор по собственному расписанию
+	c := collector.New(bus.Immediate(messages), reporter, faults, geocoder, logger)
+	c.SetForecasts(cfg.KafkaForecastTopic, cfg.ForecastInterval, cfg.ForecastDays)
+	providers := provider.FromConfig(cfg, logger)
+	if len(providers) == 0 {
+		logger.Error("Провайдеры погоды не настроены: задайте ключ API, METNO_ENABLED " +
+			"или PROVIDER_EMULATOR=true для разработки")
+		os.Exit(1)
+	}
+	c.SetProviders(providers)
+	c.SetCities(cfg.CollectorCities, cfg.CollectorCitiesFile, cfg.CollectorInterval)
+	c.SetCitiesRefresh(cfg.CollectorCitiesRefresh)
+	if err := c.SetSchedules(cfg.CollectorSchedules); err != nil {
+		logger.Error("Ошибка в расписании сбора", "error", err)
+		os.Exit(1)
+	}
+	c.SetCitySource(store)
+	collectCtx, stopCollector := context.WithCancel(context.Background())
+	collectorDone := make(chan struct{})
+	go func() {
+		defer close(collectorDone)
+		c.Run(collectCtx)
+	}()
+	shutdown.Register(lifecycle.PhaseStopIntake, "collector-loop", func(ctx context.Context) error {
+		stopCollector()
+		select {
+		case <-collectorDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	// 5. API. Кэш обновляется по TTL: подписка cachepush работает только с Kafka
+	weatherHandler := handlers.NewWeatherHandler(store, redisCache, logger)
+	weatherHandler.SetGeocoder(geocoder)
+
+	accounts := account.New(cfg, redisCache, logger)
+	accounts.SetStore(store)
+	accountHandler := handlers.NewAccountHandler(accounts, logger)
+	tokens := auth.NewTokens(cfg)
+	accountHandler.SetTokens(tokens)
+	planRoles, err := auth.ParsePlanRoles(cfg.APIKeyPlanRoles)
+	if err != nil {
+		logger.Error("Ошибка настройки ролей тарифов", "error", err)
+		os.Exit(1)
+	}
+	accountHandler.SetPlanRoles(planRoles)
+	usageCtx, stopUsage := context.WithCancel(context.Background())
+	go accounts.Run(usageCtx, cfg.UsageFlushInterval)
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "usage-flush-loop", stopUsage)
+	shutdown.Register(lifecycle.PhaseFlush, "usage", accounts.Flush)
+
+	checks := health.New("gometeo", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	checks.RegisterDetailed("database", store.Health)
+	checks.RegisterDetailed("redis", redisCache.MemoryInfo)
+	// Предохранители провайдеров коллектора: разомкнутый дает degraded
+	for _, p := range providers {
+		if check := provider.CircuitCheck(p); check != nil {
+			checks.RegisterOptional("provider:"+p.Name(), check)
+		}
+	}
+	ipFilter, err := ipfilter.FromConfig(cfg)
+	if err != nil {
+		logger.Error("Неверные настройки подсетей доступа", "error", err)
+		os.Exit(1)
+	}
+	router := api.NewRouter(cfg, api.Deps{
+		Weather:  weatherHandler,
+		Accounts: accountHandler,
+		Tokens:   tokens,
+		Cache:    redisCache,
+		Checks:   checks,
+		Metrics:  metricsProvider.Handler(),
+		Reporter: reporter,
+		IPFilter: ipFilter,
+		Logger:   logger,
+	})
+
+	server := &http.Server{
+		Addr:         ":" + cfg.HTTPPort,
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+	stopTLS, err := api.ConfigureServer(server, cfg, logger)
+	if err != nil {
+		logger.Error("Не удалось настроить TLS", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "tls-reload", stopTLS)
+	shutdown.Register(lifecycle.PhaseStopIntake, "http", server.Shutdown)
+	// Хуки фазы идут в обратном порядке: сначала закрываются /ws и /wait,
+	// иначе Shutdown ждал бы их до дедлайна фазы
+	shutdown.Register(lifecycle.PhaseStopIntake, "live-streams", weatherHandler.DrainStreams)
+
+	// pprof и статистика рантайма на внутреннем порту
+	debugCtx, stopDebug := context.WithCancel(context.Background())
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "debug", stopDebug)
+	debugserver.Serve(debugCtx, cfg, "gometeo", logger)
+
+	serverCtx, serverFailed := context.WithCancel(context.Background())
+	go func() {
+		logger.Info("Сервер запущен", "port", cfg.HTTPPort, "tls", server.TLSConfig != nil)
+		if err := api.ListenAndServe(server); err != nil && err != http.ErrServerClosed {
+			logger.Error("Ошибка сервера", "error", err)
+			serverFailed()
+		}
+	}()
+
+	shutdown.Wait(serverCtx)
+	logger.Info("Остановка...")
+	shutdown.Shutdown()
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/IBM/sarama"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/backfill"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/dlq"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+const defaultBrokers = "localhost:9092"
+
+// brokersFlag добавляет общий флаг со списком брокеров Kafka
+func brokersFlag(fs *flag.FlagSet) *string {
+	return fs.String("brokers", defaultBrokers, "брокеры Kafka через запятую")
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// runCities печатает список городов через публичный API
+func runCities(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("cities", flag.ExitOnError)
+	api := fs.String("api", "http://localhost:"+e.cfg.HTTPPort, "адрес API")
+	fs.Parse(args)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(*api, "/")+"/api/v1/cities", nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr model.ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("API вернул %d %s: %s", resp.StatusCode, apiErr.Code, apiErr.Error)
+	}
+
+	var cities model.CitiesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cities); err != nil {
+		return fmt.Errorf("ошибка разбора ответа: %w", err)
+	}
+	for _, city := range cities.Cities {
+		fmt.Println(city)
+	}
+	fmt.Fprintf(os.Stderr, "всего: %d\n", cities.Total)
+	return nil
+}
+
+// runRefetch публикует команды fetch.requested, минуя расписание планировщика
+func runRefetch(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("refetch", flag.ExitOnError)
+	brokers := brokersFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("укажите хотя бы один город")
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	if err := bus.Secure(config, e.cfg); err != nil {
+		return err
+	}
+	producer, err := sarama.NewSyncProducer(splitList(*brokers), config)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к Kafka: %w", err)
+	}
+	defer producer.Close()
+
+	now := time.Now()
+	for _, city := range fs.Args() {
+		event, err := model.NewEvent(model.EventFetchRequested, "gometeoctl", now, model.FetchRequest{
+			City:        city,
+			ScheduledAt: now.UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		payload, err := event.Marshal()
+		if err != nil {
+			return err
+		}
+
+		_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+			Topic: e.cfg.KafkaFetchTopic,
+			Key:   sarama.StringEncoder(city),
+			Value: sarama.ByteEncoder(payload),
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка отправки команды для %s: %w", city, err)
+		}
+		fmt.Printf("%s: команда на сбор отправлена\n", city)
+	}
+	return nil
+}
+
+// cityList — повторяемый строковый флаг
+type cityList []string
+
+func (c *cityList) String() string     { return strings.Join(*c, ",") }
+func (c *cityList) Set(v string) error { *c = append(*c, v); return nil }
+
+// runCacheFlush удаляет ключи погоды конкретных городов или по шаблону
+func runCacheFlush(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("cache-flush", flag.ExitOnError)
+	var cities cityList
+	fs.Var(&cities, "city", "город, чей ключ удалить (можно повторять)")
+	pattern := fs.String("pattern", "", `шаблон ключей, например "weather:*"`)
+	fs.Parse(args)
+	if len(cities) == 0 && *pattern == "" {
+		return errors.New("укажите -city или -pattern")
+	}
+
+	c, err := cache.New(e.cfg.RedisAddr, e.cfg.RedisPassword, e.cfg.RedisDB, e.cfg.CacheTTL, e.logger)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	for _, city := range cities {
+		if err := c.Delete(ctx, cache.CityKey(city)); err != nil {
+			return err
+		}
+		fmt.Printf("удален %s\n", cache.CityKey(city))
+	}
+	if len(cities) > 0 {
+		// Списки городов v1 и v2 собираются из тех же данных
+		for _, key := range []string{cache.AllCitiesKey(), cache.CityListKey()} {
+			if err := c.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if *pattern != "" {
+		n, err := c.DeletePattern(ctx, *pattern)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("удалено по шаблону %s: %d\n", *pattern, n)
+	}
+	return nil
+}
+
+// runDLQReplay возвращает сообщения из DLQ агрегатора в исходные топики
+func runDLQReplay(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("dlq-replay", flag.ExitOnError)
+	brokers := brokersFlag(fs)
+	limit := fs.Int("limit", 0, "максимум сообщений, 0 — все накопленные")
+	group := fs.String("group", "gometeoctl_dlq_replay", "группа для хранения прогресса")
+	fs.Parse(args)
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Consumer.Offsets.AutoCommit.Interval = 100 * time.Millisecond
+	if err := bus.Secure(config, e.cfg); err != nil {
+		return err
+	}
+
+	client, err := sarama.NewClient(splitList(*brokers), config)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к Kafka: %w", err)
+	}
+	defer client.Close()
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return fmt.Errorf("ошибка создания producer: %w", err)
+	}
+	defer producer.Close()
+
+	replayer := dlq.NewReplayer(client, producer, e.cfg.KafkaDLQTopic, *group, e.logger)
+	n, err := replayer.Replay(ctx, *limit)
+	fmt.Printf("переотправлено: %d\n", n)
+	return err
+}
+
+// runKeyCreate создает аккаунт и печатает ключ; ключ показывается только один раз
+func runKeyCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("key-create", flag.ExitOnError)
+	name := fs.String("name", "", "название аккаунта")
+	plan := fs.String("plan", "free", "тариф")
+	quota := fs.Int64("quota", 10000, "запросов в месяц, 0 — без ограничения")
+	fs.Parse(args)
+	if *name == "" {
+		return errors.New("укажите -name")
+	}
+
+	key, err := account.GenerateKey()
+	if err != nil {
+		return fmt.Errorf("ошибка генерации ключа: %w", err)
+	}
+
+	store, err := storage.New(e.cfg.DBDSN, e.logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	id, err := store.CreateAccount(ctx, model.Account{
+		Name:         *name,
+		Plan:         *plan,
+		MonthlyQuota: *quota,
+		Active:       true,
+	}, account.HashKey(key))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "аккаунт %d создан, сохраните ключ — повторно он не показывается\n", id)
+	fmt.Println(key)
+	return nil
+}
+
+// runUserCreate создает пользователя для выпуска токенов. Пароль читается
+// из GOMETEO_PASSWORD или первой строки stdin, чтобы не попадать в историю команд.
+func runUserCreate(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("user-create", flag.ExitOnError)
+	name := fs.String("name", "", "логин")
+	roleName := fs.String("role", string(auth.RoleReader), "роль: reader, writer или admin")
+	accountID := fs.Int64("account", 0, "ID аккаунта API, от имени которого работает пользователь; 0 — без аккаунта")
+	fs.Parse(args)
+	if *name == "" {
+		return errors.New("укажите -name")
+	}
+	role, err := auth.ParseRole(*roleName)
+	if err != nil {
+		return err
+	}
+
+	password := os.Getenv("GOMETEO_PASSWORD")
+	if password == "" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if len(password) < 8 {
+		return errors.New("пароль короче 8 символов")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("ошибка хэширования пароля: %w", err)
+	}
+
+	store, err := storage.New(e.cfg.DBDSN, e.logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	user := model.User{Username: *name, Role: string(role), Active: true}
+	if *accountID != 0 {
+		user.AccountID = accountID
+	}
+	id, err := store.CreateUser(ctx, user, string(hash))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("пользователь %d создан, роль %s\n", id, role)
+	return nil
+}
+
+// runImportGHCN загружает суточные температуры станций GHCN в историю городов
+func runImportGHCN(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("import-ghcn", flag.ExitOnError)
+	var pairs cityList
+	fs.Var(&pairs, "station", "сопоставление станции и города ID=Город (можно повторять)")
+	batchSize := fs.Int("batch", 500, "замеров в одной транзакции")
+	fs.Parse(args)
+	if len(pairs) == 0 || fs.NArg() == 0 {
+		return errors.New("укажите хотя бы одну -station и файл")
+	}
+
+	stations, err := backfill.ParseStations(pairs)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.New(e.cfg.DBDSN, e.logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, path := range fs.Args() {
+		if err := importGHCNFile(ctx, store, path, stations, *batchSize); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func importGHCNFile(ctx context.Context, store *storage.WeatherStorage, path string, stations map[string]string, batchSize int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var (
+		batch    []model.WeatherData
+		inserted int
+	)
+	save := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := store.ImportHistory(ctx, batch)
+		inserted += n
+		batch = batch[:0]
+		return err
+	}
+
+	stats, err := backfill.ReadGHCN(r, stations, func(data model.WeatherData) error {
+		batch = append(batch, data)
+		if len(batch) >= batchSize {
+			return save()
+		}
+		return ctx.Err()
+	})
+	if err == nil {
+		err = save()
+	}
+	fmt.Printf("%s: строк %d, пропущено %d, суточных замеров %d, добавлено %d\n",
+		path, stats.Rows, stats.Skipped, stats.Readings, inserted)
+	return err
+}
+
+// runMigrate применяет миграции: они выполняются при подключении хранилища
+func runMigrate(_ context.Context, e *env, _ []string) error {
+	store, err := storage.New(e.cfg.DBDSN, e.logger)
+	if err != nil {
+		return err
+	}
+	store.Close()
+	fmt.Println("миграции применены")
+	return nil
+}
+
+// runLag печатает отставание группы по партициям топика
+func runLag(_ context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("lag", flag.ExitOnError)
+	brokers := brokersFlag(fs)
+	group := fs.String("group", "weather_aggregator_group", "consumer-группа")
+	topic := fs.String("topic", "weather_data", "топик")
+	fs.Parse(args)
+
+	config := sarama.NewConfig()
+	if err := bus.Secure(config, e.cfg); err != nil {
+		return err
+	}
+	client, err := sarama.NewClient(splitList(*brokers), config)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к Kafka: %w", err)
+	}
+	defer client.Close()
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		return fmt.Errorf("ошибка создания admin-клиента: %w", err)
+	}
+
+	partitions, err := client.Partitions(*topic)
+	if err != nil {
+		return fmt.Errorf("ошибка получения партиций %s: %w", *topic, err)
+	}
+
+	committed, err := admin.ListConsumerGroupOffsets(*group, map[string][]int32{*topic: partitions})
+	if err != nil {
+		return fmt.Errorf("ошибка получения смещений группы %s: %w", *group, err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PARTITION\tCOMMITTED\tEND\tLAG")
+	var total int64
+	for _, p := range partitions {
+		end, err := client.GetOffset(*topic, p, sarama.OffsetNewest)
+		if err != nil {
+			return fmt.Errorf("ошибка получения смещения партиции %d: %w", p, err)
+		}
+
+		offset := int64(-1)
+		if block := committed.GetBlock(*topic, p); block != nil {
+			offset = block.Offset
+		}
+
+		lag := end
+		if offset >= 0 {
+			lag = end - offset
+		}
+		total += lag
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\n", p, offset, end, lag)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t\t%d\n", total)
+	return w.Flush()
+}
@@ -0,0 +1,84 @@
+// gometeoctl — административная утилита: справочник городов, принудительный сбор,
+// очистка кэша, переотправка DLQ, миграции и отставание конвейера.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/logging"
+)
+
+// command — подкоманда gometeoctl
+type command struct {
+	name string
+	args string
+	help string
+	run  func(ctx context.Context, env *env, args []string) error
+}
+
+// env — общие зависимости подкоманд
+type env struct {
+	cfg    *config.Config
+	logger *slog.Logger
+}
+
+var commands = []command{
+	{"cities", "[-api URL]", "список городов из API", runCities},
+	{"refetch", "CITY...", "отправить команду на сбор погоды", runRefetch},
+	{"cache-flush", "[-city CITY]... [-pattern P]", "удалить ключи кэша", runCacheFlush},
+	{"dlq-replay", "[-limit N]", "вернуть сообщения из DLQ в исходные топики", runDLQReplay},
+	{"key-create", "-name NAME [-plan P] [-quota N]", "выпустить API-ключ", runKeyCreate},
+	{"user-create", "-name NAME [-role R] [-account ID] < PASSWORD", "создать пользователя для выпуска токенов", runUserCreate},
+	{"import-ghcn", "-station ID=CITY... FILE...", "загрузить историю из CSV NOAA GHCN-Daily (.csv, .csv.gz)", runImportGHCN},
+	{"migrate", "", "применить миграции схемы БД", runMigrate},
+	{"lag", "[-group G] [-topic T]", "отставание consumer-группы", runLag},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	e := &env{
+		cfg: cfg,
+		// Логи утилиты уходят в stderr, чтобы не смешиваться с выводом команд
+		logger: slog.New(logging.NewHandler(os.Stderr, logging.Options{Level: "warn", Format: "text"})),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	name, args := os.Args[1], os.Args[2:]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(ctx, e, args); err != nil {
+				fmt.Fprintln(os.Stderr, "ошибка:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "неизвестная команда %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Использование: gometeoctl <команда> [флаги]")
+	fmt.Fprintln(os.Stderr)
+	w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.help)
+	}
+	w.Flush()
+}
@@ -0,0 +1,90 @@
+// loadgen — нагрузочный прогон конвейера и API: синтетические замеры в Kafka
+// или запросы погоды к HTTP API с заданным числом городов и темпом.
+//
+//	loadgen kafka -rate 500 -cities 1000 -duration 1m
+//	loadgen http -api http://localhost:8080 -rate 200 -workers 32
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/collector"
+	"github.com/gometeo/app/internal/loadgen"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "kafka" && os.Args[1] != "http") {
+		fmt.Fprintln(os.Stderr, "Использование: loadgen kafka|http [флаги]")
+		os.Exit(2)
+	}
+	mode, args := os.Args[1], os.Args[2:]
+
+	fs := flag.NewFlagSet("loadgen "+mode, flag.ExitOnError)
+	opts := loadgen.Options{}
+	fs.IntVar(&opts.Cities, "cities", 100, "число разных городов")
+	fs.Float64Var(&opts.Rate, "rate", 100, "операций в секунду, 0 — без ограничения")
+	fs.DurationVar(&opts.Duration, "duration", 30*time.Second, "длительность прогона")
+	fs.IntVar(&opts.Workers, "workers", 8, "параллельных исполнителей")
+	fs.Uint64Var(&opts.Seed, "seed", 1, "сид выбора городов и значений")
+	brokers := fs.String("brokers", "localhost:9092", "адреса Kafka через запятую")
+	topic := fs.String("topic", collector.Topic, "топик замеров")
+	base := fs.String("api", "http://localhost:8080", "адрес API")
+	apiKey := fs.String("key", "", "API-ключ для запросов")
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var target loadgen.Target
+	switch mode {
+	case "kafka":
+		config := sarama.NewConfig()
+		config.Producer.Return.Successes = true
+		config.Producer.RequiredAcks = sarama.WaitForAll
+		producer, err := sarama.NewSyncProducer(strings.Split(*brokers, ","), config)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ошибка подключения к Kafka:", err)
+			os.Exit(1)
+		}
+		defer producer.Close()
+		target = loadgen.KafkaTarget(producer, *topic)
+	case "http":
+		client := &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{MaxIdleConnsPerHost: opts.Workers},
+		}
+		target = loadgen.HTTPTarget(client, strings.TrimRight(*base, "/"), *apiKey)
+	}
+
+	fmt.Fprintf(os.Stderr, "Прогон %s: %d городов, %.0f оп/с, %d исполнителей, %s\n",
+		mode, opts.Cities, opts.Rate, opts.Workers, opts.Duration)
+	report, err := loadgen.Run(ctx, opts, target)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ошибка:", err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "операций\t%d\n", report.Requests)
+	fmt.Fprintf(w, "ошибок\t%d\n", report.Errors)
+	fmt.Fprintf(w, "время\t%s\n", report.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "пропускная способность\t%.1f оп/с\n", report.Throughput)
+	fmt.Fprintf(w, "p50\t%s\n", report.P50)
+	fmt.Fprintf(w, "p95\t%s\n", report.P95)
+	fmt.Fprintf(w, "p99\t%s\n", report.P99)
+	fmt.Fprintf(w, "max\t%s\n", report.Max)
+	w.Flush()
+	if report.FirstError != nil {
+		fmt.Fprintln(os.Stderr, "первая ошибка:", report.FirstError)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/startup"
+	"github.com/gometeo/app/internal/station"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	topic       = "weather_data"
+	eventSource = "mqttbridge"
+)
+
+var tracer = tracing.Tracer("github.com/gometeo/app/cmd/mqttbridge")
+
+func main() {
+	cfg := config.Load()
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск Weather MQTT Bridge...", buildinfo.LogArgs()...)
+
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, "mqttbridge")
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "tracing", shutdownTracing)
+
+	reporter, err := errreport.New(cfg, "mqttbridge", logger)
+	if err != nil {
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "mqttbridge")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	backoff := startup.BackoffFromConfig(cfg)
+
+	// 1. Kafka Producer: замеры станций идут в общий конвейер
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	// TLS и SASL для управляемой Kafka
+	if err := bus.Secure(config, cfg); err != nil {
+		logger.Error("Ошибка настройки подключения к Kafka", "error", err)
+		os.Exit(1)
+	}
+
+	producer, err := startup.Wait(context.Background(), logger, "kafka", backoff,
+		func(context.Context) (sarama.SyncProducer, error) {
+			return sarama.NewSyncProducer(cfg.KafkaBrokers, config)
+		})
+	if err != nil {
+		logger.Error("Ошибка подключения к Kafka", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "kafka-producer", func(context.Context) error {
+		return producer.Close()
+	})
+
+	received, _ := metrics.Meter("github.com/gometeo/app/cmd/mqttbridge").Int64Counter(
+		"mqttbridge.messages",
+		metric.WithDescription("Количество сообщений станций по результату"))
+
+	b := &bridge{
+		logger:   logger,
+		producer: producer,
+		reporter: reporter,
+		faults:   chaos.New(cfg, logger),
+		received: received,
+	}
+
+	// 2. Подключение к MQTT брокеру
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.MQTTBroker).
+		SetClientID(cfg.MQTTClientID).
+		SetUsername(cfg.MQTTUsername).
+		SetPassword(cfg.MQTTPassword).
+		SetAutoReconnect(true).
+		SetCleanSession(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.Warn("Соединение с MQTT потеряно", "error", err)
+		})
+
+	// Подписки восстанавливаются при каждом (пере)подключении
+	qos := byte(cfg.MQTTQoS)
+	opts.SetOnConnectHandler(func(client mqtt.Client) {
+		for _, filter := range cfg.MQTTTopics {
+			filter = strings.TrimSpace(filter)
+			if filter == "" {
+				continue
+			}
+			token := client.Subscribe(filter, qos, b.handler(filter))
+			token.Wait()
+			if err := token.Error(); err != nil {
+				logger.Error("Ошибка подписки MQTT", "topic", filter, "error", err)
+				continue
+			}
+			logger.Info("Подписка MQTT", "topic", filter, "qos", qos)
+		}
+	})
+
+	client := mqtt.NewClient(opts)
+	_, err = startup.Wait(context.Background(), logger, "mqtt", backoff,
+		func(context.Context) (struct{}, error) {
+			token := client.Connect()
+			token.Wait()
+			return struct{}{}, token.Error()
+		})
+	if err != nil {
+		logger.Error("Ошибка подключения к MQTT", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "mqtt", func() { client.Disconnect(250) })
+
+	runCtx, stop := context.WithCancel(context.Background())
+	shutdown.RegisterFunc(lifecycle.PhaseStopIntake, "health", stop)
+	checks := health.New("mqttbridge", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	checks.Register("kafka", health.TCPCheck(cfg.KafkaBrokers[0]))
+	checks.Register("mqtt", func(context.Context) error {
+		if !client.IsConnectionOpen() {
+			return errors.New("нет соединения с MQTT брокером")
+		}
+		return nil
+	})
+	metricsProvider.Serve(runCtx, cfg.MetricsAddr, logger, map[string]http.Handler{
+		"/health": checks.Handler(),
+		"/livez":  checks.LiveHandler(),
+		"/readyz": checks.ReadyHandler(),
+	})
+	debugserver.Serve(runCtx, cfg, "mqttbridge", logger)
+
+	shutdown.Wait(runCtx)
+	logger.Info("Остановка сервиса...")
+	shutdown.Shutdown()
+}
+
+// bridge переносит замеры станций из MQTT в Kafka
+type bridge struct {
+	logger   *slog.Logger
+	producer sarama.SyncProducer
+	reporter errreport.Reporter
+	faults   *chaos.Injector
+	received metric.Int64Counter
+}
+
+func (b *bridge) handler(filter string) mqtt.MessageHandler {
+	return func(_ mqtt.Client, msg mqtt.Message) {
+		ctx := context.Background()
+		result := "ok"
+		if err := b.handle(ctx, filter, msg); err != nil {
+			result = "rejected"
+			b.logger.WarnContext(ctx, "Сообщение станции отклонено", "topic", msg.Topic(), "error", err)
+		}
+		b.received.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+	}
+}
+
+func (b *bridge) handle(ctx context.Context, filter string, msg mqtt.Message) error {
+	data, err := station.Parse(station.IDFromTopic(filter, msg.Topic()), msg.Payload(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	event, err := model.NewEvent(model.EventWeatherObserved, eventSource, data.Timestamp, data)
+	if err != nil {
+		return err
+	}
+	bytes, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	kafkaMsg := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(bytes),
+	}
+
+	spanCtx, span := tracer.Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("weather.city", data.City),
+			attribute.String("weather.provider", data.Provider),
+		))
+	defer span.End()
+	tracing.InjectKafka(spanCtx, kafkaMsg)
+
+	err = b.faults.Inject(spanCtx, "kafka.publish")
+	if err == nil {
+		_, _, err = b.producer.SendMessage(kafkaMsg)
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		b.reporter.CaptureError(spanCtx, err, map[string]string{"provider": data.Provider, "stage": "publish"})
+		return fmt.Errorf("ошибка отправки в Kafka: %w", err)
+	}
+
+	b.logger.DebugContext(spanCtx, "Замер станции отправлен", "city", data.City, "provider", data.Provider)
+	return nil
+}
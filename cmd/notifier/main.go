@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/notify"
+	"github.com/gometeo/app/internal/startup"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const consumerGroup = "weather_notifier_group"
+
+var tracer = tracing.Tracer("github.com/gometeo/app/cmd/notifier")
+
+func main() {
+	cfg := config.Load()
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск Weather Notifier...", buildinfo.LogArgs()...)
+
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, "notifier")
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "tracing", shutdownTracing)
+
+	reporter, err := errreport.New(cfg, "notifier", logger)
+	if err != nil {
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "notifier")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	notifier, err := notify.FromConfig(cfg, logger)
+	if err != nil {
+		logger.Error("Ошибка настройки каналов доставки", "error", err)
+		os.Exit(1)
+	}
+
+	// 1. Подключение к Postgres для журнала доставок
+	backoff := startup.BackoffFromConfig(cfg)
+	store, err := startup.Wait(context.Background(), logger, "postgres", backoff,
+		func(context.Context) (*storage.WeatherStorage, error) {
+			return storage.New(cfg.DBDSN, logger)
+		})
+	if err != nil {
+		logger.Error("Не удалось подключиться к БД. Выход.", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, "postgres", store.Close)
+
+	faults := chaos.New(cfg, logger)
+	store.SetFaultInjector(faults)
+
+	// 2. Настройка Kafka Consumer
+	config := sarama.NewConfig()
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	// TLS и SASL для управляемой Kafka
+	if err := bus.Secure(config, cfg); err != nil {
+		logger.Error("Ошибка настройки подключения к Kafka", "error", err)
+		os.Exit(1)
+	}
+
+	consumer, err := startup.Wait(context.Background(), logger, "kafka", backoff,
+		func(context.Context) (sarama.ConsumerGroup, error) {
+			return sarama.NewConsumerGroup(cfg.KafkaBrokers, consumerGroup, config)
+		})
+	if err != nil {
+		logger.Error("Ошибка создания Kafka consumer", "error", err)
+		os.Exit(1)
+	}
+
+	// 3. Запуск цикла чтения
+	ctx, cancel := context.WithCancel(context.Background())
+	checks := health.New("notifier", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	checks.Register("database", store.Ping)
+	checks.Register("kafka", health.TCPCheck(cfg.KafkaBrokers[0]))
+	metricsProvider.Serve(ctx, cfg.MetricsAddr, logger, map[string]http.Handler{
+		"/health": checks.Handler(),
+		"/livez":  checks.LiveHandler(),
+		"/readyz": checks.ReadyHandler(),
+	})
+	debugserver.Serve(ctx, cfg, "notifier", logger)
+
+	delivered, _ := metrics.Meter("github.com/gometeo/app/cmd/notifier").Int64Counter(
+		"notifier.deliveries",
+		metric.WithDescription("Количество доставок оповещений по каналу и статусу"))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler := &alertHandler{
+			logger:    logger,
+			store:     store,
+			notifier:  notifier,
+			reporter:  reporter,
+			faults:    faults,
+			delivered: delivered,
+		}
+		for {
+			if err := consumer.Consume(ctx, []string{cfg.KafkaAlertsTopic}, handler); err != nil {
+				logger.Error("Ошибка при чтении Kafka", "error", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	// 4. Graceful Shutdown: дожидаемся текущих доставок, потом закрываем группу
+	shutdown.Register(lifecycle.PhaseDrain, "kafka", func(shutdownCtx context.Context) error {
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+			logger.Warn("Не дождались завершения доставки оповещений")
+		}
+		return consumer.Close()
+	})
+
+	shutdown.Wait(context.Background())
+	logger.Info("Остановка сервиса...")
+	shutdown.Shutdown()
+}
+
+// alertHandler доставляет события alert.triggered и пишет журнал доставок
+type alertHandler struct {
+	logger    *slog.Logger
+	store     *storage.WeatherStorage
+	notifier  *notify.Notifier
+	reporter  errreport.Reporter
+	faults    *chaos.Injector
+	delivered metric.Int64Counter
+}
+
+func (h *alertHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (h *alertHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+
+func (h *alertHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		if h.handleMessage(sess.Context(), msg) {
+			sess.MarkMessage(msg, "")
+		}
+	}
+	return nil
+}
+
+// handleMessage возвращает true, если смещение можно зафиксировать.
+// Неудачная доставка фиксируется в журнале и не повторяется через Kafka,
+// чтобы не рассылать дубли в уже сработавшие каналы.
+func (h *alertHandler) handleMessage(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	ctx = tracing.ExtractKafka(ctx, msg)
+	ctx, span := tracer.Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.kafka.partition", int(msg.Partition)),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		))
+	defer span.End()
+
+	if err := h.faults.Inject(ctx, "kafka.consume"); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Ошибка чтения сообщения", "offset", msg.Offset, "error", err)
+		return false
+	}
+
+	event, err := model.UnmarshalEvent(msg.Value)
+	if err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Битый JSON", "offset", msg.Offset, "error", err)
+		return true
+	}
+	if event.Type != model.EventAlertTriggered {
+		h.logger.WarnContext(ctx, "Неизвестный тип события", "type", event.Type)
+		return true
+	}
+
+	var alert model.AlertEvent
+	if err := event.DecodePayload(&alert); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Битый JSON", "offset", msg.Offset, "error", err)
+		return true
+	}
+	span.SetAttributes(
+		attribute.String("weather.city", alert.City),
+		attribute.Int64("alert.rule_id", alert.RuleID),
+	)
+
+	for _, d := range h.notifier.Deliver(ctx, alert) {
+		h.delivered.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("channel", d.Channel),
+			attribute.String("status", string(d.Status)),
+		))
+		if d.Status == model.DeliveryFailed {
+			h.reporter.CaptureError(ctx, fmt.Errorf("доставка в %s не удалась: %s", d.Channel, d.LastError), map[string]string{
+				"city":    d.City,
+				"channel": d.Channel,
+				"stage":   "deliver",
+			})
+		}
+
+		if _, err := h.store.SaveDelivery(ctx, d); err != nil {
+			tracing.RecordError(span, err)
+			h.logger.ErrorContext(ctx, "Ошибка записи журнала доставки",
+				"city", d.City, "channel", d.Channel, "error", err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/startup"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/gometeo/app/cmd/replicator")
+
+// Репликатор работает в резервном регионе: читает weather.upserted из Kafka
+// основного региона и применяет их к локальной БД (DB_DSN).
+func main() {
+	cfg := config.Load()
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск Weather Replicator...", buildinfo.LogArgs()...)
+
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, "replicator")
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "tracing", shutdownTracing)
+
+	reporter, err := errreport.New(cfg, "replicator", logger)
+	if err != nil {
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "replicator")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	// 1. Подключение к Postgres резервного региона
+	backoff := startup.BackoffFromConfig(cfg)
+	store, err := startup.Wait(context.Background(), logger, "postgres", backoff,
+		func(context.Context) (*storage.WeatherStorage, error) {
+			return storage.New(cfg.DBDSN, logger)
+		})
+	if err != nil {
+		logger.Error("Не удалось подключиться к БД. Выход.", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, "postgres", store.Close)
+
+	faults := chaos.New(cfg, logger)
+	store.SetFaultInjector(faults)
+
+	// 2. Kafka основного региона; группа своя для каждого резервного региона
+	config := sarama.NewConfig()
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	// TLS и SASL для управляемой Kafka основного региона
+	if err := bus.Secure(config, cfg); err != nil {
+		logger.Error("Ошибка настройки подключения к Kafka", "error", err)
+		os.Exit(1)
+	}
+
+	consumerGroup := "weather_replicator_" + cfg.Region
+	consumer, err := startup.Wait(context.Background(), logger, "kafka", backoff,
+		func(context.Context) (sarama.ConsumerGroup, error) {
+			return sarama.NewConsumerGroup(cfg.ReplicationBrokers, consumerGroup, config)
+		})
+	if err != nil {
+		logger.Error("Ошибка создания Kafka consumer", "error", err)
+		os.Exit(1)
+	}
+
+	// 3. Запуск цикла чтения
+	ctx, cancel := context.WithCancel(context.Background())
+	checks := health.New("replicator", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	checks.Register("database", store.Ping)
+	if len(cfg.ReplicationBrokers) > 0 {
+		checks.Register("kafka", health.TCPCheck(cfg.ReplicationBrokers[0]))
+	}
+	metricsProvider.Serve(ctx, cfg.MetricsAddr, logger, map[string]http.Handler{
+		"/health": checks.Handler(),
+		"/livez":  checks.LiveHandler(),
+		"/readyz": checks.ReadyHandler(),
+	})
+	debugserver.Serve(ctx, cfg, "replicator", logger)
+
+	meter := metrics.Meter("github.com/gometeo/app/cmd/replicator")
+	applied, _ := meter.Int64Counter("replicator.events",
+		metric.WithDescription("Количество событий репликации по результату"))
+	lag, _ := meter.Float64Histogram("replicator.lag",
+		metric.WithDescription("Задержка применения обновления относительно записи в основном регионе"),
+		metric.WithUnit("s"))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler := &replicaHandler{
+			logger:   logger,
+			store:    store,
+			reporter: reporter,
+			faults:   faults,
+			applied:  applied,
+			lag:      lag,
+		}
+		for {
+			if err := consumer.Consume(ctx, []string{cfg.KafkaReplicationTopic}, handler); err != nil {
+				logger.Error("Ошибка при чтении Kafka", "error", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	// 4. Graceful Shutdown: дожидаемся текущих записей, потом закрываем группу
+	shutdown.Register(lifecycle.PhaseDrain, "kafka", func(shutdownCtx context.Context) error {
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+			logger.Warn("Не дождались завершения репликации")
+		}
+		return consumer.Close()
+	})
+
+	shutdown.Wait(context.Background())
+	logger.Info("Остановка сервиса...")
+	shutdown.Shutdown()
+}
+
+// replicaHandler применяет события weather.upserted к локальной БД
+type replicaHandler struct {
+	logger   *slog.Logger
+	store    *storage.WeatherStorage
+	reporter errreport.Reporter
+	faults   *chaos.Injector
+	applied  metric.Int64Counter
+	lag      metric.Float64Histogram
+}
+
+func (h *replicaHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (h *replicaHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+
+func (h *replicaHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		result := h.handleMessage(sess.Context(), msg)
+		h.applied.Add(sess.Context(), 1, metric.WithAttributes(attribute.String("result", result)))
+		if result != "failed" {
+			sess.MarkMessage(msg, "")
+		}
+	}
+	return nil
+}
+
+// handleMessage возвращает результат: applied, stale (есть более свежие данные),
+// skipped (битое, невалидное или чужое сообщение) или failed (повторить)
+func (h *replicaHandler) handleMessage(ctx context.Context, msg *sarama.ConsumerMessage) string {
+	ctx = tracing.ExtractKafka(ctx, msg)
+	ctx, span := tracer.Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.kafka.partition", int(msg.Partition)),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		))
+	defer span.End()
+
+	if err := h.faults.Inject(ctx, "kafka.consume"); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Ошибка чтения сообщения", "offset", msg.Offset, "error", err)
+		return "failed"
+	}
+
+	event, err := model.UnmarshalEvent(msg.Value)
+	if err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Битый JSON", "offset", msg.Offset, "error", err)
+		return "skipped"
+	}
+	if event.Type != model.EventWeatherUpserted {
+		h.logger.WarnContext(ctx, "Неизвестный тип события", "type", event.Type)
+		return "skipped"
+	}
+
+	var data model.WeatherData
+	if err := event.DecodePayload(&data); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Битый JSON", "offset", msg.Offset, "error", err)
+		return "skipped"
+	}
+	span.SetAttributes(attribute.String("weather.city", data.City))
+
+	// Основной регион проверяет замеры, но реплика не доверяет топику и
+	// проверяет их так же, как агрегатор
+	data.NormalizeCondition()
+	if err := data.Validate(); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Невалидные данные", "city", data.City, "offset", msg.Offset, "error", err)
+		return "skipped"
+	}
+
+	applied, err := h.store.ApplyReplicated(ctx, data)
+	if err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Ошибка записи реплики", "city", data.City, "error", err)
+		h.reporter.CaptureError(ctx, err, map[string]string{"city": data.City, "stage": "replicate"})
+		return "failed"
+	}
+	if !applied {
+		h.logger.DebugContext(ctx, "Устаревшее событие пропущено", "city", data.City)
+		return "stale"
+	}
+
+	h.lag.Record(ctx, time.Since(data.Timestamp).Seconds())
+	h.logger.DebugContext(ctx, "Реплика обновлена", "city", data.City, "updated_at", data.Timestamp)
+	return "applied"
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/debugserver"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/lifecycle"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/startup"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const eventSource = "scheduler"
+
+var tracer = tracing.Tracer("github.com/gometeo/app/cmd/scheduler")
+
+func main() {
+	cfg := config.Load()
+	logger := logging.FromConfig(cfg)
+	logger.Info("Запуск Weather Scheduler...", buildinfo.LogArgs()...)
+
+	shutdown := lifecycle.New(logger, cfg.ShutdownTimeout)
+	shutdown.SetPhaseTimeouts(cfg.ShutdownPhaseTimeouts)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, "scheduler")
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "tracing", shutdownTracing)
+
+	reporter, err := errreport.New(cfg, "scheduler", logger)
+	if err != nil {
+		logger.Error("Ошибка настройки отправки ошибок", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseFlush, "errreport", func() { reporter.Flush(2 * time.Second) })
+
+	metricsProvider, err := metrics.Setup(context.Background(), cfg, "scheduler")
+	if err != nil {
+		logger.Error("Ошибка настройки метрик", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "metrics", metricsProvider.Shutdown)
+
+	// 1. Подключение к Postgres: расписания городов
+	backoff := startup.BackoffFromConfig(cfg)
+	store, err := startup.Wait(context.Background(), logger, "postgres", backoff,
+		func(context.Context) (*storage.WeatherStorage, error) {
+			return storage.New(cfg.DBDSN, logger)
+		})
+	if err != nil {
+		logger.Error("Не удалось подключиться к БД. Выход.", "error", err)
+		os.Exit(1)
+	}
+	shutdown.RegisterFunc(lifecycle.PhaseCloseStorage, "postgres", store.Close)
+
+	faults := chaos.New(cfg, logger)
+	store.SetFaultInjector(faults)
+
+	// 2. Kafka Producer для команд
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	// TLS и SASL для управляемой Kafka
+	if err := bus.Secure(config, cfg); err != nil {
+		logger.Error("Ошибка настройки подключения к Kafka", "error", err)
+		os.Exit(1)
+	}
+
+	producer, err := startup.Wait(context.Background(), logger, "kafka", backoff,
+		func(context.Context) (sarama.SyncProducer, error) {
+			return sarama.NewSyncProducer(cfg.KafkaBrokers, config)
+		})
+	if err != nil {
+		logger.Error("Ошибка подключения к Kafka", "error", err)
+		os.Exit(1)
+	}
+	shutdown.Register(lifecycle.PhaseFlush, "kafka-producer", func(context.Context) error {
+		return producer.Close()
+	})
+
+	runCtx, stop := context.WithCancel(context.Background())
+	checks := health.New("scheduler", cfg.HealthCheckTimeout, cfg.HealthCacheTTL)
+	checks.SetOptional(cfg.HealthOptional)
+	checks.Register("database", store.Ping)
+	checks.Register("kafka", health.TCPCheck(cfg.KafkaBrokers[0]))
+	metricsProvider.Serve(runCtx, cfg.MetricsAddr, logger, map[string]http.Handler{
+		"/health": checks.Handler(),
+		"/livez":  checks.LiveHandler(),
+		"/readyz": checks.ReadyHandler(),
+	})
+	debugserver.Serve(runCtx, cfg, "scheduler", logger)
+
+	requested, _ := metrics.Meter("github.com/gometeo/app/cmd/scheduler").Int64Counter(
+		"scheduler.fetch.requested",
+		metric.WithDescription("Количество отправленных команд на сбор по результату"))
+
+	// 3. Цикл планирования
+	s := &scheduler{
+		logger:    logger,
+		store:     store,
+		producer:  producer,
+		reporter:  reporter,
+		faults:    faults,
+		requested: requested,
+		topic:     cfg.KafkaFetchTopic,
+		tick:      cfg.SchedulerTick,
+		refresh:   cfg.SchedulerRefresh,
+	}
+
+	schedulerDone := make(chan struct{})
+	shutdown.Register(lifecycle.PhaseStopIntake, "scheduler-loop", func(ctx context.Context) error {
+		stop()
+		select {
+		case <-schedulerDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	go func() {
+		defer close(schedulerDone)
+		s.run(runCtx)
+	}()
+
+	shutdown.Wait(runCtx)
+	logger.Info("Остановка сервиса...")
+	shutdown.Shutdown()
+}
+
+// scheduler по расписаниям из БД отправляет воркерам команды fetch.requested
+type scheduler struct {
+	logger    *slog.Logger
+	store     *storage.WeatherStorage
+	producer  sarama.SyncProducer
+	reporter  errreport.Reporter
+	faults    *chaos.Injector
+	requested metric.Int64Counter
+	topic     string
+	tick      time.Duration
+	refresh   time.Duration
+
+	schedules []model.CitySchedule
+	loadedAt  time.Time
+}
+
+func (s *scheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	s.logger.InfoContext(ctx, "Планировщик запущен", "topic", s.topic, "tick", s.tick.String())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if now.Sub(s.loadedAt) >= s.refresh {
+				s.reload(ctx, now)
+			}
+			for i := range s.schedules {
+				if s.schedules[i].Due(now) {
+					s.request(ctx, &s.schedules[i], now)
+				}
+			}
+		}
+	}
+}
+
+// reload перечитывает расписания; при ошибке остаются прежние
+func (s *scheduler) reload(ctx context.Context, now time.Time) {
+	schedules, err := s.store.ListSchedules(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Не удалось загрузить расписания", "error", err)
+		return
+	}
+	s.schedules = schedules
+	s.loadedAt = now
+	s.logger.DebugContext(ctx, "Расписания загружены", "count", len(schedules))
+}
+
+// request публикует команду для города и сдвигает его расписание
+func (s *scheduler) request(ctx context.Context, sc *model.CitySchedule, now time.Time) {
+	// Идентификатор команды продолжится в замере коллектора
+	ctx, _ = logging.EnsureRequestID(ctx)
+	event, err := model.NewEvent(model.EventFetchRequested, eventSource, now, sc.FetchRequest(now))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Ошибка JSON", "error", err)
+		return
+	}
+	bytes, err := event.Marshal()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Ошибка JSON", "error", err)
+		return
+	}
+
+	// Ключ по городу: команды одного города попадают в одну партицию и к одному воркеру
+	msg := &sarama.ProducerMessage{
+		Topic: s.topic,
+		Key:   sarama.StringEncoder(sc.City),
+		Value: sarama.ByteEncoder(bytes),
+	}
+
+	spanCtx, span := tracer.Start(ctx, s.topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", s.topic),
+			attribute.String("weather.city", sc.City),
+		))
+	tracing.InjectKafka(spanCtx, msg)
+
+	err = s.faults.Inject(spanCtx, "kafka.publish")
+	if err == nil {
+		_, _, err = s.producer.SendMessage(msg)
+	}
+	tracing.RecordError(span, err)
+	span.End()
+
+	result := "ok"
+	if err != nil {
+		result = "failed"
+	}
+	s.requested.Add(spanCtx, 1, metric.WithAttributes(attribute.String("result", result)))
+
+	if err != nil {
+		s.logger.ErrorContext(spanCtx, "Не удалось отправить команду", "city", sc.City, "error", err)
+		s.reporter.CaptureError(spanCtx, err, map[string]string{"city": sc.City, "stage": "publish"})
+		return
+	}
+
+	sc.LastRequestedAt = &now
+	if err := s.store.MarkRequested(spanCtx, sc.City, now); err != nil {
+		s.logger.WarnContext(spanCtx, "Не удалось сохранить время запроса", "city", sc.City, "error", err)
+	}
+	s.logger.DebugContext(spanCtx, "Команда на сбор отправлена", "city", sc.City)
+}
@@ -0,0 +1,57 @@
+// Package account отвечает за API-ключи внешних потребителей: поиск аккаунта
+// по ключу, учет запросов в Redis с периодическим сохранением в Postgres
+// и проверку месячной квоты.
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// HeaderAPIKey — заголовок, в котором клиент передает ключ
+const HeaderAPIKey = "X-API-Key"
+
+// keyPrefix помогает узнать ключ GoMeteo в логах и секретах
+const keyPrefix = "gm_"
+
+// GenerateKey создает новый случайный ключ; в БД хранится только его хэш
+func GenerateKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashKey — хэш ключа для хранения и поиска
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Month — расчетный месяц (UTC) в формате YYYY-MM
+func Month(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// MonthReset — начало следующего расчетного месяца
+func MonthReset(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+type ctxKey struct{}
+
+// WithAccount сохраняет аккаунт вызывающего в контексте запроса
+func WithAccount(ctx context.Context, a *Caller) context.Context {
+	return context.WithValue(ctx, ctxKey{}, a)
+}
+
+// FromContext возвращает аккаунт вызывающего, nil для анонимного запроса
+func FromContext(ctx context.Context) *Caller {
+	a, _ := ctx.Value(ctxKey{}).(*Caller)
+	return a
+}
@@ -0,0 +1,75 @@
+package account
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/config"
+)
+
+// AnonymousPolicy — что доступно без API-ключа: только чтение разрешенных
+// маршрутов с ограничением частоты по адресу клиента
+type AnonymousPolicy struct {
+	routes    map[string]bool
+	all       bool
+	perMinute int
+}
+
+// PolicyFromConfig собирает политику анонимного доступа из настроек
+func PolicyFromConfig(cfg *config.Config) AnonymousPolicy {
+	p := AnonymousPolicy{routes: make(map[string]bool), perMinute: cfg.AnonymousRateLimit}
+	for _, route := range cfg.AnonymousRoutes {
+		switch route = strings.TrimSpace(route); route {
+		case "":
+		case "*":
+			p.all = true
+		default:
+			p.routes[route] = true
+		}
+	}
+	return p
+}
+
+// Allows сообщает, доступен ли маршрут (шаблон пути mux) анонимно.
+// Маршруты, которым нужна роль выше читателя, анонимно недоступны даже с "*".
+func (p AnonymousPolicy) Allows(method, route string) bool {
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	if auth.RequiredRole(method, route) != auth.RoleReader {
+		return false
+	}
+	return p.all || p.routes[route]
+}
+
+// RateLimit — запросов в минуту с одного адреса, 0 — без ограничения
+func (p AnonymousPolicy) RateLimit() int {
+	return p.perMinute
+}
+
+// Anonymous возвращает политику анонимного доступа
+func (s *Service) Anonymous() AnonymousPolicy {
+	return s.anonymous
+}
+
+// CountAnonymous учитывает анонимный запрос с адреса client в минутном окне
+// и возвращает число запросов в окне вместе с текущим и время сброса окна
+func (s *Service) CountAnonymous(ctx context.Context, client string, now time.Time) (int64, time.Time, error) {
+	window := now.UTC().Truncate(time.Minute)
+	reset := window.Add(time.Minute)
+	n, err := s.cache.IncrCounter(ctx, cache.AnonymousKey(client, window.Format("200601021504")), time.Minute)
+	if err != nil {
+		s.record(ctx, "anonymous_error")
+		return 0, reset, err
+	}
+	if s.anonymous.perMinute > 0 && n > int64(s.anonymous.perMinute) {
+		s.record(ctx, "anonymous_limited")
+	} else {
+		s.record(ctx, "anonymous")
+	}
+	return n, reset, nil
+}
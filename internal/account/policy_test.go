@@ -0,0 +1,40 @@
+package account_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/config"
+)
+
+func TestAnonymousPolicyAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []string
+		method string
+		route  string
+		want   bool
+	}{
+		{"разрешенный маршрут", []string{"/api/v1/weather/{city}"}, http.MethodGet, "/api/v1/weather/{city}", true},
+		{"HEAD разрешенного маршрута", []string{"/api/v1/weather/{city}"}, http.MethodHead, "/api/v1/weather/{city}", true},
+		{"маршрут не в списке", []string{"/api/v1/weather/{city}"}, http.MethodGet, "/api/v1/cities", false},
+		{"запись разрешенного маршрута", []string{"/api/v1/weather/{city}"}, http.MethodPut, "/api/v1/weather/{city}", false},
+		{"звездочка открывает чтение", []string{"*"}, http.MethodGet, "/api/v1/forecast/{city}", true},
+		{"звездочка не открывает запись", []string{"*"}, http.MethodDelete, "/api/v1/weather/{city}", false},
+		{"звездочка не открывает админку", []string{"*"}, http.MethodGet, "/api/v1/admin/cities", false},
+		{"звездочка не открывает ключи", []string{"*"}, http.MethodGet, "/api/v1/keys", false},
+		{"звездочка не открывает вебхуки", []string{"*"}, http.MethodGet, "/api/v1/webhooks/{id}", false},
+		{"админка в списке явно", []string{"/api/v1/admin/cities"}, http.MethodGet, "/api/v1/admin/cities", false},
+		{"пустой список", nil, http.MethodGet, "/api/v1/weather/{city}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Load()
+			cfg.AnonymousRoutes = tt.routes
+			if got := account.PolicyFromConfig(cfg).Allows(tt.method, tt.route); got != tt.want {
+				t.Errorf("Allows(%s, %s) = %v, ожидалось %v", tt.method, tt.route, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	// ErrUnknownKey — ключ не найден
+	ErrUnknownKey = errors.New("неизвестный API-ключ")
+	// ErrSuspended — доступ аккаунта приостановлен
+	ErrSuspended = errors.New("доступ аккаунта приостановлен")
+	// ErrQuotaExceeded — месячная квота исчерпана
+	ErrQuotaExceeded = errors.New("месячная квота исчерпана")
+	// ErrUnavailable — хранилище аккаунтов недоступно
+	ErrUnavailable = errors.New("хранилище аккаунтов недоступно")
+)
+
+// Caller — аккаунт вызывающего, его настройки и расход квоты с учетом текущего запроса
+type Caller struct {
+	model.Account
+	Preferences model.Preferences
+	Requests    int64
+}
+
+// Store — операции с БД, нужные учету аккаунтов; реализуется storage.WeatherStorage
+type Store interface {
+	GetAccountByKeyHash(ctx context.Context, keyHash string) (*model.Account, error)
+	GetAccountByID(ctx context.Context, id int64) (*model.Account, error)
+	GetPreferences(ctx context.Context, accountID int64) (model.Preferences, error)
+	GetUsage(ctx context.Context, accountID int64, month string) (int64, error)
+	SaveUsage(ctx context.Context, accountID int64, month string, requests int64) error
+}
+
+var _ Store = (*storage.WeatherStorage)(nil)
+
+// storeRef — обертка над Store для atomic.Pointer
+type storeRef struct {
+	Store
+}
+
+// Service находит аккаунты по ключу и считает запросы
+type Service struct {
+	store    atomic.Pointer[storeRef] // nil, пока БД недоступна
+	cache    *cache.WeatherCache
+	logger   *slog.Logger
+	memoTTL  time.Duration
+	required bool
+
+	anonymous AnonymousPolicy
+
+	mu    sync.Mutex
+	memo  map[string]memoEntry    // по хэшу ключа или "id:N" для аккаунтов пользователей с токеном
+	dirty map[string]usageCounter // по ключу Redis: счетчики, ждущие сохранения
+
+	requests metric.Int64Counter
+}
+
+type memoEntry struct {
+	account   *model.Account // nil — ключ неизвестен
+	prefs     model.Preferences
+	expiresAt time.Time
+}
+
+type usageCounter struct {
+	accountID int64
+	month     string
+}
+
+func New(cfg *config.Config, c *cache.WeatherCache, logger *slog.Logger) *Service {
+	requests, _ := metrics.Meter("github.com/gometeo/app/internal/account").Int64Counter(
+		"account.requests",
+		metric.WithDescription("Количество запросов с API-ключом по результату проверки"))
+
+	return &Service{
+		cache:    c,
+		logger:   logger,
+		memoTTL:  cfg.APIKeyCacheTTL,
+		required: cfg.APIKeyRequired,
+		memo:     make(map[string]memoEntry),
+		dirty:    make(map[string]usageCounter),
+		requests: requests,
+
+		anonymous: PolicyFromConfig(cfg),
+	}
+}
+
+// SetStore подключает хранилище аккаунтов
+func (s *Service) SetStore(store Store) {
+	s.store.Store(&storeRef{store})
+}
+
+// Required — запросы без ключа отклоняются
+func (s *Service) Required() bool {
+	return s.required
+}
+
+// Lookup находит аккаунт по ключу. Результат, включая промах, кэшируется в памяти,
+// чтобы не обращаться к БД на каждый запрос.
+func (s *Service) Lookup(ctx context.Context, key string) (*model.Account, error) {
+	entry, err := s.lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return entry.account, nil
+}
+
+// lookup находит аккаунт по ключу и его настройки, кэшируя результат в памяти
+func (s *Service) lookup(ctx context.Context, key string) (memoEntry, error) {
+	hash := HashKey(key)
+	return s.lookupBy(ctx, hash, func(store Store) (*model.Account, error) {
+		return store.GetAccountByKeyHash(ctx, hash)
+	})
+}
+
+// lookupBy находит аккаунт через fetch, кэшируя результат под memoKey
+func (s *Service) lookupBy(ctx context.Context, memoKey string, fetch func(Store) (*model.Account, error)) (memoEntry, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.memo[memoKey]
+	s.mu.Unlock()
+	if !ok || now.After(entry.expiresAt) {
+		store := s.store.Load()
+		if store == nil {
+			return memoEntry{}, ErrUnavailable
+		}
+		account, err := fetch(store)
+		if err != nil {
+			return memoEntry{}, err
+		}
+		entry = memoEntry{account: account, prefs: model.DefaultPreferences(), expiresAt: now.Add(s.memoTTL)}
+		if account != nil {
+			// Без настроек запрос обслуживается со значениями по умолчанию
+			if entry.prefs, err = store.GetPreferences(ctx, account.ID); err != nil {
+				s.logger.WarnContext(ctx, "Не удалось получить настройки аккаунта", "account", account.Name, "error", err)
+				entry.prefs = model.DefaultPreferences()
+			}
+		}
+		s.mu.Lock()
+		s.memo[memoKey] = entry
+		s.mu.Unlock()
+	}
+
+	if entry.account == nil {
+		return memoEntry{}, ErrUnknownKey
+	}
+	return entry, nil
+}
+
+// Forget сбрасывает запомненный аккаунт ключа, чтобы изменения применились сразу
+func (s *Service) Forget(key string) {
+	s.mu.Lock()
+	delete(s.memo, HashKey(key))
+	s.mu.Unlock()
+}
+
+// Authorize проверяет ключ, учитывает запрос и проверяет квоту.
+// Запрос сверх квоты тоже учитывается: расход показывает реальную нагрузку.
+func (s *Service) Authorize(ctx context.Context, key string, now time.Time) (*Caller, error) {
+	entry, err := s.lookup(ctx, key)
+	return s.authorize(ctx, entry, err, now)
+}
+
+// AuthorizeAccount — то же, что Authorize, для аккаунта пользователя с токеном
+func (s *Service) AuthorizeAccount(ctx context.Context, id int64, now time.Time) (*Caller, error) {
+	entry, err := s.lookupBy(ctx, "id:"+strconv.FormatInt(id, 10), func(store Store) (*model.Account, error) {
+		return store.GetAccountByID(ctx, id)
+	})
+	return s.authorize(ctx, entry, err, now)
+}
+
+// authorize учитывает запрос найденного аккаунта и проверяет квоту
+func (s *Service) authorize(ctx context.Context, entry memoEntry, err error, now time.Time) (*Caller, error) {
+	if err != nil {
+		s.record(ctx, resultFor(err))
+		return nil, err
+	}
+	account := entry.account
+	caller := &Caller{Account: *account, Preferences: entry.prefs}
+	if !account.Active {
+		s.record(ctx, "suspended")
+		return caller, ErrSuspended
+	}
+
+	caller.Requests, err = s.count(ctx, account.ID, Month(now))
+	if err != nil {
+		// Учет не должен ронять API: при недоступности Redis запрос пропускается
+		s.logger.WarnContext(ctx, "Не удалось учесть запрос", "account", account.Name, "error", err)
+	}
+	if account.MonthlyQuota > 0 && caller.Requests > account.MonthlyQuota {
+		s.record(ctx, "quota_exceeded")
+		return caller, ErrQuotaExceeded
+	}
+	s.record(ctx, "ok")
+	return caller, nil
+}
+
+// count увеличивает счетчик месяца. Первый запрос месяца после потери
+// ключа в Redis поднимает счетчик до значения из БД.
+func (s *Service) count(ctx context.Context, accountID int64, month string) (int64, error) {
+	key := cache.UsageKey(accountID, month)
+	n, err := s.cache.IncrUsage(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.dirty[key] = usageCounter{accountID: accountID, month: month}
+	s.mu.Unlock()
+
+	if n == 1 {
+		if store := s.store.Load(); store != nil {
+			persisted, err := store.GetUsage(ctx, accountID, month)
+			if err != nil {
+				return n, err
+			}
+			if persisted > 0 {
+				return s.cache.SeedUsage(ctx, key, persisted+1)
+			}
+		}
+	}
+	return n, nil
+}
+
+// Usage возвращает расход квоты за текущий месяц без учета запроса
+func (s *Service) Usage(ctx context.Context, caller *Caller, now time.Time) (model.UsageResponse, error) {
+	month := Month(now)
+	requests, err := s.cache.GetUsage(ctx, cache.UsageKey(caller.ID, month))
+	if err != nil {
+		return model.UsageResponse{}, err
+	}
+	// Счетчик в Redis мог пропасть: берем большее из двух значений
+	if store := s.store.Load(); store != nil {
+		persisted, err := store.GetUsage(ctx, caller.ID, month)
+		if err != nil {
+			return model.UsageResponse{}, err
+		}
+		requests = max(requests, persisted)
+	}
+
+	remaining := int64(-1)
+	if caller.MonthlyQuota > 0 {
+		remaining = max(caller.MonthlyQuota-requests, 0)
+	}
+	return model.UsageResponse{
+		Account:   caller.Name,
+		Plan:      caller.Plan,
+		Month:     month,
+		Requests:  requests,
+		Quota:     caller.MonthlyQuota,
+		Remaining: remaining,
+		ResetAt:   MonthReset(now),
+	}, nil
+}
+
+// Flush сохраняет в БД счетчики, изменившиеся с прошлого сохранения
+func (s *Service) Flush(ctx context.Context) error {
+	store := s.store.Load()
+	if store == nil {
+		return ErrUnavailable
+	}
+
+	s.mu.Lock()
+	pending := s.dirty
+	s.dirty = make(map[string]usageCounter)
+	s.mu.Unlock()
+
+	var errs []error
+	for key, c := range pending {
+		n, err := s.cache.GetUsage(ctx, key)
+		if err == nil {
+			err = store.SaveUsage(ctx, c.accountID, c.month, n)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			// Вернем счетчик в очередь, чтобы сохранить при следующем сбросе
+			s.mu.Lock()
+			s.dirty[key] = c
+			s.mu.Unlock()
+		}
+	}
+	if len(pending) > 0 {
+		s.logger.DebugContext(ctx, "Расход API-ключей сохранен", "counters", len(pending), "failed", len(errs))
+	}
+	return errors.Join(errs...)
+}
+
+// Run сохраняет счетчики каждые interval до отмены ctx
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil && !errors.Is(err, ErrUnavailable) {
+				s.logger.WarnContext(ctx, "Ошибка сохранения расхода API-ключей", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) record(ctx context.Context, result string) {
+	s.requests.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+}
+
+func resultFor(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownKey):
+		return "unknown_key"
+	case errors.Is(err, ErrUnavailable):
+		return "unavailable"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,372 @@
+// Package aggregator обрабатывает замеры из топика weather_data: проверяет,
+// сохраняет в Postgres и передает дальше по конвейеру. Используется
+// сервисом cmd/aggregator и монолитом cmd/gometeo.
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/alerts"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/codec"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/dlq"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/nowcast"
+	"github.com/gometeo/app/internal/quality"
+	"github.com/gometeo/app/internal/replication"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/tracing"
+	"github.com/gometeo/app/internal/webhook"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Topic — топик замеров погоды
+const Topic = "weather_data"
+
+var tracer = tracing.Tracer("github.com/gometeo/app/cmd/aggregator")
+
+//go:generate go tool mockgen -destination=../testutil/mocks/aggregator.go -package=mocks -mock_names=Store=MockAggregatorStore . Store
+
+// Store — операции с БД, нужные обработчику и его стадиям;
+// реализуется storage.WeatherStorage
+type Store interface {
+	alerts.Store
+	nowcast.Store
+	quality.Store
+	webhook.Store
+	SaveWithHistory(ctx context.Context, data model.WeatherData) (time.Time, error)
+	SaveAirQuality(ctx context.Context, data model.AirQuality) error
+}
+
+var _ Store = (*storage.WeatherStorage)(nil)
+
+// Handler сохраняет замеры из топика weather_data и запускает
+// необязательные стадии: качество, прогноз, оповещения, webhooks, репликацию и обновление кэша API
+type Handler struct {
+	logger    *slog.Logger
+	store     Store
+	reporter  errreport.Reporter
+	dlq       *dlq.Publisher
+	faults    *chaos.Injector
+	codec     codec.Codec
+	nowcast   *nowcast.Stage
+	quality   *quality.Stage
+	alerts    *alerts.Stage
+	webhooks  *webhook.Dispatcher    // nil — webhooks выключены
+	replicas  *replication.Publisher // nil — репликация выключена
+	updates   *replication.Publisher // nil — обновление кэша API выключено
+	processed metric.Int64Counter
+	panics    metric.Int64Counter
+	lastWrite atomic.Int64 // UnixNano последней успешной записи в БД
+}
+
+// NewHandler создает обработчик; стадии качества, прогноза и оповещений включаются по конфигурации
+func NewHandler(cfg *config.Config, store Store, deadLetters *dlq.Publisher, reporter errreport.Reporter, faults *chaos.Injector, logger *slog.Logger) *Handler {
+	meter := metrics.Meter("github.com/gometeo/app/cmd/aggregator")
+	processed, _ := meter.Int64Counter("aggregator.messages.processed",
+		metric.WithDescription("Количество обработанных сообщений по результату"))
+	panics, _ := meter.Int64Counter("aggregator.panics",
+		metric.WithDescription("Количество паник при обработке сообщений"))
+
+	return &Handler{
+		logger:    logger,
+		store:     store,
+		reporter:  reporter,
+		dlq:       deadLetters,
+		faults:    faults,
+		codec:     codec.JSON{},
+		nowcast:   nowcast.NewStage(cfg, store, logger),
+		quality:   quality.NewStage(cfg, store, logger),
+		alerts:    alerts.NewStage(cfg, store, logger),
+		processed: processed,
+		panics:    panics,
+	}
+}
+
+// SetCodec задает кодек сообщений; читаются сообщения любого формата,
+// Avro и Protobuf — если у кодека есть реестр схем
+func (h *Handler) SetCodec(codec codec.Codec) {
+	h.codec = codec
+}
+
+// SetPublishers включает публикацию сохраненных замеров: replicas — для
+// резервного региона, updates — для кэша экземпляров API. nil выключает публикацию.
+func (h *Handler) SetPublishers(replicas, updates *replication.Publisher) {
+	h.replicas = replicas
+	h.updates = updates
+}
+
+// SetAlertPublisher включает доставку сработавших правил в cmd/notifier.
+// nil — срабатывания только записываются в БД.
+func (h *Handler) SetAlertPublisher(publisher *alerts.Publisher) {
+	h.alerts.SetPublisher(publisher)
+}
+
+// SetWebhooks включает отправку сохраненных замеров на webhooks пользователей
+func (h *Handler) SetWebhooks(webhooks *webhook.Dispatcher) {
+	h.webhooks = webhooks
+}
+
+// LastWrite возвращает время последней успешной записи замера в БД; нулевое — записей еще не было
+func (h *Handler) LastWrite() time.Time {
+	if ns := h.lastWrite.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+func (h *Handler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (h *Handler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+
+func (h *Handler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		result := "failed"
+		if h.safeHandleMessage(sess.Context(), msg) {
+			sess.MarkMessage(msg, "")
+			result = "ok"
+		}
+		h.processed.Add(sess.Context(), 1, metric.WithAttributes(attribute.String("result", result)))
+	}
+	return nil
+}
+
+// safeHandleMessage изолирует панику одного сообщения: оно уходит в DLQ,
+// а чтение партиции продолжается.
+func (h *Handler) safeHandleMessage(ctx context.Context, msg *sarama.ConsumerMessage) (ok bool) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+
+		h.panics.Add(ctx, 1)
+		cause := fmt.Sprint(rec)
+		h.logger.ErrorContext(ctx, "Паника при обработке сообщения",
+			"partition", msg.Partition,
+			"offset", msg.Offset,
+			"panic", cause,
+			"stack", string(debug.Stack()))
+		h.reporter.CapturePanic(ctx, rec, map[string]string{
+			"topic":     msg.Topic,
+			"partition": strconv.Itoa(int(msg.Partition)),
+			"offset":    strconv.FormatInt(msg.Offset, 10),
+		})
+
+		if err := h.dlq.Send(msg, dlq.ReasonPanic, cause); err != nil {
+			h.logger.ErrorContext(ctx, "Не удалось отправить сообщение в DLQ", "offset", msg.Offset, "error", err)
+			ok = false
+			return
+		}
+		ok = true
+	}()
+
+	return h.handleMessage(ctx, msg)
+}
+
+// handleMessage обрабатывает одно сообщение внутри спана, продолжающего трассу коллектора.
+// Возвращает true, если смещение можно зафиксировать.
+func (h *Handler) handleMessage(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	ctx = tracing.ExtractKafka(ctx, msg)
+	ctx, span := tracer.Start(ctx, Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.kafka.partition", int(msg.Partition)),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		))
+	defer span.End()
+
+	if err := h.faults.Inject(ctx, "kafka.consume"); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Ошибка чтения сообщения", "offset", msg.Offset, "error", err)
+		return false
+	}
+
+	_, decodeSpan := tracer.Start(ctx, "decode")
+	event, err := h.codec.Decode(ctx, msg.Topic, msg.Value)
+	if err != nil {
+		tracing.RecordError(decodeSpan, err)
+		decodeSpan.End()
+		h.logger.ErrorContext(ctx, "Битое сообщение", "error", err)
+		return false
+	}
+	switch event.Type {
+	case model.EventWeatherObserved:
+	case model.EventAirQualityObserved:
+		decodeSpan.End()
+		return h.handleAirQuality(ctx, span, event)
+	case model.EventForecastIssued:
+		decodeSpan.End()
+		return h.handleForecast(ctx, span, event)
+	default:
+		decodeSpan.End()
+		h.logger.WarnContext(ctx, "Неизвестный тип события", "type", event.Type)
+		return true
+	}
+
+	var data model.WeatherData
+	err = event.DecodePayload(&data)
+	tracing.RecordError(decodeSpan, err)
+	decodeSpan.End()
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Битый JSON", "error", err)
+		return false
+	}
+	span.SetAttributes(attribute.String("weather.city", data.City))
+
+	_, validateSpan := tracer.Start(ctx, "validate")
+	data.NormalizeCondition()
+	err = data.Validate()
+	tracing.RecordError(validateSpan, err)
+	validateSpan.End()
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Невалидные данные", "city", data.City, "error", err)
+		return false
+	}
+
+	dbCtx, dbSpan := tracer.Start(ctx, "db.save", trace.WithSpanKind(trace.SpanKindClient))
+	// Текущая погода и история пишутся в одной транзакции
+	savedAt, err := h.store.SaveWithHistory(dbCtx, data)
+	tracing.RecordError(dbSpan, err)
+	dbSpan.End()
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка записи в БД", "city", data.City, "error", err)
+		h.reporter.CaptureError(ctx, err, map[string]string{"city": data.City, "stage": "db.save"})
+		return false
+	}
+	h.lastWrite.Store(savedAt.UnixNano())
+
+	h.logger.InfoContext(ctx, "Данные сохранены в БД",
+		"city", data.City,
+		"temp", data.Temp)
+
+	// Отставание реплики догоняется следующим обновлением города, поэтому сообщение не повторяется
+	if err := h.replicas.Publish(ctx, data, savedAt); err != nil {
+		h.logger.WarnContext(ctx, "Ошибка публикации для репликации", "city", data.City, "error", err)
+	}
+	// Без события кэш API обновится по истечении TTL
+	if err := h.updates.Publish(ctx, data, savedAt); err != nil {
+		h.logger.WarnContext(ctx, "Ошибка публикации обновления кэша", "city", data.City, "error", err)
+	}
+	// Доставка идет в фоне; здесь замер только ставится в очередь
+	if err := h.webhooks.Observe(ctx, data); err != nil {
+		h.logger.WarnContext(ctx, "Ошибка постановки webhooks в очередь", "city", data.City, "error", err)
+	}
+
+	// Оценка качества и прогноз не критичны: ошибка не мешает зафиксировать сообщение
+	qualityCtx, qualitySpan := tracer.Start(ctx, "quality")
+	err = h.quality.Observe(qualityCtx, data)
+	tracing.RecordError(qualitySpan, err)
+	qualitySpan.End()
+	if err != nil {
+		h.logger.WarnContext(ctx, "Ошибка оценки качества замера", "city", data.City, "error", err)
+	}
+
+	nowcastCtx, nowcastSpan := tracer.Start(ctx, "nowcast")
+	err = h.nowcast.Observe(nowcastCtx, data)
+	tracing.RecordError(nowcastSpan, err)
+	nowcastSpan.End()
+	if err != nil {
+		h.logger.WarnContext(ctx, "Ошибка построения прогноза", "city", data.City, "error", err)
+	}
+
+	alertsCtx, alertsSpan := tracer.Start(ctx, "alerts")
+	err = h.alerts.Observe(alertsCtx, data)
+	tracing.RecordError(alertsSpan, err)
+	alertsSpan.End()
+	if err != nil {
+		h.logger.WarnContext(ctx, "Ошибка проверки правил оповещений", "city", data.City, "error", err)
+	}
+	return true
+}
+
+// handleAirQuality сохраняет замер качества воздуха и проверяет правила
+// оповещений по AQI. Остальные стадии и публикации конвейера погоды к нему не относятся.
+func (h *Handler) handleAirQuality(ctx context.Context, span trace.Span, event model.Event) bool {
+	var data model.AirQuality
+	if err := event.DecodePayload(&data); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Битый JSON", "error", err)
+		return false
+	}
+	span.SetAttributes(attribute.String("weather.city", data.City))
+
+	data.Normalize()
+	if err := data.Validate(); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Невалидные данные качества воздуха", "city", data.City, "error", err)
+		return false
+	}
+
+	dbCtx, dbSpan := tracer.Start(ctx, "db.save_air_quality", trace.WithSpanKind(trace.SpanKindClient))
+	err := h.store.SaveAirQuality(dbCtx, data)
+	tracing.RecordError(dbSpan, err)
+	dbSpan.End()
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка записи в БД", "city", data.City, "error", err)
+		h.reporter.CaptureError(ctx, err, map[string]string{"city": data.City, "stage": "db.save_air_quality"})
+		return false
+	}
+	h.lastWrite.Store(time.Now().UnixNano())
+
+	h.logger.InfoContext(ctx, "Качество воздуха сохранено в БД", "city", data.City, "aqi", data.AQI)
+
+	alertsCtx, alertsSpan := tracer.Start(ctx, "alerts")
+	err = h.alerts.ObserveAirQuality(alertsCtx, data)
+	tracing.RecordError(alertsSpan, err)
+	alertsSpan.End()
+	if err != nil {
+		h.logger.WarnContext(ctx, "Ошибка проверки правил оповещений", "city", data.City, "error", err)
+	}
+	return true
+}
+
+// handleForecast заменяет прогноз провайдера для города новым выпуском
+func (h *Handler) handleForecast(ctx context.Context, span trace.Span, event model.Event) bool {
+	var batch model.ForecastBatch
+	if err := event.DecodePayload(&batch); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Битый JSON", "error", err)
+		return false
+	}
+	span.SetAttributes(attribute.String("weather.city", batch.City))
+
+	if err := batch.Validate(); err != nil {
+		tracing.RecordError(span, err)
+		h.logger.ErrorContext(ctx, "Невалидный прогноз", "city", batch.City, "provider", batch.Provider, "error", err)
+		return false
+	}
+	for i := range batch.Forecasts {
+		batch.Forecasts[i].City = batch.City
+		batch.Forecasts[i].Provider = batch.Provider
+		if batch.Forecasts[i].IssuedAt.IsZero() {
+			batch.Forecasts[i].IssuedAt = batch.IssuedAt
+		}
+	}
+
+	dbCtx, dbSpan := tracer.Start(ctx, "db.replace_forecasts", trace.WithSpanKind(trace.SpanKindClient))
+	err := h.store.ReplaceForecasts(dbCtx, batch.City, batch.Provider, batch.Forecasts)
+	tracing.RecordError(dbSpan, err)
+	dbSpan.End()
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка записи в БД", "city", batch.City, "error", err)
+		h.reporter.CaptureError(ctx, err, map[string]string{"city": batch.City, "stage": "db.replace_forecasts"})
+		return false
+	}
+
+	h.logger.InfoContext(ctx, "Прогноз сохранен в БД", "city", batch.City, "provider", batch.Provider, "points", len(batch.Forecasts))
+	return true
+}
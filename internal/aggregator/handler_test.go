@@ -0,0 +1,161 @@
+package aggregator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/mock/gomock"
+
+	"github.com/gometeo/app/internal/aggregator"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/dlq"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/replication"
+	"github.com/gometeo/app/internal/testutil"
+	"github.com/gometeo/app/internal/testutil/mocks"
+)
+
+// session и claim передают обработчику одно сообщение; смещения фиксируются в marked
+type session struct {
+	sarama.ConsumerGroupSession
+	marked []int64
+}
+
+func (s *session) Context() context.Context { return context.Background() }
+func (s *session) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type claim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *claim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+// consume прогоняет через обработчик одно сообщение и возвращает зафиксированные смещения
+func consume(t *testing.T, h *aggregator.Handler, msg *sarama.ConsumerMessage) []int64 {
+	t.Helper()
+	c := &claim{messages: make(chan *sarama.ConsumerMessage, 1)}
+	c.messages <- msg
+	close(c.messages)
+	sess := &session{}
+	if err := h.ConsumeClaim(sess, c); err != nil {
+		t.Fatal(err)
+	}
+	return sess.marked
+}
+
+func newHandler(t *testing.T, deadLetters *dlq.Publisher) (*aggregator.Handler, *mocks.MockAggregatorStore) {
+	cfg := config.Load()
+	// Стадии проверены в своих пакетах; здесь только запись и публикации
+	cfg.QualityEnabled = false
+	cfg.NowcastEnabled = false
+	cfg.AlertsEnabled = false
+	store := mocks.NewMockAggregatorStore(gomock.NewController(t))
+	return aggregator.NewHandler(cfg, store, deadLetters, errreport.Nop{}, nil, testutil.Logger(t)), store
+}
+
+func observation(t *testing.T, data model.WeatherData) *sarama.ConsumerMessage {
+	t.Helper()
+	event, err := model.NewEvent(model.EventWeatherObserved, "handler_test", data.Timestamp, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := event.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &sarama.ConsumerMessage{Topic: aggregator.Topic, Offset: 42, Key: []byte(data.City), Value: payload}
+}
+
+var moscow = model.WeatherData{
+	City:          "Moscow",
+	Temp:          -3.5,
+	ConditionCode: model.ConditionSnow,
+	Provider:      "owm",
+	Timestamp:     time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+}
+
+func TestHandlerSavesAndPublishesReplica(t *testing.T) {
+	h, store := newHandler(t, nil)
+	producer := mocks.NewMockPublisher(gomock.NewController(t))
+	h.SetPublishers(replication.NewPublisher(producer, "weather_replica", "test"), nil)
+
+	savedAt := moscow.Timestamp.Add(time.Second)
+	store.EXPECT().SaveWithHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, data model.WeatherData) (time.Time, error) {
+			if data.City != moscow.City || data.Temp != moscow.Temp || data.ConditionCode != moscow.ConditionCode {
+				t.Errorf("сохранен неверный замер: %+v", data)
+			}
+			return savedAt, nil
+		})
+	producer.EXPECT().SendMessage(gomock.Any()).DoAndReturn(
+		func(msg *sarama.ProducerMessage) (int32, int64, error) {
+			if key, _ := msg.Key.Encode(); msg.Topic != "weather_replica" || string(key) != moscow.City {
+				t.Errorf("событие репликации в %s с ключом %q", msg.Topic, key)
+			}
+			return 0, 0, nil
+		})
+
+	if marked := consume(t, h, observation(t, moscow)); len(marked) != 1 {
+		t.Fatalf("зафиксировано %v, ожидалось одно смещение", marked)
+	}
+	if !h.LastWrite().Equal(savedAt) {
+		t.Errorf("время последней записи %s, ожидалось %s", h.LastWrite(), savedAt)
+	}
+}
+
+func TestHandlerKeepsOffsetOnStoreError(t *testing.T) {
+	h, store := newHandler(t, nil)
+
+	store.EXPECT().SaveWithHistory(gomock.Any(), gomock.Any()).Return(time.Time{}, errors.New("соединение разорвано"))
+
+	// Сообщение будет прочитано повторно
+	if marked := consume(t, h, observation(t, moscow)); len(marked) != 0 {
+		t.Fatalf("зафиксировано %v при ошибке записи", marked)
+	}
+}
+
+func TestHandlerRejectsInvalidReading(t *testing.T) {
+	h, _ := newHandler(t, nil)
+
+	invalid := moscow
+	invalid.Temp = 1000
+	// БД не вызывается: gomock провалит тест на неожиданном вызове
+	if marked := consume(t, h, observation(t, invalid)); len(marked) != 0 {
+		t.Fatalf("зафиксировано %v для невалидного замера", marked)
+	}
+}
+
+func TestHandlerSendsPanicToDLQ(t *testing.T) {
+	producer := mocks.NewMockPublisher(gomock.NewController(t))
+	h, store := newHandler(t, dlq.NewPublisher(producer, "weather_data_dlq"))
+
+	store.EXPECT().SaveWithHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, model.WeatherData) (time.Time, error) {
+			panic("сбой драйвера")
+		})
+	producer.EXPECT().SendMessage(gomock.Any()).DoAndReturn(
+		func(msg *sarama.ProducerMessage) (int32, int64, error) {
+			reason := ""
+			for _, header := range msg.Headers {
+				if string(header.Key) == dlq.HeaderReason {
+					reason = string(header.Value)
+				}
+			}
+			if msg.Topic != "weather_data_dlq" || reason != dlq.ReasonPanic {
+				t.Errorf("в DLQ %s с причиной %q", msg.Topic, reason)
+			}
+			return 0, 0, nil
+		})
+
+	// Сообщение в DLQ — смещение фиксируется, партиция читается дальше
+	if marked := consume(t, h, observation(t, moscow)); len(marked) != 1 {
+		t.Fatalf("зафиксировано %v, ожидалось одно смещение", marked)
+	}
+}
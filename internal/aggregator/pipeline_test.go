@@ -0,0 +1,113 @@
+package aggregator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/gometeo/app/internal/aggregator"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/testutil"
+)
+
+// Замер из Kafka проходит агрегатор и попадает в текущую погоду и историю
+func TestPipelineSavesObservation(t *testing.T) {
+	store := testutil.Postgres(t)
+	brokers := testutil.Kafka(t)
+	logger := testutil.Logger(t)
+
+	observedAt := time.Now().UTC().Truncate(time.Second)
+	data := model.WeatherData{
+		City:          "Kazan",
+		Temp:          -3.5,
+		Condition:     "snow",
+		ConditionCode: model.ConditionSnow,
+		Provider:      "test",
+		Timestamp:     observedAt,
+	}
+	event, err := model.NewEvent(model.EventWeatherObserved, "pipeline_test", observedAt, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := event.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producerConfig := sarama.NewConfig()
+	producerConfig.Producer.Return.Successes = true
+	producer, err := sarama.NewSyncProducer(brokers, producerConfig)
+	if err != nil {
+		t.Fatalf("ошибка создания producer: %v", err)
+	}
+	defer producer.Close()
+	if _, _, err := producer.SendMessage(&sarama.ProducerMessage{
+		Topic: aggregator.Topic,
+		Key:   sarama.StringEncoder(data.City),
+		Value: sarama.ByteEncoder(payload),
+	}); err != nil {
+		t.Fatalf("ошибка отправки замера: %v", err)
+	}
+
+	consumerConfig := sarama.NewConfig()
+	consumerConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	consumer, err := sarama.NewConsumerGroup(brokers, "pipeline_test", consumerConfig)
+	if err != nil {
+		t.Fatalf("ошибка создания consumer: %v", err)
+	}
+	defer consumer.Close()
+
+	handler := aggregator.NewHandler(config.Load(), store, nil, errreport.Nop{}, nil, logger)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	done := make(chan struct{})
+	// Горутина пишет в t.Logf, поэтому тест завершается только после нее,
+	// в том числе при t.Fatal в цикле ожидания
+	stopConsumer := func() {
+		cancel()
+		<-done
+	}
+	defer stopConsumer()
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			if err := consumer.Consume(ctx, []string{aggregator.Topic}, handler); err != nil {
+				t.Logf("ошибка чтения Kafka: %v", err)
+			}
+		}
+	}()
+
+	var saved *model.WeatherData
+	for saved == nil {
+		saved, err = store.GetByCity(ctx, data.City)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("ошибка чтения погоды: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("замер не сохранен за минуту")
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	stopConsumer()
+
+	if saved.Temp != data.Temp || saved.ConditionCode != data.ConditionCode || saved.Provider != data.Provider {
+		t.Errorf("сохранено %+v, ожидалось %+v", *saved, data)
+	}
+	if !handler.LastWrite().Equal(saved.Timestamp) {
+		t.Errorf("время записи агрегатора %s, в БД %s", handler.LastWrite(), saved.Timestamp)
+	}
+
+	history, err := store.GetHistory(context.Background(), data.City, observedAt.Add(-time.Minute), observedAt.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("ошибка чтения истории: %v", err)
+	}
+	if len(history) != 1 || !history[0].Timestamp.Equal(observedAt) {
+		t.Errorf("история %+v, ожидался один замер на %s", history, observedAt)
+	}
+}
@@ -0,0 +1,72 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Publisher отправляет события alert.triggered для cmd/notifier. Нулевой
+// указатель — доставка выключена, Publish ничего не делает.
+type Publisher struct {
+	producer bus.Publisher
+	topic    string
+	source   string
+}
+
+func NewPublisher(producer bus.Publisher, topic, source string) *Publisher {
+	return &Publisher{producer: producer, topic: topic, source: source}
+}
+
+// Publish отправляет сохраненное срабатывание правила
+func (p *Publisher) Publish(ctx context.Context, alert model.AlertEvent) error {
+	if p == nil {
+		return nil
+	}
+
+	event, err := model.NewEvent(model.EventAlertTriggered, p.source, alert.TriggeredAt, alert)
+	if err != nil {
+		return err
+	}
+	payload, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(alert.City),
+		Value: sarama.ByteEncoder(payload),
+	}
+
+	ctx, span := tracer.Start(ctx, p.topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", p.topic),
+			attribute.String("weather.city", alert.City),
+			attribute.Int64("alert.rule_id", alert.RuleID),
+		))
+	defer span.End()
+	tracing.InjectKafka(ctx, msg)
+
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		tracing.RecordError(span, err)
+		return fmt.Errorf("ошибка отправки оповещения: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает producer
+func (p *Publisher) Close() error {
+	if p == nil {
+		return nil
+	}
+	return p.producer.Close()
+}
@@ -0,0 +1,141 @@
+// Package alerts проверяет сохраненные агрегатором замеры пользовательскими
+// правилами оповещений, записывает срабатывания в alerts_triggered и
+// передает их в cmd/notifier через Kafka.
+package alerts
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var tracer = tracing.Tracer("github.com/gometeo/app/internal/alerts")
+
+//go:generate go tool mockgen -destination=../testutil/mocks/alerts.go -package=mocks -mock_names=Store=MockAlertsStore . Store
+
+// Store — операции с БД, нужные стадии; реализуется storage.WeatherStorage
+type Store interface {
+	ListAlertRulesForCity(ctx context.Context, city string) ([]model.AlertRule, error)
+	SaveAlertEvent(ctx context.Context, event model.AlertEvent) (int64, error)
+}
+
+// Stage проверяет каждый сохраненный замер правилами его города.
+// Правило срабатывает, когда условие начинает выполняться, и не повторяется,
+// пока оно выполняется на следующих замерах. Нулевой указатель безопасен
+// и ничего не делает.
+type Stage struct {
+	store     Store
+	publisher *Publisher
+	logger    *slog.Logger
+	triggered metric.Int64Counter
+
+	mu     sync.Mutex
+	active map[int64]bool // правила, условие которых выполнено на последнем замере
+}
+
+// NewStage создает стадию по конфигурации, nil если проверка выключена
+func NewStage(cfg *config.Config, store Store, logger *slog.Logger) *Stage {
+	if !cfg.AlertsEnabled {
+		return nil
+	}
+
+	triggered, _ := metrics.Meter("github.com/gometeo/app/internal/alerts").Int64Counter("alerts.triggered",
+		metric.WithDescription("Количество сработавших правил оповещений по показателю"))
+
+	return &Stage{
+		store:     store,
+		logger:    logger,
+		triggered: triggered,
+		active:    make(map[int64]bool),
+	}
+}
+
+// SetPublisher включает доставку срабатываний; nil — только запись в БД
+func (s *Stage) SetPublisher(publisher *Publisher) {
+	if s == nil {
+		return
+	}
+	s.publisher = publisher
+}
+
+// Observe проверяет сохраненный замер погоды
+func (s *Stage) Observe(ctx context.Context, data model.WeatherData) error {
+	if s == nil {
+		return nil
+	}
+	return s.evaluate(ctx, data.City, model.MetricTemperature, func(rule model.AlertRule) (model.AlertEvent, bool) {
+		return rule.Evaluate(data)
+	})
+}
+
+// ObserveAirQuality проверяет сохраненный замер качества воздуха
+func (s *Stage) ObserveAirQuality(ctx context.Context, data model.AirQuality) error {
+	if s == nil {
+		return nil
+	}
+	return s.evaluate(ctx, data.City, model.MetricAQI, func(rule model.AlertRule) (model.AlertEvent, bool) {
+		return rule.EvaluateAirQuality(data)
+	})
+}
+
+func (s *Stage) evaluate(ctx context.Context, city string, m model.AlertMetric, check func(model.AlertRule) (model.AlertEvent, bool)) error {
+	rules, err := s.store.ListAlertRulesForCity(ctx, city)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if rule.Metric != m {
+			continue
+		}
+		event, ok := check(rule)
+		if !s.transition(rule.ID, ok) {
+			continue
+		}
+
+		id, err := s.store.SaveAlertEvent(ctx, event)
+		if err != nil {
+			// Следующий замер с выполненным условием повторит попытку
+			s.transition(rule.ID, false)
+			return err
+		}
+		event.ID = id
+		s.triggered.Add(ctx, 1, metric.WithAttributes(attribute.String("metric", string(m))))
+		s.logger.InfoContext(ctx, "Сработало правило оповещения",
+			"rule_id", rule.ID,
+			"city", event.City,
+			"metric", event.Metric,
+			"operator", event.Operator,
+			"threshold", event.Threshold,
+			"value", event.Value)
+
+		// Срабатывание уже в БД: без доставки его видно через API
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			s.logger.WarnContext(ctx, "Ошибка отправки оповещения", "rule_id", rule.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// transition запоминает, выполнено ли условие правила, и сообщает, что
+// оно только что стало выполненным
+func (s *Stage) transition(ruleID int64, met bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !met {
+		delete(s.active, ruleID)
+		return false
+	}
+	if s.active[ruleID] {
+		return false
+	}
+	s.active[ruleID] = true
+	return true
+}
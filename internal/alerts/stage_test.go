@@ -0,0 +1,86 @@
+package alerts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/gometeo/app/internal/alerts"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/testutil"
+	"github.com/gometeo/app/internal/testutil/mocks"
+)
+
+var frostRule = model.AlertRule{
+	ID:        7,
+	City:      "Moscow",
+	Metric:    model.MetricTemperature,
+	Operator:  model.OpLess,
+	Threshold: 0,
+}
+
+func newStage(t *testing.T) (*alerts.Stage, *mocks.MockAlertsStore) {
+	cfg := config.Load()
+	cfg.AlertsEnabled = true
+	store := mocks.NewMockAlertsStore(gomock.NewController(t))
+	store.EXPECT().ListAlertRulesForCity(gomock.Any(), "Moscow").Return([]model.AlertRule{frostRule}, nil).AnyTimes()
+	return alerts.NewStage(cfg, store, testutil.Logger(t)), store
+}
+
+func observe(t *testing.T, stage *alerts.Stage, temps ...float64) []error {
+	t.Helper()
+	errs := make([]error, 0, len(temps))
+	for i, temp := range temps {
+		errs = append(errs, stage.Observe(context.Background(), model.WeatherData{
+			City:      "Moscow",
+			Temp:      temp,
+			Timestamp: time.Date(2026, 1, 15, i, 0, 0, 0, time.UTC),
+		}))
+	}
+	return errs
+}
+
+func TestStageTriggersOncePerCrossing(t *testing.T) {
+	stage, store := newStage(t)
+
+	// Срабатывание при переходе через порог, повтор — только после выхода из условия
+	var values []float64
+	store.EXPECT().SaveAlertEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event model.AlertEvent) (int64, error) {
+			if event.RuleID != frostRule.ID || event.City != "Moscow" || event.Threshold != 0 {
+				t.Errorf("неверное срабатывание: %+v", event)
+			}
+			values = append(values, event.Value)
+			return int64(len(values)), nil
+		}).Times(2)
+
+	for _, err := range observe(t, stage, -1, -2, 3, -4) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(values) != 2 || values[0] != -1 || values[1] != -4 {
+		t.Errorf("сработало на %v, ожидалось на [-1 -4]", values)
+	}
+}
+
+func TestStageRetriesAfterSaveError(t *testing.T) {
+	stage, store := newStage(t)
+
+	gomock.InOrder(
+		store.EXPECT().SaveAlertEvent(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("соединение разорвано")),
+		store.EXPECT().SaveAlertEvent(gomock.Any(), gomock.Any()).Return(int64(1), nil),
+	)
+
+	errs := observe(t, stage, -1, -2, -3)
+	if errs[0] == nil {
+		t.Error("ошибка записи срабатывания не возвращена")
+	}
+	if errs[1] != nil || errs[2] != nil {
+		t.Errorf("повторная попытка: %v", errs[1:])
+	}
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gometeo/app/internal/config"
+)
+
+// Поддерживаемые кодировки в порядке предпочтения при равных q
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// compressor создает и переиспользует кодировщики одной кодировки и уровня
+type compressor struct {
+	encoding string
+	pool     sync.Pool
+}
+
+// flushWriter — кодировщик с Flush; gzip.Writer и flate.Writer подходят
+type flushWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+func newCompressor(encoding string, level int) *compressor {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		// Неверный уровень из окружения не должен ронять сервис
+		level = flate.DefaultCompression
+	}
+	c := &compressor{encoding: encoding}
+	c.pool.New = func() any {
+		var w flushWriter
+		if encoding == encodingGzip {
+			w, _ = gzip.NewWriterLevel(io.Discard, level)
+		} else {
+			w, _ = flate.NewWriter(io.Discard, level)
+		}
+		return w
+	}
+	return c
+}
+
+func (c *compressor) get(dst io.Writer) flushWriter {
+	w := c.pool.Get().(flushWriter)
+	w.Reset(dst)
+	return w
+}
+
+func (c *compressor) put(w flushWriter) {
+	c.pool.Put(w)
+}
+
+// Middleware сжатия ответов по Accept-Encoding. Ответ копится в буфере до
+// CompressionMinSize байт: короткие ответы уходят как есть, длинные — сжатыми.
+// Уже сжатые форматы (PNG-тайлы) и ответы с Content-Encoding не трогаются.
+func compressionMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+	compressors := map[string]*compressor{
+		encodingGzip:    newCompressor(encodingGzip, cfg.CompressionGzipLevel),
+		encodingDeflate: newCompressor(encodingDeflate, cfg.CompressionDeflateLevel),
+	}
+	minSize := max(cfg.CompressionMinSize, 0)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				compressor:     compressors[encoding],
+				minSize:        minSize,
+				status:         http.StatusOK,
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding выбирает кодировку из Accept-Encoding с учетом q-значений;
+// пустая строка — сжимать не нужно
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		switch name {
+		case encodingGzip, encodingDeflate:
+		case "*":
+			name = encodingGzip
+		default:
+			continue
+		}
+		// gzip предпочтительнее deflate при равном весе
+		if q > bestQ || q == bestQ && q > 0 && name == encodingGzip {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressible сообщает, имеет ли смысл сжимать тело такого типа
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// compressWriter откладывает заголовки, пока не станет ясно, сжимать ли ответ
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *compressor
+	minSize    int
+
+	status      int
+	wroteHeader bool // WriteHeader вызван обработчиком
+	decided     bool // заголовки отправлены, способ записи выбран
+	buf         []byte
+	encoder     flushWriter // nil — ответ идет без сжатия
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.decided || cw.wroteHeader {
+		return
+	}
+	if code < http.StatusOK {
+		// Информационные ответы уходят сразу и не завершают ответ
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.status = code
+	cw.wroteHeader = true
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		h := cw.Header()
+		if h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) {
+			cw.start(false)
+		} else {
+			cw.buf = append(cw.buf, p...)
+			if len(cw.buf) < cw.minSize {
+				return len(p), nil
+			}
+			cw.start(true)
+			if err := cw.flushBuffer(); err != nil {
+				return 0, err
+			}
+			return len(p), nil
+		}
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// start отправляет заголовки; при compress тело дальше идет через кодировщик
+func (cw *compressWriter) start(compress bool) {
+	cw.decided = true
+	if compress {
+		h := cw.Header()
+		h.Set("Content-Encoding", cw.compressor.encoding)
+		// Длина исходного тела больше не верна
+		h.Del("Content-Length")
+		cw.encoder = cw.compressor.get(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// flushBuffer отправляет накопленное тело выбранным способом
+func (cw *compressWriter) flushBuffer() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(cw.buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf)
+	}
+	cw.buf = nil
+	return err
+}
+
+// close завершает ответ: короткое тело уходит без сжатия, поток кодировщика закрывается
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if !cw.wroteHeader && len(cw.buf) == 0 {
+			// Обработчик ничего не написал (например, Hijack) — net/http ответит сам
+			return
+		}
+		cw.start(false)
+		cw.flushBuffer()
+	}
+	if cw.encoder != nil {
+		cw.encoder.Close()
+		cw.compressor.put(cw.encoder)
+		cw.encoder = nil
+	}
+}
+
+// Flush отправляет накопленное клиенту; до набора minSize ответ уходит без сжатия
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.start(false)
+		cw.flushBuffer()
+	}
+	if cw.encoder != nil {
+		cw.encoder.Flush()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Hijack передает соединение обработчику WebSocket; сжатие на нем не применяется
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	cw.decided = true
+	return http.NewResponseController(cw.ResponseWriter).Hijack()
+}
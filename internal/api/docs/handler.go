@@ -0,0 +1,266 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	swaggerFiles "github.com/swaggo/files/v2"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/model"
+)
+
+// Имена схем авторизации в документе: ключ API и токен пользователя
+const (
+	apiKeyScheme = "apiKey"
+	bearerScheme = "bearerAuth"
+)
+
+// pathParam находит переменные шаблона пути; регулярное выражение после
+// двоеточия, как в {z:[0-9]+}, в документ не попадает
+var pathParam = regexp.MustCompile(`\{([^{}:]+)(?::(?:[^{}]|\{[^{}]*\})*)?\}`)
+
+// Spec отдает описание OpenAPI маршрутов router под /api/. Документ
+// собирается при первом запросе, когда все маршруты уже зарегистрированы.
+func Spec(router *mux.Router) http.Handler {
+	var (
+		once sync.Once
+		body []byte
+		err  error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			var doc *Document
+			if doc, err = Build(router); err == nil {
+				body, err = json.MarshalIndent(doc, "", "  ")
+			}
+		})
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(errcode.Internal.Status())
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Code:    string(errcode.Internal),
+				Error:   "Не удалось собрать описание API",
+				Message: err.Error(),
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(body)
+	})
+}
+
+// Build собирает документ по маршрутам router и описаниям из operations
+func Build(router *mux.Router) (*Document, error) {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "GoMeteo API",
+			Description: "Текущая погода, история и прогнозы по городам",
+			Version:     buildinfo.Get().Version,
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				apiKeyScheme: {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        account.HeaderAPIKey,
+					Description: "Без ключа доступны только маршруты анонимной политики с лимитом по адресу",
+				},
+				bearerScheme: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "Токен из POST /api/v1/auth/token; reader — чтение, writer — запись, admin — ключи и webhooks",
+				},
+			},
+		},
+		Security: []map[string][]string{{apiKeyScheme: {}}, {bearerScheme: {}}},
+	}
+	errorSchema := s.of(model.ErrorResponse{})
+	// Коды ошибок перечисляются из каталога, чтобы клиенты могли их сгенерировать
+	s.components["ErrorResponse"].Properties["code"].Enum = errcode.All()
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		// Шаблон со слешем на конце — статика под PathPrefix, например Swagger UI
+		if err != nil || !strings.HasPrefix(tmpl, "/api/") || strings.HasSuffix(tmpl, "/") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Маршрут без ограничения методов — только префикс подмаршрутизатора
+			return nil
+		}
+
+		path := pathParam.ReplaceAllString(tmpl, "{$1}")
+		item := doc.Paths[path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		for _, method := range methods {
+			if method == http.MethodHead || method == http.MethodOptions {
+				continue
+			}
+			note, ok := operations[method+" "+path]
+			if !ok {
+				note = annotation{Tag: "other"}
+			}
+			item[strings.ToLower(method)] = operation(s, method, path, note, errorSchema)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обхода маршрутов: %w", err)
+	}
+
+	doc.Components.Schemas = s.components
+	return doc, nil
+}
+
+// operation описывает один метод пути
+func operation(s *schemas, method, path string, note annotation, errorSchema *Schema) *Operation {
+	op := &Operation{
+		Summary:     note.Summary,
+		OperationID: operationID(method, path),
+		Responses:   make(map[string]Response),
+	}
+	if note.Tag != "" {
+		op.Tags = []string{note.Tag}
+	}
+	if note.Public {
+		// Пустое требование — ключ необязателен
+		op.Security = []map[string][]string{{}}
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	for _, p := range note.Query {
+		typ := p.Type
+		if typ == "" {
+			typ = "string"
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      &Schema{Type: typ, Format: p.Format},
+		})
+	}
+
+	if body := s.of(note.Body); body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: body}},
+		}
+	}
+
+	status := note.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := Response{Description: http.StatusText(status)}
+	switch {
+	case note.ContentType != "":
+		resp.Content = map[string]MediaType{note.ContentType: {}}
+	case note.Response != nil:
+		schema := s.of(note.Response)
+		resp.Content = map[string]MediaType{"application/json": {Schema: schema}}
+		if note.Formats {
+			resp.Content["text/csv"] = MediaType{Schema: &Schema{Type: "string"}}
+			resp.Content["application/xml"] = MediaType{Schema: schema}
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = resp
+	op.Responses["default"] = Response{
+		Description: "Ошибка",
+		Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+	}
+	return op
+}
+
+// operationID строит идентификатор вида getWeatherCityHistory
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	// Версия v1 в идентификатор не входит, остальные — входят: getV2Cities
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/api/v1"), "/api")
+	parts := strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '.' || r == '_'
+	})
+	for _, part := range parts {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// uiPage — страница Swagger UI; статические файлы берутся из swaggo/files
+const uiPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="UTF-8">
+  <title>GoMeteo API</title>
+  <link rel="stylesheet" type="text/css" href="swagger-ui.css">
+  <link rel="stylesheet" type="text/css" href="index.css">
+  <link rel="icon" type="image/png" href="favicon-32x32.png" sizes="32x32">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="swagger-ui-bundle.js" charset="UTF-8"></script>
+  <script src="swagger-ui-standalone-preset.js" charset="UTF-8"></script>
+  <script>
+    window.onload = function() {
+      window.ui = SwaggerUIBundle({
+        url: %q,
+        dom_id: '#swagger-ui',
+        deepLinking: true,
+        presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+        plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+        layout: "StandaloneLayout"
+      });
+    };
+  </script>
+</body>
+</html>
+`
+
+// UI отдает Swagger UI под prefix (со слешем на конце), который загружает
+// описание по specURL
+func UI(prefix, specURL string) http.Handler {
+	page := []byte(fmt.Sprintf(uiPage, specURL))
+	files := http.StripPrefix(prefix, http.FileServer(http.FS(swaggerFiles.FS)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Общий middleware проставляет application/json; FileServer не переопределяет
+		// уже заданный тип, поэтому сбрасываем его и даем определить по расширению
+		w.Header().Del("Content-Type")
+
+		switch strings.TrimPrefix(r.URL.Path, prefix) {
+		case "", "index.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Write(page)
+		case "swagger-initializer.js":
+			// Инициализатор из дистрибутива открывает чужой демонстрационный документ
+			http.NotFound(w, r)
+		default:
+			files.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,401 @@
+package docs
+
+import (
+	"net/http"
+
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/model"
+)
+
+// annotation — описание маршрута, которое не выводится из маршрутизатора:
+// назначение, параметры запроса и модели тел
+type annotation struct {
+	Summary string
+	Tag     string
+	Query   []param
+	Body    any // Пример типа тела запроса; nil — без тела
+	// Пример типа ответа; nil при Status 204 — без тела
+	Response any
+	Status   int  // 0 — 200
+	Formats  bool // Кроме JSON отдает CSV и XML
+	Public   bool // Доступен без API-ключа
+	// Тип ответа, отличный от JSON, например image/png
+	ContentType string
+}
+
+// param — параметр строки запроса
+type param struct {
+	Name        string
+	Type        string // string, integer; пусто — string
+	Format      string
+	Description string
+	Required    bool
+}
+
+// Общие параметры оформления ответа с погодой
+var (
+	unitsParam  = param{Name: "units", Description: "Система единиц: metric, imperial или kelvin; по умолчанию из настроек аккаунта"}
+	langParam   = param{Name: "lang", Description: "Язык описания погоды: ru, en или de; по умолчанию из настроек аккаунта"}
+	tzParam     = param{Name: "tz", Description: "Часовой пояс IANA для времени в ответе; по умолчанию — пояс города"}
+	fieldsParam = param{Name: "fields", Description: "Поля ответа через запятую; пусто — все поля"}
+	formatParam = param{Name: "format", Description: "Формат ответа: json, csv или xml; приоритетнее заголовка Accept"}
+
+	viewParams = []param{unitsParam, langParam, tzParam}
+)
+
+// operations — описания маршрутов по ключу "МЕТОД шаблон-пути". Маршруты без
+// описания попадают в документ с общим ответом.
+var operations = map[string]annotation{
+	"GET /api/v1/weather/changes": {
+		Summary: "Изменения погоды после курсора",
+		Tag:     "weather",
+		Query: []param{
+			{Name: "since", Description: "Курсор из прошлого ответа или время в формате RFC3339"},
+			{Name: "limit", Type: "integer", Description: "Число изменений в ответе"},
+		},
+		Response: model.ChangesResponse{},
+	},
+	"POST /api/v1/weather/batch": {
+		Summary:  "Погода нескольких городов за один запрос",
+		Tag:      "weather",
+		Query:    viewParams,
+		Body:     []string{},
+		Response: model.BatchResponse{},
+	},
+	"PUT /api/v1/weather": {
+		Summary:  "Сохранение замеров нескольких городов в одной транзакции",
+		Tag:      "weather",
+		Body:     []model.WeatherData{},
+		Response: map[string]any{},
+	},
+	"GET /api/v1/weather/{city}": {
+		Summary: "Текущая погода города",
+		Tag:     "weather",
+		Query: append(viewParams, fieldsParam, formatParam,
+			param{Name: "include", Description: "Дополнительные блоки через запятую: astro"}),
+		Response: model.WeatherResponse{},
+		Formats:  true,
+	},
+	"PUT /api/v1/weather/{city}": {
+		Summary:  "Сохранение замера погоды города",
+		Tag:      "weather",
+		Body:     model.WeatherData{},
+		Response: map[string]string{},
+	},
+	"DELETE /api/v1/weather/{city}": {
+		Summary: "Удаление города и его замеров",
+		Tag:     "weather",
+		Status:  http.StatusNoContent,
+	},
+	"GET /api/v1/weather/{city}/at": {
+		Summary: "Погода города на момент времени",
+		Tag:     "weather",
+		Query: append(viewParams,
+			param{Name: "time", Format: "date-time", Description: "Момент времени в формате RFC3339", Required: true},
+			param{Name: "mode", Description: "nearest — ближайший замер, interpolate — интерполяция соседних"}),
+		Response: model.PointInTimeResponse{},
+	},
+	"GET /api/v1/weather/{city}/history": {
+		Summary: "История замеров города",
+		Tag:     "weather",
+		Query: append(viewParams, formatParam,
+			param{Name: "from", Format: "date-time", Description: "Начало периода в формате RFC3339; по умолчанию сутки до to"},
+			param{Name: "to", Format: "date-time", Description: "Конец периода в формате RFC3339; по умолчанию сейчас"}),
+		Response: model.HistoryResponse{},
+		Formats:  true,
+	},
+	"GET /api/v1/weather/{city}/providers": {
+		Summary:  "Последние замеры каждого провайдера и их консенсус",
+		Tag:      "weather",
+		Query:    viewParams,
+		Response: model.ProvidersResponse{},
+	},
+	"GET /api/v1/weather/{city}/wait": {
+		Summary: "Ожидание нового замера города (long polling)",
+		Tag:     "weather",
+		Query: append(viewParams,
+			param{Name: "timeout", Description: "Максимальное ожидание, например 30s"},
+			param{Name: "since", Format: "date-time", Description: "Вернуть сразу, если замер новее этого времени"}),
+		Response: model.WeatherResponse{},
+	},
+	"GET /api/v1/weather/{city}/stats": {
+		Summary: "Сводка температуры города за период",
+		Tag:     "weather",
+		Query: append(viewParams,
+			param{Name: "period", Description: "Период сводки: 24h, 7d или 30d; по умолчанию 24h"}),
+		Response: model.WeatherStats{},
+	},
+	"GET /api/v1/cities": {
+		Summary: "Города с данными",
+		Tag:     "cities",
+		Query: []param{
+			{Name: "page", Type: "integer", Description: "Номер страницы с 1"},
+			{Name: "per_page", Type: "integer", Description: "Городов на странице, до 500"},
+			{Name: "sort", Description: `city, updated_at или temp; "-" в начале — по убыванию`},
+			formatParam,
+		},
+		Response: model.CitiesResponse{},
+		Formats:  true,
+	},
+	"GET /api/v1/cities/search": {
+		Summary: "Поиск городов по началу или части названия",
+		Tag:     "cities",
+		Query: []param{
+			{Name: "q", Description: "Строка поиска", Required: true},
+			{Name: "limit", Type: "integer", Description: "Число городов в ответе, до 50"},
+		},
+		Response: model.CitySearchResponse{},
+	},
+	"GET /api/v1/ws": {
+		Summary: "WebSocket с обновлениями погоды подписанных городов",
+		Tag:     "weather",
+		Status:  http.StatusSwitchingProtocols,
+	},
+	"GET /api/v1/forecast/{city}": {
+		Summary: "Прогноз погоды города",
+		Tag:     "forecast",
+		Query: append(viewParams,
+			param{Name: "days", Type: "integer", Description: "Число дней прогноза"}),
+		Response: model.ForecastResponse{},
+	},
+	"GET /api/v1/astro/{city}": {
+		Summary: "Восход, закат и фаза луны",
+		Tag:     "forecast",
+		Query: []param{
+			tzParam,
+			{Name: "date", Format: "date", Description: "Дата в формате YYYY-MM-DD; по умолчанию сегодня"},
+		},
+		Response: model.AstroResponse{},
+	},
+	"GET /api/v1/quality/{city}": {
+		Summary:  "Оценка качества данных провайдеров",
+		Tag:      "quality",
+		Response: model.QualityReport{},
+	},
+	"GET /api/v1/air/{city}": {
+		Summary:  "Текущее качество воздуха",
+		Tag:      "air",
+		Query:    []param{tzParam},
+		Response: model.AirQualityResponse{},
+	},
+	"GET /api/v1/air/{city}/history": {
+		Summary: "История качества воздуха",
+		Tag:     "air",
+		Query: []param{tzParam,
+			{Name: "hours", Type: "integer", Description: "Период в часах; по умолчанию 24"}},
+		Response: model.AirQualityHistoryResponse{},
+	},
+	"GET /api/v1/air/{city}/stats": {
+		Summary: "Сводка качества воздуха за период",
+		Tag:     "air",
+		Query: []param{tzParam,
+			{Name: "hours", Type: "integer", Description: "Период в часах; по умолчанию 24"}},
+		Response: model.AirQualityStatsResponse{},
+	},
+	"GET /api/v1/badge/{city}.svg": {
+		Summary:     "SVG-значок с текущей температурой",
+		Tag:         "weather",
+		Query:       []param{unitsParam, {Name: "theme", Description: "Тема значка"}},
+		ContentType: "image/svg+xml",
+	},
+	"GET /api/v1/tiles/{layer}/{z}/{x}/{y}.png": {
+		Summary:     "Тайл карты погоды",
+		Tag:         "tiles",
+		ContentType: "image/png",
+	},
+	"GET /api/v1/replication/status": {
+		Summary:  "Сравнение данных регионов",
+		Tag:      "system",
+		Response: model.ReplicationStatusResponse{},
+	},
+	"POST /api/v1/graphql": {
+		Summary: "GraphQL: погода, история и города",
+		Tag:     "graphql",
+		Body: struct {
+			Query         string         `json:"query"`
+			OperationName string         `json:"operationName,omitempty"`
+			Variables     map[string]any `json:"variables,omitempty"`
+		}{},
+		Response: map[string]any{},
+	},
+	"GET /api/v1/version": {
+		Summary:  "Версия сборки",
+		Tag:      "system",
+		Response: buildinfo.Info{},
+		Public:   true,
+	},
+	"GET /api/v1/account/usage": {
+		Summary:  "Расход квоты по API-ключу",
+		Tag:      "account",
+		Response: model.UsageResponse{},
+	},
+	"GET /api/v1/me/preferences": {
+		Summary:  "Настройки аккаунта",
+		Tag:      "account",
+		Response: model.Preferences{},
+	},
+	"PUT /api/v1/me/preferences": {
+		Summary: "Изменение настроек аккаунта",
+		Tag:     "account",
+		Body: struct {
+			Units    *string `json:"units"`
+			Language *string `json:"language"`
+			Timezone *string `json:"timezone"`
+		}{},
+		Response: model.Preferences{},
+	},
+	"GET /api/v1/me/favorites": {
+		Summary:  "Избранные города",
+		Tag:      "account",
+		Response: model.FavoritesResponse{},
+	},
+	"PUT /api/v1/me/favorites/{city}": {
+		Summary: "Добавление города в избранное",
+		Tag:     "account",
+		Status:  http.StatusNoContent,
+	},
+	"DELETE /api/v1/me/favorites/{city}": {
+		Summary: "Удаление города из избранного",
+		Tag:     "account",
+		Status:  http.StatusNoContent,
+	},
+	"GET /api/v1/me/weather": {
+		Summary:  "Погода избранных городов",
+		Tag:      "account",
+		Query:    viewParams,
+		Response: model.MyWeatherResponse{},
+	},
+	"POST /api/v1/alerts": {
+		Summary: "Создание правила оповещения",
+		Tag:     "alerts",
+		Body: struct {
+			City      string              `json:"city"`
+			Metric    model.AlertMetric   `json:"metric"`
+			Operator  model.AlertOperator `json:"operator"`
+			Threshold float64             `json:"threshold"`
+			Channels  []string            `json:"channels"`
+		}{},
+		Response: model.AlertRule{},
+		Status:   http.StatusCreated,
+	},
+	"GET /api/v1/alerts": {
+		Summary:  "Правила оповещений аккаунта",
+		Tag:      "alerts",
+		Response: model.AlertRulesResponse{},
+	},
+	"GET /api/v1/alerts/triggered": {
+		Summary: "Последние срабатывания правил аккаунта",
+		Tag:     "alerts",
+		Query: []param{
+			{Name: "limit", Type: "integer", Description: "Число срабатываний, до 500; по умолчанию 50"},
+		},
+		Response: model.AlertEventsResponse{},
+	},
+	"DELETE /api/v1/alerts/{id}": {
+		Summary: "Удаление правила оповещения",
+		Tag:     "alerts",
+		Status:  http.StatusNoContent,
+	},
+	"POST /api/v1/webhooks": {
+		Summary: "Регистрация webhook для новых замеров; ключ подписи только в ответе",
+		Tag:     "webhooks",
+		Body: struct {
+			URL  string `json:"url"`
+			City string `json:"city,omitempty"`
+		}{},
+		Response: model.Webhook{},
+		Status:   http.StatusCreated,
+	},
+	"GET /api/v1/webhooks": {
+		Summary:  "Webhooks аккаунта",
+		Tag:      "webhooks",
+		Response: model.WebhooksResponse{},
+	},
+	"DELETE /api/v1/webhooks/{id}": {
+		Summary: "Удаление webhook",
+		Tag:     "webhooks",
+		Status:  http.StatusNoContent,
+	},
+	"POST /api/v1/auth/token": {
+		Summary:  "Токен пользователя по логину и паролю",
+		Tag:      "auth",
+		Body:     model.TokenRequest{},
+		Response: model.TokenResponse{},
+		Public:   true,
+	},
+	"POST /api/v1/keys": {
+		Summary:  "Выпуск API-ключа; только токен с ролью admin",
+		Tag:      "auth",
+		Body:     model.CreateKeyRequest{},
+		Response: model.KeyResponse{},
+		Status:   http.StatusCreated,
+	},
+	"GET /api/v1/admin/cities": {
+		Summary:  "Справочник городов, включая выключенные; только токен с ролью admin",
+		Tag:      "admin",
+		Response: model.CityDirectoryResponse{},
+	},
+	"POST /api/v1/admin/cities": {
+		Summary:  "Добавление города в справочник и расписание опроса",
+		Tag:      "admin",
+		Body:     model.City{},
+		Response: model.City{},
+		Status:   http.StatusCreated,
+	},
+	"GET /api/v1/admin/cities/{name}": {
+		Summary:  "Город справочника по названию или синониму",
+		Tag:      "admin",
+		Response: model.City{},
+	},
+	"PUT /api/v1/admin/cities/{name}": {
+		Summary:  "Изменение координат, страны, часового пояса, синонимов и флага enabled",
+		Tag:      "admin",
+		Body:     model.City{},
+		Response: model.City{},
+	},
+	"DELETE /api/v1/admin/cities/{name}": {
+		Summary: "Удаление города из справочника и расписания; замеры остаются",
+		Tag:     "admin",
+		Status:  http.StatusNoContent,
+	},
+	"GET /api/v1/admin/maintenance": {
+		Summary:  "Состояние режима обслуживания",
+		Tag:      "admin",
+		Response: model.MaintenanceStatus{},
+	},
+	"PUT /api/v1/admin/maintenance": {
+		Summary:  "Включение режима обслуживания: остальные маршруты отвечают 503 MAINTENANCE с Retry-After",
+		Tag:      "admin",
+		Body:     model.MaintenanceRequest{},
+		Response: model.MaintenanceStatus{},
+	},
+	"DELETE /api/v1/admin/maintenance": {
+		Summary: "Выключение режима обслуживания",
+		Tag:     "admin",
+		Status:  http.StatusNoContent,
+	},
+	"GET /api/v2/cities": {
+		Summary:  "Города с провайдером и временем последнего замера",
+		Tag:      "v2",
+		Response: model.CitiesV2Response{},
+	},
+	"GET /api/v2/weather/{city}": {
+		Summary:  "Текущая погода города и сведения о провайдере",
+		Tag:      "v2",
+		Query:    viewParams,
+		Response: model.WeatherV2Response{},
+	},
+	"GET /api/v1/openapi.json": {
+		Summary:  "Это описание API",
+		Tag:      "system",
+		Response: map[string]any{},
+		Public:   true,
+	},
+	"GET /api/v1/health": {
+		Summary:  "Состояние сервиса и зависимостей",
+		Tag:      "system",
+		Response: health.Report{},
+		Public:   true,
+	},
+}
@@ -0,0 +1,90 @@
+// Package errcode — каталог машиночитаемых кодов ошибок API. Код в поле code
+// тела ошибки стабилен между версиями, в отличие от текста, и однозначно
+// определяет HTTP-статус ответа.
+package errcode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// Code — машиночитаемый код ошибки
+type Code string
+
+const (
+	// 400
+	InvalidRequest   Code = "INVALID_REQUEST"   // неверные параметры или тело запроса
+	ValidationFailed Code = "VALIDATION_FAILED" // поля тела не прошли проверку
+	// 401, 402, 403
+	Unauthorized     Code = "UNAUTHORIZED"      // нет или неверен API-ключ или токен
+	AccountSuspended Code = "ACCOUNT_SUSPENDED" // доступ аккаунта приостановлен
+	Forbidden        Code = "FORBIDDEN"         // роли или аккаунта недостаточно
+	// 404
+	NotFound     Code = "NOT_FOUND"      // ресурс не найден
+	CityNotFound Code = "CITY_NOT_FOUND" // нет данных о городе
+	// 409, 413
+	Conflict        Code = "CONFLICT"          // ресурс уже существует
+	PayloadTooLarge Code = "PAYLOAD_TOO_LARGE" // тело больше лимита
+	// 429
+	RateLimited   Code = "RATE_LIMITED"   // слишком часто, см. Retry-After
+	QuotaExceeded Code = "QUOTA_EXCEEDED" // месячная квота исчерпана
+	// 5xx
+	Internal           Code = "INTERNAL"            // ошибка сервера
+	NotImplemented     Code = "NOT_IMPLEMENTED"     // возможность выключена в сборке
+	UpstreamError      Code = "UPSTREAM_ERROR"      // внешний сервис ответил ошибкой
+	StoreUnavailable   Code = "STORE_UNAVAILABLE"   // база недоступна, режим только чтения
+	ServiceUnavailable Code = "SERVICE_UNAVAILABLE" // временно недоступно, см. Retry-After
+	Maintenance        Code = "MAINTENANCE"         // режим обслуживания, см. Retry-After
+	UpstreamTimeout    Code = "UPSTREAM_TIMEOUT"    // база, Redis или внешний сервис не ответили вовремя
+)
+
+// statuses — HTTP-статус каждого кода
+var statuses = map[Code]int{
+	InvalidRequest:     http.StatusBadRequest,
+	ValidationFailed:   http.StatusBadRequest,
+	Unauthorized:       http.StatusUnauthorized,
+	AccountSuspended:   http.StatusPaymentRequired,
+	Forbidden:          http.StatusForbidden,
+	NotFound:           http.StatusNotFound,
+	CityNotFound:       http.StatusNotFound,
+	Conflict:           http.StatusConflict,
+	PayloadTooLarge:    http.StatusRequestEntityTooLarge,
+	RateLimited:        http.StatusTooManyRequests,
+	QuotaExceeded:      http.StatusTooManyRequests,
+	Internal:           http.StatusInternalServerError,
+	NotImplemented:     http.StatusNotImplemented,
+	UpstreamError:      http.StatusBadGateway,
+	StoreUnavailable:   http.StatusServiceUnavailable,
+	ServiceUnavailable: http.StatusServiceUnavailable,
+	Maintenance:        http.StatusServiceUnavailable,
+	UpstreamTimeout:    http.StatusGatewayTimeout,
+}
+
+// Status возвращает HTTP-статус кода; неизвестный код — 500
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FromError выбирает код для ошибки инфраструктуры: истекший дедлайн запроса —
+// UPSTREAM_TIMEOUT, остальное — INTERNAL
+func FromError(err error) Code {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return UpstreamTimeout
+	}
+	return Internal
+}
+
+// All возвращает все коды каталога по алфавиту, например для описания OpenAPI
+func All() []string {
+	codes := make([]string, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	return codes
+}
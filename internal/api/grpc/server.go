@@ -0,0 +1,297 @@
+// Package grpc — gRPC-сервер погоды рядом с HTTP API для внутренних сервисов.
+// Использует те же хранилище и кэш, что и обработчики HTTP; код weatherpb
+// сгенерирован из weather.proto.
+package grpc
+
+//go:generate protoc -I . --go_out=../../.. --go_opt=module=github.com/gometeo/app --go-grpc_out=../../.. --go-grpc_opt=module=github.com/gometeo/app weather.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gometeo/app/internal/api/grpc/weatherpb"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/changefeed"
+	"github.com/gometeo/app/internal/geocode"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// Ограничения запросов, те же, что в HTTP API
+const (
+	defaultCitiesPerPage = 50
+	maxCitiesPerPage     = 500
+	maxStreamCities      = 50
+)
+
+// Store — операции с БД, нужные gRPC-серверу; реализуется storage.WeatherStorage
+type Store interface {
+	GetByCity(ctx context.Context, city string) (*model.WeatherData, error)
+	GetAllCities(ctx context.Context, opts storage.CityListOptions) ([]string, int, error)
+}
+
+// Cache — операции с кэшем, нужные gRPC-серверу; реализуется cache.WeatherCache
+type Cache interface {
+	Get(ctx context.Context, key string) (*model.WeatherData, error)
+	Set(ctx context.Context, key string, data model.WeatherData) error
+}
+
+var (
+	_ Store = (*storage.WeatherStorage)(nil)
+	_ Cache = (*cache.WeatherCache)(nil)
+)
+
+// storeRef позволяет хранить интерфейс в atomic.Pointer
+type storeRef struct {
+	Store
+}
+
+// Server реализует WeatherService
+type Server struct {
+	weatherpb.UnimplementedWeatherServiceServer
+
+	// nil, пока API работает в режиме только чтения из кэша
+	store    atomic.Pointer[storeRef]
+	cache    Cache
+	geocoder atomic.Pointer[geocode.Resolver]
+	logger   *slog.Logger
+
+	// Обновления для StreamWeather; nil — потоки выключены
+	feed *changefeed.Hub
+}
+
+func NewServer(cache Cache, logger *slog.Logger) *Server {
+	return &Server{cache: cache, logger: logger}
+}
+
+// SetStore подключает БД после частичного старта
+func (s *Server) SetStore(store Store) {
+	s.store.Store(&storeRef{store})
+}
+
+// SetGeocoder включает приведение названий городов к каноническим
+func (s *Server) SetGeocoder(geocoder *geocode.Resolver) {
+	s.geocoder.Store(geocoder)
+}
+
+// SetFeed включает StreamWeather
+func (s *Server) SetFeed(feed *changefeed.Hub) {
+	s.feed = feed
+}
+
+// Listen открывает порт и обслуживает вызовы в фоне. Возвращенная stop ждет
+// завершения текущих вызовов до отмены ctx, после чего обрывает оставшиеся.
+func (s *Server) Listen(addr string) (stop func(ctx context.Context) error, err error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия порта gRPC %s: %w", addr, err)
+	}
+
+	srv := grpclib.NewServer()
+	weatherpb.RegisterWeatherServiceServer(srv, s)
+
+	go func() {
+		s.logger.Info("gRPC-сервер запущен", "addr", addr)
+		if err := srv.Serve(lis); err != nil {
+			s.logger.Error("Ошибка gRPC-сервера", "error", err)
+		}
+	}()
+
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			srv.Stop()
+		}
+		return nil
+	}, nil
+}
+
+// db возвращает подключенную БД или nil в режиме только чтения
+func (s *Server) db() Store {
+	ref := s.store.Load()
+	if ref == nil {
+		return nil
+	}
+	return ref.Store
+}
+
+// city приводит название к каноническому, как для города из пути HTTP API
+func (s *Server) city(ctx context.Context, name string) string {
+	return s.geocoder.Load().Canonical(ctx, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// GetWeather возвращает погоду из кэша, при промахе — из БД с записью в кэш
+func (s *Server) GetWeather(ctx context.Context, req *weatherpb.GetWeatherRequest) (*weatherpb.Weather, error) {
+	city := s.city(ctx, req.GetCity())
+	if city == "" {
+		return nil, status.Error(codes.InvalidArgument, "не указан город")
+	}
+
+	cached, err := s.cache.Get(ctx, cache.CityKey(city))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+	}
+	if cached != nil {
+		return toProto(*cached, true), nil
+	}
+
+	store := s.db()
+	if store == nil {
+		return nil, status.Error(codes.Unavailable, "БД не подключена, режим только чтения из кэша")
+	}
+	data, err := store.GetByCity(ctx, city)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "город %s не найден", city)
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, status.Error(codes.DeadlineExceeded, "БД не ответила вовремя")
+	case storage.IsUnavailable(err):
+		s.logger.ErrorContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+		return nil, status.Error(codes.Unavailable, "БД недоступна")
+	case err != nil:
+		s.logger.ErrorContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+		return nil, status.Error(codes.Internal, "внутренняя ошибка сервера")
+	}
+	if err := s.cache.Set(ctx, cache.CityKey(city), *data); err != nil {
+		s.logger.WarnContext(ctx, "Не удалось сохранить в кэш", "city", city, "error", err)
+	}
+	return toProto(*data, false), nil
+}
+
+// ListCities возвращает страницу списка городов из БД
+func (s *Server) ListCities(ctx context.Context, req *weatherpb.ListCitiesRequest) (*weatherpb.ListCitiesResponse, error) {
+	page := int(req.GetPage())
+	if page == 0 {
+		page = 1
+	}
+	perPage := int(req.GetPerPage())
+	if perPage == 0 {
+		perPage = defaultCitiesPerPage
+	}
+	sort := req.GetSort()
+	if sort == "" {
+		sort = "city"
+	}
+	switch {
+	case page < 1:
+		return nil, status.Error(codes.InvalidArgument, "page должно быть не меньше 1")
+	case perPage < 1 || perPage > maxCitiesPerPage:
+		return nil, status.Errorf(codes.InvalidArgument, "per_page должно быть от 1 до %d", maxCitiesPerPage)
+	case !storage.ValidCitySort(sort):
+		return nil, status.Error(codes.InvalidArgument, `sort: допустимо city, updated_at, temp; "-" в начале — по убыванию`)
+	}
+
+	store := s.db()
+	if store == nil {
+		return nil, status.Error(codes.Unavailable, "БД не подключена, режим только чтения из кэша")
+	}
+	cities, total, err := store.GetAllCities(ctx, storage.CityListOptions{
+		Sort:   sort,
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Ошибка получения городов из БД", "error", err)
+		return nil, status.Error(codes.Internal, "внутренняя ошибка сервера")
+	}
+
+	resp := &weatherpb.ListCitiesResponse{Cities: cities, Total: int32(total)}
+	if page*perPage < total {
+		resp.NextPage = int32(page + 1)
+	}
+	return resp, nil
+}
+
+// StreamWeather отправляет текущую погоду городов из кэша, затем каждый
+// новый замер до отмены вызова клиентом
+func (s *Server) StreamWeather(req *weatherpb.StreamWeatherRequest, stream grpclib.ServerStreamingServer[weatherpb.Weather]) error {
+	if s.feed == nil {
+		return status.Error(codes.Unimplemented, "подписка на обновления кэша выключена")
+	}
+	ctx := stream.Context()
+
+	seen := make(map[string]bool, len(req.GetCities()))
+	var cities []string
+	for _, name := range req.GetCities() {
+		if city := s.city(ctx, name); city != "" && !seen[city] {
+			seen[city] = true
+			cities = append(cities, city)
+		}
+	}
+	if len(cities) == 0 || len(cities) > maxStreamCities {
+		return status.Errorf(codes.InvalidArgument, "ожидается от 1 до %d городов", maxStreamCities)
+	}
+
+	// Подписка до чтения кэша: обновление между ними не потеряется
+	updates := make(chan model.WeatherData, len(cities))
+	for _, city := range cities {
+		ch, cancel := s.feed.Subscribe(city)
+		defer cancel()
+		go func() {
+			for {
+				select {
+				case data := <-ch:
+					select {
+					case updates <- data:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	for _, city := range cities {
+		current, err := s.cache.Get(ctx, cache.CityKey(city))
+		if err != nil {
+			s.logger.WarnContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+		}
+		if current == nil {
+			continue
+		}
+		if err := stream.Send(toProto(*current, true)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case data := <-updates:
+			if err := stream.Send(toProto(data, false)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func toProto(data model.WeatherData, cached bool) *weatherpb.Weather {
+	return &weatherpb.Weather{
+		City:          data.City,
+		Temperature:   data.Temp,
+		Condition:     data.Condition,
+		ConditionCode: string(data.ConditionCode),
+		Provider:      data.Provider,
+		Timestamp:     timestamppb.New(data.Timestamp),
+		Cached:        cached,
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/auth"
+)
+
+// publicPaths не требуют ключа и не расходуют квоту
+var publicPaths = map[string]bool{
+	"/api/v1/health":     true,
+	"/api/v1/version":    true,
+	"/api/v1/auth/token": true,
+}
+
+// AccountHandler проверяет API-ключи и токены пользователей и отдает расход квоты
+type AccountHandler struct {
+	accounts *account.Service
+	tokens   *auth.Tokens // nil — токены не принимаются
+	plans    auth.PlanRoles
+	logger   *slog.Logger
+}
+
+func NewAccountHandler(accounts *account.Service, logger *slog.Logger) *AccountHandler {
+	return &AccountHandler{accounts: accounts, logger: logger}
+}
+
+// SetTokens включает проверку заголовка Authorization: Bearer
+func (h *AccountHandler) SetTokens(tokens *auth.Tokens) {
+	h.tokens = tokens
+}
+
+// SetPlanRoles задает роли аккаунтов по тарифу; без них ключи только читают
+func (h *AccountHandler) SetPlanRoles(plans auth.PlanRoles) {
+	h.plans = plans
+}
+
+// Middleware находит аккаунт по X-API-Key, учитывает запрос и применяет квоту:
+// 401 — ключ неизвестен или обязателен, 402 — доступ приостановлен,
+// 403 — тарифу не хватает роли для маршрута, 429 — месячная квота исчерпана. Запросы с токеном проходят через serveToken,
+// запросы без ключа — через serveAnonymous.
+func (h *AccountHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token := auth.BearerToken(r.Header.Get(auth.HeaderAuthorization)); token != "" {
+			h.serveToken(w, r, next, token)
+			return
+		}
+
+		key := r.Header.Get(account.HeaderAPIKey)
+		if key == "" {
+			if h.accounts.Required() {
+				sendError(w, errcode.Unauthorized, "Требуется API-ключ", "передайте ключ в заголовке "+account.HeaderAPIKey)
+				return
+			}
+			h.serveAnonymous(w, r, next)
+			return
+		}
+
+		ctx := r.Context()
+		now := time.Now()
+		caller, err := h.accounts.Authorize(ctx, key, now)
+		if caller != nil && caller.MonthlyQuota > 0 {
+			w.Header().Set("X-Quota-Limit", strconv.FormatInt(caller.MonthlyQuota, 10))
+			w.Header().Set("X-Quota-Remaining", strconv.FormatInt(max(caller.MonthlyQuota-caller.Requests, 0), 10))
+		}
+
+		switch {
+		case err == nil:
+			// Роль ключа задает тариф: права те же, что у пользователя с токеном
+			if required := auth.RequiredRole(r.Method, routeTemplate(r)); !h.plans.Role(caller.Plan).Allows(required) {
+				sendError(w, errcode.Forbidden, "Недостаточно прав", "тарифу "+caller.Plan+" нужна роль "+string(required))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(account.WithAccount(ctx, caller)))
+		case errors.Is(err, account.ErrUnknownKey):
+			sendError(w, errcode.Unauthorized, "Неверный API-ключ", "")
+		case h.rejectAccount(w, err, now):
+		case h.accounts.Required():
+			h.logger.ErrorContext(ctx, "Ошибка проверки API-ключа", "error", err)
+			w.Header().Set("Retry-After", "5")
+			sendError(w, errcode.ServiceUnavailable, "Проверка API-ключа недоступна", "")
+		default:
+			// Ключ необязателен: при недоступности хранилища обслуживаем анонимно
+			h.logger.WarnContext(ctx, "Ключ не проверен, запрос обслуживается анонимно", "error", err)
+			h.serveAnonymous(w, r, next)
+		}
+	})
+}
+
+// rejectAccount отвечает 402 или 429, если аккаунт приостановлен или исчерпал квоту
+func (h *AccountHandler) rejectAccount(w http.ResponseWriter, err error, now time.Time) bool {
+	switch {
+	case errors.Is(err, account.ErrSuspended):
+		sendError(w, errcode.AccountSuspended, "Доступ приостановлен", "обратитесь к администратору для продления тарифа")
+	case errors.Is(err, account.ErrQuotaExceeded):
+		reset := account.MonthReset(now)
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(reset).Seconds())))
+		sendError(w, errcode.QuotaExceeded, "Месячная квота исчерпана",
+			"квота обновится "+reset.Format(time.RFC3339))
+	default:
+		return false
+	}
+	return true
+}
+
+// serveToken проверяет токен пользователя и его роль для маршрута: 401 — токен
+// неверен или истек, 403 — роли недостаточно. Пользователь, привязанный
+// к аккаунту, работает от его имени и расходует его квоту.
+func (h *AccountHandler) serveToken(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	ctx := r.Context()
+	principal, err := h.tokens.Parse(token)
+	if err != nil {
+		sendError(w, errcode.Unauthorized, "Неверный токен", err.Error())
+		return
+	}
+	if required := auth.RequiredRole(r.Method, routeTemplate(r)); !principal.Role.Allows(required) {
+		sendError(w, errcode.Forbidden, "Недостаточно прав", "нужна роль "+string(required))
+		return
+	}
+	ctx = auth.WithPrincipal(ctx, principal)
+
+	if principal.AccountID != 0 {
+		now := time.Now()
+		caller, err := h.accounts.AuthorizeAccount(ctx, principal.AccountID, now)
+		switch {
+		case err == nil:
+			ctx = account.WithAccount(ctx, caller)
+		case h.rejectAccount(w, err, now):
+			return
+		default:
+			// Аккаунт удален или хранилище недоступно: права роли остаются,
+			// маршруты аккаунта ответят 401
+			h.logger.WarnContext(ctx, "Аккаунт пользователя не проверен", "user", principal.Username, "error", err)
+		}
+	}
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// routeTemplate — шаблон маршрута mux, по которому сопоставлен запрос
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if tmpl, err := current.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return r.URL.Path
+}
+
+// serveAnonymous применяет политику анонимного доступа: 401 для маршрутов,
+// доступных только с ключом, 429 при превышении лимита запросов с адреса
+func (h *AccountHandler) serveAnonymous(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	ctx := r.Context()
+	policy := h.accounts.Anonymous()
+
+	if !policy.Allows(r.Method, routeTemplate(r)) {
+		sendError(w, errcode.Unauthorized, "Требуется API-ключ",
+			"эндпоинт доступен только с ключом в заголовке "+account.HeaderAPIKey)
+		return
+	}
+
+	if limit := policy.RateLimit(); limit > 0 {
+		now := time.Now()
+		n, reset, err := h.accounts.CountAnonymous(ctx, clientAddr(r), now)
+		if err != nil {
+			// Учет не должен ронять API: при недоступности Redis запрос пропускается
+			h.logger.WarnContext(ctx, "Не удалось учесть анонимный запрос", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(max(int64(limit)-n, 0), 10))
+		if n > int64(limit) {
+			w.Header().Set("Retry-After", strconv.Itoa(max(int(reset.Sub(now).Seconds()), 1)))
+			sendError(w, errcode.RateLimited, "Слишком много запросов без API-ключа",
+				"лимит "+strconv.Itoa(limit)+" запросов в минуту; с ключом лимиты выше")
+			return
+		}
+	}
+	next.ServeHTTP(w, r)
+}
+
+// GetUsage возвращает расход квоты аккаунта за текущий месяц
+func (h *AccountHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	caller := account.FromContext(ctx)
+	if caller == nil {
+		sendError(w, errcode.Unauthorized, "Требуется API-ключ", "передайте ключ в заголовке "+account.HeaderAPIKey)
+		return
+	}
+
+	usage, err := h.accounts.Usage(ctx, caller, time.Now())
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения расхода", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// AdminCityHandler управляет справочником городов. Коллектор и планировщик
+// перечитывают справочник, поэтому новый город начинает опрашиваться без
+// перезапуска сервисов.
+type AdminCityHandler struct {
+	weather *WeatherHandler
+}
+
+func NewAdminCityHandler(weather *WeatherHandler) *AdminCityHandler {
+	return &AdminCityHandler{weather: weather}
+}
+
+// requireAdmin отвечает 403, если запрос пришел не с токеном администратора
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if principal := auth.FromContext(r.Context()); principal == nil || !principal.Role.Allows(auth.RoleAdmin) {
+		sendError(w, errcode.Forbidden, "Недостаточно прав", "нужен токен с ролью "+string(auth.RoleAdmin))
+		return false
+	}
+	return true
+}
+
+// decodeCity читает город из тела; enabled по умолчанию true
+func decodeCity(w http.ResponseWriter, r *http.Request) (model.City, bool) {
+	city := model.City{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&city); err != nil {
+		sendBodyError(w, err, "Неверный формат JSON", err.Error())
+		return city, false
+	}
+	city.Name = strings.TrimSpace(city.Name)
+	city.Country = strings.ToUpper(strings.TrimSpace(city.Country))
+	return city, true
+}
+
+// ListCities возвращает весь справочник, включая выключенные города
+func (h *AdminCityHandler) ListCities(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	ctx := r.Context()
+	cities, err := store.ListCities(ctx)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения справочника городов", "error", err)
+		sendStoreError(w, err)
+		return
+	}
+	if cities == nil {
+		cities = []model.City{}
+	}
+	sendJSON(w, http.StatusOK, model.CityDirectoryResponse{Cities: cities, Total: len(cities)})
+}
+
+// GetCity возвращает город справочника по названию или синониму
+func (h *AdminCityHandler) GetCity(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	ctx := r.Context()
+	name := mux.Vars(r)["name"]
+	city, err := store.GetCity(ctx, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		sendError(w, errcode.CityNotFound, "Город не найден", name)
+		return
+	}
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения города", "city", name, "error", err)
+		sendStoreError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, city)
+}
+
+// CreateCity добавляет город {"name","country","lat","lon","timezone","aliases","enabled"}
+// и расписание его опроса; 409 — город уже есть
+func (h *AdminCityHandler) CreateCity(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	city, ok := decodeCity(w, r)
+	if !ok {
+		return
+	}
+	if err := city.Validate(); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	err := store.CreateCity(ctx, city)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		sendError(w, errcode.Conflict, "Город уже есть в справочнике", city.Name)
+		return
+	}
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка добавления города", "city", city.Name, "error", err)
+		sendStoreError(w, err)
+		return
+	}
+
+	h.weather.logger.InfoContext(ctx, "Город добавлен в справочник", "city", city.Name, "enabled", city.Enabled)
+	sendJSON(w, http.StatusCreated, city)
+}
+
+// UpdateCity заменяет координаты, страну, часовой пояс, синонимы и флаг
+// enabled города; название берется из пути
+func (h *AdminCityHandler) UpdateCity(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	city, ok := decodeCity(w, r)
+	if !ok {
+		return
+	}
+	city.Name = name
+	if err := city.Validate(); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	updated, err := store.UpdateCity(ctx, name, city)
+	if errors.Is(err, storage.ErrNotFound) {
+		sendError(w, errcode.CityNotFound, "Город не найден", name)
+		return
+	}
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка обновления города", "city", name, "error", err)
+		sendStoreError(w, err)
+		return
+	}
+
+	h.weather.logger.InfoContext(ctx, "Город в справочнике обновлен", "city", updated.Name, "enabled", updated.Enabled)
+	sendJSON(w, http.StatusOK, updated)
+}
+
+// DeleteCity удаляет город из справочника и расписания; замеры остаются
+func (h *AdminCityHandler) DeleteCity(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	ctx := r.Context()
+	name := mux.Vars(r)["name"]
+	deleted, err := store.DeleteCity(ctx, name)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка удаления города", "city", name, "error", err)
+		sendStoreError(w, err)
+		return
+	}
+	if !deleted {
+		sendError(w, errcode.CityNotFound, "Город не найден", name)
+		return
+	}
+
+	h.weather.logger.InfoContext(ctx, "Город удален из справочника", "city", name)
+	w.WriteHeader(http.StatusNoContent)
+}
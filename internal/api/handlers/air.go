@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+)
+
+// maxAirHistory — наибольший период истории и сводки качества воздуха
+const maxAirHistory = 30 * 24 * time.Hour
+
+// AirHandler отдает качество воздуха из конвейера AQI
+type AirHandler struct {
+	weather    *WeatherHandler
+	cacheTTL   time.Duration
+	staleAfter time.Duration
+}
+
+func NewAirHandler(weather *WeatherHandler, cacheTTL, staleAfter time.Duration) *AirHandler {
+	return &AirHandler{weather: weather, cacheTTL: cacheTTL, staleAfter: staleAfter}
+}
+
+// GetAir возвращает текущее качество воздуха в городе. Устаревший замер из кэша
+// перепроверяется в БД; если свежее нет, ответ помечается stale.
+func (h *AirHandler) GetAir(w http.ResponseWriter, r *http.Request) {
+	city := h.weather.cityParam(r)
+	ctx := r.Context()
+	now := time.Now()
+
+	view, err := h.weather.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+
+	cached, err := h.weather.cache.GetAirQuality(ctx, cache.AirKey(city))
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+	}
+	if cached != nil && now.Sub(cached.Timestamp) <= h.staleAfter {
+		sendJSON(w, http.StatusOK, h.response(*cached, true, now, view))
+		return
+	}
+
+	store := h.weather.db()
+	if store == nil {
+		if cached != nil {
+			// Без БД устаревший замер лучше, чем ничего
+			sendJSON(w, http.StatusOK, h.response(*cached, true, now, view))
+			return
+		}
+		sendReadOnly(w)
+		return
+	}
+
+	data, err := store.GetAirQuality(ctx, city)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if data == nil {
+		sendError(w, errcode.NotFound, "Нет данных о качестве воздуха", "нет замеров для города "+city)
+		return
+	}
+
+	if err := h.weather.cache.SetAirQuality(ctx, cache.AirKey(city), *data, h.cacheTTL); err != nil {
+		h.weather.logger.WarnContext(ctx, "Не удалось сохранить в кэш", "city", city, "error", err)
+	}
+	sendJSON(w, http.StatusOK, h.response(*data, false, now, view))
+}
+
+// GetAirHistory возвращает замеры качества воздуха за последние ?hours= (по умолчанию 24)
+func (h *AirHandler) GetAirHistory(w http.ResponseWriter, r *http.Request) {
+	city := h.weather.cityParam(r)
+	ctx := r.Context()
+
+	from, to, ok := airPeriod(w, r)
+	if !ok {
+		return
+	}
+	view, err := h.weather.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	readings, err := store.GetAirQualityHistory(ctx, city, from, to)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения истории из БД", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	for i := range readings {
+		readings[i].Timestamp = readings[i].Timestamp.In(view.loc)
+	}
+	sendJSON(w, http.StatusOK, model.AirQualityHistoryResponse{City: city, Readings: readings, Total: len(readings)})
+}
+
+// GetAirStats возвращает сводку по качеству воздуха за последние ?hours= (по умолчанию 24)
+func (h *AirHandler) GetAirStats(w http.ResponseWriter, r *http.Request) {
+	city := h.weather.cityParam(r)
+	ctx := r.Context()
+
+	from, to, ok := airPeriod(w, r)
+	if !ok {
+		return
+	}
+	view, err := h.weather.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	readings, err := store.GetAirQualityHistory(ctx, city, from, to)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения истории из БД", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, model.SummarizeAirQuality(city, readings, from.In(view.loc), to.In(view.loc)))
+}
+
+// response дополняет замер возрастом и признаком устаревания
+func (h *AirHandler) response(data model.AirQuality, cached bool, now time.Time, view presentation) model.AirQualityResponse {
+	age := now.Sub(data.Timestamp)
+	data.Timestamp = data.Timestamp.In(view.loc)
+	return model.AirQualityResponse{
+		AirQuality: data,
+		Cached:     cached,
+		Stale:      age > h.staleAfter,
+		AgeSeconds: int64(max(age, 0).Seconds()),
+	}
+}
+
+// airPeriod разбирает ?hours=; при ошибке ответ уже отправлен
+func airPeriod(w http.ResponseWriter, r *http.Request) (from, to time.Time, ok bool) {
+	period := 24 * time.Hour
+	if v := r.URL.Query().Get("hours"); v != "" {
+		hours, err := strconv.Atoi(v)
+		if err != nil || hours < 1 || time.Duration(hours)*time.Hour > maxAirHistory {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр hours",
+				"ожидается целое число часов от 1 до "+strconv.Itoa(int(maxAirHistory.Hours())))
+			return time.Time{}, time.Time{}, false
+		}
+		period = time.Duration(hours) * time.Hour
+	}
+	to = time.Now()
+	return to.Add(-period), to, true
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+)
+
+// Ограничения правил оповещений аккаунта
+const (
+	maxAlertRules       = 100
+	defaultAlertEvents  = 50
+	maxAlertEventsLimit = 500
+)
+
+// AlertHandler — правила оповещений аккаунта и их срабатывания.
+// Правила проверяет агрегатор на каждом сохраненном замере.
+type AlertHandler struct {
+	weather *WeatherHandler
+}
+
+func NewAlertHandler(weather *WeatherHandler) *AlertHandler {
+	return &AlertHandler{weather: weather}
+}
+
+// CreateRule сохраняет правило вида {"city":"Moscow","metric":"temperature","operator":"<","threshold":-20}
+func (h *AlertHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	var req struct {
+		City      string              `json:"city"`
+		Metric    model.AlertMetric   `json:"metric"`
+		Operator  model.AlertOperator `json:"operator"`
+		Threshold float64             `json:"threshold"`
+		Channels  []string            `json:"channels"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendBodyError(w, err, "Неверный формат JSON", err.Error())
+		return
+	}
+
+	rule := model.AlertRule{
+		AccountID: caller.ID,
+		City:      h.weather.geocoder.Load().Canonical(ctx, normalizeCity(req.City)),
+		Metric:    req.Metric,
+		Operator:  req.Operator,
+		Threshold: req.Threshold,
+		Channels:  req.Channels,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := rule.Validate(); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+
+	rules, err := store.ListAlertRules(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения правил оповещений", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if len(rules) >= maxAlertRules {
+		sendError(w, errcode.Conflict, "Слишком много правил оповещений", "удалите правило перед добавлением нового")
+		return
+	}
+
+	rule, err = store.CreateAlertRule(ctx, rule)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка сохранения правила оповещения", "account", caller.Name, "city", rule.City, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+
+	h.weather.logger.InfoContext(ctx, "Правило оповещения создано",
+		"account", caller.Name, "rule_id", rule.ID, "city", rule.City)
+	sendJSON(w, http.StatusCreated, rule)
+}
+
+// ListRules возвращает правила аккаунта
+func (h *AlertHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	rules, err := store.ListAlertRules(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения правил оповещений", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, model.AlertRulesResponse{Rules: rules, Total: len(rules)})
+}
+
+// DeleteRule удаляет правило аккаунта по ID из пути
+func (h *AlertHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id < 1 {
+		sendError(w, errcode.InvalidRequest, "Неверный ID правила", "ожидается положительное целое число")
+		return
+	}
+
+	deleted, err := store.DeleteAlertRule(ctx, caller.ID, id)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка удаления правила оповещения", "account", caller.Name, "rule_id", id, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if !deleted {
+		sendError(w, errcode.NotFound, "Правило не найдено", strconv.FormatInt(id, 10))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTriggered возвращает последние срабатывания правил аккаунта, ?limit= до 500
+func (h *AlertHandler) ListTriggered(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	limit := defaultAlertEvents
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAlertEventsLimit {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр limit",
+				"ожидается число от 1 до "+strconv.Itoa(maxAlertEventsLimit))
+			return
+		}
+		limit = n
+	}
+
+	events, err := store.ListAlertEvents(ctx, caller.ID, limit)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения срабатываний", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, model.AlertEventsResponse{Events: events, Total: len(events)})
+}
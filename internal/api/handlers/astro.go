@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/astro"
+	"github.com/gometeo/app/internal/geocode"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// GetAstro возвращает восход, заход, долготу дня и фазу Луны для города.
+// ?date=YYYY-MM-DD задает дату в часовом поясе ответа, по умолчанию сегодня.
+func (h *WeatherHandler) GetAstro(w http.ResponseWriter, r *http.Request) {
+	city := h.cityParam(r)
+	ctx := r.Context()
+
+	view, err := h.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+
+	day := time.Now().In(view.loc)
+	if v := r.URL.Query().Get("date"); v != "" {
+		if day, err = time.ParseInLocation(time.DateOnly, v, view.loc); err != nil {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр date", "ожидается дата в формате YYYY-MM-DD")
+			return
+		}
+	}
+
+	info, err := h.cityInfo(ctx, city)
+	switch {
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, geocode.ErrNotFound):
+		h.logger.DebugContext(ctx, "Координаты города неизвестны", "city", city, "error", err)
+		sendError(w, errcode.CityNotFound, "Город не найден", city)
+		return
+	case err != nil:
+		h.logger.ErrorContext(ctx, "Ошибка получения координат города", "city", city, "error", err)
+		sendStoreError(w, err)
+		return
+	}
+
+	data := astro.Day(info.Lat, info.Lon, day)
+	data.Translate(view.lang)
+	sendJSON(w, http.StatusOK, model.AstroResponse{
+		City:     info.Name,
+		Lat:      info.Lat,
+		Lon:      info.Lon,
+		Timezone: view.loc.String(),
+		Astro:    data,
+	})
+}
+
+// includeAstro добавляет в ответ астрономические данные на дату замера при ?include=astro.
+// Если координаты города неизвестны, ответ отдается без них.
+func (h *WeatherHandler) includeAstro(ctx context.Context, r *http.Request, city string, resp *model.WeatherResponse, view presentation) {
+	if !includes(r, "astro") {
+		return
+	}
+	info, err := h.cityInfo(ctx, city)
+	if err != nil {
+		h.logger.DebugContext(ctx, "Астрономические данные пропущены: координаты города неизвестны", "city", city, "error", err)
+		return
+	}
+	data := astro.Day(info.Lat, info.Lon, resp.Timestamp.In(view.loc))
+	data.Translate(view.lang)
+	resp.Astro = &data
+}
+
+// cityInfo возвращает справочные данные города: через геокодер, из БД
+// или из стартового набора, если API работает без БД
+func (h *WeatherHandler) cityInfo(ctx context.Context, city string) (*model.City, error) {
+	if geocoder := h.geocoder.Load(); geocoder != nil {
+		if info, err := geocoder.Resolve(ctx, city); err == nil {
+			return info, nil
+		}
+	}
+	if store := h.db(); store != nil {
+		return store.GetCity(ctx, city)
+	}
+	for _, info := range model.DefaultCities {
+		if info.Matches(city) {
+			return &info, nil
+		}
+	}
+	return nil, geocode.ErrNotFound
+}
+
+// includes сообщает, перечислен ли name в ?include= (через запятую)
+func includes(r *http.Request, name string) bool {
+	for _, v := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), name) {
+			return true
+		}
+	}
+	return false
+}
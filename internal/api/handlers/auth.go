@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/model"
+)
+
+// dummyHash сравнивается с паролем неизвестного пользователя, чтобы время
+// ответа не выдавало, существует ли логин
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("gometeo"), bcrypt.DefaultCost)
+
+// AuthHandler выпускает токены пользователей
+type AuthHandler struct {
+	weather *WeatherHandler
+	tokens  *auth.Tokens
+}
+
+func NewAuthHandler(weather *WeatherHandler, tokens *auth.Tokens) *AuthHandler {
+	return &AuthHandler{weather: weather, tokens: tokens}
+}
+
+// IssueToken обменивает {"username","password"} на токен с ролью пользователя
+func (h *AuthHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	if h.tokens == nil {
+		sendError(w, errcode.NotFound, "Аутентификация по токенам выключена", "задайте JWT_SECRET")
+		return
+	}
+	ctx := r.Context()
+
+	var req model.TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendBodyError(w, err, "Неверный формат JSON", err.Error())
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" || req.Password == "" {
+		sendError(w, errcode.InvalidRequest, "Укажите username и password", "")
+		return
+	}
+
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+	user, hash, err := store.GetUserByName(ctx, req.Username)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения пользователя", "user", req.Username, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if user == nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+	}
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)) != nil || !user.Active {
+		sendError(w, errcode.Unauthorized, "Неверный логин или пароль", "")
+		return
+	}
+
+	now := time.Now()
+	token, expires, err := h.tokens.Issue(*user, now)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка выпуска токена", "user", user.Username, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	h.weather.logger.InfoContext(ctx, "Выпущен токен", "user", user.Username, "role", user.Role)
+
+	sendJSON(w, http.StatusOK, model.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(expires.Sub(now).Seconds()),
+		ExpiresAt:   expires.UTC(),
+		Role:        user.Role,
+	})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/badge"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// BadgeHandler рисует SVG-бейдж текущей погоды для встраивания в README и панели
+type BadgeHandler struct {
+	weather *WeatherHandler
+	theme   string
+	ttl     time.Duration
+}
+
+// NewBadgeHandler создает обработчик; theme — тема по умолчанию для запросов без ?theme=
+func NewBadgeHandler(weather *WeatherHandler, theme string, ttl time.Duration) *BadgeHandler {
+	return &BadgeHandler{weather: weather, theme: theme, ttl: ttl}
+}
+
+// GetBadge отдает бейдж /badge/{city}.svg. ?theme= выбирает тему,
+// ?units= и ?lang= действуют как в /weather/{city}.
+func (h *BadgeHandler) GetBadge(w http.ResponseWriter, r *http.Request) {
+	city := h.weather.cityParam(r)
+	ctx := r.Context()
+
+	view, err := h.weather.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+	themeName := r.URL.Query().Get("theme")
+	if themeName == "" {
+		themeName = h.theme
+	}
+	theme, err := badge.Lookup(themeName)
+	if err != nil {
+		sendError(w, errcode.InvalidRequest, "Неверный параметр theme", err.Error())
+		return
+	}
+
+	key := cache.BadgeKey(city, theme.Name, string(view.units), string(view.lang))
+	svg, err := h.weather.cache.GetBytes(ctx, key)
+	if err != nil {
+		h.weather.logger.WarnContext(ctx, "Ошибка чтения бейджа из кэша", "city", city, "error", err)
+	}
+	if svg == nil {
+		data, err := h.weather.cache.Get(ctx, cache.CityKey(city))
+		if err != nil {
+			h.weather.logger.ErrorContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+		}
+		if data == nil {
+			store := h.weather.db()
+			if store == nil {
+				sendReadOnly(w)
+				return
+			}
+			data, err = store.GetByCity(ctx, city)
+			if errors.Is(err, storage.ErrNotFound) {
+				sendError(w, errcode.CityNotFound, "Город не найден", city)
+				return
+			}
+			if err != nil {
+				h.weather.logger.ErrorContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+				sendStoreError(w, err)
+				return
+			}
+		}
+
+		resp := model.WeatherResponse{WeatherData: *data}
+		view.apply(&resp)
+		svg = badge.Render(badge.Badge{
+			City:      resp.City,
+			Temp:      resp.Temp,
+			Units:     resp.Units,
+			Condition: resp.ConditionCode,
+			Title:     resp.Condition,
+		}, theme)
+		if err := h.weather.cache.SetBytes(ctx, key, svg, h.ttl); err != nil {
+			h.weather.logger.WarnContext(ctx, "Не удалось сохранить бейдж в кэш", "city", city, "error", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(svg)))
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.ttl.Seconds())))
+	w.WriteHeader(http.StatusOK)
+	w.Write(svg)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+)
+
+// maxBatchCities — наибольшее число городов в одном запросе /weather/batch
+const maxBatchCities = 100
+
+// GetBatch возвращает погоду для массива городов из тела запроса: кэш читается
+// одним MGET, промахи добираются из БД одним запросом
+func (h *WeatherHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var names []string
+	if err := json.NewDecoder(r.Body).Decode(&names); err != nil {
+		sendBodyError(w, err, "Неверный формат JSON", "ожидается массив названий городов")
+		return
+	}
+	if len(names) == 0 || len(names) > maxBatchCities {
+		sendError(w, errcode.InvalidRequest, "Неверный размер запроса",
+			"ожидается от 1 до "+strconv.Itoa(maxBatchCities)+" городов")
+		return
+	}
+
+	// Те же правила, что для города из пути; повторы схлопываются
+	geocoder := h.geocoder.Load()
+	cities := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		city := geocoder.Canonical(ctx, strings.ToLower(strings.TrimSpace(name)))
+		if city == "" || seen[city] {
+			continue
+		}
+		seen[city] = true
+		cities = append(cities, city)
+	}
+
+	found := make(map[string]model.WeatherData, len(cities))
+	keys := make([]string, len(cities))
+	for i, city := range cities {
+		keys[i] = cache.CityKey(city)
+	}
+	cached, err := h.cache.GetMany(ctx, keys)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка чтения из кэша", "error", err)
+	}
+	var misses []string
+	for i, city := range cities {
+		if i < len(cached) && cached[i] != nil {
+			found[city] = *cached[i]
+			continue
+		}
+		misses = append(misses, city)
+	}
+
+	var response model.BatchResponse
+	if len(misses) > 0 {
+		store := h.db()
+		if store == nil {
+			response.Unavailable = misses
+		} else {
+			rows, err := store.GetByCities(ctx, misses)
+			if err != nil {
+				h.logger.ErrorContext(ctx, "Ошибка чтения из БД", "cities", len(misses), "error", err)
+				sendInternalError(w, err)
+				return
+			}
+			for _, data := range rows {
+				found[data.City] = data
+				if err := h.cache.Set(ctx, cache.CityKey(data.City), data); err != nil {
+					h.logger.WarnContext(ctx, "Не удалось сохранить в кэш", "city", data.City, "error", err)
+				}
+			}
+			for _, city := range misses {
+				if _, ok := found[city]; !ok {
+					response.NotFound = append(response.NotFound, city)
+				}
+			}
+		}
+	}
+
+	response.Results = make([]model.WeatherResponse, 0, len(found))
+	for _, city := range cities {
+		data, ok := found[city]
+		if !ok {
+			continue
+		}
+		view, err := h.presentation(ctx, r, city)
+		if err != nil {
+			sendPresentationError(w, err)
+			return
+		}
+		item := model.WeatherResponse{WeatherData: data, Cached: !slices.Contains(misses, city)}
+		view.apply(&item)
+		response.Results = append(response.Results, item)
+	}
+	response.Total = len(response.Results)
+	sendJSON(w, http.StatusOK, response)
+}
+
+// UpdateWeatherBatch сохраняет массив замеров из тела запроса одной транзакцией
+// и сбрасывает кэш затронутых городов. Замер без времени получает текущее.
+func (h *WeatherHandler) UpdateWeatherBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	var batch []model.WeatherData
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		sendBodyError(w, err, "Неверный формат JSON", "ожидается массив замеров")
+		return
+	}
+
+	// Те же правила, что для города из пути
+	geocoder := h.geocoder.Load()
+	now := time.Now()
+	for i := range batch {
+		batch[i].City = geocoder.Canonical(ctx, strings.ToLower(strings.TrimSpace(batch[i].City)))
+		if batch[i].Timestamp.IsZero() {
+			batch[i].Timestamp = now
+		}
+		batch[i].NormalizeCondition()
+	}
+	if err := model.ValidateBatch(batch); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+
+	if err := store.SaveBatch(ctx, batch); err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка пакетного сохранения в БД", "count", len(batch), "error", err)
+		sendError(w, errcode.FromError(err), "Ошибка сохранения", err.Error())
+		return
+	}
+
+	// Инвалидируем кэш городов и списка городов
+	invalidated := make(map[string]bool, len(batch))
+	for _, data := range batch {
+		if invalidated[data.City] {
+			continue
+		}
+		invalidated[data.City] = true
+		if err := h.cache.Delete(ctx, cache.CityKey(data.City)); err != nil {
+			h.logger.WarnContext(ctx, "Не удалось удалить из кэша", "city", data.City, "error", err)
+		}
+	}
+	h.invalidateCityLists(ctx)
+
+	sendJSON(w, http.StatusOK, map[string]any{"status": "ok", "saved": len(batch), "cities": len(invalidated)})
+
+	h.logger.InfoContext(ctx, "Пакет данных обновлен", "count", len(batch), "cities", len(invalidated))
+}
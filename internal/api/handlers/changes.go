@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+)
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+// GetChanges возвращает города, обновленные после ?since=, и курсор для следующего
+// запроса. since — курсор из прошлого ответа или время RFC3339; без since отдаются
+// все города (первичная синхронизация). ?limit= ограничивает размер страницы.
+func (h *WeatherHandler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var (
+		afterRevision int64
+		updatedAfter  time.Time
+	)
+	if since := query.Get("since"); since != "" {
+		revision, err := strconv.ParseInt(since, 10, 64)
+		switch {
+		case err == nil && revision >= 0:
+			afterRevision = revision
+		default:
+			if updatedAfter, err = time.Parse(time.RFC3339, since); err != nil {
+				sendError(w, errcode.InvalidRequest, "Неверный параметр since",
+					"ожидается курсор из прошлого ответа или время в формате RFC3339")
+				return
+			}
+		}
+	}
+
+	limit := defaultChangesLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxChangesLimit {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр limit",
+				"ожидается число от 1 до "+strconv.Itoa(maxChangesLimit))
+			return
+		}
+		limit = n
+	}
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	changes, cursor, err := store.ListChanges(ctx, afterRevision, updatedAfter, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения изменений", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+
+	response := model.ChangesResponse{
+		Changes: make([]model.WeatherResponse, 0, len(changes)),
+		Cursor:  strconv.FormatInt(cursor, 10),
+		HasMore: len(changes) == limit,
+		Total:   len(changes),
+	}
+	for _, data := range changes {
+		view, err := h.presentation(ctx, r, data.City)
+		if err != nil {
+			sendPresentationError(w, err)
+			return
+		}
+		item := model.WeatherResponse{WeatherData: data}
+		view.apply(&item)
+		response.Changes = append(response.Changes, item)
+	}
+	sendJSON(w, http.StatusOK, response)
+}
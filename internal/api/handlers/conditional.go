@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/account"
+)
+
+// weatherETag строит слабый ETag по времени замера и оформлению ответа:
+// один и тот же замер в других единицах, на другом языке, в другом формате
+// или с другим набором полей — другое тело
+func weatherETag(updatedAt time.Time, view presentation, format string, r *http.Request) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%s|%s|%s|%s|%s|%s", updatedAt.UnixNano(), view.units, view.lang, view.loc,
+		strings.Join(view.fields, ","), format, r.URL.Query().Get("include"))
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
+// notModified выставляет ETag и Last-Modified и сообщает, что клиент уже имеет
+// актуальную версию. If-None-Match приоритетнее If-Modified-Since (RFC 9110).
+// При true ответ 304 уже отправлен.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, updatedAt time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !updatedAt.IsZero() {
+		w.Header().Set("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if !etagMatches(match, etag) {
+			return false
+		}
+	} else if since := r.Header.Get("If-Modified-Since"); since != "" && !updatedAt.IsZero() {
+		t, err := http.ParseTime(since)
+		// Точность заголовка — секунда
+		if err != nil || updatedAt.Truncate(time.Second).After(t) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches сравнивает If-None-Match с ETag слабым сравнением
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl выставляет Cache-Control и Expires по оставшемуся сроку ключа
+// в Redis: CDN и браузер держат ответ не дольше, чем его держит сервер.
+// Ответ с ключом оформлен по настройкам аккаунта, поэтому общие кэши его не хранят.
+func (h *WeatherHandler) cacheControl(ctx context.Context, w http.ResponseWriter, key string) {
+	ttl, err := h.cache.TTL(ctx, key)
+	if err != nil {
+		h.logger.WarnContext(ctx, "Ошибка получения срока жизни ключа", "key", key, "error", err)
+	}
+	// Секунды округляются вниз, чтобы клиент не пережил запись в Redis
+	seconds := int64(ttl / time.Second)
+	if seconds <= 0 {
+		w.Header().Set("Cache-Control", "no-cache")
+		return
+	}
+
+	scope := "public"
+	if account.FromContext(ctx) != nil {
+		scope = "private"
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, seconds))
+	w.Header().Set("Expires", time.Now().Add(time.Duration(seconds)*time.Second).UTC().Format(http.TimeFormat))
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+//go:generate go tool mockgen -destination=../../testutil/mocks/handlers.go -package=mocks -mock_names=Store=MockHandlerStore,Cache=MockHandlerCache . Store,Cache
+
+// Store — операции с БД, нужные обработчикам API; реализуется storage.WeatherStorage
+type Store interface {
+	Ping(ctx context.Context) error
+	Health(ctx context.Context) (map[string]string, error)
+	Save(ctx context.Context, data model.WeatherData) (time.Time, error)
+	SaveBatch(ctx context.Context, batch []model.WeatherData) error
+	Delete(ctx context.Context, city string) (bool, error)
+	GetByCity(ctx context.Context, city string) (*model.WeatherData, error)
+	GetByCities(ctx context.Context, cities []string) ([]model.WeatherData, error)
+	GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error)
+	GetHistoryStats(ctx context.Context, city string, from, to time.Time) (model.WeatherStats, error)
+	GetHistoryAround(ctx context.Context, city string, at time.Time) (before, after *model.WeatherData, err error)
+	GetProviderReadings(ctx context.Context, city string) ([]model.WeatherData, error)
+	GetAllCities(ctx context.Context, opts storage.CityListOptions) ([]string, int, error)
+	ListCityEntries(ctx context.Context) ([]model.CityEntry, error)
+	ListChanges(ctx context.Context, afterRevision int64, updatedAfter time.Time, limit int) ([]model.WeatherData, int64, error)
+	GetCity(ctx context.Context, name string) (*model.City, error)
+	ListCities(ctx context.Context) ([]model.City, error)
+	CreateCity(ctx context.Context, city model.City) error
+	UpdateCity(ctx context.Context, name string, city model.City) (*model.City, error)
+	DeleteCity(ctx context.Context, name string) (bool, error)
+	SearchCities(ctx context.Context, q string, limit int) ([]model.City, error)
+	GetForecasts(ctx context.Context, city string, from, to time.Time) ([]model.Forecast, error)
+	ListQualityScores(ctx context.Context, city string, from, to time.Time) ([]model.QualityScore, error)
+	ListUpdatedAt(ctx context.Context) (map[string]time.Time, error)
+	GetPreferences(ctx context.Context, accountID int64) (model.Preferences, error)
+	SavePreferences(ctx context.Context, accountID int64, prefs model.Preferences) error
+	ListFavorites(ctx context.Context, accountID int64) ([]string, error)
+	AddFavorite(ctx context.Context, accountID int64, city string) error
+	RemoveFavorite(ctx context.Context, accountID int64, city string) (bool, error)
+	GetAirQuality(ctx context.Context, city string) (*model.AirQuality, error)
+	CreateAlertRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error)
+	ListAlertRules(ctx context.Context, accountID int64) ([]model.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, accountID, id int64) (bool, error)
+	ListAlertEvents(ctx context.Context, accountID int64, limit int) ([]model.AlertEvent, error)
+	CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error)
+	CreateAccount(ctx context.Context, account model.Account, keyHash string) (int64, error)
+	GetUserByName(ctx context.Context, username string) (*model.User, string, error)
+	ListWebhooks(ctx context.Context, accountID int64) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, accountID, id int64) (bool, error)
+	GetAirQualityHistory(ctx context.Context, city string, from, to time.Time) ([]model.AirQuality, error)
+}
+
+// Cache — операции с кэшем, нужные обработчикам API; реализуется cache.WeatherCache
+type Cache interface {
+	Get(ctx context.Context, key string) (*model.WeatherData, error)
+	Set(ctx context.Context, key string, data model.WeatherData) error
+	GetMany(ctx context.Context, keys []string) ([]*model.WeatherData, error)
+	Delete(ctx context.Context, key string) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	GetAirQuality(ctx context.Context, key string) (*model.AirQuality, error)
+	SetAirQuality(ctx context.Context, key string, data model.AirQuality, ttl time.Duration) error
+	GetBytes(ctx context.Context, key string) ([]byte, error)
+	SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	IncrCounter(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	TakeToken(ctx context.Context, key string, rate float64, burst int64, now time.Time) (cache.Bucket, error)
+	GetCityList(ctx context.Context, key string) ([]model.CityEntry, error)
+	SetCityList(ctx context.Context, key string, cities []model.CityEntry) error
+	GetMaintenance(ctx context.Context) (*model.Maintenance, error)
+	SetMaintenance(ctx context.Context, m model.Maintenance, ttl time.Duration) error
+}
+
+var (
+	_ Store = (*storage.WeatherStorage)(nil)
+	_ Cache = (*cache.WeatherCache)(nil)
+)
+
+// storeRef позволяет хранить интерфейс в atomic.Pointer
+type storeRef struct {
+	Store
+}
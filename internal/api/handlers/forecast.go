@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+)
+
+// defaultForecastDays — насколько вперед отдается прогноз без ?days=
+const defaultForecastDays = 7
+
+// GetForecast возвращает прогноз города на ?days= дней (по умолчанию 7) от всех
+// провайдеров, включая внутренний (provider="internal")
+func (h *WeatherHandler) GetForecast(w http.ResponseWriter, r *http.Request) {
+	city := h.cityParam(r)
+	ctx := r.Context()
+
+	days := defaultForecastDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > model.MaxForecastDays {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр days",
+				"ожидается число от 1 до "+strconv.Itoa(model.MaxForecastDays))
+			return
+		}
+		days = n
+	}
+
+	view, err := h.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	now := time.Now()
+	forecasts, err := store.GetForecasts(ctx, city, now, now.Add(time.Duration(days)*24*time.Hour))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения прогноза из БД", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if len(forecasts) == 0 {
+		sendError(w, errcode.NotFound, "Прогноз не найден", "нет прогноза для города "+city)
+		return
+	}
+
+	resp := model.ForecastResponse{
+		City:      forecasts[0].City,
+		Days:      days,
+		Forecasts: forecasts,
+		Total:     len(forecasts),
+	}
+	resp.ConvertUnits(view.units)
+	sendJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gometeo/app/internal/api/errcode"
+)
+
+// Форматы ответа; ошибки всегда отдаются в JSON
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXML  = "xml"
+)
+
+// exporter — ответ, который умеет выгружаться в CSV и XML
+type exporter interface {
+	CSV() (header []string, rows [][]string)
+	XML() any
+}
+
+// responseFormat выбирает формат ответа: ?format= приоритетнее заголовка Accept.
+// Неизвестный Accept дает JSON, неизвестный ?format= — ошибку.
+func responseFormat(r *http.Request) (string, error) {
+	if v := r.URL.Query().Get("format"); v != "" {
+		switch f := strings.ToLower(v); f {
+		case formatJSON, formatCSV, formatXML:
+			return f, nil
+		default:
+			return "", fmt.Errorf("неизвестный формат %q, допустимо: json, csv, xml", v)
+		}
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			return formatJSON, nil
+		case "text/csv":
+			return formatCSV, nil
+		case "application/xml", "text/xml":
+			return formatXML, nil
+		}
+	}
+	return formatJSON, nil
+}
+
+// sendFormatError отдает 400 для неверного ?format=
+func sendFormatError(w http.ResponseWriter, err error) {
+	sendError(w, errcode.InvalidRequest, "Неверный параметр format", err.Error())
+}
+
+// sendAs отдает ответ в выбранном формате
+func sendAs(w http.ResponseWriter, format string, status int, data exporter) {
+	switch format {
+	case formatCSV:
+		header, rows := data.CSV()
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Vary", "Accept")
+		w.WriteHeader(status)
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+	case formatXML:
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Header().Set("Vary", "Accept")
+		w.WriteHeader(status)
+		w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		enc.Encode(data.XML())
+	default:
+		w.Header().Set("Vary", "Accept")
+		sendJSON(w, status, data)
+	}
+}
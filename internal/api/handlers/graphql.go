@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// Ограничения запроса GraphQL
+const (
+	graphqlMaxDepth       = 5
+	graphqlMaxQueryLength = 10000
+)
+
+// graphqlSchema — текущая погода, история и список городов. Оформление
+// (units, lang, tz) задается аргументами так же, как параметрами REST.
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	"Текущая погода города; null, если города нет"
+	weather(city: String!, units: String, lang: String, tz: String): Weather
+	"Погода нескольких городов за один запрос; города без данных пропускаются"
+	weatherBatch(cities: [String!]!, units: String, lang: String, tz: String): [Weather!]!
+	"Замеры города за период [from, to] в RFC3339; по умолчанию последние сутки"
+	history(city: String!, from: String, to: String, units: String, lang: String, tz: String): [Weather!]!
+	"Все города с данными"
+	cities: [String!]!
+}
+
+"Замер погоды в единицах и часовом поясе запроса"
+type Weather {
+	city: String!
+	temperature: Float!
+	units: String!
+	condition: String!
+	conditionCode: String
+	provider: String!
+	timestamp: String!
+	timestampUtc: String!
+	timezone: String!
+	cached: Boolean!
+}
+`
+
+// NewGraphQLHandler возвращает обработчик POST /graphql поверх данных REST API
+func NewGraphQLHandler(weather *WeatherHandler) http.Handler {
+	schema := graphql.MustParseSchema(graphqlSchema, &graphqlResolver{weather: weather},
+		graphql.UseStringDescriptions(),
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(graphqlMaxDepth),
+		graphql.MaxQueryLength(graphqlMaxQueryLength),
+	)
+	return &relay.Handler{Schema: schema}
+}
+
+// graphqlResolver — корневой резолвер Query
+type graphqlResolver struct {
+	weather *WeatherHandler
+}
+
+// graphqlWeather — тип Weather схемы
+type graphqlWeather struct {
+	City          string  `graphql:"city"`
+	Temperature   float64 `graphql:"temperature"`
+	Units         string  `graphql:"units"`
+	Condition     string  `graphql:"condition"`
+	ConditionCode *string `graphql:"conditionCode"`
+	Provider      string  `graphql:"provider"`
+	Timestamp     string  `graphql:"timestamp"`
+	TimestampUTC  string  `graphql:"timestampUtc"`
+	Timezone      string  `graphql:"timezone"`
+	Cached        bool    `graphql:"cached"`
+}
+
+// graphqlView — аргументы оформления, общие для запросов погоды
+type graphqlView struct {
+	Units *string
+	Lang  *string
+	Tz    *string
+}
+
+func (v graphqlView) params() presentationParams {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	return presentationParams{units: deref(v.Units), lang: deref(v.Lang), tz: deref(v.Tz)}
+}
+
+func (r *graphqlResolver) Weather(ctx context.Context, args struct {
+	City string
+	graphqlView
+}) (*graphqlWeather, error) {
+	city := r.weather.geocoder.Load().Canonical(ctx, normalizeCity(args.City))
+	view, err := r.weather.presentationOf(ctx, city, args.params())
+	if err != nil {
+		return nil, err
+	}
+	data, cached, err := r.weather.current(ctx, city)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return newGraphqlWeather(*data, cached, view), nil
+}
+
+func (r *graphqlResolver) WeatherBatch(ctx context.Context, args struct {
+	Cities []string
+	graphqlView
+}) ([]*graphqlWeather, error) {
+	if len(args.Cities) > maxBatchCities {
+		return nil, fmt.Errorf("не больше %d городов за запрос", maxBatchCities)
+	}
+
+	result := make([]*graphqlWeather, 0, len(args.Cities))
+	seen := make(map[string]bool, len(args.Cities))
+	for _, name := range args.Cities {
+		city := r.weather.geocoder.Load().Canonical(ctx, normalizeCity(name))
+		if city == "" || seen[city] {
+			continue
+		}
+		seen[city] = true
+
+		view, err := r.weather.presentationOf(ctx, city, args.params())
+		if err != nil {
+			return nil, err
+		}
+		data, cached, err := r.weather.current(ctx, city)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			result = append(result, newGraphqlWeather(*data, cached, view))
+		}
+	}
+	return result, nil
+}
+
+func (r *graphqlResolver) History(ctx context.Context, args struct {
+	City string
+	From *string
+	To   *string
+	graphqlView
+}) ([]*graphqlWeather, error) {
+	city := r.weather.geocoder.Load().Canonical(ctx, normalizeCity(args.City))
+
+	to := time.Now()
+	if args.To != nil {
+		t, err := time.Parse(time.RFC3339, *args.To)
+		if err != nil {
+			return nil, errors.New("to: ожидается время в формате RFC3339")
+		}
+		to = t
+	}
+	from := to.Add(-defaultHistoryPeriod)
+	if args.From != nil {
+		t, err := time.Parse(time.RFC3339, *args.From)
+		if err != nil {
+			return nil, errors.New("from: ожидается время в формате RFC3339")
+		}
+		from = t
+	}
+	if !from.Before(to) || to.Sub(from) > maxHistoryPeriod {
+		return nil, errors.New("период: from раньше to и не больше 31 дня")
+	}
+
+	view, err := r.weather.presentationOf(ctx, city, args.params())
+	if err != nil {
+		return nil, err
+	}
+	store := r.weather.db()
+	if store == nil {
+		return nil, ErrReadOnly
+	}
+	history, err := store.GetHistory(ctx, city, from, to)
+	if err != nil {
+		r.weather.logger.ErrorContext(ctx, "Ошибка получения истории из БД", "city", city, "error", err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+
+	result := make([]*graphqlWeather, 0, len(history))
+	for _, data := range history {
+		result = append(result, newGraphqlWeather(data, false, view))
+	}
+	return result, nil
+}
+
+func (r *graphqlResolver) Cities(ctx context.Context) ([]string, error) {
+	store := r.weather.db()
+	if store == nil {
+		return nil, ErrReadOnly
+	}
+	cities, _, err := store.GetAllCities(ctx, storage.CityListOptions{})
+	if err != nil {
+		r.weather.logger.ErrorContext(ctx, "Ошибка получения городов из БД", "error", err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+	if cities == nil {
+		cities = []string{}
+	}
+	return cities, nil
+}
+
+// current возвращает погоду города из кэша, при промахе — из БД с записью в кэш.
+// nil без ошибки — города нет.
+func (h *WeatherHandler) current(ctx context.Context, city string) (*model.WeatherData, bool, error) {
+	cached, err := h.cache.Get(ctx, cache.CityKey(city))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+	}
+	if cached != nil {
+		return cached, true, nil
+	}
+
+	store := h.db()
+	if store == nil {
+		return nil, false, ErrReadOnly
+	}
+	data, err := store.GetByCity(ctx, city)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+		return nil, false, errors.New("внутренняя ошибка сервера")
+	}
+	if err := h.cache.Set(ctx, cache.CityKey(city), *data); err != nil {
+		h.logger.WarnContext(ctx, "Не удалось сохранить в кэш", "city", city, "error", err)
+	}
+	return data, false, nil
+}
+
+func newGraphqlWeather(data model.WeatherData, cached bool, view presentation) *graphqlWeather {
+	resp := model.WeatherResponse{WeatherData: data, Cached: cached}
+	view.apply(&resp)
+	rec := resp.Record()
+
+	w := &graphqlWeather{
+		City:         rec.City,
+		Temperature:  rec.Temperature,
+		Units:        string(rec.Units),
+		Condition:    rec.Condition,
+		Provider:     rec.Provider,
+		Timestamp:    rec.Timestamp,
+		TimestampUTC: rec.TimestampUTC,
+		Timezone:     rec.Timezone,
+		Cached:       rec.Cached,
+	}
+	if rec.ConditionCode != "" {
+		w.ConditionCode = &rec.ConditionCode
+	}
+	return w
+}
+
+// normalizeCity приводит название к виду города из пути REST API
+func normalizeCity(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+)
+
+const (
+	defaultHistoryPeriod = 24 * time.Hour
+	maxHistoryPeriod     = 31 * 24 * time.Hour
+)
+
+// GetHistory возвращает замеры города за [?from=, ?to=] (RFC3339).
+// По умолчанию to — текущее время, from — на сутки раньше to.
+// Кроме JSON отдается CSV и XML по Accept или ?format=.
+func (h *WeatherHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	city := h.cityParam(r)
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	to := time.Now()
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр to", "ожидается время в формате RFC3339")
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultHistoryPeriod)
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр from", "ожидается время в формате RFC3339")
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		sendError(w, errcode.InvalidRequest, "Неверный период", "from должно быть раньше to")
+		return
+	}
+	if to.Sub(from) > maxHistoryPeriod {
+		sendError(w, errcode.InvalidRequest, "Слишком длинный период", "не больше 31 дня за запрос")
+		return
+	}
+
+	view, err := h.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+	format, err := responseFormat(r)
+	if err != nil {
+		sendFormatError(w, err)
+		return
+	}
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	history, err := store.GetHistory(ctx, city, from, to)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения истории из БД", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+
+	response := model.HistoryResponse{
+		City:     city,
+		From:     from.In(view.loc),
+		To:       to.In(view.loc),
+		Readings: make([]model.WeatherResponse, 0, len(history)),
+		Total:    len(history),
+	}
+	for _, data := range history {
+		reading := model.WeatherResponse{WeatherData: data}
+		view.apply(&reading)
+		response.Readings = append(response.Readings, reading)
+	}
+	sendAs(w, format, http.StatusOK, response)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/model"
+)
+
+// KeyHandler выпускает API-ключи; доступен только администраторам с токеном
+type KeyHandler struct {
+	weather *WeatherHandler
+}
+
+func NewKeyHandler(weather *WeatherHandler) *KeyHandler {
+	return &KeyHandler{weather: weather}
+}
+
+// CreateKey создает аккаунт {"name","plan","monthly_quota"} и возвращает его ключ.
+// Ключ показывается только в этом ответе, в БД хранится его хэш.
+func (h *KeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req model.CreateKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendBodyError(w, err, "Неверный формат JSON", err.Error())
+		return
+	}
+	acc := model.Account{
+		Name:         strings.TrimSpace(req.Name),
+		Plan:         strings.TrimSpace(req.Plan),
+		MonthlyQuota: req.MonthlyQuota,
+		Active:       true,
+	}
+	if acc.Plan == "" {
+		acc.Plan = "free"
+	}
+	if err := acc.Validate(); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+	key, err := account.GenerateKey()
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка генерации ключа", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	acc.ID, err = store.CreateAccount(ctx, acc, account.HashKey(key))
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка создания аккаунта", "account", acc.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	h.weather.logger.InfoContext(ctx, "Выпущен API-ключ", "account", acc.Name, "id", acc.ID)
+
+	sendJSON(w, http.StatusCreated, model.KeyResponse{Account: acc, Key: key})
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+)
+
+const (
+	maxLiveCities      = 50
+	liveWriteWait      = 10 * time.Second
+	livePongWait       = 60 * time.Second
+	livePingPeriod     = livePongWait * 9 / 10
+	liveMaxMessageSize = 4 << 10
+)
+
+// Origin проверяется по умолчанию: подключения только с того же хоста
+var liveUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// liveSub — подписка соединения на город
+type liveSub struct {
+	view   presentation
+	cancel context.CancelFunc
+}
+
+// ServeLive открывает WebSocket с подписками на обновления нескольких городов.
+// Клиент шлет {"action":"subscribe","cities":[...]} и получает кадры
+// {"type":"weather",...} по мере прихода новых замеров. Оформление ответа
+// задают ?units=, ?lang=, ?tz= запроса на подключение.
+func (h *WeatherHandler) ServeLive(w http.ResponseWriter, r *http.Request) {
+	if h.feed == nil {
+		sendError(w, errcode.NotImplemented, "Подписка на обновления недоступна",
+			"подписка на обновления кэша выключена")
+		return
+	}
+
+	done, ok := h.streams.open()
+	if !ok {
+		sendShuttingDown(w)
+		return
+	}
+	defer done()
+
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade уже ответил клиенту ошибкой
+		h.logger.DebugContext(r.Context(), "Не удалось открыть WebSocket", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	requests := make(chan model.LiveRequest)
+	go h.readLive(ctx, conn, requests)
+
+	updates := make(chan model.WeatherData, maxLiveCities)
+	subs := make(map[string]liveSub)
+	defer func() {
+		for _, sub := range subs {
+			sub.cancel()
+		}
+	}()
+
+	ping := time.NewTicker(livePingPeriod)
+	defer ping.Stop()
+	drain := h.streams.draining()
+
+	h.logger.InfoContext(ctx, "WebSocket открыт", "remote_addr", r.RemoteAddr)
+	defer h.logger.InfoContext(ctx, "WebSocket закрыт", "remote_addr", r.RemoteAddr)
+
+	for {
+		var err error
+		select {
+		case req, ok := <-requests:
+			if !ok {
+				return
+			}
+			err = h.handleLiveRequest(ctx, r, conn, req, subs, updates)
+		case data := <-updates:
+			sub, ok := subs[strings.ToLower(data.City)]
+			if !ok {
+				// Отписка пришла раньше обновления
+				continue
+			}
+			err = writeLive(conn, h.liveWeather(data, sub.view))
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			err = conn.WriteMessage(websocket.PingMessage, nil)
+		case <-drain:
+			// Клиент переподключится к другому экземпляру
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "сервер останавливается"),
+				time.Now().Add(liveWriteWait))
+			return
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			h.logger.DebugContext(ctx, "Ошибка записи в WebSocket", "error", err)
+			return
+		}
+	}
+}
+
+// readLive читает сообщения клиента до ошибки или закрытия соединения,
+// после чего закрывает requests
+func (h *WeatherHandler) readLive(ctx context.Context, conn *websocket.Conn, requests chan<- model.LiveRequest) {
+	defer close(requests)
+
+	conn.SetReadLimit(liveMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(livePongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(livePongWait))
+	})
+
+	for {
+		var req model.LiveRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				h.logger.DebugContext(ctx, "Ошибка чтения из WebSocket", "error", err)
+			}
+			return
+		}
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleLiveRequest меняет подписки соединения. Ошибки в сообщении клиента
+// отправляются ему кадром error; возвращается только ошибка записи.
+func (h *WeatherHandler) handleLiveRequest(ctx context.Context, r *http.Request, conn *websocket.Conn,
+	req model.LiveRequest, subs map[string]liveSub, updates chan<- model.WeatherData) error {
+	cities := make([]string, 0, len(req.Cities))
+	for _, city := range req.Cities {
+		city = strings.ToLower(strings.TrimSpace(city))
+		if city == "" {
+			continue
+		}
+		cities = append(cities, h.geocoder.Load().Canonical(ctx, city))
+	}
+
+	switch req.Action {
+	case model.LiveSubscribe:
+		var snapshots []model.LiveMessage
+		for _, city := range cities {
+			key := strings.ToLower(city)
+			if _, ok := subs[key]; ok {
+				continue
+			}
+			if len(subs) >= maxLiveCities {
+				return writeLive(conn, model.LiveMessage{Type: model.LiveError,
+					Error: fmt.Sprintf("не больше %d городов на соединение", maxLiveCities)})
+			}
+			view, err := h.presentation(ctx, r, city)
+			if err != nil {
+				return writeLive(conn, model.LiveMessage{Type: model.LiveError, Error: err.Error()})
+			}
+			subs[key] = liveSub{view: view, cancel: h.forwardLive(ctx, city, updates)}
+
+			// Текущий замер сразу, не дожидаясь следующего
+			if current, err := h.cache.Get(ctx, cache.CityKey(city)); err == nil && current != nil {
+				snapshots = append(snapshots, h.liveWeather(*current, view))
+			}
+		}
+		if err := writeLive(conn, liveSubscribed(subs)); err != nil {
+			return err
+		}
+		for _, msg := range snapshots {
+			if err := writeLive(conn, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case model.LiveUnsubscribe:
+		for _, city := range cities {
+			key := strings.ToLower(city)
+			if sub, ok := subs[key]; ok {
+				sub.cancel()
+				delete(subs, key)
+			}
+		}
+		return writeLive(conn, liveSubscribed(subs))
+
+	default:
+		return writeLive(conn, model.LiveMessage{Type: model.LiveError,
+			Error: fmt.Sprintf("неизвестное действие %q, ожидается subscribe или unsubscribe", req.Action)})
+	}
+}
+
+// forwardLive пересылает обновления города из хаба в общий канал соединения.
+// Возвращает функцию отписки.
+func (h *WeatherHandler) forwardLive(ctx context.Context, city string, updates chan<- model.WeatherData) context.CancelFunc {
+	ctx, cancel := context.WithCancel(ctx)
+	ch, unsubscribe := h.feed.Subscribe(city)
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case data := <-ch:
+				select {
+				case updates <- data:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+// liveWeather оформляет замер для кадра weather
+func (h *WeatherHandler) liveWeather(data model.WeatherData, view presentation) model.LiveMessage {
+	response := model.WeatherResponse{WeatherData: data}
+	view.apply(&response)
+	return model.LiveMessage{Type: model.LiveWeather, Weather: &response}
+}
+
+// liveSubscribed перечисляет подписки соединения
+func liveSubscribed(subs map[string]liveSub) model.LiveMessage {
+	cities := make([]string, 0, len(subs))
+	for city := range subs {
+		cities = append(cities, city)
+	}
+	slices.Sort(cities)
+	return model.LiveMessage{Type: model.LiveSubscribed, Cities: cities}
+}
+
+// writeLive отправляет кадр с таймаутом записи
+func writeLive(conn *websocket.Conn, msg model.LiveMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+	return conn.WriteJSON(msg)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+)
+
+// maintenanceRefresh — как часто экземпляр перечитывает флаг из Redis;
+// на столько же запаздывает включение режима на остальных экземплярах
+const maintenanceRefresh = 2 * time.Second
+
+// maintenanceExempt — пути, которые работают и в режиме обслуживания:
+// проверки здоровья для балансировщика и оркестратора, выпуск токена
+// и маршруты администратора, чтобы режим можно было выключить
+var maintenanceExempt = []string{
+	"/api/v1/health",
+	"/api/v1/version",
+	"/livez",
+	"/readyz",
+	"/metrics",
+	"/api/v1/auth/token",
+	"/api/v1/admin",
+}
+
+// maintenanceState — последний прочитанный флаг
+type maintenanceState struct {
+	current *model.Maintenance // nil — режим выключен
+	checked time.Time
+}
+
+// MaintenanceHandler включает и выключает режим обслуживания. Флаг хранится
+// в Redis и общий для всех экземпляров API: во время миграции схемы клиенты
+// получают 503 с Retry-After вместо ошибок базы.
+type MaintenanceHandler struct {
+	weather *WeatherHandler
+
+	state   atomic.Pointer[maintenanceState]
+	refresh sync.Mutex // один запрос к Redis на обновление
+}
+
+func NewMaintenanceHandler(weather *WeatherHandler) *MaintenanceHandler {
+	return &MaintenanceHandler{weather: weather}
+}
+
+// current возвращает действующий режим, перечитывая флаг не чаще maintenanceRefresh.
+// Пока Redis недоступен, действует последнее прочитанное значение.
+func (h *MaintenanceHandler) current(r *http.Request) *model.Maintenance {
+	now := time.Now()
+	state := h.state.Load()
+	if state != nil && now.Sub(state.checked) < maintenanceRefresh {
+		return state.active(now)
+	}
+	// Остальные запросы не ждут обновления и берут прежнее значение
+	if !h.refresh.TryLock() {
+		return state.active(now)
+	}
+	defer h.refresh.Unlock()
+
+	ctx := r.Context()
+	m, err := h.weather.cache.GetMaintenance(ctx)
+	if err != nil {
+		h.weather.logger.WarnContext(ctx, "Не удалось прочитать флаг режима обслуживания", "error", err)
+		if state != nil {
+			m = state.current
+		}
+	}
+	next := &maintenanceState{current: m, checked: now}
+	h.state.Store(next)
+	return next.active(now)
+}
+
+// active учитывает плановое окончание, пока флаг в Redis еще не истек
+func (s *maintenanceState) active(now time.Time) *model.Maintenance {
+	if s == nil || s.current == nil {
+		return nil
+	}
+	if s.current.Until != nil && !now.Before(*s.current.Until) {
+		return nil
+	}
+	return s.current
+}
+
+// Middleware отвечает 503 MAINTENANCE с Retry-After на все запросы, кроме
+// проверок здоровья и маршрутов администратора
+func (h *MaintenanceHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maintenanceExempted(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		m := h.current(r)
+		if m == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := m.RetryAfterSeconds
+		if m.Until != nil {
+			retryAfter = max(min(retryAfter, int(time.Until(*m.Until).Seconds())+1), 1)
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		message := m.Message
+		if message == "" {
+			message = "повторите запрос позже"
+		}
+		sendError(w, errcode.Maintenance, "Сервис на обслуживании", message)
+	})
+}
+
+func maintenanceExempted(path string) bool {
+	for _, prefix := range maintenanceExempt {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMaintenance возвращает состояние режима обслуживания
+func (h *MaintenanceHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	ctx := r.Context()
+	m, err := h.weather.cache.GetMaintenance(ctx)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения флага режима обслуживания", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, model.MaintenanceStatus{Enabled: m != nil, Maintenance: m})
+}
+
+// EnableMaintenance включает режим обслуживания на всех экземплярах
+func (h *MaintenanceHandler) EnableMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	var req model.MaintenanceRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendBodyError(w, err, "Неверный формат JSON", err.Error())
+			return
+		}
+	}
+	if req.RetryAfterSeconds < 0 || req.DurationSeconds < 0 {
+		sendError(w, errcode.InvalidRequest, "Неверные параметры режима обслуживания",
+			"retry_after_seconds и duration_seconds не могут быть отрицательными")
+		return
+	}
+
+	now := time.Now().UTC()
+	m := model.Maintenance{
+		Message:           strings.TrimSpace(req.Message),
+		RetryAfterSeconds: req.RetryAfterSeconds,
+		Since:             now,
+	}
+	if m.RetryAfterSeconds == 0 {
+		m.RetryAfterSeconds = model.DefaultMaintenanceRetryAfter
+	}
+	var ttl time.Duration
+	if req.DurationSeconds > 0 {
+		ttl = time.Duration(req.DurationSeconds) * time.Second
+		until := now.Add(ttl)
+		m.Until = &until
+	}
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		m.By = principal.Username
+	}
+
+	ctx := r.Context()
+	if err := h.weather.cache.SetMaintenance(ctx, m, ttl); err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка включения режима обслуживания", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	h.state.Store(&maintenanceState{current: &m, checked: time.Now()})
+	h.weather.logger.WarnContext(ctx, "Режим обслуживания включен", "by", m.By, "until", m.Until)
+	sendJSON(w, http.StatusOK, model.MaintenanceStatus{Enabled: true, Maintenance: &m})
+}
+
+// DisableMaintenance выключает режим обслуживания
+func (h *MaintenanceHandler) DisableMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	ctx := r.Context()
+	if err := h.weather.cache.Delete(ctx, cache.MaintenanceKey()); err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка выключения режима обслуживания", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	h.state.Store(&maintenanceState{checked: time.Now()})
+	h.weather.logger.WarnContext(ctx, "Режим обслуживания выключен")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// maxFavorites — сколько городов аккаунт может держать в избранном
+const maxFavorites = 50
+
+// MeHandler — избранные города и настройки аккаунта вызывающего
+type MeHandler struct {
+	weather  *WeatherHandler
+	accounts *AccountHandler
+}
+
+func NewMeHandler(weather *WeatherHandler, accounts *AccountHandler) *MeHandler {
+	return &MeHandler{weather: weather, accounts: accounts}
+}
+
+// caller возвращает аккаунт и БД запроса; при ошибке ответ уже отправлен.
+// Используется обработчиками данных аккаунта: настройки, избранное, оповещения.
+func (h *WeatherHandler) caller(w http.ResponseWriter, r *http.Request) (*account.Caller, Store) {
+	caller := account.FromContext(r.Context())
+	if caller == nil {
+		sendError(w, errcode.Unauthorized, "Требуется API-ключ", "передайте ключ в заголовке "+account.HeaderAPIKey)
+		return nil, nil
+	}
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return nil, nil
+	}
+	return caller, store
+}
+
+// GetPreferences возвращает настройки аккаунта
+func (h *MeHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	prefs, err := store.GetPreferences(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения настроек", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences сохраняет настройки; незаданные поля сохраняют прежние значения
+func (h *MeHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	var req struct {
+		Units    *string `json:"units"`
+		Language *string `json:"language"`
+		Timezone *string `json:"timezone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendBodyError(w, err, "Неверный формат данных", err.Error())
+		return
+	}
+
+	prefs, err := store.GetPreferences(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения настроек", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if req.Units != nil {
+		if prefs.Units, err = model.ParseUnits(*req.Units); err != nil {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр units", err.Error())
+			return
+		}
+	}
+	if req.Language != nil {
+		if prefs.Language, err = model.ParseLanguage(*req.Language); err != nil {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр language", err.Error())
+			return
+		}
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр timezone", err.Error())
+			return
+		}
+		prefs.Timezone = *req.Timezone
+	}
+	prefs.UpdatedAt = time.Now().UTC()
+
+	if err := store.SavePreferences(ctx, caller.ID, prefs); err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка сохранения настроек", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	// Следующие запросы с этим ключом сразу получат новые настройки
+	h.accounts.accounts.Forget(r.Header.Get(account.HeaderAPIKey))
+	sendJSON(w, http.StatusOK, prefs)
+}
+
+// GetFavorites возвращает избранные города
+func (h *MeHandler) GetFavorites(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	cities, err := store.ListFavorites(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения избранного", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, model.FavoritesResponse{Cities: cities, Total: len(cities)})
+}
+
+// AddFavorite добавляет город из пути в избранное
+func (h *MeHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+	city := h.weather.cityParam(r)
+
+	cities, err := store.ListFavorites(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения избранного", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if len(cities) >= maxFavorites {
+		sendError(w, errcode.Conflict, "Слишком много избранных городов", "удалите город перед добавлением нового")
+		return
+	}
+
+	if err := store.AddFavorite(ctx, caller.ID, city); err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка добавления в избранное", "account", caller.Name, "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFavorite удаляет город из пути из избранного
+func (h *MeHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+	city := h.weather.cityParam(r)
+
+	removed, err := store.RemoveFavorite(ctx, caller.ID, city)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка удаления из избранного", "account", caller.Name, "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if !removed {
+		sendError(w, errcode.NotFound, "Город не в избранном", city)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMyWeather возвращает погоду всех избранных городов в единицах и на языке аккаунта;
+// параметры запроса перекрывают настройки, как и в остальных ответах
+func (h *MeHandler) GetMyWeather(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	prefs, err := store.GetPreferences(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения настроек", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	cities, err := store.ListFavorites(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения избранного", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+
+	resp := model.MyWeatherResponse{Preferences: prefs, Weather: make([]model.WeatherResponse, 0, len(cities))}
+	for _, city := range cities {
+		data, cached := h.current(ctx, store, city)
+		if data == nil {
+			resp.Missing = append(resp.Missing, city)
+			continue
+		}
+		view, err := h.weather.presentation(ctx, r, city)
+		if err != nil {
+			sendPresentationError(w, err)
+			return
+		}
+
+		item := model.WeatherResponse{WeatherData: *data, Cached: cached}
+		view.apply(&item)
+		resp.Weather = append(resp.Weather, item)
+	}
+	sendJSON(w, http.StatusOK, resp)
+}
+
+// current возвращает последний замер города из кэша или БД, nil если данных нет
+func (h *MeHandler) current(ctx context.Context, store Store, city string) (*model.WeatherData, bool) {
+	data, err := h.weather.cache.Get(ctx, cache.CityKey(city))
+	if err != nil {
+		h.weather.logger.WarnContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+	}
+	if data != nil {
+		return data, true
+	}
+
+	data, err = store.GetByCity(ctx, city)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.weather.logger.DebugContext(ctx, "Нет данных для избранного города", "city", city)
+		return nil, false
+	}
+	if err != nil {
+		// Один город без данных не должен ломать весь список избранного
+		h.weather.logger.WarnContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+		return nil, false
+	}
+	if err := h.weather.cache.Set(ctx, cache.CityKey(city), *data); err != nil {
+		h.weather.logger.WarnContext(ctx, "Не удалось сохранить в кэш", "city", city, "error", err)
+	}
+	return data, false
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+)
+
+// presentation — единицы, язык, часовой пояс и поля ответа. Параметры запроса
+// ?units=, ?lang=, ?tz= перекрывают настройки аккаунта, те — значения по умолчанию.
+// ?fields= сокращает JSON до перечисленных полей.
+type presentation struct {
+	units  model.Units
+	lang   model.Language
+	loc    *time.Location
+	fields []string
+}
+
+// presentationParams — оформление, заданное клиентом; пустое значение — из настроек аккаунта
+type presentationParams struct {
+	units, lang, tz, fields string
+}
+
+// presentation определяет оформление ответа для города по параметрам запроса
+func (h *WeatherHandler) presentation(ctx context.Context, r *http.Request, city string) (presentation, error) {
+	query := r.URL.Query()
+	return h.presentationOf(ctx, city, presentationParams{
+		units:  query.Get("units"),
+		lang:   query.Get("lang"),
+		tz:     query.Get("tz"),
+		fields: query.Get("fields"),
+	})
+}
+
+// presentationOf определяет оформление ответа для города по явным параметрам
+func (h *WeatherHandler) presentationOf(ctx context.Context, city string, params presentationParams) (presentation, error) {
+	prefs := model.DefaultPreferences()
+	if caller := account.FromContext(ctx); caller != nil {
+		prefs = caller.Preferences
+	}
+
+	p := presentation{units: prefs.Units, lang: prefs.Language}
+	var err error
+	if params.units != "" {
+		if p.units, err = model.ParseUnits(params.units); err != nil {
+			return presentation{}, fmt.Errorf("units: %w", err)
+		}
+	}
+	if params.lang != "" {
+		if p.lang, err = model.ParseLanguage(params.lang); err != nil {
+			return presentation{}, fmt.Errorf("lang: %w", err)
+		}
+	}
+	if p.fields, err = model.ParseFields(params.fields); err != nil {
+		return presentation{}, fmt.Errorf("fields: %w", err)
+	}
+	if p.loc, err = h.location(ctx, params.tz, city); err != nil {
+		return presentation{}, fmt.Errorf("tz: %w", err)
+	}
+	return p, nil
+}
+
+// apply оформляет ответ: часовой пояс, единицы, язык и поля
+func (p presentation) apply(resp *model.WeatherResponse) {
+	resp.Localize(p.loc)
+	resp.ConvertUnits(p.units)
+	resp.Translate(p.lang)
+	resp.Fields = p.fields
+}
+
+// sendPresentationError отдает 400 для неверных ?units=, ?lang=, ?tz= или ?fields=
+func sendPresentationError(w http.ResponseWriter, err error) {
+	sendError(w, errcode.InvalidRequest, "Неверный параметр оформления ответа", err.Error())
+}
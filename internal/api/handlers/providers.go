@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/quality"
+)
+
+// GetProviders возвращает последний замер каждого провайдера города и
+// консенсус: медиану температуры и самое частое состояние
+func (h *WeatherHandler) GetProviders(w http.ResponseWriter, r *http.Request) {
+	city := h.cityParam(r)
+	ctx := r.Context()
+
+	view, err := h.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	readings, err := store.GetProviderReadings(ctx, city)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения замеров провайдеров из БД", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if len(readings) == 0 {
+		sendError(w, errcode.CityNotFound, "Город не найден", "нет замеров провайдеров для "+city)
+		return
+	}
+
+	response := model.ProvidersResponse{
+		City:      city,
+		Readings:  make([]model.WeatherResponse, 0, len(readings)),
+		Consensus: quality.Consensus(readings),
+	}
+	for _, data := range readings {
+		reading := model.WeatherResponse{WeatherData: data}
+		view.apply(&reading)
+		response.Readings = append(response.Readings, reading)
+	}
+	response.Consensus.ConvertUnits(view.units)
+	response.Consensus.Translate(view.lang)
+
+	sendJSON(w, http.StatusOK, response)
+}
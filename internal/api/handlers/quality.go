@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/quality"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// QualityHandler отдает отчеты о качестве данных городов
+type QualityHandler struct {
+	weather *WeatherHandler
+	opts    quality.Options
+}
+
+func NewQualityHandler(weather *WeatherHandler, opts quality.Options) *QualityHandler {
+	return &QualityHandler{weather: weather, opts: opts}
+}
+
+// GetQuality возвращает сводку оценок замеров города за скользящее окно:
+// долю аномалий, разбивку по признакам и итоговый статус потока данных
+func (h *QualityHandler) GetQuality(w http.ResponseWriter, r *http.Request) {
+	city := h.weather.cityParam(r)
+	ctx := r.Context()
+
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	now := time.Now()
+	scores, err := store.ListQualityScores(ctx, city, now.Add(-h.opts.Window), now)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения оценок качества", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if len(scores) == 0 {
+		_, err := store.GetByCity(ctx, city)
+		if errors.Is(err, storage.ErrNotFound) {
+			sendError(w, errcode.CityNotFound, "Город не найден", city)
+			return
+		}
+		if err != nil {
+			h.weather.logger.ErrorContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+			sendStoreError(w, err)
+			return
+		}
+	}
+
+	sendJSON(w, http.StatusOK, quality.Summarize(city, scores, now, h.opts))
+}
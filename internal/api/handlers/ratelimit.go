@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/config"
+)
+
+// bucketLimit — скорость пополнения и емкость корзины токенов
+type bucketLimit struct {
+	rate  float64 // токенов в секунду
+	burst int64
+}
+
+// RateLimiter ограничивает частоту запросов корзиной токенов в Redis. Корзина
+// общая для всех экземпляров API и заводится на аккаунт, пользователя токена
+// или, для запросов без ключа, на адрес клиента.
+type RateLimiter struct {
+	cache  Cache
+	keyed  bucketLimit
+	anon   bucketLimit
+	logger *slog.Logger
+}
+
+// NewRateLimiter создает ограничитель по настройкам; nil — ограничение выключено
+func NewRateLimiter(cfg *config.Config, c Cache, logger *slog.Logger) *RateLimiter {
+	if !cfg.RateLimitEnabled || cfg.RateLimitRPS <= 0 || cfg.RateLimitAnonRPS <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		cache:  c,
+		keyed:  bucketLimit{rate: cfg.RateLimitRPS, burst: int64(max(cfg.RateLimitBurst, 1))},
+		anon:   bucketLimit{rate: cfg.RateLimitAnonRPS, burst: int64(max(cfg.RateLimitAnonBurst, 1))},
+		logger: logger,
+	}
+}
+
+// client возвращает корзину запроса и ее лимит. Middleware стоит после
+// AccountHandler.Middleware, поэтому аккаунт и пользователь уже в контексте.
+func (l *RateLimiter) client(r *http.Request) (string, bucketLimit) {
+	ctx := r.Context()
+	if caller := account.FromContext(ctx); caller != nil {
+		return "account:" + strconv.FormatInt(caller.ID, 10), l.keyed
+	}
+	if principal := auth.FromContext(ctx); principal != nil {
+		return "user:" + principal.Username, l.keyed
+	}
+	return "addr:" + clientAddr(r), l.anon
+}
+
+// Middleware пропускает запрос, если в корзине есть токен, иначе отвечает 429
+// с Retry-After. Заголовки X-RateLimit-* сообщают емкость корзины, остаток
+// и через сколько секунд она наполнится.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		client, limit := l.client(r)
+		bucket, err := l.cache.TakeToken(ctx, cache.RateLimitKey(client), limit.rate, limit.burst, time.Now())
+		if err != nil {
+			// Как и учет анонимных запросов, лимит не должен ронять API
+			l.logger.WarnContext(ctx, "Не удалось проверить лимит запросов", "client", client, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("X-RateLimit-Limit", strconv.FormatInt(limit.burst, 10))
+		h.Set("X-RateLimit-Remaining", strconv.FormatInt(bucket.Remaining, 10))
+		h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(bucket.ResetAfter)))
+		if !bucket.Allowed {
+			h.Set("Retry-After", strconv.Itoa(max(ceilSeconds(bucket.RetryAfter), 1)))
+			sendError(w, errcode.RateLimited, "Слишком много запросов",
+				"не больше "+strconv.FormatFloat(limit.rate, 'f', -1, 64)+" запросов в секунду")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ceilSeconds округляет длительность до целых секунд вверх
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
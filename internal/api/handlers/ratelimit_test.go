@@ -0,0 +1,113 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/handlers"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/testutil"
+	"github.com/gometeo/app/internal/testutil/mocks"
+)
+
+func newRateLimiter(t *testing.T) (http.Handler, *mocks.MockHandlerCache) {
+	cfg := config.Load()
+	cfg.RateLimitEnabled = true
+	cfg.RateLimitRPS = 20
+	cfg.RateLimitBurst = 40
+	cfg.RateLimitAnonRPS = 2
+	cfg.RateLimitAnonBurst = 10
+	c := mocks.NewMockHandlerCache(gomock.NewController(t))
+	limiter := handlers.NewRateLimiter(cfg, c, testutil.Logger(t))
+	return limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})), c
+}
+
+func limited(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestRateLimiterAnonymousBucket(t *testing.T) {
+	h, c := newRateLimiter(t)
+
+	c.EXPECT().TakeToken(gomock.Any(), cache.RateLimitKey("addr:203.0.113.7"), 2.0, int64(10), gomock.Any()).
+		Return(cache.Bucket{Allowed: true, Remaining: 9, ResetAfter: 500 * time.Millisecond}, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/weather/moscow", nil)
+	r.RemoteAddr = "203.0.113.7:5000"
+	rec := limited(h, r)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("код ответа %d, ожидался 204", rec.Code)
+	}
+	for header, want := range map[string]string{"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "9", "X-RateLimit-Reset": "1"} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, ожидалось %q", header, got, want)
+		}
+	}
+}
+
+func TestRateLimiterAccountBucket(t *testing.T) {
+	h, c := newRateLimiter(t)
+
+	c.EXPECT().TakeToken(gomock.Any(), cache.RateLimitKey("account:42"), 20.0, int64(40), gomock.Any()).
+		Return(cache.Bucket{Allowed: true, Remaining: 39}, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/weather/moscow", nil)
+	r = r.WithContext(account.WithAccount(r.Context(), &account.Caller{Account: model.Account{ID: 42}}))
+	if rec := limited(h, r); rec.Code != http.StatusNoContent {
+		t.Fatalf("код ответа %d, ожидался 204", rec.Code)
+	}
+}
+
+func TestRateLimiterRejectsEmptyBucket(t *testing.T) {
+	h, c := newRateLimiter(t)
+
+	c.EXPECT().TakeToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(cache.Bucket{RetryAfter: 1200 * time.Millisecond, ResetAfter: 5 * time.Second}, nil)
+
+	rec := limited(h, httptest.NewRequest(http.MethodGet, "/api/v1/weather/moscow", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("код ответа %d, ожидался 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, ожидалось 2", got)
+	}
+	if got := rec.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q, ожидалось 0", got)
+	}
+}
+
+func TestRateLimiterPassesWhenRedisDown(t *testing.T) {
+	h, c := newRateLimiter(t)
+
+	c.EXPECT().TakeToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(cache.Bucket{}, errors.New("redis недоступен"))
+
+	rec := limited(h, httptest.NewRequest(http.MethodGet, "/api/v1/weather/moscow", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("код ответа %d, ожидался 204", rec.Code)
+	}
+	if rec.Header().Get("X-RateLimit-Limit") != "" {
+		t.Error("заголовки лимита без ответа Redis")
+	}
+}
+
+func TestRateLimiterSkipsPublicPaths(t *testing.T) {
+	h, _ := newRateLimiter(t)
+
+	// Кэш не вызывается: gomock провалит тест на неожиданном вызове
+	if rec := limited(h, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)); rec.Code != http.StatusNoContent {
+		t.Fatalf("код ответа %d, ожидался 204", rec.Code)
+	}
+}
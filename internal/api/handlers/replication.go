@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/replication"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// ReplicationHandler сравнивает свежесть данных локального и соседнего региона
+type ReplicationHandler struct {
+	weather   *WeatherHandler
+	peer      atomic.Pointer[storage.WeatherStorage] // nil, пока БД соседа недоступна
+	region    string
+	tolerance time.Duration
+}
+
+func NewReplicationHandler(weather *WeatherHandler, region string, tolerance time.Duration) *ReplicationHandler {
+	return &ReplicationHandler{weather: weather, region: region, tolerance: tolerance}
+}
+
+// SetPeer подключает БД соседнего региона
+func (h *ReplicationHandler) SetPeer(peer *storage.WeatherStorage) {
+	h.peer.Store(peer)
+}
+
+// GetStatus возвращает время обновления каждого города в обоих регионах
+func (h *ReplicationHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	local := h.weather.db()
+	if local == nil {
+		sendReadOnly(w)
+		return
+	}
+	peer := h.peer.Load()
+	if peer == nil {
+		sendError(w, errcode.ServiceUnavailable, "БД соседнего региона недоступна", "")
+		return
+	}
+
+	localAt, err := local.ListUpdatedAt(ctx)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения локальной БД", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	peerAt, err := peer.ListUpdatedAt(ctx)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения БД соседнего региона", "error", err)
+		sendError(w, errcode.UpstreamError, "БД соседнего региона недоступна", "")
+		return
+	}
+
+	cities := replication.Compare(localAt, peerAt, h.tolerance)
+	resp := model.ReplicationStatusResponse{
+		Region: h.region,
+		Cities: cities,
+		Total:  len(cities),
+	}
+	for _, c := range cities {
+		resp.MaxLagSeconds = max(resp.MaxLagSeconds, c.LagSeconds)
+	}
+	sendJSON(w, http.StatusOK, resp)
+}
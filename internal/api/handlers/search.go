@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// SearchCities ищет города справочника для автодополнения: ?q= — начало или
+// часть названия либо синонима, ?limit= — не больше 50. Без БД поиск идет
+// по стартовому набору городов и только по началу названия.
+func (h *WeatherHandler) SearchCities(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" || len(q) > model.MaxCityLength {
+		sendError(w, errcode.InvalidRequest, "Неверный параметр q",
+			fmt.Sprintf("ожидается строка от 1 до %d символов", model.MaxCityLength))
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр limit",
+				fmt.Sprintf("ожидается целое число от 1 до %d", maxSearchLimit))
+			return
+		}
+		limit = n
+	}
+
+	var cities []model.City
+	if store := h.db(); store != nil {
+		var err error
+		if cities, err = store.SearchCities(ctx, q, limit); err != nil {
+			h.logger.ErrorContext(ctx, "Ошибка поиска городов", "q", q, "error", err)
+			sendInternalError(w, err)
+			return
+		}
+	} else {
+		for _, city := range model.DefaultCities {
+			if len(cities) < limit && city.HasPrefix(q) {
+				cities = append(cities, city)
+			}
+		}
+	}
+	if cities == nil {
+		cities = []model.City{}
+	}
+
+	sendJSON(w, http.StatusOK, model.CitySearchResponse{
+		Query:  q,
+		Cities: cities,
+		Total:  len(cities),
+	})
+
+	h.logger.InfoContext(ctx, "Поиск городов",
+		"q", q,
+		"count", len(cities),
+		"duration_ms", time.Since(start).Milliseconds())
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+)
+
+// StatsHandler отдает сводку температуры по истории замеров
+type StatsHandler struct {
+	weather  *WeatherHandler
+	cacheTTL time.Duration
+}
+
+func NewStatsHandler(weather *WeatherHandler, cacheTTL time.Duration) *StatsHandler {
+	return &StatsHandler{weather: weather, cacheTTL: cacheTTL}
+}
+
+// GetStats возвращает минимум, максимум и среднее температуры города
+// за ?period=24h|7d|30d до текущего момента. Сводка в метрической системе
+// кэшируется по городу и периоду; единицы применяются при ответе.
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	city := h.weather.cityParam(r)
+	ctx := r.Context()
+
+	period, length, err := model.ParseStatsPeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		sendError(w, errcode.InvalidRequest, "Неверный параметр period", err.Error())
+		return
+	}
+	view, err := h.weather.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+
+	key := cache.StatsKey(city, period)
+	cached, err := h.weather.cache.GetBytes(ctx, key)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+	}
+	if cached != nil {
+		var stats model.WeatherStats
+		if err := json.Unmarshal(cached, &stats); err == nil {
+			h.send(w, stats, view)
+			return
+		}
+		h.weather.logger.WarnContext(ctx, "Битая сводка в кэше", "city", city, "period", period, "error", err)
+	}
+
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	to := time.Now()
+	stats, err := store.GetHistoryStats(ctx, city, to.Add(-length), to)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка расчета сводки", "city", city, "period", period, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	stats.Period = period
+
+	if data, err := json.Marshal(stats); err == nil {
+		if err := h.weather.cache.SetBytes(ctx, key, data, h.cacheTTL); err != nil {
+			h.weather.logger.WarnContext(ctx, "Не удалось сохранить сводку в кэш", "city", city, "error", err)
+		}
+	}
+
+	h.send(w, stats, view)
+}
+
+// send оформляет сводку под единицы и часовой пояс запроса
+func (h *StatsHandler) send(w http.ResponseWriter, stats model.WeatherStats, view presentation) {
+	stats.From = stats.From.In(view.loc)
+	stats.To = stats.To.In(view.loc)
+	stats.ConvertUnits(view.units)
+	sendJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/gometeo/app/internal/api/errcode"
+)
+
+// streamTracker учитывает долгие соединения: WebSocket /ws, который
+// http.Server.Shutdown не закрывает сам, и ожидание /wait длиной до минуты
+type streamTracker struct {
+	mu      sync.Mutex
+	closing bool
+	drain   chan struct{} // закрывается при остановке
+	active  sync.WaitGroup
+}
+
+// open регистрирует соединение; false — сервер останавливается и новых не принимает
+func (t *streamTracker) open() (done func(), ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closing {
+		return nil, false
+	}
+	t.active.Add(1)
+	return t.active.Done, true
+}
+
+// draining закрывается, когда соединениям пора завершаться
+func (t *streamTracker) draining() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.drain == nil {
+		t.drain = make(chan struct{})
+	}
+	return t.drain
+}
+
+// close перестает принимать соединения и будит открытые
+func (t *streamTracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closing {
+		return
+	}
+	t.closing = true
+	if t.drain == nil {
+		t.drain = make(chan struct{})
+	}
+	close(t.drain)
+}
+
+// DrainStreams перестает принимать подписки /ws и ожидания /wait, просит
+// открытые соединения завершиться (WebSocket получает кадр закрытия 1001,
+// ожидание — 304) и ждет их до отмены ctx. Вызывается в начале остановки,
+// до http.Server.Shutdown, который иначе ждал бы их до своего дедлайна.
+func (h *WeatherHandler) DrainStreams(ctx context.Context) error {
+	h.streams.close()
+
+	done := make(chan struct{})
+	go func() {
+		h.streams.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendShuttingDown отвечает на новую подписку во время остановки:
+// клиент переподключится к другому экземпляру
+func sendShuttingDown(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	sendError(w, errcode.ServiceUnavailable, "Сервер останавливается", "переподключитесь позже")
+}
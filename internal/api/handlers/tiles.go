@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/ipfilter"
+	"github.com/gometeo/app/internal/tiles"
+)
+
+// TileHandler проксирует тайлы радара и облачности: кэширует их в Redis
+// и ограничивает число тайлов в сутки на клиента
+type TileHandler struct {
+	upstream *tiles.Upstream
+	cache    Cache
+	ttl      time.Duration
+	quota    int
+	logger   *slog.Logger
+}
+
+func NewTileHandler(upstream *tiles.Upstream, cache Cache, ttl time.Duration, quota int, logger *slog.Logger) *TileHandler {
+	return &TileHandler{upstream: upstream, cache: cache, ttl: ttl, quota: quota, logger: logger}
+}
+
+// GetTile отдает PNG тайла /tiles/{layer}/{z}/{x}/{y}.png
+func (h *TileHandler) GetTile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	tile := tiles.Tile{Layer: vars["layer"]}
+	var errZ, errX, errY error
+	tile.Z, errZ = strconv.Atoi(vars["z"])
+	tile.X, errX = strconv.Atoi(vars["x"])
+	tile.Y, errY = strconv.Atoi(vars["y"])
+	if err := errors.Join(errZ, errX, errY); err != nil {
+		sendError(w, errcode.InvalidRequest, "Неверные координаты тайла", err.Error())
+		return
+	}
+	if err := h.upstream.Validate(tile); err != nil {
+		code := errcode.InvalidRequest
+		if errors.Is(err, tiles.ErrUnknownLayer) {
+			code = errcode.NotFound
+		}
+		sendError(w, code, "Тайл недоступен", err.Error())
+		return
+	}
+
+	if !h.allow(w, r) {
+		return
+	}
+
+	key := cache.TileKey(tile.String())
+	data, err := h.cache.GetBytes(ctx, key)
+	if err != nil {
+		h.logger.WarnContext(ctx, "Ошибка чтения тайла из кэша", "tile", tile.String(), "error", err)
+	}
+	source := "cache"
+	if data == nil {
+		source = "upstream"
+		data, err = h.upstream.Fetch(ctx, tile)
+		switch {
+		case errors.Is(err, tiles.ErrNotFound):
+			sendError(w, errcode.NotFound, "Тайл не найден", "")
+			return
+		case errors.Is(err, context.DeadlineExceeded):
+			h.logger.ErrorContext(ctx, "Провайдер тайлов не ответил вовремя", "tile", tile.String(), "error", err)
+			sendError(w, errcode.UpstreamTimeout, "Провайдер тайлов не ответил вовремя", "")
+			return
+		case err != nil:
+			h.logger.ErrorContext(ctx, "Ошибка загрузки тайла", "tile", tile.String(), "error", err)
+			sendError(w, errcode.UpstreamError, "Провайдер тайлов недоступен", "")
+			return
+		}
+		if err := h.cache.SetBytes(ctx, key, data, h.ttl); err != nil {
+			h.logger.WarnContext(ctx, "Не удалось сохранить тайл в кэш", "tile", tile.String(), "error", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.ttl.Seconds())))
+	w.Header().Set("X-Tile-Source", source)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// allow учитывает тайл в суточном счетчике клиента: аккаунта, если запрос
+// с API-ключом, иначе адреса. При превышении отправляет 429 и возвращает false.
+func (h *TileHandler) allow(w http.ResponseWriter, r *http.Request) bool {
+	if h.quota <= 0 {
+		return true
+	}
+	ctx := r.Context()
+
+	client := "ip:" + clientAddr(r)
+	if caller := account.FromContext(ctx); caller != nil {
+		client = "account:" + strconv.FormatInt(caller.ID, 10)
+	}
+	now := time.Now().UTC()
+	reset := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	n, err := h.cache.IncrCounter(ctx, cache.TileQuotaKey(client, now.Format(time.DateOnly)), reset.Sub(now))
+	if err != nil {
+		// Учет не должен ронять карты: при недоступности Redis тайл отдается
+		h.logger.WarnContext(ctx, "Не удалось учесть тайл", "client", client, "error", err)
+		return true
+	}
+
+	w.Header().Set("X-Tiles-Limit", strconv.Itoa(h.quota))
+	w.Header().Set("X-Tiles-Remaining", strconv.FormatInt(max(int64(h.quota)-n, 0), 10))
+	if n > int64(h.quota) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds())))
+		sendError(w, errcode.RateLimited, "Суточный лимит тайлов исчерпан",
+			"лимит обновится "+reset.Format(time.RFC3339))
+		return false
+	}
+	return true
+}
+
+// clientAddr возвращает адрес клиента без порта; за доверенными прокси —
+// адрес из X-Forwarded-For, определенный middleware API
+func clientAddr(r *http.Request) string {
+	if addr, ok := ipfilter.FromContext(r.Context()); ok {
+		return addr.String()
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
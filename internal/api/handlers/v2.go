@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+)
+
+// V2Handler отдает ответы API v2. Данные те же, что у v1, но список городов
+// хранится в кэше своим типом, а ответы содержат сведения о провайдере.
+type V2Handler struct {
+	weather *WeatherHandler
+}
+
+func NewV2Handler(weather *WeatherHandler) *V2Handler {
+	return &V2Handler{weather: weather}
+}
+
+// GetCities возвращает все города с провайдером и временем последнего замера
+func (h *V2Handler) GetCities(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := h.weather.logger
+
+	cities, err := h.weather.cache.GetCityList(ctx, cache.CityListKey())
+	if err != nil {
+		logger.ErrorContext(ctx, "Ошибка чтения кэша городов", "error", err)
+	}
+	if cities != nil {
+		sendJSON(w, http.StatusOK, newCitiesV2Response(cities, true))
+		return
+	}
+
+	store := h.weather.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+	cities, err = store.ListCityEntries(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "Ошибка получения городов из БД", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if err := h.weather.cache.SetCityList(ctx, cache.CityListKey(), cities); err != nil {
+		logger.WarnContext(ctx, "Не удалось сохранить города в кэш", "error", err)
+	}
+
+	sendJSON(w, http.StatusOK, newCitiesV2Response(cities, false))
+}
+
+// GetWeather возвращает текущую погоду города и сведения о ее источнике.
+// Параметры оформления те же, что у v1.
+func (h *V2Handler) GetWeather(w http.ResponseWriter, r *http.Request) {
+	city := h.weather.cityParam(r)
+	ctx := r.Context()
+
+	view, err := h.weather.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+	data, cached, err := h.weather.current(ctx, city)
+	if err != nil {
+		sendReadOnly(w)
+		return
+	}
+	if data == nil {
+		sendError(w, errcode.CityNotFound, "Город не найден", city)
+		return
+	}
+
+	h.weather.cacheControl(ctx, w, cache.CityKey(city))
+
+	resp := model.WeatherResponse{WeatherData: *data, Cached: cached}
+	view.apply(&resp)
+	sendJSON(w, http.StatusOK, model.WeatherV2Response{
+		Weather:  resp,
+		Provider: model.NewProviderInfo(*data, time.Now()),
+	})
+}
+
+func newCitiesV2Response(cities []model.CityEntry, cached bool) model.CitiesV2Response {
+	providers := []string{}
+	for _, c := range cities {
+		if c.Provider != "" && !slices.Contains(providers, c.Provider) {
+			providers = append(providers, c.Provider)
+		}
+	}
+	slices.Sort(providers)
+	return model.CitiesV2Response{
+		Cities:    cities,
+		Total:     len(cities),
+		Providers: providers,
+		Cached:    cached,
+	}
+}
+
+// invalidateCityLists удаляет из кэша списки городов v1 и v2 после
+// изменения набора городов
+func (h *WeatherHandler) invalidateCityLists(ctx context.Context) {
+	for _, key := range []string{cache.AllCitiesKey(), cache.CityListKey()} {
+		if err := h.cache.Delete(ctx, key); err != nil {
+			h.logger.WarnContext(ctx, "Не удалось удалить список городов из кэша", "key", key, "error", err)
+		}
+	}
+}
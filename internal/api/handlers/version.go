@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gometeo/app/internal/buildinfo"
+)
+
+// Version возвращает версию, коммит и время сборки запущенного сервиса
+func Version(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, http.StatusOK, buildinfo.Get())
+}
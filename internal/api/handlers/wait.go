@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/changefeed"
+	"github.com/gometeo/app/internal/model"
+)
+
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 60 * time.Second
+)
+
+// SetFeed включает ожидание обновлений /weather/{city}/wait и подписки /ws
+func (h *WeatherHandler) SetFeed(feed *changefeed.Hub) {
+	h.feed = feed
+}
+
+// WaitForUpdate держит запрос до обновления погоды города или истечения
+// ?timeout= (по умолчанию 30s, не больше 60s): 200 с новыми данными или 304.
+// ?since= (RFC3339) — время замера, который уже есть у клиента: более свежие
+// данные в кэше отдаются сразу, без ожидания.
+func (h *WeatherHandler) WaitForUpdate(w http.ResponseWriter, r *http.Request) {
+	city := h.cityParam(r)
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	if h.feed == nil {
+		sendError(w, errcode.NotImplemented, "Ожидание обновлений недоступно",
+			"подписка на обновления кэша выключена")
+		return
+	}
+
+	timeout := defaultWaitTimeout
+	if v := query.Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxWaitTimeout {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр timeout",
+				"ожидается длительность вида 30s, не больше "+maxWaitTimeout.String())
+			return
+		}
+		timeout = d
+	}
+	var since time.Time
+	if v := query.Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр since", "ожидается время в формате RFC3339")
+			return
+		}
+	}
+	view, err := h.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+
+	done, ok := h.streams.open()
+	if !ok {
+		sendShuttingDown(w)
+		return
+	}
+	defer done()
+
+	// Подписка до проверки кэша: обновление между ними не потеряется
+	updates, cancel := h.feed.Subscribe(city)
+	defer cancel()
+
+	send := func(data model.WeatherData) {
+		response := model.WeatherResponse{WeatherData: data}
+		view.apply(&response)
+		sendJSON(w, http.StatusOK, response)
+	}
+
+	if !since.IsZero() {
+		current, err := h.cache.Get(ctx, cache.CityKey(city))
+		if err != nil {
+			h.logger.WarnContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
+		}
+		if current != nil && current.Timestamp.After(since) {
+			send(*current)
+			return
+		}
+	}
+
+	// Общий WriteTimeout сервера короче ожидания; без поддержки ответ может оборваться
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + 5*time.Second)); err != nil {
+		h.logger.DebugContext(ctx, "Не удалось продлить дедлайн записи", "error", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case data := <-updates:
+		send(data)
+	case <-timer.C:
+		w.WriteHeader(http.StatusNotModified)
+	case <-h.streams.draining():
+		// Сервер останавливается: клиент повторит ожидание на другом экземпляре
+		w.WriteHeader(http.StatusNotModified)
+	case <-ctx.Done():
+	}
+}
@@ -1,59 +1,150 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
 	"log/slog"
 
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/api/errcode"
 	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/changefeed"
+	"github.com/gometeo/app/internal/geocode"
+	"github.com/gometeo/app/internal/logging"
 	"github.com/gometeo/app/internal/model"
 	"github.com/gometeo/app/internal/storage"
 )
 
 type WeatherHandler struct {
-	store  *storage.WeatherStorage
-	cache  *cache.WeatherCache
+	// nil, пока API работает в режиме только чтения из кэша
+	store  atomic.Pointer[storeRef]
+	cache  Cache
 	logger *slog.Logger
+
+	// Приводит "NYC", "Москва" к названию из справочника; nil — без геокодирования
+	geocoder atomic.Pointer[geocode.Resolver]
+
+	// Часовые пояса городов из справочника, меняются редко
+	locations sync.Map
+
+	// Обновления для ожидающих и подписанных клиентов; nil — выключено
+	feed *changefeed.Hub
+
+	// Открытые /ws и /wait, которые нужно завершить при остановке
+	streams streamTracker
 }
 
-func NewWeatherHandler(store *storage.WeatherStorage, cache *cache.WeatherCache, logger *slog.Logger) *WeatherHandler {
-	return &WeatherHandler{
-		store:  store,
+// NewWeatherHandler создает обработчик; store может быть nil при частичном старте
+func NewWeatherHandler(store Store, cache Cache, logger *slog.Logger) *WeatherHandler {
+	h := &WeatherHandler{
 		cache:  cache,
 		logger: logger,
 	}
+	if store != nil {
+		h.SetStore(store)
+	}
+	return h
+}
+
+// SetGeocoder включает приведение названий городов к каноническим
+func (h *WeatherHandler) SetGeocoder(geocoder *geocode.Resolver) {
+	h.geocoder.Store(geocoder)
+}
+
+// cityParam возвращает город из пути: каноническое название,
+// если геокодер его знает, иначе исходную строку в нижнем регистре
+func (h *WeatherHandler) cityParam(r *http.Request) string {
+	city := strings.ToLower(mux.Vars(r)["city"])
+	return h.geocoder.Load().Canonical(r.Context(), city)
+}
+
+// SetStore подключает БД после частичного старта
+func (h *WeatherHandler) SetStore(store Store) {
+	h.store.Store(&storeRef{store})
+}
+
+// db возвращает подключенную БД или nil в режиме только чтения
+func (h *WeatherHandler) db() Store {
+	ref := h.store.Load()
+	if ref == nil {
+		return nil
+	}
+	return ref.Store
 }
 
-// GetWeather возвращает погоду для конкретного города
+// ErrReadOnly — БД еще не подключена после частичного старта
+var ErrReadOnly = errors.New("БД не подключена, режим только чтения из кэша")
+
+// StoreHealth проверяет БД для health-check и добавляет время последней записи
+func (h *WeatherHandler) StoreHealth(ctx context.Context) (map[string]string, error) {
+	store := h.db()
+	if store == nil {
+		return nil, ErrReadOnly
+	}
+	return store.Health(ctx)
+}
+
+// ReadOnly сообщает, что БД еще не подключена и данные отдаются только из кэша
+func (h *WeatherHandler) ReadOnly() bool {
+	return h.db() == nil
+}
+
+// GetWeather возвращает погоду для конкретного города. Отдает ETag и Last-Modified
+// по времени замера; If-None-Match / If-Modified-Since без изменений — 304.
 func (h *WeatherHandler) GetWeather(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
-	city := strings.ToLower(mux.Vars(r)["city"])
+	city := h.cityParam(r)
+	ctx := r.Context()
 	
-	h.logger.Info("Запрос погоды", "city", city, "method", r.Method)
+	h.logger.InfoContext(ctx, "Запрос погоды", "city", city, "method", r.Method)
 	
+	view, err := h.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+	format, err := responseFormat(r)
+	if err != nil {
+		sendFormatError(w, err)
+		return
+	}
+
 	// 1. Пробуем получить из кэша
-	ctx := r.Context()
 	cachedData, err := h.cache.Get(ctx, cache.CityKey(city))
 	if err != nil {
-		h.logger.Error("Ошибка чтения из кэша", "city", city, "error", err)
+		h.logger.ErrorContext(ctx, "Ошибка чтения из кэша", "city", city, "error", err)
 		// Продолжаем - кэш не критичен
 	}
 
 	if cachedData != nil {
-		h.logger.Debug("Данные из кэша", "city", city)
+		h.logger.DebugContext(ctx, "Данные из кэша", "city", city)
+		h.cacheControl(ctx, w, cache.CityKey(city))
+
+		if notModified(w, r, weatherETag(cachedData.Timestamp, view, format, r), cachedData.Timestamp) {
+			h.logger.DebugContext(ctx, "Данные не изменились", "city", city, "source", "cache")
+			return
+		}
 		
 		response := model.WeatherResponse{
 			WeatherData: *cachedData,
 			Cached:      true,
 		}
+		view.apply(&response)
+		h.includeAstro(ctx, r, city, &response, view)
 		
-		sendJSON(w, http.StatusOK, response)
+		sendAs(w, format, http.StatusOK, response)
 		
-		h.logger.Info("Данные отданы из кэша", 
+		h.logger.InfoContext(ctx, "Данные отданы из кэша", 
 			"city", city, 
 			"duration_ms", time.Since(start).Milliseconds(),
 			"source", "cache")
@@ -61,26 +152,43 @@ func (h *WeatherHandler) GetWeather(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 2. Получаем из базы данных
-	dbData, err := h.store.GetByCity(ctx, city)
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+	dbData, err := store.GetByCity(ctx, city)
+	if errors.Is(err, storage.ErrNotFound) {
+		sendError(w, errcode.CityNotFound, "Город не найден", city)
+		return
+	}
 	if err != nil {
-		h.logger.Error("Ошибка чтения из БД", "city", city, "error", err)
-		sendError(w, http.StatusNotFound, "Город не найден", err.Error())
+		h.logger.ErrorContext(ctx, "Ошибка чтения из БД", "city", city, "error", err)
+		sendStoreError(w, err)
 		return
 	}
 
 	// 3. Сохраняем в кэш для будущих запросов
 	if err := h.cache.Set(ctx, cache.CityKey(city), *dbData); err != nil {
-		h.logger.Warn("Не удалось сохранить в кэш", "city", city, "error", err)
+		h.logger.WarnContext(ctx, "Не удалось сохранить в кэш", "city", city, "error", err)
+	}
+	h.cacheControl(ctx, w, cache.CityKey(city))
+
+	if notModified(w, r, weatherETag(dbData.Timestamp, view, format, r), dbData.Timestamp) {
+		h.logger.DebugContext(ctx, "Данные не изменились", "city", city, "source", "database")
+		return
 	}
 
 	response := model.WeatherResponse{
 		WeatherData: *dbData,
 		Cached:      false,
 	}
+	view.apply(&response)
+	h.includeAstro(ctx, r, city, &response, view)
 
-	sendJSON(w, http.StatusOK, response)
+	sendAs(w, format, http.StatusOK, response)
 	
-	h.logger.Info("Данные отданы из БД", 
+	h.logger.InfoContext(ctx, "Данные отданы из БД", 
 		"city", city, 
 		"duration_ms", time.Since(start).Milliseconds(),
 		"source", "database")
@@ -90,11 +198,24 @@ func (h *WeatherHandler) GetWeather(w http.ResponseWriter, r *http.Request) {
 func (h *WeatherHandler) GetAllCities(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	
+	format, err := responseFormat(r)
+	if err != nil {
+		sendFormatError(w, err)
+		return
+	}
+
+	// Постраничный запрос идет в БД мимо кэша полного списка
+	q := r.URL.Query()
+	if q.Has("page") || q.Has("per_page") || q.Has("sort") {
+		h.getCitiesPage(w, r, format)
+		return
+	}
+
 	// Проверяем кэш
 	ctx := r.Context()
 	cached, err := h.cache.Get(ctx, cache.AllCitiesKey())
 	if err != nil {
-		h.logger.Error("Ошибка чтения кэша городов", "error", err)
+		h.logger.ErrorContext(ctx, "Ошибка чтения кэша городов", "error", err)
 	}
 	
 	if cached != nil {
@@ -104,19 +225,24 @@ func (h *WeatherHandler) GetAllCities(w http.ResponseWriter, r *http.Request) {
 			Total:  len(cities),
 		}
 		
-		sendJSON(w, http.StatusOK, response)
+		sendAs(w, format, http.StatusOK, response)
 		
-		h.logger.Info("Список городов из кэша",
+		h.logger.InfoContext(ctx, "Список городов из кэша",
 			"count", len(cities),
 			"duration_ms", time.Since(start).Milliseconds())
 		return
 	}
 
 	// Получаем из БД
-	cities, err := h.store.GetAllCities(ctx)
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+	cities, _, err := store.GetAllCities(ctx, storage.CityListOptions{})
 	if err != nil {
-		h.logger.Error("Ошибка получения городов из БД", "error", err)
-		sendError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера", "")
+		h.logger.ErrorContext(ctx, "Ошибка получения городов из БД", "error", err)
+		sendInternalError(w, err)
 		return
 	}
 
@@ -126,7 +252,7 @@ func (h *WeatherHandler) GetAllCities(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := h.cache.Set(ctx, cache.AllCitiesKey(), cacheData); err != nil {
-		h.logger.Warn("Не удалось сохранить города в кэш", "error", err)
+		h.logger.WarnContext(ctx, "Не удалось сохранить города в кэш", "error", err)
 	}
 
 	response := model.CitiesResponse{
@@ -134,83 +260,207 @@ func (h *WeatherHandler) GetAllCities(w http.ResponseWriter, r *http.Request) {
 		Total:  len(cities),
 	}
 
-	sendJSON(w, http.StatusOK, response)
+	sendAs(w, format, http.StatusOK, response)
 	
-	h.logger.Info("Список городов из БД",
+	h.logger.InfoContext(ctx, "Список городов из БД",
+		"count", len(cities),
+		"duration_ms", time.Since(start).Milliseconds())
+}
+
+// Ограничения постраничного списка городов
+const (
+	defaultCitiesPerPage = 50
+	maxCitiesPerPage     = 500
+)
+
+// getCitiesPage отдает страницу списка городов: ?page, ?per_page, ?sort
+func (h *WeatherHandler) getCitiesPage(w http.ResponseWriter, r *http.Request, format string) {
+	start := time.Now()
+	ctx := r.Context()
+	q := r.URL.Query()
+
+	page := 1
+	if raw := q.Get("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр page", "ожидается целое число от 1")
+			return
+		}
+		page = n
+	}
+
+	perPage := defaultCitiesPerPage
+	if raw := q.Get("per_page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxCitiesPerPage {
+			sendError(w, errcode.InvalidRequest, "Неверный параметр per_page",
+				fmt.Sprintf("ожидается целое число от 1 до %d", maxCitiesPerPage))
+			return
+		}
+		perPage = n
+	}
+
+	sort := q.Get("sort")
+	if sort == "" {
+		sort = "city"
+	}
+	if !storage.ValidCitySort(sort) {
+		sendError(w, errcode.InvalidRequest, "Неверный параметр sort",
+			"допустимо: city, updated_at, temp; \"-\" в начале — по убыванию")
+		return
+	}
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+	cities, total, err := store.GetAllCities(ctx, storage.CityListOptions{
+		Sort:   sort,
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	})
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения городов из БД", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+
+	response := model.CitiesResponse{
+		Cities:  cities,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}
+	if response.Cities == nil {
+		response.Cities = []string{}
+	}
+	if page*perPage < total {
+		next := page + 1
+		response.NextPage = &next
+	}
+
+	sendAs(w, format, http.StatusOK, response)
+
+	h.logger.InfoContext(ctx, "Страница списка городов из БД",
+		"page", page,
+		"per_page", perPage,
+		"sort", sort,
 		"count", len(cities),
 		"duration_ms", time.Since(start).Milliseconds())
 }
 
 // UpdateWeather (только для теста) - обновляет данные города
 func (h *WeatherHandler) UpdateWeather(w http.ResponseWriter, r *http.Request) {
-	city := strings.ToLower(mux.Vars(r)["city"])
+	city := h.cityParam(r)
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
 	
 	var data model.WeatherData
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-		sendError(w, http.StatusBadRequest, "Неверный формат JSON", err.Error())
+		sendBodyError(w, err, "Неверный формат JSON", err.Error())
 		return
 	}
 	
 	data.City = city
 	data.Timestamp = time.Now()
+	data.NormalizeCondition()
+	
+	if err := data.Validate(); err != nil {
+		sendValidationError(w, err)
+		return
+	}
 	
 	ctx := r.Context()
 	
 	// Сохраняем в БД
-	if err := h.store.Save(ctx, data); err != nil {
-		h.logger.Error("Ошибка сохранения в БД", "city", city, "error", err)
-		sendError(w, http.StatusInternalServerError, "Ошибка сохранения", err.Error())
+	if _, err := store.Save(ctx, data); err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка сохранения в БД", "city", city, "error", err)
+		sendError(w, errcode.FromError(err), "Ошибка сохранения", err.Error())
 		return
 	}
 	
 	// Инвалидируем кэш
 	if err := h.cache.Delete(ctx, cache.CityKey(city)); err != nil {
-		h.logger.Warn("Не удалось удалить из кэша", "city", city, "error", err)
+		h.logger.WarnContext(ctx, "Не удалось удалить из кэша", "city", city, "error", err)
 	}
 	
-	// Также инвалидируем кэш списка городов
-	if err := h.cache.Delete(ctx, cache.AllCitiesKey()); err != nil {
-		h.logger.Warn("Не удалось удалить список городов из кэша", "error", err)
-	}
+	// Также инвалидируем кэш списков городов
+	h.invalidateCityLists(ctx)
 	
 	sendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	
-	h.logger.Info("Данные обновлены", "city", city)
+	h.logger.InfoContext(ctx, "Данные обновлены", "city", city)
 }
 
-// HealthCheck проверяет доступность сервисов
-func (h *WeatherHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
+// DeleteWeather удаляет город из БД и кэша: тестовые данные и выведенные из работы точки
+func (h *WeatherHandler) DeleteWeather(w http.ResponseWriter, r *http.Request) {
+	city := h.cityParam(r)
 	ctx := r.Context()
-	
-	health := map[string]string{
-		"status": "ok",
-		"time":   time.Now().Format(time.RFC3339),
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
 	}
-	
-	// Проверка БД
-	if err := h.store.Ping(ctx); err != nil {
-		health["database"] = "unhealthy"
-		health["status"] = "degraded"
-		h.logger.Error("Health check: DB недоступна", "error", err)
-	} else {
-		health["database"] = "healthy"
+
+	deleted, err := store.Delete(ctx, city)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка удаления из БД", "city", city, "error", err)
+		sendError(w, errcode.FromError(err), "Ошибка удаления", err.Error())
+		return
 	}
-	
-	// Проверка Redis
-	if _, err := h.cache.Exists(ctx, "health:check"); err != nil {
-		health["redis"] = "unhealthy"
-		health["status"] = "degraded"
-		h.logger.Error("Health check: Redis недоступен", "error", err)
-	} else {
-		health["redis"] = "healthy"
+	if !deleted {
+		sendError(w, errcode.CityNotFound, "Город не найден", city)
+		return
 	}
-	
-	status := http.StatusOK
-	if health["status"] == "degraded" {
-		status = http.StatusServiceUnavailable
+
+	// Инвалидируем кэш города и списков городов
+	if err := h.cache.Delete(ctx, cache.CityKey(city)); err != nil {
+		h.logger.WarnContext(ctx, "Не удалось удалить из кэша", "city", city, "error", err)
 	}
-	
-	sendJSON(w, status, health)
+	h.invalidateCityLists(ctx)
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.logger.InfoContext(ctx, "Город удален", "city", city)
+}
+
+// location выбирает часовой пояс ответа: tz из запроса либо пояс города из справочника
+func (h *WeatherHandler) location(ctx context.Context, tz, city string) (*time.Location, error) {
+	if tz != "" {
+		return time.LoadLocation(tz)
+	}
+	if caller := account.FromContext(ctx); caller != nil && caller.Preferences.Timezone != "" {
+		return time.LoadLocation(caller.Preferences.Timezone)
+	}
+
+	if loc, ok := h.locations.Load(city); ok {
+		return loc.(*time.Location), nil
+	}
+
+	store := h.db()
+	if store == nil {
+		return time.UTC, nil
+	}
+
+	info, err := store.GetCity(ctx, city)
+	if err != nil {
+		h.logger.DebugContext(ctx, "Часовой пояс города неизвестен, используется UTC", "city", city, "error", err)
+		return time.UTC, nil
+	}
+	loc, err := info.Location()
+	if err != nil {
+		h.logger.WarnContext(ctx, "Некорректный часовой пояс в справочнике", "city", city, "error", err)
+		return time.UTC, nil
+	}
+
+	h.locations.Store(city, loc)
+	return loc, nil
 }
 
 // Вспомогательные функции
@@ -220,13 +470,72 @@ func sendJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func sendError(w http.ResponseWriter, status int, errorMsg, details string) {
+// sendError отдает ошибку с кодом из каталога errcode; статус определяется кодом
+func sendError(w http.ResponseWriter, code errcode.Code, errorMsg, details string) {
 	response := model.ErrorResponse{
-		Error:   errorMsg,
-		Message: details,
+		Code:      string(code),
+		Error:     errorMsg,
+		Message:   details,
+		RequestID: w.Header().Get(logging.HeaderRequestID),
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+	w.WriteHeader(code.Status())
 	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
+
+// sendInternalError отдает 500 или, если истек дедлайн запроса, 504; подробности
+// ошибки остаются в логе
+func sendInternalError(w http.ResponseWriter, err error) {
+	if code := errcode.FromError(err); code == errcode.UpstreamTimeout {
+		sendError(w, code, "Превышено время ожидания ответа", "")
+		return
+	}
+	sendError(w, errcode.Internal, "Внутренняя ошибка сервера", "")
+}
+
+// sendStoreError отдает ошибку чтения из БД: 503 с Retry-After, если база
+// недоступна, иначе 500 или 504, как sendInternalError
+func sendStoreError(w http.ResponseWriter, err error) {
+	if errcode.FromError(err) != errcode.UpstreamTimeout && storage.IsUnavailable(err) {
+		w.Header().Set("Retry-After", "5")
+		sendError(w, errcode.StoreUnavailable, "База данных недоступна", "")
+		return
+	}
+	sendInternalError(w, err)
+}
+
+// sendReadOnly отдает 503, пока API работает без БД
+func sendReadOnly(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "5")
+	sendError(w, errcode.StoreUnavailable, "База данных недоступна",
+		"сервис работает в режиме только чтения из кэша")
+}
+
+// sendBodyError отдает 413 для тела больше лимита, остальные ошибки чтения — 400 с msg
+func sendBodyError(w http.ResponseWriter, err error, msg, details string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		sendError(w, errcode.PayloadTooLarge, "Слишком большое тело запроса",
+			fmt.Sprintf("не больше %d байт", tooLarge.Limit))
+		return
+	}
+	sendError(w, errcode.InvalidRequest, msg, details)
+}
+
+// sendValidationError отдает 400 со списком ошибок по полям
+func sendValidationError(w http.ResponseWriter, err error) {
+	response := model.ErrorResponse{
+		Code:      string(errcode.ValidationFailed),
+		Error:     "Ошибка валидации",
+		Message:   err.Error(),
+		RequestID: w.Header().Get(logging.HeaderRequestID),
+	}
+
+	var verrs model.ValidationErrors
+	if errors.As(err, &verrs) {
+		response.Fields = verrs
+	}
+
+	sendJSON(w, errcode.ValidationFailed.Status(), response)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+)
+
+// GetWeatherAt возвращает погоду города на момент ?time= (RFC3339) по истории:
+// ближайший замер или, с ?mode=interpolate, интерполяцию между соседними
+func (h *WeatherHandler) GetWeatherAt(w http.ResponseWriter, r *http.Request) {
+	city := h.cityParam(r)
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	at, err := time.Parse(time.RFC3339, query.Get("time"))
+	if err != nil {
+		sendError(w, errcode.InvalidRequest, "Неверный параметр time", "ожидается время в формате RFC3339")
+		return
+	}
+	mode := query.Get("mode")
+	if mode == "" {
+		mode = "nearest"
+	}
+	if mode != "nearest" && mode != "interpolate" {
+		sendError(w, errcode.InvalidRequest, "Неверный параметр mode", "допустимо nearest или interpolate")
+		return
+	}
+
+	view, err := h.presentation(ctx, r, city)
+	if err != nil {
+		sendPresentationError(w, err)
+		return
+	}
+
+	store := h.db()
+	if store == nil {
+		sendReadOnly(w)
+		return
+	}
+
+	before, after, err := store.GetHistoryAround(ctx, city, at)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Ошибка получения истории из БД", "city", city, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if before == nil && after == nil {
+		sendError(w, errcode.NotFound, "История не найдена", "нет замеров для города "+city)
+		return
+	}
+
+	resp := pointInTime(before, after, at, mode == "interpolate")
+	resp.RequestedAt = at.In(view.loc)
+	view.apply(&resp.Reading)
+	sendJSON(w, http.StatusOK, resp)
+}
+
+// pointInTime выбирает ближайший к at замер или интерполирует температуру
+// между before и after; хотя бы один из них не nil
+func pointInTime(before, after *model.WeatherData, at time.Time, interpolate bool) model.PointInTimeResponse {
+	if before != nil && before.Timestamp.Equal(at) {
+		return model.PointInTimeResponse{Method: model.PointExact, Reading: model.WeatherResponse{WeatherData: *before}}
+	}
+
+	nearest := before
+	if nearest == nil || (after != nil && after.Timestamp.Sub(at) < at.Sub(before.Timestamp)) {
+		nearest = after
+	}
+	offset := nearest.Timestamp.Sub(at).Abs()
+
+	if !interpolate || before == nil || after == nil {
+		return model.PointInTimeResponse{
+			Method:        model.PointNearest,
+			OffsetSeconds: int64(offset.Seconds()),
+			Reading:       model.WeatherResponse{WeatherData: *nearest},
+		}
+	}
+
+	// Температура линейно между соседями, состояние и провайдер — от ближайшего
+	frac := float64(at.Sub(before.Timestamp)) / float64(after.Timestamp.Sub(before.Timestamp))
+	data := *nearest
+	data.Temp = before.Temp + (after.Temp-before.Temp)*frac
+	data.Timestamp = at
+	return model.PointInTimeResponse{
+		Method:        model.PointInterpolated,
+		OffsetSeconds: int64(offset.Seconds()),
+		Reading:       model.WeatherResponse{WeatherData: data},
+	}
+}
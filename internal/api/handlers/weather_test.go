@@ -0,0 +1,129 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/mock/gomock"
+
+	"github.com/gometeo/app/internal/api/handlers"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/testutil"
+	"github.com/gometeo/app/internal/testutil/mocks"
+)
+
+func newWeatherHandler(t *testing.T) (*handlers.WeatherHandler, *mocks.MockHandlerStore, *mocks.MockHandlerCache) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockHandlerStore(ctrl)
+	c := mocks.NewMockHandlerCache(ctrl)
+	return handlers.NewWeatherHandler(store, c, testutil.Logger(t)), store, c
+}
+
+func putWeather(h *handlers.WeatherHandler, city, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/weather/"+city, strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"city": city})
+	rec := httptest.NewRecorder()
+	h.UpdateWeather(rec, req)
+	return rec
+}
+
+func TestUpdateWeatherSavesAndInvalidatesCache(t *testing.T) {
+	h, store, c := newWeatherHandler(t)
+
+	store.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, data model.WeatherData) (time.Time, error) {
+			if data.City != "moscow" || data.Temp != 21.5 || data.ConditionCode != model.ConditionClear {
+				t.Errorf("сохранен неверный замер: %+v", data)
+			}
+			if data.Timestamp.IsZero() {
+				t.Error("время замера не заполнено")
+			}
+			return data.Timestamp, nil
+		})
+	// Кэш города и обоих списков городов
+	c.EXPECT().Delete(gomock.Any(), cache.CityKey("moscow")).Return(nil)
+	c.EXPECT().Delete(gomock.Any(), cache.AllCitiesKey()).Return(nil)
+	c.EXPECT().Delete(gomock.Any(), cache.CityListKey()).Return(errors.New("redis недоступен"))
+
+	rec := putWeather(h, "Moscow", `{"temperature": 21.5, "condition_code": "clear"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("код ответа %d, ожидался 200: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestUpdateWeatherRejectsInvalidData(t *testing.T) {
+	h, _, _ := newWeatherHandler(t)
+
+	// Ни БД, ни кэш не вызываются: gomock провалит тест на неожиданном вызове
+	rec := putWeather(h, "moscow", `{"temperature": 1000}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("код ответа %d, ожидался 400: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "VALIDATION_FAILED") {
+		t.Errorf("в ответе нет кода VALIDATION_FAILED: %s", rec.Body)
+	}
+}
+
+func TestUpdateWeatherStoreError(t *testing.T) {
+	h, store, _ := newWeatherHandler(t)
+
+	store.EXPECT().Save(gomock.Any(), gomock.Any()).Return(time.Time{}, errors.New("соединение разорвано"))
+
+	rec := putWeather(h, "moscow", `{"temperature": 10}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("код ответа %d, ожидался 500: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestDeleteWeatherUnknownCity(t *testing.T) {
+	h, store, _ := newWeatherHandler(t)
+
+	store.EXPECT().Delete(gomock.Any(), "atlantis").Return(false, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/weather/atlantis", nil)
+	req = mux.SetURLVars(req, map[string]string{"city": "atlantis"})
+	rec := httptest.NewRecorder()
+	h.DeleteWeather(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("код ответа %d, ожидался 404: %s", rec.Code, rec.Body)
+	}
+}
+
+// Повторный запрос отдается из настоящего Redis без обращения к БД
+func TestGetWeatherServedFromRedis(t *testing.T) {
+	c := testutil.Redis(t, time.Minute)
+	store := mocks.NewMockHandlerStore(gomock.NewController(t))
+	h := handlers.NewWeatherHandler(store, c, testutil.Logger(t))
+
+	if err := c.Set(context.Background(), cache.CityKey("moscow"), model.WeatherData{
+		City:          "moscow",
+		Temp:          -7,
+		ConditionCode: model.ConditionSnow,
+		Timestamp:     time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Явный tz: часовой пояс города не запрашивается из БД
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/moscow?tz=UTC", nil)
+	req = mux.SetURLVars(req, map[string]string{"city": "moscow"})
+	rec := httptest.NewRecorder()
+	h.GetWeather(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("код ответа %d, ожидался 200: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), `"cached":true`) {
+		t.Errorf("ответ не из кэша: %s", rec.Body)
+	}
+	if cc := rec.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "public, max-age=") {
+		t.Errorf("Cache-Control %q, ожидался срок жизни ключа Redis", cc)
+	}
+}
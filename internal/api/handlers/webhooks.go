@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/webhook"
+)
+
+// maxWebhooks — сколько webhooks может зарегистрировать аккаунт
+const maxWebhooks = 20
+
+// WebhookHandler — webhooks аккаунта. Замеры по ним отправляет агрегатор
+// после сохранения в БД.
+type WebhookHandler struct {
+	weather *WeatherHandler
+}
+
+func NewWebhookHandler(weather *WeatherHandler) *WebhookHandler {
+	return &WebhookHandler{weather: weather}
+}
+
+// CreateWebhook регистрирует адрес {"url":"https://...","city":"Moscow"}; без
+// city приходят замеры всех городов. Ключ подписи отдается только в этом ответе.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	var req struct {
+		URL  string `json:"url"`
+		City string `json:"city"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendBodyError(w, err, "Неверный формат JSON", err.Error())
+		return
+	}
+
+	hook := model.Webhook{
+		AccountID: caller.ID,
+		URL:       strings.TrimSpace(req.URL),
+		CreatedAt: time.Now().UTC(),
+	}
+	if city := normalizeCity(req.City); city != "" {
+		hook.City = h.weather.geocoder.Load().Canonical(ctx, city)
+	}
+	if err := hook.Validate(); err != nil {
+		sendValidationError(w, err)
+		return
+	}
+	if err := webhook.CheckURL(ctx, hook.URL); err != nil {
+		sendValidationError(w, model.ValidationErrors{{Field: "url", Message: err.Error()}})
+		return
+	}
+
+	hooks, err := store.ListWebhooks(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения webhooks", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if len(hooks) >= maxWebhooks {
+		sendError(w, errcode.Conflict, "Слишком много webhooks", "удалите webhook перед добавлением нового")
+		return
+	}
+
+	hook.Secret, err = webhook.GenerateSecret()
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка создания ключа подписи", "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	hook, err = store.CreateWebhook(ctx, hook)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка сохранения webhook", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+
+	h.weather.logger.InfoContext(ctx, "Webhook создан", "account", caller.Name, "webhook_id", hook.ID, "city", hook.City)
+	sendJSON(w, http.StatusCreated, hook)
+}
+
+// ListWebhooks возвращает webhooks аккаунта без ключей подписи
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	hooks, err := store.ListWebhooks(ctx, caller.ID)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка получения webhooks", "account", caller.Name, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, model.WebhooksResponse{Webhooks: hooks, Total: len(hooks)})
+}
+
+// DeleteWebhook удаляет webhook аккаунта по ID из пути
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	caller, store := h.weather.caller(w, r)
+	if caller == nil {
+		return
+	}
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id < 1 {
+		sendError(w, errcode.InvalidRequest, "Неверный ID webhook", "ожидается положительное целое число")
+		return
+	}
+
+	deleted, err := store.DeleteWebhook(ctx, caller.ID, id)
+	if err != nil {
+		h.weather.logger.ErrorContext(ctx, "Ошибка удаления webhook", "account", caller.Name, "webhook_id", id, "error", err)
+		sendInternalError(w, err)
+		return
+	}
+	if !deleted {
+		sendError(w, errcode.NotFound, "Webhook не найден", strconv.FormatInt(id, 10))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gorilla/mux"
+)
+
+// requestLimits — таймауты обработки по маршрутам и лимит размера тела
+type requestLimits struct {
+	timeout time.Duration            // для маршрутов без переопределения; 0 — без таймаута
+	routes  map[string]time.Duration // по шаблону маршрута mux
+	maxBody int64                    // 0 — без лимита
+}
+
+// newRequestLimits разбирает ROUTE_TIMEOUTS; неверные записи пропускаются с предупреждением
+func newRequestLimits(cfg *config.Config, logger *slog.Logger) requestLimits {
+	limits := requestLimits{
+		timeout: max(cfg.RequestTimeout, 0),
+		routes:  make(map[string]time.Duration, len(cfg.RouteTimeouts)),
+		maxBody: max(cfg.RequestMaxBodyBytes, 0),
+	}
+	for _, entry := range cfg.RouteTimeouts {
+		route, timeout, err := parseRouteTimeout(entry)
+		if err != nil {
+			logger.Warn("Неверный таймаут маршрута пропущен", "entry", entry, "error", err)
+			continue
+		}
+		limits.routes[route] = timeout
+	}
+	return limits
+}
+
+// parseRouteTimeout разбирает запись вида "/api/v1/graphql=30s"
+func parseRouteTimeout(entry string) (string, time.Duration, error) {
+	route, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	if !ok || route == "" {
+		return "", 0, fmt.Errorf("ожидается шаблон=длительность")
+	}
+	timeout, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || timeout < 0 {
+		return "", 0, fmt.Errorf("неверная длительность: %s", value)
+	}
+	return strings.TrimSpace(route), timeout, nil
+}
+
+// timeoutFor возвращает таймаут маршрута запроса
+func (l requestLimits) timeoutFor(r *http.Request) time.Duration {
+	if current := mux.CurrentRoute(r); current != nil {
+		if tpl, err := current.GetPathTemplate(); err == nil {
+			if timeout, ok := l.routes[tpl]; ok {
+				return timeout
+			}
+		}
+	}
+	return l.timeout
+}
+
+// Middleware ограничений запроса: дедлайн в контексте доходит до запросов
+// к Postgres и Redis, тело больше лимита обрывается на чтении с ошибкой
+// *http.MaxBytesError, которую обработчики превращают в 413
+func limitsMiddleware(limits requestLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limits.maxBody > 0 && hasBody(r.Method) {
+				if r.ContentLength > limits.maxBody {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(errcode.PayloadTooLarge.Status())
+					json.NewEncoder(w).Encode(model.ErrorResponse{
+						Code:      string(errcode.PayloadTooLarge),
+						Error:     "Слишком большое тело запроса",
+						Message:   fmt.Sprintf("не больше %d байт", limits.maxBody),
+						RequestID: w.Header().Get(logging.HeaderRequestID),
+					})
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, limits.maxBody)
+			}
+
+			if timeout := limits.timeoutFor(r); timeout > 0 {
+				ctx, cancel := context.WithTimeout(r.Context(), timeout)
+				defer cancel()
+				r = r.WithContext(ctx)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasBody — методы, которыми клиенты передают данные
+func hasBody(method string) bool {
+	return method == http.MethodPut || method == http.MethodPost || method == http.MethodPatch
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/api/errcode"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/ipfilter"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/tracing"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/gometeo/app/cmd/api")
+
+// accessLogPolicy решает, какие запросы попадают в access-лог и с какими полями
+type accessLogPolicy struct {
+	level       slog.Level // уровень записей об успешных ответах
+	skipPaths   map[string]bool
+	successRate float64            // доля логируемых успешных ответов
+	routeRates  map[string]float64 // доли по шаблону маршрута или пути
+	bodies      bool
+	bodyLimit   int
+}
+
+// sensitiveRoutes — маршруты (и их подмаршруты), тела которых содержат пароли,
+// токены, ключи API или секреты webhooks и никогда не пишутся в лог
+var sensitiveRoutes = []string{
+	"/api/v1/auth",
+	"/api/v1/keys",
+	"/api/v1/webhooks",
+}
+
+func newAccessLogPolicy(cfg *config.Config, logger *slog.Logger) accessLogPolicy {
+	skip := make(map[string]bool, len(cfg.AccessLogSkipPaths))
+	for _, p := range cfg.AccessLogSkipPaths {
+		if p = strings.TrimSpace(p); p != "" {
+			skip[p] = true
+		}
+	}
+	rates := make(map[string]float64, len(cfg.AccessLogRouteSampleRates))
+	for _, entry := range cfg.AccessLogRouteSampleRates {
+		route, rate, err := parseRouteSampleRate(entry)
+		if err != nil {
+			logger.Warn("Неверная доля access-лога пропущена", "entry", entry, "error", err)
+			continue
+		}
+		rates[route] = rate
+	}
+	return accessLogPolicy{
+		level:       logging.ParseLevel(cfg.AccessLogLevel),
+		skipPaths:   skip,
+		successRate: cfg.AccessLogSuccessSampleRate,
+		routeRates:  rates,
+		bodies:      cfg.AccessLogBodies && cfg.AccessLogBodyLimit > 0,
+		bodyLimit:   cfg.AccessLogBodyLimit,
+	}
+}
+
+// parseRouteSampleRate разбирает запись вида "/api/v1/health=0.01"
+func parseRouteSampleRate(entry string) (string, float64, error) {
+	route, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	if !ok || strings.TrimSpace(route) == "" {
+		return "", 0, fmt.Errorf("ожидается маршрут=доля")
+	}
+	rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return "", 0, fmt.Errorf("доля должна быть от 0 до 1: %s", value)
+	}
+	return strings.TrimSpace(route), rate, nil
+}
+
+// shouldLog: ошибки 4xx/5xx логируются всегда, успешные — по пути и с сэмплированием
+func (p accessLogPolicy) shouldLog(route, path string, status int) bool {
+	if status >= http.StatusBadRequest {
+		return true
+	}
+	if p.skipPaths[path] {
+		return false
+	}
+	rate, ok := p.routeRates[route]
+	if !ok {
+		if rate, ok = p.routeRates[path]; !ok {
+			rate = p.successRate
+		}
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// levelFor возвращает уровень записи по статусу ответа
+func (p accessLogPolicy) levelFor(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return p.level
+	}
+}
+
+// captureBodies сообщает, писать ли тела запроса и ответа маршрута
+func (p accessLogPolicy) captureBodies(route string) bool {
+	if !p.bodies {
+		return false
+	}
+	for _, prefix := range sensitiveRoutes {
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyCapture хранит первые limit байт тела
+type bodyCapture struct {
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (c *bodyCapture) write(p []byte) {
+	room := c.limit - len(c.buf)
+	if len(p) > room {
+		p = p[:max(room, 0)]
+		c.truncated = true
+	}
+	c.buf = append(c.buf, p...)
+}
+
+// String возвращает сохраненное начало тела; обрезанный на границе байт
+// символ UTF-8 отбрасывается
+func (c *bodyCapture) String() string {
+	s := strings.ToValidUTF8(string(c.buf), "")
+	if c.truncated {
+		return s + "…"
+	}
+	return s
+}
+
+// captureReader копирует прочитанное обработчиком тело запроса
+type captureReader struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.write(p[:n])
+	return n, err
+}
+
+// Middleware идентификатора запроса: принимает X-Request-ID клиента или прокси,
+// а неверный или отсутствующий заменяет новым. Идентификатор возвращается
+// в ответе, попадает в логи через контекст, в тела ошибок и в заголовки
+// сообщений Kafka, которые отправляются при обработке запроса.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(logging.HeaderRequestID)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set(logging.HeaderRequestID, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// Middleware адреса клиента: адрес за доверенными прокси сохраняется
+// в контексте для лимитов, логов и проверки подсетей
+func clientIPMiddleware(filter *ipfilter.Filter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if addr := filter.ClientIP(r); addr.IsValid() {
+				r = r.WithContext(ipfilter.WithClientIP(r.Context(), addr))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// adminNetworkPrefix — маршруты, доступные только из разрешенных подсетей
+const adminNetworkPrefix = "/api/v1/admin"
+
+// Middleware подсетей администратора: запросы к /api/v1/admin с адресов
+// вне ADMIN_ALLOW_CIDRS или из ADMIN_DENY_CIDRS получают 403 до проверки токена
+func adminNetworkMiddleware(filter *ipfilter.Filter, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if !filter.Restricted() || path != adminNetworkPrefix && !strings.HasPrefix(path, adminNetworkPrefix+"/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			addr, _ := ipfilter.FromContext(r.Context())
+			if filter.Allows(addr) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logger.WarnContext(r.Context(), "Запрос к маршруту администратора с неразрешенного адреса",
+				"client_ip", addr.String(), "path", path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(errcode.Forbidden.Status())
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Code:      string(errcode.Forbidden),
+				Error:     "Доступ запрещен",
+				Message:   "адрес клиента не входит в разрешенные подсети",
+				RequestID: w.Header().Get(logging.HeaderRequestID),
+			})
+		})
+	}
+}
+
+// Middleware трассировки: серверный спан на запрос, продолжающий трассу
+// вызывающей стороны из traceparent. Запросы к Postgres и Redis внутри
+// обработчика становятся его дочерними спанами, trace_id попадает в логи.
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		ctx := tracing.ExtractHTTP(r.Context(), r.Header)
+		ctx, span := tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("request.id", logging.RequestID(r.Context())),
+			),
+		)
+		defer span.End()
+
+		rw := &responseWriter{ResponseWriter: w, status: 200}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", rw.status))
+		if rw.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rw.status))
+		}
+	})
+}
+
+// routeTemplate — шаблон маршрута mux для метрик и спанов
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if tpl, err := current.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return "unmatched"
+}
+
+// Middleware для логирования
+func loggingMiddleware(logger *slog.Logger, policy accessLogPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			route := routeTemplate(r)
+
+			// Создаем ResponseWriter для отслеживания статуса и размера ответа
+			rw := &responseWriter{ResponseWriter: w, status: 200}
+
+			var requestBody *bodyCapture
+			if policy.captureBodies(route) {
+				requestBody = &bodyCapture{limit: policy.bodyLimit}
+				if r.Body != nil && r.Body != http.NoBody {
+					r.Body = &captureReader{ReadCloser: r.Body, capture: requestBody}
+				}
+				rw.capture = &bodyCapture{limit: policy.bodyLimit}
+			}
+
+			next.ServeHTTP(rw, r)
+
+			if !policy.shouldLog(route, r.URL.Path, rw.status) {
+				return
+			}
+
+			duration := time.Since(start)
+
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"route", route,
+				"status", rw.status,
+				"duration_ms", duration.Milliseconds(),
+				"request_bytes", max(r.ContentLength, 0),
+				"response_bytes", rw.bytes,
+				"user_agent", r.UserAgent(),
+				"remote_addr", r.RemoteAddr,
+			}
+			if addr, ok := ipfilter.FromContext(r.Context()); ok {
+				attrs = append(attrs, "client_ip", addr.String())
+			}
+			if requestBody != nil {
+				attrs = append(attrs, "request_body", requestBody.String())
+				// Сжатые и двоичные ответы (тайлы) в лог не попадают
+				h := rw.Header()
+				if h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+					attrs = append(attrs, "response_body", rw.capture.String())
+				}
+			}
+			logger.Log(r.Context(), policy.levelFor(rw.status), "HTTP запрос", attrs...)
+		})
+	}
+}
+
+// Middleware для метрик HTTP: число запросов и длительность по шаблону маршрута
+func metricsMiddleware() func(http.Handler) http.Handler {
+	meter := metrics.Meter("github.com/gometeo/app/cmd/api")
+	requests, _ := meter.Int64Counter("http.server.requests",
+		metric.WithDescription("Количество обработанных HTTP-запросов"))
+	duration, _ := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Длительность обработки HTTP-запросов"),
+		metric.WithUnit("s"))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, status: 200}
+
+			next.ServeHTTP(rw, r)
+
+			attrs := metric.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", routeTemplate(r)),
+				attribute.Int("http.response.status_code", rw.status),
+			)
+			requests.Add(r.Context(), 1, attrs)
+			duration.Record(r.Context(), time.Since(start).Seconds(), attrs)
+		})
+	}
+}
+
+// Middleware для перехвата паник: 500 вместо обрыва соединения и отчет в репортер
+func recoveryMiddleware(logger *slog.Logger, reporter errreport.Reporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					// Штатное прерывание ответа, его обрабатывает сам net/http
+					panic(rec)
+				}
+
+				logger.ErrorContext(r.Context(), "Паника при обработке запроса",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"panic", rec,
+					"stack", string(debug.Stack()),
+				)
+				// Идентификатор запроса связывает событие репортера с логами и ответом клиенту
+				reporter.CapturePanic(r.Context(), rec, map[string]string{
+					"method":     r.Method,
+					"path":       r.URL.Path,
+					"route":      routeTemplate(r),
+					"request_id": logging.RequestID(r.Context()),
+				})
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(errcode.Internal.Status())
+				json.NewEncoder(w).Encode(model.ErrorResponse{
+					Code:      string(errcode.Internal),
+					Error:     "Внутренняя ошибка сервера",
+					RequestID: w.Header().Get(logging.HeaderRequestID),
+				})
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Кастомный ResponseWriter для отслеживания статуса и размера ответа
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	bytes   int64
+	capture *bodyCapture // nil — тело ответа не сохраняется
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+	if rw.capture != nil {
+		rw.capture.write(p[:n])
+	}
+	return n, err
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Hijack передает соединение обработчику WebSocket; ответ 101 пишет он сам
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(rw.ResponseWriter).Hijack()
+	if err == nil {
+		rw.status = http.StatusSwitchingProtocols
+	}
+	return conn, brw, err
+}
+
+// Middleware для установки Content-Type
+func contentTypeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,177 @@
+// Package api собирает HTTP-маршруты и middleware погодного API.
+// Используется сервисом cmd/api и монолитом cmd/gometeo.
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gometeo/app/internal/api/docs"
+	"github.com/gometeo/app/internal/api/handlers"
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/dashboard"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/health"
+	"github.com/gometeo/app/internal/ipfilter"
+	"github.com/gometeo/app/internal/quality"
+	"github.com/gometeo/app/internal/tiles"
+	"github.com/gorilla/mux"
+)
+
+// Deps — обработчики и инфраструктура, нужные маршрутизатору
+type Deps struct {
+	Weather     *handlers.WeatherHandler
+	Accounts    *handlers.AccountHandler
+	Replication *handlers.ReplicationHandler // nil — сравнение регионов выключено
+	Cache       handlers.Cache
+	Tokens      *auth.Tokens // nil — JWT выключены
+	Checks      *health.Registry
+	Metrics     http.Handler
+	Reporter    errreport.Reporter
+	IPFilter    *ipfilter.Filter // nil — X-Forwarded-For не учитывается, подсети не ограничены
+	Logger      *slog.Logger
+}
+
+// NewRouter регистрирует маршруты API и middleware
+func NewRouter(cfg *config.Config, deps Deps) *mux.Router {
+	router := mux.NewRouter()
+
+	// Описание OpenAPI и Swagger UI доступны без ключа, поэтому регистрируются
+	// на корневом маршрутизаторе до подмаршрутизатора API
+	router.Handle("/api/v1/openapi.json", docs.Spec(router)).Methods("GET")
+	router.PathPrefix("/api/v1/docs/").Handler(docs.UI("/api/v1/docs/", "/api/v1/openapi.json")).Methods("GET")
+
+	// API маршруты
+	api := router.PathPrefix("/api/v1").Subrouter()
+
+	// Weather endpoints; /weather/changes и /weather/batch регистрируются раньше /weather/{city}
+	api.HandleFunc("/weather/changes", deps.Weather.GetChanges).Methods("GET")
+	api.HandleFunc("/weather/batch", deps.Weather.GetBatch).Methods("POST")
+	api.HandleFunc("/weather", deps.Weather.UpdateWeatherBatch).Methods("PUT")
+	api.HandleFunc("/weather/{city}", deps.Weather.GetWeather).Methods("GET", "HEAD")
+	api.HandleFunc("/weather/{city}", deps.Weather.UpdateWeather).Methods("PUT")
+	api.HandleFunc("/weather/{city}", deps.Weather.DeleteWeather).Methods("DELETE")
+	api.HandleFunc("/weather/{city}/at", deps.Weather.GetWeatherAt).Methods("GET")
+	api.HandleFunc("/weather/{city}/history", deps.Weather.GetHistory).Methods("GET")
+	api.HandleFunc("/weather/{city}/providers", deps.Weather.GetProviders).Methods("GET")
+	api.HandleFunc("/weather/{city}/wait", deps.Weather.WaitForUpdate).Methods("GET")
+	stats := handlers.NewStatsHandler(deps.Weather, cfg.WeatherStatsCacheTTL)
+	api.HandleFunc("/weather/{city}/stats", stats.GetStats).Methods("GET")
+	api.HandleFunc("/cities", deps.Weather.GetAllCities).Methods("GET")
+	api.HandleFunc("/cities/search", deps.Weather.SearchCities).Methods("GET")
+	api.HandleFunc("/ws", deps.Weather.ServeLive).Methods("GET")
+	api.HandleFunc("/forecast/{city}", deps.Weather.GetForecast).Methods("GET")
+	api.HandleFunc("/astro/{city}", deps.Weather.GetAstro).Methods("GET")
+	qualityHandler := handlers.NewQualityHandler(deps.Weather, quality.OptionsFromConfig(cfg))
+	api.HandleFunc("/quality/{city}", qualityHandler.GetQuality).Methods("GET")
+	air := handlers.NewAirHandler(deps.Weather, cfg.AirQualityCacheTTL, cfg.AirQualityStaleAfter)
+	api.HandleFunc("/air/{city}", air.GetAir).Methods("GET")
+	api.HandleFunc("/air/{city}/history", air.GetAirHistory).Methods("GET")
+	api.HandleFunc("/air/{city}/stats", air.GetAirStats).Methods("GET")
+	badgeHandler := handlers.NewBadgeHandler(deps.Weather, cfg.BadgeTheme, cfg.BadgeCacheTTL)
+	api.HandleFunc("/badge/{city}.svg", badgeHandler.GetBadge).Methods("GET")
+	if upstream := tiles.FromConfig(cfg); upstream != nil {
+		tileHandler := handlers.NewTileHandler(upstream, deps.Cache, cfg.TilesCacheTTL, cfg.TilesDailyQuota, deps.Logger)
+		api.HandleFunc("/tiles/{layer}/{z:[0-9]+}/{x:[0-9]+}/{y:[0-9]+}.png", tileHandler.GetTile).Methods("GET")
+	}
+	if deps.Replication != nil {
+		api.HandleFunc("/replication/status", deps.Replication.GetStatus).Methods("GET")
+	}
+
+	// GraphQL поверх тех же данных: несколько городов и нужные поля за один запрос
+	api.Handle("/graphql", handlers.NewGraphQLHandler(deps.Weather)).Methods("POST")
+
+	// Версия сборки
+	api.HandleFunc("/version", handlers.Version).Methods("GET")
+
+	// Расход квоты по API-ключу
+	api.HandleFunc("/account/usage", deps.Accounts.GetUsage).Methods("GET")
+
+	// Избранное и настройки аккаунта
+	me := handlers.NewMeHandler(deps.Weather, deps.Accounts)
+	api.HandleFunc("/me/preferences", me.GetPreferences).Methods("GET")
+	api.HandleFunc("/me/preferences", me.UpdatePreferences).Methods("PUT")
+	api.HandleFunc("/me/favorites", me.GetFavorites).Methods("GET")
+	api.HandleFunc("/me/favorites/{city}", me.AddFavorite).Methods("PUT")
+	api.HandleFunc("/me/favorites/{city}", me.RemoveFavorite).Methods("DELETE")
+	api.HandleFunc("/me/weather", me.GetMyWeather).Methods("GET")
+
+	// Правила оповещений аккаунта и их срабатывания
+	alerts := handlers.NewAlertHandler(deps.Weather)
+	api.HandleFunc("/alerts", alerts.CreateRule).Methods("POST")
+	api.HandleFunc("/alerts", alerts.ListRules).Methods("GET")
+	api.HandleFunc("/alerts/triggered", alerts.ListTriggered).Methods("GET")
+	api.HandleFunc("/alerts/{id:[0-9]+}", alerts.DeleteRule).Methods("DELETE")
+
+	// Webhooks аккаунта: агрегатор отправляет на них сохраненные замеры
+	webhooks := handlers.NewWebhookHandler(deps.Weather)
+	api.HandleFunc("/webhooks", webhooks.CreateWebhook).Methods("POST")
+	api.HandleFunc("/webhooks", webhooks.ListWebhooks).Methods("GET")
+	api.HandleFunc("/webhooks/{id:[0-9]+}", webhooks.DeleteWebhook).Methods("DELETE")
+
+	// Токены пользователей и выпуск API-ключей администратором
+	authHandler := handlers.NewAuthHandler(deps.Weather, deps.Tokens)
+	api.HandleFunc("/auth/token", authHandler.IssueToken).Methods("POST")
+	keys := handlers.NewKeyHandler(deps.Weather)
+	api.HandleFunc("/keys", keys.CreateKey).Methods("POST")
+
+	// Справочник городов: коллектор и планировщик опрашивают включенные города
+	adminCities := handlers.NewAdminCityHandler(deps.Weather)
+	api.HandleFunc("/admin/cities", adminCities.ListCities).Methods("GET")
+	api.HandleFunc("/admin/cities", adminCities.CreateCity).Methods("POST")
+	api.HandleFunc("/admin/cities/{name}", adminCities.GetCity).Methods("GET")
+	api.HandleFunc("/admin/cities/{name}", adminCities.UpdateCity).Methods("PUT")
+	api.HandleFunc("/admin/cities/{name}", adminCities.DeleteCity).Methods("DELETE")
+	maintenance := handlers.NewMaintenanceHandler(deps.Weather)
+	api.HandleFunc("/admin/maintenance", maintenance.GetMaintenance).Methods("GET")
+	api.HandleFunc("/admin/maintenance", maintenance.EnableMaintenance).Methods("PUT")
+	api.HandleFunc("/admin/maintenance", maintenance.DisableMaintenance).Methods("DELETE")
+	api.Use(deps.Accounts.Middleware)
+	limiter := handlers.NewRateLimiter(cfg, deps.Cache, deps.Logger)
+	if limiter != nil {
+		api.Use(limiter.Middleware)
+	}
+
+	// Health check
+	api.HandleFunc("/health", deps.Checks.Handler()).Methods("GET")
+
+	// API v2: список городов из собственного типа кэша и сведения о провайдере.
+	// Маршруты v1 остаются прежними для обратной совместимости.
+	apiV2 := router.PathPrefix("/api/v2").Subrouter()
+	v2 := handlers.NewV2Handler(deps.Weather)
+	apiV2.HandleFunc("/cities", v2.GetCities).Methods("GET")
+	apiV2.HandleFunc("/weather/{city}", v2.GetWeather).Methods("GET", "HEAD")
+	apiV2.Use(deps.Accounts.Middleware)
+	if limiter != nil {
+		apiV2.Use(limiter.Middleware)
+	}
+
+	// Пробы Kubernetes: живость без обращения к зависимостям и готовность
+	// с проверкой Postgres и Redis; ключ API для них не нужен
+	router.HandleFunc("/livez", deps.Checks.LiveHandler()).Methods("GET", "HEAD")
+	router.HandleFunc("/readyz", deps.Checks.ReadyHandler()).Methods("GET", "HEAD")
+
+	// Метрики Prometheus
+	router.Handle("/metrics", deps.Metrics).Methods("GET")
+
+	// Встроенная панель мониторинга
+	router.Handle("/", dashboard.Handler()).Methods("GET", "HEAD")
+
+	// Middleware
+	router.Use(requestIDMiddleware)
+	router.Use(clientIPMiddleware(deps.IPFilter))
+	router.Use(tracingMiddleware)
+	router.Use(recoveryMiddleware(deps.Logger, deps.Reporter))
+	router.Use(metricsMiddleware())
+	router.Use(loggingMiddleware(deps.Logger, newAccessLogPolicy(cfg, deps.Logger)))
+	router.Use(adminNetworkMiddleware(deps.IPFilter, deps.Logger))
+	if cfg.CompressionEnabled {
+		router.Use(compressionMiddleware(cfg))
+	}
+	router.Use(limitsMiddleware(newRequestLimits(cfg, deps.Logger)))
+	router.Use(maintenance.Middleware)
+	router.Use(contentTypeMiddleware)
+
+	return router
+}
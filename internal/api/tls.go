@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+)
+
+// certReloader отдает сертификат для рукопожатия и подменяет его, когда
+// файлы сертификата или ключа меняются на диске (ротация cert-manager и т.п.)
+type certReloader struct {
+	certFile, keyFile string
+	cert              atomic.Pointer[tls.Certificate]
+	modTime           time.Time // самое позднее изменение файлов; только из reload
+	logger            *slog.Logger
+}
+
+func newCertReloader(certFile, keyFile string, logger *slog.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload перечитывает пару, если файлы изменились; true — сертификат заменен
+func (r *certReloader) reload() (bool, error) {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return false, err
+	}
+	if !modTime.After(r.modTime) {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("ошибка загрузки сертификата: %w", err)
+	}
+	r.cert.Store(&cert)
+	r.modTime = modTime
+	return true, nil
+}
+
+// lastModified возвращает время последнего изменения сертификата или ключа
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("ошибка чтения файла TLS: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// watch проверяет файлы раз в interval до отмены ctx. Ошибка загрузки
+// (например, сертификат записан, а ключ еще нет) не сбрасывает действующий
+// сертификат: следующая проверка попробует снова.
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			replaced, err := r.reload()
+			switch {
+			case err != nil:
+				r.logger.Warn("Не удалось перечитать сертификат TLS", "error", err)
+			case replaced:
+				r.logger.Info("Сертификат TLS перечитан", "cert", r.certFile)
+			}
+		}
+	}
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// ConfigureServer включает на сервере TLS и HTTP/2 по настройкам. Возвращает
+// функцию остановки наблюдения за файлами сертификата; без TLS она ничего не делает.
+func ConfigureServer(server *http.Server, cfg *config.Config, logger *slog.Logger) (func(), error) {
+	stop := func() {}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(cfg.HTTP2Enabled)
+	protocols.SetUnencryptedHTTP2(cfg.HTTP2Enabled && cfg.HTTP2Cleartext)
+	server.Protocols = protocols
+
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return stop, nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return stop, fmt.Errorf("для TLS нужны и TLS_CERT_FILE, и TLS_KEY_FILE")
+	}
+
+	reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, logger)
+	if err != nil {
+		return stop, err
+	}
+	server.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.getCertificate,
+	}
+
+	if cfg.TLSReloadInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		go reloader.watch(ctx, cfg.TLSReloadInterval)
+		stop = cancel
+	}
+	return stop, nil
+}
+
+// ListenAndServe запускает сервер по HTTPS, если ConfigureServer включил TLS,
+// иначе по HTTP
+func ListenAndServe(server *http.Server) error {
+	if server.TLSConfig != nil {
+		// Сертификат отдает GetCertificate, пути к файлам не нужны
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,117 @@
+// Package astro рассчитывает восход и заход Солнца и фазу Луны по координатам
+// и дате. Точность — около минуты для Солнца, этого достаточно для погодного API.
+package astro
+
+import (
+	"math"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+const (
+	// j2000 — юлианская дата эпохи J2000.0
+	j2000 = 2451545.0
+	// unixEpochJD — юлианская дата 1970-01-01T00:00:00Z
+	unixEpochJD = 2440587.5
+	// sunAltitude — высота центра Солнца при восходе с учетом рефракции и радиуса диска
+	sunAltitude = -0.833
+	// obliquity — наклон эклиптики
+	obliquity = 23.4397
+
+	// synodicMonth — средняя длительность лунного месяца в днях
+	synodicMonth = 29.530588853
+	// knownNewMoon — новолуние 2000-01-06 18:14 UTC
+	knownNewMoon = 2451550.26
+)
+
+// Day рассчитывает данные на календарную дату day в ее часовом поясе.
+// lat и lon — в градусах, восточная долгота положительна.
+func Day(lat, lon float64, day time.Time) model.Astro {
+	loc := day.Location()
+	y, m, d := day.Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+
+	out := model.Astro{Date: day.Format(time.DateOnly)}
+
+	// Уравнение восхода: средний солнечный полдень для долготы
+	n := math.Round(julian(noon) - j2000 + 0.0008)
+	meanNoon := n - lon/360
+	anomaly := normalize(357.5291 + 0.98560028*meanNoon)
+	mRad := rad(anomaly)
+	center := 1.9148*math.Sin(mRad) + 0.02*math.Sin(2*mRad) + 0.0003*math.Sin(3*mRad)
+	longitude := normalize(anomaly + center + 180 + 102.9372)
+	lRad := rad(longitude)
+	transit := j2000 + meanNoon + 0.0053*math.Sin(mRad) - 0.0069*math.Sin(2*lRad)
+	out.SolarNoon = fromJulian(transit).In(loc)
+
+	declination := math.Asin(math.Sin(lRad) * math.Sin(rad(obliquity)))
+	phi := rad(lat)
+	cosHour := (math.Sin(rad(sunAltitude)) - math.Sin(phi)*math.Sin(declination)) /
+		(math.Cos(phi) * math.Cos(declination))
+	switch {
+	case cosHour < -1:
+		out.PolarDay = true
+		out.DayLengthSeconds = 24 * 60 * 60
+	case cosHour > 1:
+		out.PolarNight = true
+	default:
+		hourAngle := deg(math.Acos(cosHour))
+		sunrise := fromJulian(transit - hourAngle/360).In(loc)
+		sunset := fromJulian(transit + hourAngle/360).In(loc)
+		out.Sunrise, out.Sunset = &sunrise, &sunset
+		out.DayLengthSeconds = int64(sunset.Sub(sunrise).Seconds())
+	}
+
+	// Луна: возраст от известного новолуния по среднему синодическому месяцу
+	age := math.Mod(julian(out.SolarNoon)-knownNewMoon, synodicMonth)
+	if age < 0 {
+		age += synodicMonth
+	}
+	fraction := age / synodicMonth
+	out.MoonAgeDays = round(age, 2)
+	out.MoonIllumination = round((1-math.Cos(2*math.Pi*fraction))/2, 3)
+	out.MoonPhase = phase(fraction)
+	return out
+}
+
+// phases — фазы по восьмушкам лунного месяца, начиная с новолуния
+var phases = [...]model.MoonPhase{
+	model.MoonNew,
+	model.MoonWaxingCrescent,
+	model.MoonFirstQuarter,
+	model.MoonWaxingGibbous,
+	model.MoonFull,
+	model.MoonWaningGibbous,
+	model.MoonLastQuarter,
+	model.MoonWaningCrescent,
+}
+
+// phase выбирает ближайшую фазу: каждая занимает 1/8 месяца с центром на своей точке
+func phase(fraction float64) model.MoonPhase {
+	return phases[int(math.Floor(fraction*8+0.5))%len(phases)]
+}
+
+func julian(t time.Time) float64 {
+	return float64(t.UnixMilli())/86400000 + unixEpochJD
+}
+
+func fromJulian(jd float64) time.Time {
+	return time.UnixMilli(int64(math.Round((jd - unixEpochJD) * 86400000))).Truncate(time.Second)
+}
+
+func normalize(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
+
+func rad(degrees float64) float64 { return degrees * math.Pi / 180 }
+func deg(radians float64) float64 { return radians * 180 / math.Pi }
+
+func round(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
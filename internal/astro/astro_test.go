@@ -0,0 +1,116 @@
+package astro_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gometeo/app/internal/astro"
+	"github.com/gometeo/app/internal/model"
+)
+
+// tolerance — расхождение с таблицами восхода и захода, которое допускает упрощенная формула
+const tolerance = 2 * time.Minute
+
+func location(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("нет базы часовых поясов: %v", err)
+	}
+	return loc
+}
+
+func TestSunriseSunset(t *testing.T) {
+	// Восход и заход по таблицам timeanddate.com, местное время
+	tests := []struct {
+		name            string
+		lat, lon        float64
+		zone            string
+		date            string
+		sunrise, sunset string
+	}{
+		{"Москва, летнее солнцестояние", 55.7558, 37.6173, "Europe/Moscow", "2026-06-21", "03:44", "21:18"},
+		{"Москва, зима", 55.7558, 37.6173, "Europe/Moscow", "2026-01-03", "08:59", "16:08"},
+		{"Лондон, зимнее солнцестояние", 51.5074, -0.1278, "Europe/London", "2026-12-21", "08:04", "15:53"},
+		{"Сидней, южное полушарие", -33.8688, 151.2093, "Australia/Sydney", "2026-12-21", "05:41", "20:05"},
+		{"экватор, равноденствие", 0, 0, "UTC", "2026-03-20", "06:04", "18:11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := location(t, tt.zone)
+			day, err := time.ParseInLocation(time.DateOnly, tt.date, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := astro.Day(tt.lat, tt.lon, day)
+			if got.Date != tt.date {
+				t.Errorf("дата %s, ожидалась %s", got.Date, tt.date)
+			}
+			if got.Sunrise == nil || got.Sunset == nil {
+				t.Fatalf("нет восхода или захода: %+v", got)
+			}
+			for _, c := range []struct {
+				name string
+				got  time.Time
+				want string
+			}{{"восход", *got.Sunrise, tt.sunrise}, {"заход", *got.Sunset, tt.sunset}} {
+				want, err := time.ParseInLocation(time.DateOnly+" 15:04", tt.date+" "+c.want, loc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := c.got.Sub(want).Abs(); diff > tolerance {
+					t.Errorf("%s %s, ожидался %s", c.name, c.got.Format("15:04:05"), c.want)
+				}
+				if c.got.Location() != loc {
+					t.Errorf("%s в зоне %s, ожидалась %s", c.name, c.got.Location(), loc)
+				}
+			}
+			if want := int64(got.Sunset.Sub(*got.Sunrise).Seconds()); got.DayLengthSeconds != want {
+				t.Errorf("долгота дня %d, ожидалась %d", got.DayLengthSeconds, want)
+			}
+		})
+	}
+}
+
+func TestPolarDayAndNight(t *testing.T) {
+	loc := location(t, "Europe/Moscow")
+	// Мурманск
+	const lat, lon = 68.97, 33.09
+
+	summer := astro.Day(lat, lon, time.Date(2026, 6, 21, 0, 0, 0, 0, loc))
+	if !summer.PolarDay || summer.PolarNight || summer.Sunrise != nil || summer.Sunset != nil || summer.DayLengthSeconds != 24*60*60 {
+		t.Errorf("летом ожидался полярный день: %+v", summer)
+	}
+
+	winter := astro.Day(lat, lon, time.Date(2026, 12, 21, 0, 0, 0, 0, loc))
+	if !winter.PolarNight || winter.PolarDay || winter.Sunrise != nil || winter.Sunset != nil || winter.DayLengthSeconds != 0 {
+		t.Errorf("зимой ожидалась полярная ночь: %+v", winter)
+	}
+}
+
+func TestMoonPhase(t *testing.T) {
+	// Полнолуние 3 января 2026 и новолуние 18 января 2026
+	tests := []struct {
+		date     time.Time
+		phase    model.MoonPhase
+		minIllum float64
+		maxIllum float64
+	}{
+		{time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), model.MoonFull, 0.95, 1},
+		{time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC), model.MoonNew, 0, 0.05},
+		{time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), model.MoonLastQuarter, 0.3, 0.7},
+		{time.Date(2026, 1, 26, 0, 0, 0, 0, time.UTC), model.MoonFirstQuarter, 0.3, 0.7},
+	}
+	for _, tt := range tests {
+		got := astro.Day(55.7558, 37.6173, tt.date)
+		if got.MoonPhase != tt.phase {
+			t.Errorf("%s: фаза %s, ожидалась %s", got.Date, got.MoonPhase, tt.phase)
+		}
+		if got.MoonIllumination < tt.minIllum || got.MoonIllumination > tt.maxIllum {
+			t.Errorf("%s: освещенность %.3f вне %.2f–%.2f", got.Date, got.MoonIllumination, tt.minIllum, tt.maxIllum)
+		}
+		if got.MoonAgeDays < 0 || got.MoonAgeDays >= 29.54 {
+			t.Errorf("%s: возраст Луны %.2f", got.Date, got.MoonAgeDays)
+		}
+	}
+}
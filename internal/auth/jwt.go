@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+var (
+	// ErrDisabled — секрет подписи не задан, токены не выпускаются и не принимаются
+	ErrDisabled = errors.New("аутентификация по токенам выключена")
+	// ErrInvalidToken — токен поврежден, подписан другим ключом или истек
+	ErrInvalidToken = errors.New("неверный или истекший токен")
+)
+
+// HeaderAuthorization и схема, в которой клиент передает токен
+const (
+	HeaderAuthorization = "Authorization"
+	bearerPrefix        = "Bearer "
+)
+
+// Claims — содержимое токена: роль и аккаунт API пользователя
+type Claims struct {
+	Role      Role  `json:"role"`
+	AccountID int64 `json:"account_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Tokens выпускает и проверяет токены HS256
+type Tokens struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+}
+
+// NewTokens создает выпуск токенов по настройкам; nil — JWT_SECRET не задан
+func NewTokens(cfg *config.Config) *Tokens {
+	if cfg.JWTSecret == "" {
+		return nil
+	}
+	return &Tokens{secret: []byte(cfg.JWTSecret), issuer: cfg.JWTIssuer, ttl: cfg.JWTTTL}
+}
+
+// Issue выпускает токен пользователя и возвращает его вместе со временем истечения
+func (t *Tokens) Issue(user model.User, now time.Time) (string, time.Time, error) {
+	if t == nil {
+		return "", time.Time{}, ErrDisabled
+	}
+	expires := now.Add(t.ttl)
+	claims := Claims{
+		Role: Role(user.Role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   user.Username,
+			ID:        strconv.FormatInt(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	}
+	if user.AccountID != nil {
+		claims.AccountID = *user.AccountID
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка подписи токена: %w", err)
+	}
+	return signed, expires, nil
+}
+
+// Parse проверяет подпись, издателя и срок токена и возвращает пользователя
+func (t *Tokens) Parse(token string) (*Principal, error) {
+	if t == nil {
+		return nil, ErrDisabled
+	}
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return t.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(t.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if _, err := ParseRole(string(claims.Role)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &Principal{Username: claims.Subject, Role: claims.Role, AccountID: claims.AccountID}, nil
+}
+
+// BearerToken извлекает токен из заголовка Authorization; пусто — токена нет
+func BearerToken(header string) string {
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):])
+}
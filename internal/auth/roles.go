@@ -0,0 +1,124 @@
+// Package auth выпускает и проверяет JWT пользователей API и определяет,
+// какая роль нужна для маршрута.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Role — роль пользователя; каждая следующая включает права предыдущей
+type Role string
+
+const (
+	RoleReader Role = "reader" // чтение (GET)
+	RoleWriter Role = "writer" // запись погоды (PUT, POST, DELETE)
+	RoleAdmin  Role = "admin"  // управление ключами, webhooks и справочником городов
+)
+
+// ParseRole разбирает название роли
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(strings.TrimSpace(s))); r {
+	case RoleReader, RoleWriter, RoleAdmin:
+		return r, nil
+	default:
+		return "", fmt.Errorf("неизвестная роль: %s", s)
+	}
+}
+
+func (r Role) level() int {
+	switch r {
+	case RoleReader:
+		return 1
+	case RoleWriter:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows сообщает, достаточно ли роли r для действия, требующего required
+func (r Role) Allows(required Role) bool {
+	return r.level() >= required.level()
+}
+
+// adminRoutes — шаблоны маршрутов (и их подмаршруты), доступные только администратору
+var adminRoutes = []string{
+	"/api/v1/keys",
+	"/api/v1/admin",
+	"/api/v1/webhooks",
+}
+
+// readRoutes — маршруты, которые читают данные методом POST
+var readRoutes = map[string]bool{
+	"POST /api/v1/weather/batch": true,
+	"POST /api/v1/graphql":       true,
+}
+
+// PlanRoles — роли аккаунтов API-ключей по тарифу
+type PlanRoles map[string]Role
+
+// ParsePlanRoles разбирает список "тариф=роль"
+func ParsePlanRoles(pairs []string) (PlanRoles, error) {
+	roles := make(PlanRoles)
+	for _, pair := range pairs {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		plan, name, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(plan) == "" {
+			return nil, fmt.Errorf("ожидается тариф=роль: %s", pair)
+		}
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("тариф %s: %w", plan, err)
+		}
+		roles[strings.ToLower(strings.TrimSpace(plan))] = role
+	}
+	return roles, nil
+}
+
+// Role возвращает роль аккаунта с тарифом plan; тарифы без роли только читают
+func (p PlanRoles) Role(plan string) Role {
+	if role, ok := p[strings.ToLower(plan)]; ok {
+		return role
+	}
+	return RoleReader
+}
+
+// RequiredRole возвращает роль, нужную для метода и шаблона маршрута mux
+func RequiredRole(method, route string) Role {
+	for _, prefix := range adminRoutes {
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			return RoleAdmin
+		}
+	}
+	if method == http.MethodGet || method == http.MethodHead || readRoutes[method+" "+route] {
+		return RoleReader
+	}
+	return RoleWriter
+}
+
+// Principal — пользователь, предъявивший действующий токен
+type Principal struct {
+	Username  string
+	Role      Role
+	AccountID int64 // 0 — пользователь не привязан к аккаунту API
+}
+
+type ctxKey struct{}
+
+// WithPrincipal сохраняет пользователя в контексте запроса
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext возвращает пользователя токена, nil — запрос без токена
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(ctxKey{}).(*Principal)
+	return p
+}
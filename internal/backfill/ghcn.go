@@ -0,0 +1,157 @@
+// Package backfill загружает публичные исторические наборы данных в историю
+// наблюдений, чтобы статистика и прогнозы работали с первого дня.
+package backfill
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// ProviderGHCN — провайдер импортированных суточных данных NOAA GHCN-Daily
+const ProviderGHCN = "noaa-ghcn"
+
+// Элементы GHCN-Daily, значения в десятых долях °C
+const (
+	elementTAVG = "TAVG"
+	elementTMAX = "TMAX"
+	elementTMIN = "TMIN"
+)
+
+// ghcnMissing — значение-заглушка для отсутствующих данных
+const ghcnMissing = -9999
+
+// day накапливает температурные элементы станции за сутки
+type day struct {
+	station string
+	date    time.Time
+	avg     *float64
+	max     *float64
+	min     *float64
+}
+
+// temp: TAVG, а при его отсутствии среднее TMAX и TMIN
+func (d *day) temp() (float64, bool) {
+	switch {
+	case d.avg != nil:
+		return *d.avg, true
+	case d.max != nil && d.min != nil:
+		return (*d.max + *d.min) / 2, true
+	default:
+		return 0, false
+	}
+}
+
+// Stats — итоги разбора файла
+type Stats struct {
+	Rows     int // строк прочитано
+	Skipped  int // отброшено: чужая станция, не температура, флаг контроля качества
+	Readings int // суточных замеров передано в emit
+}
+
+// ReadGHCN разбирает CSV формата GHCN-Daily by_year
+// (ID,YYYYMMDD,ELEMENT,VALUE,MFLAG,QFLAG,SFLAG,OBS-TIME) и передает в emit
+// по одному замеру на станцию и сутки. stations сопоставляет ID станции с городом;
+// станции вне списка пропускаются. Время замера — полдень UTC указанных суток.
+// Строки одной станции за сутки в файлах NOAA идут подряд.
+func ReadGHCN(r io.Reader, stations map[string]string, emit func(model.WeatherData) error) (Stats, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var (
+		stats   Stats
+		current *day
+	)
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		d := current
+		current = nil
+		temp, ok := d.temp()
+		if !ok {
+			return nil
+		}
+		stats.Readings++
+		return emit(model.WeatherData{
+			City:      stations[d.station],
+			Temp:      temp,
+			Provider:  ProviderGHCN,
+			Timestamp: d.date.Add(12 * time.Hour),
+		})
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("ошибка чтения CSV: %w", err)
+		}
+		stats.Rows++
+
+		if len(record) < 6 {
+			return stats, fmt.Errorf("строка %d: ожидалось не меньше 6 полей, получено %d", stats.Rows, len(record))
+		}
+		station, rawDate, element, rawValue, qflag := record[0], record[1], record[2], record[3], record[5]
+
+		if _, ok := stations[station]; !ok ||
+			(element != elementTAVG && element != elementTMAX && element != elementTMIN) ||
+			strings.TrimSpace(qflag) != "" {
+			stats.Skipped++
+			continue
+		}
+
+		date, err := time.Parse("20060102", rawDate)
+		if err != nil {
+			return stats, fmt.Errorf("строка %d: некорректная дата %q", stats.Rows, rawDate)
+		}
+		tenths, err := strconv.Atoi(strings.TrimSpace(rawValue))
+		if err != nil {
+			return stats, fmt.Errorf("строка %d: некорректное значение %q", stats.Rows, rawValue)
+		}
+		if tenths == ghcnMissing {
+			stats.Skipped++
+			continue
+		}
+		value := float64(tenths) / 10
+
+		if current == nil || current.station != station || !current.date.Equal(date) {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+			current = &day{station: station, date: date}
+		}
+		switch element {
+		case elementTAVG:
+			current.avg = &value
+		case elementTMAX:
+			current.max = &value
+		case elementTMIN:
+			current.min = &value
+		}
+	}
+	return stats, flush()
+}
+
+// ParseStations разбирает сопоставления вида "USW00094728=New York"
+func ParseStations(pairs []string) (map[string]string, error) {
+	stations := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		id, city, ok := strings.Cut(pair, "=")
+		id, city = strings.TrimSpace(id), strings.TrimSpace(city)
+		if !ok || id == "" || city == "" {
+			return nil, fmt.Errorf("некорректное сопоставление станции %q, ожидается ID=Город", pair)
+		}
+		stations[id] = city
+	}
+	return stations, nil
+}
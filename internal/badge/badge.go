@@ -0,0 +1,158 @@
+// Package badge рисует SVG-бейдж текущей погоды для README и панелей
+package badge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// Theme — цвета бейджа
+type Theme struct {
+	Name       string
+	Label      string // фон части с названием города
+	Value      string // фон части с температурой
+	Text       string
+	TextShadow string
+	Icon       string // основной цвет значка
+	Accent     string // осадки и молния
+}
+
+// Themes — встроенные темы, ?theme= выбирает одну из них
+var Themes = map[string]Theme{
+	"light": {Name: "light", Label: "#555555", Value: "#4c9be8", Text: "#ffffff", TextShadow: "#010101", Icon: "#ffffff", Accent: "#d6ecff"},
+	"dark":  {Name: "dark", Label: "#1f2328", Value: "#2d333b", Text: "#e6edf3", TextShadow: "#000000", Icon: "#f2cc60", Accent: "#6cb6ff"},
+	"flat":  {Name: "flat", Label: "#e1e4e8", Value: "#ffffff", Text: "#24292f", TextShadow: "#ffffff", Icon: "#f0a500", Accent: "#0969da"},
+}
+
+// Lookup возвращает тему по имени без учета регистра
+func Lookup(name string) (Theme, error) {
+	theme, ok := Themes[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Theme{}, fmt.Errorf("неизвестная тема бейджа: %s", name)
+	}
+	return theme, nil
+}
+
+// Badge — содержимое бейджа
+type Badge struct {
+	City      string
+	Temp      float64 // уже в единицах Units
+	Units     model.Units
+	Condition model.ConditionCode
+	Title     string // всплывающая подсказка, обычно состояние погоды
+}
+
+// Ширина символа шрифта 11px Verdana в среднем; точная метрика для бейджа не нужна
+const (
+	charWidth = 7
+	iconWidth = 18
+	padding   = 6
+)
+
+type layout struct {
+	Badge
+	Theme
+	Value       string
+	Width       int
+	LabelWidth  int
+	ValueWidth  int
+	LabelText   int    // центр текста города
+	ValueText   int    // центр текста температуры
+	Icon        string // готовый значок в цветах темы
+	IconOffsetX int
+}
+
+// Render рисует бейдж в теме theme
+func Render(b Badge, theme Theme) []byte {
+	value := fmt.Sprintf("%d%s", int(math.Round(b.Temp)), b.Units.TemperatureSymbol())
+	l := layout{
+		Badge:      b,
+		Theme:      theme,
+		Value:      value,
+		LabelWidth: textWidth(b.City) + 2*padding,
+		ValueWidth: iconWidth + textWidth(value) + 2*padding,
+	}
+	l.Width = l.LabelWidth + l.ValueWidth
+	l.LabelText = l.LabelWidth / 2
+	l.IconOffsetX = l.LabelWidth + padding
+	l.ValueText = l.LabelWidth + padding + iconWidth + textWidth(value)/2
+	icon, ok := iconTemplates[b.Condition]
+	if !ok {
+		icon = iconTemplates[model.ConditionUnknown]
+	}
+
+	// Шаблоны разобраны при старте, запись в буфер не возвращает ошибок
+	var buf bytes.Buffer
+	_ = icon.Execute(&buf, theme)
+	l.Icon = buf.String()
+	buf.Reset()
+	_ = badgeTemplate.Execute(&buf, l)
+	return buf.Bytes()
+}
+
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s) * charWidth
+}
+
+// icons — значки 16x16 по коду состояния; цвета подставляются из темы
+var icons = map[model.ConditionCode]string{
+	model.ConditionClear: `<circle cx="8" cy="8" r="4" fill="{{.Icon}}"/>` +
+		`<g stroke="{{.Icon}}" stroke-width="1.5" stroke-linecap="round">` +
+		`<path d="M8 1v1.5M8 13.5V15M1 8h1.5M13.5 8H15M3 3l1 1M12 12l1 1M3 13l1-1M12 4l1-1"/></g>`,
+	model.ConditionPartlyCloudy: `<circle cx="6" cy="6" r="3.5" fill="{{.Icon}}"/>` + cloud,
+	model.ConditionCloudy:       cloud,
+	model.ConditionRain: cloud +
+		`<g stroke="{{.Accent}}" stroke-width="1.5" stroke-linecap="round"><path d="M5 14l-1 1.5M8 14l-1 1.5M11 14l-1 1.5"/></g>`,
+	model.ConditionSnow: cloud +
+		`<g fill="{{.Accent}}"><circle cx="5" cy="14.5" r="1"/><circle cx="8" cy="15" r="1"/><circle cx="11" cy="14.5" r="1"/></g>`,
+	model.ConditionSleet: cloud +
+		`<g fill="{{.Accent}}" stroke="{{.Accent}}" stroke-width="1.5" stroke-linecap="round"><path d="M5 14l-1 1.5M11 14l-1 1.5"/><circle cx="8" cy="15" r="1"/></g>`,
+	model.ConditionStorm: cloud + `<path d="M8.5 11.5L6.5 15h2l-1 2.5 3-4h-2l1-2z" fill="{{.Accent}}"/>`,
+	model.ConditionFog: `<g stroke="{{.Icon}}" stroke-width="1.5" stroke-linecap="round">` +
+		`<path d="M2 5h12M1 8h14M2 11h12M4 14h8"/></g>`,
+	model.ConditionUnknown: `<circle cx="8" cy="8" r="6" fill="none" stroke="{{.Icon}}" stroke-width="1.5"/>` +
+		`<text x="8" y="11.5" font-size="9" text-anchor="middle" fill="{{.Icon}}">?</text>`,
+}
+
+const cloud = `<path d="M4.5 13a3 3 0 0 1-.4-6A4 4 0 0 1 11.8 6.2 3.4 3.4 0 0 1 12 13z" fill="{{.Icon}}" fill-opacity=".9"/>`
+
+var iconTemplates = func() map[model.ConditionCode]*template.Template {
+	out := make(map[model.ConditionCode]*template.Template, len(icons))
+	for code, src := range icons {
+		out[code] = template.Must(template.New(string(code)).Parse(src))
+	}
+	return out
+}()
+
+var badgeTemplate = template.Must(template.New("badge").Funcs(template.FuncMap{
+	"xml": escape,
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.City | xml}}: {{.Value | xml}}">
+<title>{{.City | xml}}: {{.Value | xml}}{{if .Title}}, {{.Title | xml}}{{end}}</title>
+<clipPath id="r"><rect width="{{.Width}}" height="20" rx="3"/></clipPath>
+<g clip-path="url(#r)">
+<rect width="{{.LabelWidth}}" height="20" fill="{{.Label}}"/>
+<rect x="{{.LabelWidth}}" width="{{.ValueWidth}}" height="20" fill="{{.Theme.Value}}"/>
+</g>
+<g fill="{{.Text}}" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{.LabelText}}" y="15" fill="{{.TextShadow}}" fill-opacity=".3">{{.City | xml}}</text>
+<text x="{{.LabelText}}" y="14">{{.City | xml}}</text>
+<text x="{{.ValueText}}" y="15" fill="{{.TextShadow}}" fill-opacity=".3">{{.Value | xml}}</text>
+<text x="{{.ValueText}}" y="14">{{.Value | xml}}</text>
+</g>
+<g transform="translate({{.IconOffsetX}} 2)">{{.Icon}}</g>
+</svg>
+`))
+
+// escape экранирует текст для вставки в SVG
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
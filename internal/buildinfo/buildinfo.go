@@ -0,0 +1,65 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Заполняются при сборке:
+//
+//	go build -ldflags "-X github.com/gometeo/app/internal/buildinfo.Version=v1.2.3 \
+//	  -X github.com/gometeo/app/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/gometeo/app/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info описывает сборку запущенного бинарника
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает данные сборки; если ldflags не заданы, коммит и время берутся из VCS-меток Go
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = s.Value
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
+
+// LogArgs возвращает поля для строки лога при старте сервиса
+func LogArgs() []any {
+	info := Get()
+	return []any{
+		"version", info.Version,
+		"commit", info.Commit,
+		"build_time", info.BuildTime,
+		"go_version", info.GoVersion,
+	}
+}
@@ -0,0 +1,100 @@
+package bus
+
+import (
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// Sent — результат отправки: партиция и смещение или ошибка
+type Sent func(partition int32, offset int64, err error)
+
+// AsyncPublisher ставит сообщение в очередь отправки и не ждет подтверждения:
+// результат приходит в done из другой горутины. Close отправляет все
+// сообщения из очереди и дожидается вызова их done.
+type AsyncPublisher interface {
+	Publish(msg *sarama.ProducerMessage, done Sent)
+	Close() error
+}
+
+// Async — AsyncPublisher поверх sarama.AsyncProducer: сообщения копятся
+// в пачки по Producer.Flush, каналы подтверждений и ошибок читаются здесь же
+type Async struct {
+	producer sarama.AsyncProducer
+
+	mu      sync.RWMutex // Publish — чтение, Close — запись: в закрытый Input писать нельзя
+	closed  bool
+	drained sync.WaitGroup
+}
+
+// NewAsync начинает читать подтверждения продюсера. В его настройках должен
+// быть включен Producer.Return.Successes, иначе done успешных сообщений
+// не вызывается.
+func NewAsync(producer sarama.AsyncProducer) *Async {
+	a := &Async{producer: producer}
+	a.drained.Add(2)
+	go func() {
+		defer a.drained.Done()
+		for msg := range producer.Successes() {
+			if done, ok := msg.Metadata.(Sent); ok {
+				done(msg.Partition, msg.Offset, nil)
+			}
+		}
+	}()
+	go func() {
+		defer a.drained.Done()
+		for perr := range producer.Errors() {
+			if done, ok := perr.Msg.Metadata.(Sent); ok {
+				done(0, 0, perr.Err)
+			}
+		}
+	}()
+	return a
+}
+
+// Publish ставит сообщение в очередь продюсера; блокируется, только если
+// очередь заполнена
+func (a *Async) Publish(msg *sarama.ProducerMessage, done Sent) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		done(0, 0, ErrClosed)
+		return
+	}
+	msg.Metadata = done
+	a.producer.Input() <- msg
+}
+
+// Close отправляет накопленные пачки и ждет подтверждения всех сообщений
+func (a *Async) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	// Close продюсера ждет отправки пачек и закрывает каналы, после чего
+	// горутины чтения вызывают done последних сообщений
+	err := a.producer.Close()
+	a.drained.Wait()
+	return err
+}
+
+// Immediate приводит синхронный Publisher к AsyncPublisher: сообщение
+// отправляется сразу, done вызывается до возврата из Publish. Для шины
+// в памяти и тестов.
+func Immediate(p Publisher) AsyncPublisher {
+	return immediate{p}
+}
+
+type immediate struct {
+	Publisher
+}
+
+func (i immediate) Publish(msg *sarama.ProducerMessage, done Sent) {
+	done(i.SendMessage(msg))
+}
+
+var _ AsyncPublisher = (*Async)(nil)
@@ -0,0 +1,30 @@
+// Package bus описывает транспорт сообщений между сервисами конвейера.
+// В рабочей конфигурации это Kafka (sarama.SyncProducer и sarama.ConsumerGroup
+// подходят под интерфейсы без обертки, sarama.AsyncProducer оборачивает Async),
+// в монолите — очередь в памяти процесса.
+package bus
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+)
+
+//go:generate go tool mockgen -destination=../testutil/mocks/bus.go -package=mocks . Publisher
+
+// Publisher отправляет сообщение и ждет подтверждения
+type Publisher interface {
+	SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error)
+	Close() error
+}
+
+// Consumer читает топики группой и передает сообщения обработчику до отмены ctx
+type Consumer interface {
+	Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error
+	Close() error
+}
+
+var (
+	_ Publisher = sarama.SyncProducer(nil)
+	_ Consumer  = sarama.ConsumerGroup(nil)
+)
@@ -0,0 +1,196 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// ErrQueueFull — очередь топика заполнена: потребитель не успевает или его нет
+var ErrQueueFull = errors.New("очередь сообщений в памяти заполнена")
+
+// ErrClosed — шина закрыта
+var ErrClosed = errors.New("шина сообщений закрыта")
+
+// Memory — шина в памяти процесса для монолита. У топика одна очередь:
+// несколько потребителей делят сообщения, как участники одной группы Kafka.
+// Сообщения не переживают перезапуск, фиксация смещений ничего не делает.
+type Memory struct {
+	buffer int
+
+	mu     sync.Mutex
+	topics map[string]*memTopic
+	closed bool
+}
+
+type memTopic struct {
+	queue  chan *sarama.ConsumerMessage
+	offset int64
+}
+
+// NewMemory создает шину; buffer — емкость очереди каждого топика
+func NewMemory(buffer int) *Memory {
+	return &Memory{buffer: buffer, topics: make(map[string]*memTopic)}
+}
+
+func (m *Memory) topic(name string) (*memTopic, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, ErrClosed
+	}
+	t, ok := m.topics[name]
+	if !ok {
+		t = &memTopic{queue: make(chan *sarama.ConsumerMessage, m.buffer)}
+		m.topics[name] = t
+	}
+	return t, nil
+}
+
+// SendMessage ставит сообщение в очередь топика. Очередь не блокирует
+// отправителя: при заполнении возвращается ErrQueueFull.
+func (m *Memory) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	t, err := m.topic(msg.Topic)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	consumed, err := toConsumerMessage(msg)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	m.mu.Lock()
+	consumed.Offset = t.offset
+	t.offset++
+	m.mu.Unlock()
+
+	select {
+	case t.queue <- consumed:
+		return 0, consumed.Offset, nil
+	default:
+		return 0, 0, fmt.Errorf("%s: %w", msg.Topic, ErrQueueFull)
+	}
+}
+
+// Consume передает сообщения топиков обработчику до отмены ctx
+func (m *Memory) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	sess := &memSession{ctx: ctx, claims: make(map[string][]int32, len(topics))}
+	queues := make(map[string]*memTopic, len(topics))
+	for _, name := range topics {
+		t, err := m.topic(name)
+		if err != nil {
+			return err
+		}
+		queues[name] = t
+		sess.claims[name] = []int32{0}
+	}
+
+	if err := handler.Setup(sess); err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for name, t := range queues {
+		claim := &memClaim{topic: name, messages: make(chan *sarama.ConsumerMessage)}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			defer close(claim.messages)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-t.queue:
+					select {
+					case claim.messages <- msg:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := handler.ConsumeClaim(sess, claim); err != nil {
+				errMu.Lock()
+				firstErr = errors.Join(firstErr, err)
+				errMu.Unlock()
+			}
+			// Обработчик мог выйти раньше: дочитываем канал, чтобы не блокировать пересылку
+			for range claim.messages {
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(firstErr, handler.Cleanup(sess))
+}
+
+// Close закрывает шину: новые отправки и чтения возвращают ErrClosed
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func toConsumerMessage(msg *sarama.ProducerMessage) (*sarama.ConsumerMessage, error) {
+	out := &sarama.ConsumerMessage{
+		Topic:     msg.Topic,
+		Timestamp: time.Now(),
+	}
+	if msg.Key != nil {
+		key, err := msg.Key.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка кодирования ключа: %w", err)
+		}
+		out.Key = key
+	}
+	if msg.Value != nil {
+		value, err := msg.Value.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка кодирования сообщения: %w", err)
+		}
+		out.Value = value
+	}
+	for i := range msg.Headers {
+		h := msg.Headers[i]
+		out.Headers = append(out.Headers, &h)
+	}
+	return out, nil
+}
+
+// memSession — сессия группы в памяти: смещения не хранятся
+type memSession struct {
+	ctx    context.Context
+	claims map[string][]int32
+}
+
+func (s *memSession) Claims() map[string][]int32                  { return s.claims }
+func (s *memSession) MemberID() string                            { return "memory" }
+func (s *memSession) GenerationID() int32                         { return 0 }
+func (s *memSession) MarkOffset(string, int32, int64, string)     {}
+func (s *memSession) Commit()                                     {}
+func (s *memSession) ResetOffset(string, int32, int64, string)    {}
+func (s *memSession) MarkMessage(*sarama.ConsumerMessage, string) {}
+func (s *memSession) Context() context.Context                    { return s.ctx }
+
+type memClaim struct {
+	topic    string
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *memClaim) Topic() string                            { return c.topic }
+func (c *memClaim) Partition() int32                         { return 0 }
+func (c *memClaim) InitialOffset() int64                     { return 0 }
+func (c *memClaim) HighWaterMarkOffset() int64               { return 0 }
+func (c *memClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
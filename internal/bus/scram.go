@@ -0,0 +1,134 @@
+package bus
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// scramClient — клиентская сторона SCRAM (RFC 5802) для sarama
+type scramClient struct {
+	hash func() hash.Hash
+
+	user     string
+	password string
+	authzID  string
+	nonce    string
+	step     int
+	// clientFirstBare и serverSignature нужны для проверки ответа сервера
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func (c *scramClient) Begin(user, password, authzID string) error {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("ошибка генерации nonce SCRAM: %w", err)
+	}
+	c.user, c.password, c.authzID = user, password, authzID
+	c.nonce = base64.RawStdEncoding.EncodeToString(nonce)
+	c.step = 0
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	c.step++
+	switch c.step {
+	case 1:
+		c.clientFirstBare = "n=" + scramName(c.user) + ",r=" + c.nonce
+		return c.gs2Header() + c.clientFirstBare, nil
+	case 2:
+		return c.clientFinal(challenge)
+	case 3:
+		return "", c.verifyServer(challenge)
+	}
+	return "", errors.New("SCRAM: лишний шаг обмена")
+}
+
+func (c *scramClient) Done() bool {
+	return c.step >= 3
+}
+
+func (c *scramClient) gs2Header() string {
+	if c.authzID == "" {
+		return "n,,"
+	}
+	return "n,a=" + scramName(c.authzID) + ","
+}
+
+// clientFinal отвечает на server-first доказательством знания пароля
+func (c *scramClient) clientFinal(serverFirst string) (string, error) {
+	attrs := scramAttrs(serverFirst)
+	nonce, salt64, iterations := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, c.nonce) || len(nonce) == len(c.nonce) {
+		return "", errors.New("SCRAM: сервер вернул чужой nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return "", fmt.Errorf("SCRAM: неверная соль: %w", err)
+	}
+	iter, err := strconv.Atoi(iterations)
+	if err != nil || iter <= 0 {
+		return "", fmt.Errorf("SCRAM: неверное число итераций: %s", iterations)
+	}
+
+	salted, err := pbkdf2.Key(c.hash, c.password, salt, iter, c.hash().Size())
+	if err != nil {
+		return "", fmt.Errorf("SCRAM: %w", err)
+	}
+	clientKey := c.hmac(salted, "Client Key")
+	h := c.hash()
+	h.Write(clientKey)
+	storedKey := h.Sum(nil)
+
+	withoutProof := "c=" + base64.StdEncoding.EncodeToString([]byte(c.gs2Header())) + ",r=" + nonce
+	authMessage := c.clientFirstBare + "," + serverFirst + "," + withoutProof
+
+	proof := c.hmac(storedKey, authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	c.serverSignature = c.hmac(c.hmac(salted, "Server Key"), authMessage)
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// verifyServer проверяет подпись сервера: брокер тоже знает пароль
+func (c *scramClient) verifyServer(serverFinal string) error {
+	attrs := scramAttrs(serverFinal)
+	if e, ok := attrs["e"]; ok {
+		return fmt.Errorf("SCRAM: сервер отклонил аутентификацию: %s", e)
+	}
+	signature, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(signature, c.serverSignature) {
+		return errors.New("SCRAM: неверная подпись сервера")
+	}
+	return nil
+}
+
+func (c *scramClient) hmac(key []byte, message string) []byte {
+	mac := hmac.New(c.hash, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// scramAttrs разбирает сообщение "r=...,s=...,i=..."
+func scramAttrs(message string) map[string]string {
+	attrs := make(map[string]string)
+	for _, part := range strings.Split(message, ",") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			attrs[key] = value
+		}
+	}
+	return attrs
+}
+
+// scramName экранирует имя по RFC 5802
+func scramName(name string) string {
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(name)
+}
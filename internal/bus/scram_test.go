@@ -0,0 +1,86 @@
+package bus
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+	"testing"
+)
+
+// Обмен из примеров RFC 5802 (SHA-1) и RFC 7677 (SHA-256)
+func TestSCRAMKnownAnswer(t *testing.T) {
+	tests := []struct {
+		name        string
+		hash        func() hash.Hash
+		nonce       string
+		serverFirst string
+		clientFinal string
+		serverFinal string
+	}{
+		{
+			name:        "RFC 5802 SHA-1",
+			hash:        sha1.New,
+			nonce:       "fyko+d2lbbFgONRv9qkxdawL",
+			serverFirst: "r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096",
+			clientFinal: "c=biws,r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,p=v0X8v3Bz2T0CJGbJQyF0X+HI4Ts=",
+			serverFinal: "v=rmF9pqV8S7suAoZWja4dJRkFsKQ=",
+		},
+		{
+			name:        "RFC 7677 SHA-256",
+			hash:        sha256.New,
+			nonce:       "rOprNGfwEbeRWgbNEkqO",
+			serverFirst: "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096",
+			clientFinal: "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=",
+			serverFinal: "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &scramClient{hash: tt.hash}
+			if err := c.Begin("user", "pencil", ""); err != nil {
+				t.Fatalf("Begin: %v", err)
+			}
+			// Nonce из примера вместо случайного
+			c.nonce = tt.nonce
+
+			first, err := c.Step("")
+			if err != nil {
+				t.Fatalf("client-first: %v", err)
+			}
+			if want := "n,,n=user,r=" + tt.nonce; first != want {
+				t.Errorf("client-first = %q, ожидалось %q", first, want)
+			}
+
+			final, err := c.Step(tt.serverFirst)
+			if err != nil {
+				t.Fatalf("client-final: %v", err)
+			}
+			if final != tt.clientFinal {
+				t.Errorf("client-final = %q, ожидалось %q", final, tt.clientFinal)
+			}
+
+			if _, err := c.Step(tt.serverFinal); err != nil {
+				t.Errorf("подпись сервера не принята: %v", err)
+			}
+			if !c.Done() {
+				t.Error("обмен не завершен после server-final")
+			}
+		})
+	}
+}
+
+func TestSCRAMRejectsWrongServerSignature(t *testing.T) {
+	c := &scramClient{hash: sha256.New}
+	if err := c.Begin("user", "pencil", ""); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	c.nonce = "rOprNGfwEbeRWgbNEkqO"
+	c.Step("")
+	if _, err := c.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"); err != nil {
+		t.Fatalf("client-final: %v", err)
+	}
+	if _, err := c.Step("v=rmF9pqV8S7suAoZWja4dJRkFsKQ="); err == nil {
+		t.Error("чужая подпись сервера принята")
+	}
+}
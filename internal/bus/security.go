@@ -0,0 +1,84 @@
+package bus
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/config"
+)
+
+// Secure включает TLS и SASL для подключения к управляемой Kafka (MSK,
+// Confluent Cloud). Без настроек KAFKA_TLS_* и KAFKA_SASL_* ничего не меняет.
+func Secure(kafka *sarama.Config, cfg *config.Config) error {
+	if cfg.KafkaTLSEnabled || cfg.KafkaTLSCAFile != "" || cfg.KafkaTLSCertFile != "" {
+		tlsConfig, err := kafkaTLS(cfg)
+		if err != nil {
+			return err
+		}
+		kafka.Net.TLS.Enable = true
+		kafka.Net.TLS.Config = tlsConfig
+	}
+
+	if cfg.KafkaSASLMechanism == "" {
+		return nil
+	}
+	if cfg.KafkaSASLUser == "" {
+		return fmt.Errorf("для SASL нужен KAFKA_SASL_USER")
+	}
+	kafka.Net.SASL.Enable = true
+	kafka.Net.SASL.User = cfg.KafkaSASLUser
+	kafka.Net.SASL.Password = cfg.KafkaSASLPassword
+	switch mechanism := strings.ToUpper(cfg.KafkaSASLMechanism); mechanism {
+	case sarama.SASLTypePlaintext:
+		kafka.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case sarama.SASLTypeSCRAMSHA256:
+		kafka.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		kafka.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: sha256.New} }
+	case sarama.SASLTypeSCRAMSHA512:
+		kafka.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		kafka.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: sha512.New} }
+	default:
+		return fmt.Errorf("неизвестный механизм SASL: %s (PLAIN, SCRAM-SHA-256 или SCRAM-SHA-512)", cfg.KafkaSASLMechanism)
+	}
+	if kafka.Net.SASL.Mechanism == sarama.SASLTypePlaintext && !kafka.Net.TLS.Enable {
+		// PLAIN передает пароль открытым текстом: без TLS только для отладки
+		return fmt.Errorf("SASL PLAIN без TLS передает пароль открытым текстом, включите KAFKA_TLS_ENABLED")
+	}
+	return nil
+}
+
+// kafkaTLS собирает настройки TLS: свой CA для самоподписанных брокеров и
+// сертификат клиента для mTLS
+func kafkaTLS(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.KafkaTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.KafkaTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения CA Kafka: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("в %s нет сертификатов PEM", cfg.KafkaTLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.KafkaTLSCertFile != "" || cfg.KafkaTLSKeyFile != "" {
+		if cfg.KafkaTLSCertFile == "" || cfg.KafkaTLSKeyFile == "" {
+			return nil, fmt.Errorf("для сертификата клиента Kafka нужны и KAFKA_TLS_CERT_FILE, и KAFKA_TLS_KEY_FILE")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.KafkaTLSCertFile, cfg.KafkaTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения сертификата клиента Kafka: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// SetAirQuality сохраняет замер качества воздуха со своим TTL
+func (c *WeatherCache) SetAirQuality(ctx context.Context, key string, data model.AirQuality, ttl time.Duration) error {
+	if err := c.faults.Inject(ctx, "cache.SetAirQuality"); err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	if err := c.client.Set(ctx, key, bytes, ttl).Err(); err != nil {
+		return fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return nil
+}
+
+// GetAirQuality возвращает замер качества воздуха, nil при промахе
+func (c *WeatherCache) GetAirQuality(ctx context.Context, key string) (*model.AirQuality, error) {
+	if err := c.faults.Inject(ctx, "cache.GetAirQuality"); err != nil {
+		return nil, err
+	}
+
+	val, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		c.lookups.Add(ctx, 1, lookupMiss)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения из Redis: %w", err)
+	}
+	c.lookups.Add(ctx, 1, lookupHit)
+
+	var data model.AirQuality
+	if err := json.Unmarshal(val, &data); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации: %w", err)
+	}
+	return &data, nil
+}
+
+func AirKey(city string) string {
+	return "air:city:" + city
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SetBytes сохраняет двоичное значение как есть, без сериализации
+func (c *WeatherCache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	if err := c.faults.Inject(ctx, "cache.SetBytes"); err != nil {
+		return err
+	}
+
+	if err := c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return nil
+}
+
+// GetBytes возвращает двоичное значение, nil при промахе
+func (c *WeatherCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	if err := c.faults.Inject(ctx, "cache.GetBytes"); err != nil {
+		return nil, err
+	}
+
+	val, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		c.lookups.Add(ctx, 1, lookupMiss)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения из Redis: %w", err)
+	}
+	c.lookups.Add(ctx, 1, lookupHit)
+	return val, nil
+}
+
+// IncrCounter увеличивает счетчик окна и возвращает новое значение.
+// TTL ставится при создании ключа, чтобы окно не продлевалось запросами.
+func (c *WeatherCache) IncrCounter(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if err := c.faults.Inject(ctx, "cache.IncrCounter"); err != nil {
+		return 0, err
+	}
+
+	pipe := c.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// TileKey — ключ тайла layer/z/x/y
+func TileKey(tile string) string {
+	return "tile:" + tile
+}
+
+// TileQuotaKey — ключ суточного счетчика тайлов клиента, day — YYYY-MM-DD
+func TileQuotaKey(client, day string) string {
+	return "tiles:quota:" + client + ":" + day
+}
+
+// BadgeKey — ключ отрисованного бейджа города в теме, единицах и языке
+func BadgeKey(city, theme, units, lang string) string {
+	return "badge:" + city + ":" + theme + ":" + units + ":" + lang
+}
+
+// StatsKey — ключ сводки температуры города за период (24h, 7d, 30d)
+func StatsKey(city, period string) string {
+	return "weather:stats:" + city + ":" + period
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// SetPlace кэширует результат геокодирования на ttl
+func (c *WeatherCache) SetPlace(ctx context.Context, key string, city model.City, ttl time.Duration) error {
+	if err := c.faults.Inject(ctx, "cache.SetPlace"); err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(city)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	if err := c.client.Set(ctx, key, bytes, ttl).Err(); err != nil {
+		return fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return nil
+}
+
+// GetPlace возвращает закэшированный город, nil если ключа нет
+func (c *WeatherCache) GetPlace(ctx context.Context, key string) (*model.City, error) {
+	if err := c.faults.Inject(ctx, "cache.GetPlace"); err != nil {
+		return nil, err
+	}
+
+	val, err := c.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения из Redis: %w", err)
+	}
+
+	var city model.City
+	if err := json.Unmarshal([]byte(val), &city); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации: %w", err)
+	}
+	return &city, nil
+}
+
+// GeocodeKey — ключ результата геокодирования для нормализованного запроса
+func GeocodeKey(query string) string {
+	return "geocode:" + strings.ToLower(strings.TrimSpace(query))
+}
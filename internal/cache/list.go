@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// SetCityList сохраняет список городов API v2 с TTL кэша
+func (c *WeatherCache) SetCityList(ctx context.Context, key string, cities []model.CityEntry) error {
+	if err := c.faults.Inject(ctx, "cache.SetCityList"); err != nil {
+		return err
+	}
+
+	if cities == nil {
+		cities = []model.CityEntry{}
+	}
+	bytes, err := json.Marshal(cities)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	if err := c.client.Set(ctx, key, bytes, c.ttl).Err(); err != nil {
+		return fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return nil
+}
+
+// GetCityList возвращает список городов API v2, nil при промахе. Пустой
+// сохраненный список — не промах.
+func (c *WeatherCache) GetCityList(ctx context.Context, key string) ([]model.CityEntry, error) {
+	if err := c.faults.Inject(ctx, "cache.GetCityList"); err != nil {
+		return nil, err
+	}
+
+	val, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		c.lookups.Add(ctx, 1, lookupMiss)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения из Redis: %w", err)
+	}
+	c.lookups.Add(ctx, 1, lookupHit)
+
+	cities := []model.CityEntry{}
+	if err := json.Unmarshal(val, &cities); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации: %w", err)
+	}
+	return cities, nil
+}
+
+// CityListKey — ключ списка городов API v2; v1 хранит свой под AllCitiesKey
+func CityListKey() string {
+	return "weather:cities:list"
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// SetMaintenance включает режим обслуживания для всех экземпляров API;
+// ttl > 0 — режим снимется сам
+func (c *WeatherCache) SetMaintenance(ctx context.Context, m model.Maintenance, ttl time.Duration) error {
+	if err := c.faults.Inject(ctx, "cache.SetMaintenance"); err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	if err := c.client.Set(ctx, MaintenanceKey(), bytes, max(ttl, 0)).Err(); err != nil {
+		return fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return nil
+}
+
+// GetMaintenance возвращает режим обслуживания, nil — режим выключен
+func (c *WeatherCache) GetMaintenance(ctx context.Context) (*model.Maintenance, error) {
+	if err := c.faults.Inject(ctx, "cache.GetMaintenance"); err != nil {
+		return nil, err
+	}
+
+	val, err := c.client.Get(ctx, MaintenanceKey()).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения из Redis: %w", err)
+	}
+
+	var m model.Maintenance
+	if err := json.Unmarshal(val, &m); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации: %w", err)
+	}
+	return &m, nil
+}
+
+// MaintenanceKey — ключ флага режима обслуживания; выключение — Delete по нему
+func MaintenanceKey() string {
+	return "api:maintenance"
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// NewInMemory поднимает Redis в памяти процесса и подключает к нему кэш.
+// Для режима разработки: данные теряются при остановке, Close гасит сервер.
+func NewInMemory(ttl time.Duration, logger *slog.Logger) (*WeatherCache, error) {
+	server, err := miniredis.Run()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось запустить Redis в памяти: %w", err)
+	}
+
+	c, err := New(server.Addr(), "", 0, ttl, logger)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	c.embedded = server
+	return c, nil
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Bucket — состояние корзины токенов после попытки взять токен
+type Bucket struct {
+	Allowed    bool
+	Remaining  int64
+	RetryAfter time.Duration // через сколько появится токен; 0, если запрос пропущен
+	ResetAfter time.Duration // через сколько корзина наполнится полностью
+}
+
+// TakeToken берет токен из корзины key, которая пополняется rate токенами
+// в секунду до burst. Состояние хранится в Redis, поэтому лимит общий для всех
+// экземпляров API.
+func (c *WeatherCache) TakeToken(ctx context.Context, key string, rate float64, burst int64, now time.Time) (Bucket, error) {
+	if err := c.faults.Inject(ctx, "cache.TakeToken"); err != nil {
+		return Bucket{}, err
+	}
+
+	res, err := tokenBucketScript.Run(ctx, c.client, []string{key}, rate, burst, now.UnixMilli()).Int64Slice()
+	if err != nil {
+		return Bucket{}, fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return Bucket{
+		Allowed:    res[0] == 1,
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
+
+// Время берется у вызывающего, а не из Redis TIME: встроенный Redis его не отдает
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) / 1000 * rate)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(math.max(now, ts)))
+local reset = math.ceil((burst - tokens) / rate * 1000)
+redis.call("PEXPIRE", KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// RateLimitKey — ключ корзины токенов клиента: аккаунта, пользователя или адреса
+func RateLimitKey(client string) string {
+	return "ratelimit:" + client
+}
@@ -0,0 +1,63 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/testutil"
+)
+
+func TestTakeTokenRefillsAtRate(t *testing.T) {
+	c, err := cache.NewInMemory(time.Minute, testutil.Logger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Время передается явно: корзина 3 токена, пополнение 2 в секунду
+	start := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	key := cache.RateLimitKey("addr:203.0.113.7")
+	steps := []struct {
+		name string
+		at   time.Duration
+		want cache.Bucket
+	}{
+		{"полная корзина", 0, cache.Bucket{Allowed: true, Remaining: 2, ResetAfter: 500 * time.Millisecond}},
+		{"запас", 0, cache.Bucket{Allowed: true, Remaining: 1, ResetAfter: time.Second}},
+		{"последний токен", 0, cache.Bucket{Allowed: true, Remaining: 0, ResetAfter: 1500 * time.Millisecond}},
+		{"корзина пуста", 0, cache.Bucket{Remaining: 0, RetryAfter: 500 * time.Millisecond, ResetAfter: 1500 * time.Millisecond}},
+		{"полтокена", 250 * time.Millisecond, cache.Bucket{Remaining: 0, RetryAfter: 250 * time.Millisecond, ResetAfter: 1250 * time.Millisecond}},
+		{"токен пополнился", 500 * time.Millisecond, cache.Bucket{Allowed: true, Remaining: 0, ResetAfter: 1500 * time.Millisecond}},
+		{"часы назад не пополняют", 100 * time.Millisecond, cache.Bucket{Remaining: 0, RetryAfter: 500 * time.Millisecond, ResetAfter: 1500 * time.Millisecond}},
+		{"не больше емкости", 10 * time.Second, cache.Bucket{Allowed: true, Remaining: 2, ResetAfter: 500 * time.Millisecond}},
+	}
+	for _, step := range steps {
+		got, err := c.TakeToken(context.Background(), key, 2, 3, start.Add(step.at))
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: %+v, ожидалось %+v", step.name, got, step.want)
+		}
+	}
+
+	// Корзины клиентов независимы
+	other, err := c.TakeToken(context.Background(), cache.RateLimitKey("addr:198.51.100.1"), 2, 3, start)
+	if err != nil || !other.Allowed || other.Remaining != 2 {
+		t.Errorf("корзина другого клиента: %+v (%v)", other, err)
+	}
+}
+
+func TestTakeTokenRedisDown(t *testing.T) {
+	c, err := cache.NewInMemory(time.Minute, testutil.Logger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	if _, err := c.TakeToken(context.Background(), cache.RateLimitKey("addr:203.0.113.7"), 2, 3, time.Now()); err == nil {
+		t.Error("токен выдан без Redis")
+	}
+}
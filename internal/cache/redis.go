@@ -5,16 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/metrics"
 	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/tracing"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	lookupHit  = metric.WithAttributes(attribute.String("result", "hit"))
+	lookupMiss = metric.WithAttributes(attribute.String("result", "miss"))
 )
 
 type WeatherCache struct {
 	client *redis.Client
 	ttl    time.Duration
 	logger *slog.Logger
+	faults *chaos.Injector // nil вне режима внедрения сбоев
+
+	embedded *miniredis.Miniredis // Redis в памяти процесса, см. NewInMemory
+
+	lookups metric.Int64Counter // попадания и промахи Get
 }
 
 func New(addr, password string, db int, ttl time.Duration, logger *slog.Logger) (*WeatherCache, error) {
@@ -23,6 +40,7 @@ func New(addr, password string, db int, ttl time.Duration, logger *slog.Logger)
 		Password: password,
 		DB:       db,
 	})
+	client.AddHook(tracing.RedisHook{})
 
 	// Проверка подключения
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -34,18 +52,76 @@ func New(addr, password string, db int, ttl time.Duration, logger *slog.Logger)
 
 	logger.Info("Успешное подключение к Redis", "addr", addr)
 
+	lookups, _ := metrics.Meter("github.com/gometeo/app/internal/cache").Int64Counter(
+		"cache.lookups",
+		metric.WithDescription("Количество чтений кэша по результату (hit/miss)"))
+
 	return &WeatherCache{
-		client: client,
-		ttl:    ttl,
-		logger: logger,
+		client:  client,
+		ttl:     ttl,
+		logger:  logger,
+		lookups: lookups,
 	}, nil
 }
 
+// SetFaultInjector включает внедрение сбоев для проверки отказоустойчивости
+func (c *WeatherCache) SetFaultInjector(faults *chaos.Injector) {
+	c.faults = faults
+}
+
+// Ping проверяет доступность Redis
+func (c *WeatherCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// memoryFields — поля INFO memory, которые попадают в health-отчет
+var memoryFields = []string{
+	"used_memory_human",
+	"used_memory_peak_human",
+	"maxmemory_human",
+	"maxmemory_policy",
+	"mem_fragmentation_ratio",
+}
+
+// MemoryInfo проверяет доступность Redis и возвращает сведения о памяти.
+// Встроенный Redis раздел memory не поддерживает — тогда сведений нет.
+func (c *WeatherCache) MemoryInfo(ctx context.Context) (map[string]string, error) {
+	if err := c.Ping(ctx); err != nil {
+		return nil, err
+	}
+	info, err := c.client.Info(ctx, "memory").Result()
+	if err != nil {
+		return nil, nil
+	}
+
+	values := make(map[string]string)
+	for _, line := range strings.Split(info, "\r\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			values[key] = value
+		}
+	}
+	details := make(map[string]string, len(memoryFields))
+	for _, field := range memoryFields {
+		if v, ok := values[field]; ok {
+			details[field] = v
+		}
+	}
+	return details, nil
+}
+
 func (c *WeatherCache) Close() error {
-	return c.client.Close()
+	err := c.client.Close()
+	if c.embedded != nil {
+		c.embedded.Close()
+	}
+	return err
 }
 
 func (c *WeatherCache) Set(ctx context.Context, key string, data model.WeatherData) error {
+	if err := c.faults.Inject(ctx, "cache.Set"); err != nil {
+		return err
+	}
+
 	bytes, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("ошибка сериализации: %w", err)
@@ -56,39 +132,109 @@ func (c *WeatherCache) Set(ctx context.Context, key string, data model.WeatherDa
 		return fmt.Errorf("ошибка записи в Redis: %w", err)
 	}
 
-	c.logger.Debug("Данные сохранены в кэш", "key", key, "ttl", c.ttl)
+	c.logger.DebugContext(ctx, "Данные сохранены в кэш", "key", key, "ttl", c.ttl)
 	return nil
 }
 
 func (c *WeatherCache) Get(ctx context.Context, key string) (*model.WeatherData, error) {
+	if err := c.faults.Inject(ctx, "cache.Get"); err != nil {
+		return nil, err
+	}
+
 	val, err := c.client.Get(ctx, key).Result()
 	if err == redis.Nil {
+		c.lookups.Add(ctx, 1, lookupMiss)
 		return nil, nil // Ключ не найден - это не ошибка
 	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка чтения из Redis: %w", err)
 	}
+	c.lookups.Add(ctx, 1, lookupHit)
 
 	var data model.WeatherData
 	if err := json.Unmarshal([]byte(val), &data); err != nil {
 		return nil, fmt.Errorf("ошибка десериализации: %w", err)
 	}
 
-	c.logger.Debug("Данные получены из кэша", "key", key)
+	c.logger.DebugContext(ctx, "Данные получены из кэша", "key", key)
 	return &data, nil
 }
 
+// GetMany читает несколько ключей одним MGET. Результат в порядке keys,
+// nil на месте промаха или битого значения.
+func (c *WeatherCache) GetMany(ctx context.Context, keys []string) ([]*model.WeatherData, error) {
+	if err := c.faults.Inject(ctx, "cache.GetMany"); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	vals, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения из Redis: %w", err)
+	}
+
+	out := make([]*model.WeatherData, len(keys))
+	for i, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			c.lookups.Add(ctx, 1, lookupMiss)
+			continue
+		}
+		var data model.WeatherData
+		if err := json.Unmarshal([]byte(s), &data); err != nil {
+			c.logger.WarnContext(ctx, "Битое значение в кэше", "key", keys[i], "error", err)
+			c.lookups.Add(ctx, 1, lookupMiss)
+			continue
+		}
+		c.lookups.Add(ctx, 1, lookupHit)
+		out[i] = &data
+	}
+	return out, nil
+}
+
 func (c *WeatherCache) Delete(ctx context.Context, key string) error {
+	if err := c.faults.Inject(ctx, "cache.Delete"); err != nil {
+		return err
+	}
+
 	err := c.client.Del(ctx, key).Err()
 	if err != nil {
 		return fmt.Errorf("ошибка удаления из Redis: %w", err)
 	}
 
-	c.logger.Debug("Данные удалены из кэша", "key", key)
+	c.logger.DebugContext(ctx, "Данные удалены из кэша", "key", key)
 	return nil
 }
 
+// DeletePattern удаляет ключи по шаблону вида "weather:*" и возвращает их число
+func (c *WeatherCache) DeletePattern(ctx context.Context, pattern string) (int, error) {
+	if err := c.faults.Inject(ctx, "cache.DeletePattern"); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		if err := c.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return deleted, fmt.Errorf("ошибка удаления из Redis: %w", err)
+		}
+		deleted++
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("ошибка обхода ключей Redis: %w", err)
+	}
+
+	c.logger.DebugContext(ctx, "Ключи удалены из кэша", "pattern", pattern, "count", deleted)
+	return deleted, nil
+}
+
 func (c *WeatherCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := c.faults.Inject(ctx, "cache.Exists"); err != nil {
+		return false, err
+	}
+
 	exists, err := c.client.Exists(ctx, key).Result()
 	if err != nil {
 		return false, fmt.Errorf("ошибка проверки ключа: %w", err)
@@ -96,6 +242,20 @@ func (c *WeatherCache) Exists(ctx context.Context, key string) (bool, error) {
 	return exists > 0, nil
 }
 
+// TTL возвращает оставшееся время жизни ключа; 0 — ключа нет или срок не задан
+func (c *WeatherCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := c.faults.Inject(ctx, "cache.TTL"); err != nil {
+		return 0, err
+	}
+
+	ttl, err := c.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения срока жизни ключа: %w", err)
+	}
+	// Redis отвечает -2 для отсутствующего ключа и -1 для ключа без срока
+	return max(ttl, 0), nil
+}
+
 // Вспомогательные методы для генерации ключей
 func CityKey(city string) string {
 	return "weather:city:" + city
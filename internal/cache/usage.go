@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// usageTTL — счетчик месяца живет с запасом, чтобы успеть сохраниться в БД
+const usageTTL = 40 * 24 * time.Hour
+
+// IncrUsage увеличивает счетчик запросов и возвращает новое значение
+func (c *WeatherCache) IncrUsage(ctx context.Context, key string) (int64, error) {
+	if err := c.faults.Inject(ctx, "cache.IncrUsage"); err != nil {
+		return 0, err
+	}
+
+	pipe := c.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, usageTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// SeedUsage поднимает счетчик до сохраненного значения, если он меньше
+// (ключ пропал после перезапуска Redis). Возвращает итоговое значение.
+func (c *WeatherCache) SeedUsage(ctx context.Context, key string, persisted int64) (int64, error) {
+	if err := c.faults.Inject(ctx, "cache.SeedUsage"); err != nil {
+		return 0, err
+	}
+
+	// Скрипт атомарен: параллельные INCR не теряются
+	val, err := seedScript.Run(ctx, c.client, []string{key}, persisted, int64(usageTTL/time.Second)).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка записи в Redis: %w", err)
+	}
+	return val, nil
+}
+
+var seedScript = redis.NewScript(`
+local cur = tonumber(redis.call("GET", KEYS[1]) or "0")
+local want = tonumber(ARGV[1])
+if want > cur then
+	redis.call("INCRBY", KEYS[1], want - cur)
+	cur = want
+end
+redis.call("EXPIRE", KEYS[1], ARGV[2])
+return cur
+`)
+
+// GetUsage возвращает счетчик запросов, 0 если ключа нет
+func (c *WeatherCache) GetUsage(ctx context.Context, key string) (int64, error) {
+	if err := c.faults.Inject(ctx, "cache.GetUsage"); err != nil {
+		return 0, err
+	}
+
+	val, err := c.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения из Redis: %w", err)
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// UsageKey — ключ счетчика запросов аккаунта за месяц YYYY-MM
+func UsageKey(accountID int64, month string) string {
+	return "usage:" + strconv.FormatInt(accountID, 10) + ":" + month
+}
+
+// AnonymousKey — ключ счетчика анонимных запросов с адреса за минуту YYYYMMDDhhmm
+func AnonymousKey(client, minute string) string {
+	return "anon:" + client + ":" + minute
+}
@@ -0,0 +1,123 @@
+// Package cachepush обновляет кэш API сразу после записи агрегатором:
+// каждый экземпляр API читает события weather.upserted из общего топика
+// без consumer-группы, то есть получает все события, а не свою долю партиций.
+package cachepush
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/changefeed"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/gometeo/app/internal/cachepush")
+
+// Subscriber применяет события обновления к кэшу
+type Subscriber struct {
+	consumer sarama.Consumer
+	topic    string
+	cache    *cache.WeatherCache
+	feed     *changefeed.Hub // nil — ожидающих клиентов не уведомляем
+	logger   *slog.Logger
+}
+
+func NewSubscriber(consumer sarama.Consumer, topic string, c *cache.WeatherCache, logger *slog.Logger) *Subscriber {
+	return &Subscriber{consumer: consumer, topic: topic, cache: c, logger: logger}
+}
+
+// SetFeed включает уведомление клиентов, ожидающих обновления города
+func (s *Subscriber) SetFeed(feed *changefeed.Hub) {
+	s.feed = feed
+}
+
+// Run читает все партиции с текущего конца до отмены ctx. Пропущенные до
+// старта события не нужны: устаревшие ключи кэша истекут по TTL.
+func (s *Subscriber) Run(ctx context.Context) error {
+	partitions, err := s.consumer.Partitions(s.topic)
+	if err != nil {
+		return fmt.Errorf("ошибка получения партиций %s: %w", s.topic, err)
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range partitions {
+		pc, err := s.consumer.ConsumePartition(s.topic, p, sarama.OffsetNewest)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения партиции %d: %w", p, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer pc.Close()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg, ok := <-pc.Messages():
+					if !ok {
+						return
+					}
+					s.apply(ctx, msg)
+				case err, ok := <-pc.Errors():
+					if ok {
+						s.logger.WarnContext(ctx, "Ошибка чтения обновлений кэша", "partition", err.Partition, "error", err.Err)
+					}
+				}
+			}
+		}()
+	}
+
+	s.logger.InfoContext(ctx, "Подписка на обновления кэша запущена", "topic", s.topic, "partitions", len(partitions))
+	wg.Wait()
+	return nil
+}
+
+func (s *Subscriber) apply(ctx context.Context, msg *sarama.ConsumerMessage) {
+	ctx = tracing.ExtractKafka(ctx, msg)
+	ctx, span := tracer.Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.kafka.partition", int(msg.Partition)),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		))
+	defer span.End()
+
+	event, err := model.UnmarshalEvent(msg.Value)
+	if err != nil {
+		tracing.RecordError(span, err)
+		s.logger.ErrorContext(ctx, "Битый JSON", "offset", msg.Offset, "error", err)
+		return
+	}
+	if event.Type != model.EventWeatherUpserted {
+		return
+	}
+
+	var data model.WeatherData
+	if err := event.DecodePayload(&data); err != nil {
+		tracing.RecordError(span, err)
+		s.logger.ErrorContext(ctx, "Битый JSON", "offset", msg.Offset, "error", err)
+		return
+	}
+	span.SetAttributes(attribute.String("weather.city", data.City))
+
+	// Ожидающие клиенты получают данные из события, кэш им не нужен
+	if s.feed != nil {
+		s.feed.Publish(data)
+	}
+
+	if err := s.cache.Set(ctx, cache.CityKey(data.City), data); err != nil {
+		tracing.RecordError(span, err)
+		s.logger.WarnContext(ctx, "Не удалось обновить кэш", "city", data.City, "error", err)
+		return
+	}
+	s.logger.DebugContext(ctx, "Кэш обновлен по событию", "city", data.City)
+}
@@ -0,0 +1,75 @@
+// Package changefeed раздает обновления погоды ожидающим клиентам API
+// внутри процесса. Источник событий — подписка cachepush на топик обновлений.
+package changefeed
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// Hub рассылает обновления подписчикам города. Медленный подписчик
+// получает только последнее обновление: промежуточные отбрасываются.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan model.WeatherData]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[chan model.WeatherData]struct{})}
+}
+
+// Subscribe подписывает на обновления города. cancel обязателен: он
+// отписывает и освобождает канал.
+func (h *Hub) Subscribe(city string) (updates <-chan model.WeatherData, cancel func()) {
+	key := strings.ToLower(city)
+	ch := make(chan model.WeatherData, 1)
+
+	h.mu.Lock()
+	if h.subs[key] == nil {
+		h.subs[key] = make(map[chan model.WeatherData]struct{})
+	}
+	h.subs[key][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs[key], ch)
+		if len(h.subs[key]) == 0 {
+			delete(h.subs, key)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// Publish отправляет обновление подписчикам города без блокировки
+func (h *Hub) Publish(data model.WeatherData) {
+	key := strings.ToLower(data.City)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[key] {
+		select {
+		case ch <- data:
+		default:
+			// В буфере непрочитанное обновление: заменяем его свежим
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- data
+		}
+	}
+}
+
+// Subscribers возвращает число подписчиков по всем городам
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, subs := range h.subs {
+		n += len(subs)
+	}
+	return n
+}
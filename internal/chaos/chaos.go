@@ -0,0 +1,89 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+)
+
+// ErrInjected возвращается вместо реального вызова при внедренном сбое
+var ErrInjected = errors.New("chaos: внедренная ошибка")
+
+// Injector добавляет задержки и ошибки в вызовы хранилища, кэша и Kafka.
+// Нулевой указатель безопасен и ничего не делает.
+type Injector struct {
+	errorRate   float64
+	latencyRate float64
+	latency     time.Duration
+	targets     map[string]bool // storage, cache, kafka; пусто — все
+	logger      *slog.Logger
+}
+
+// New создает инжектор по конфигурации. В production и при выключенном режиме возвращает nil.
+func New(cfg *config.Config, logger *slog.Logger) *Injector {
+	if !cfg.ChaosEnabled {
+		return nil
+	}
+	if cfg.Environment == "production" {
+		logger.Warn("Режим внедрения сбоев запрещен в production и будет проигнорирован")
+		return nil
+	}
+
+	targets := make(map[string]bool)
+	for _, t := range cfg.ChaosTargets {
+		if t = strings.TrimSpace(t); t != "" {
+			targets[t] = true
+		}
+	}
+
+	logger.Warn("Включен режим внедрения сбоев",
+		"error_rate", cfg.ChaosErrorRate,
+		"latency_rate", cfg.ChaosLatencyRate,
+		"latency", cfg.ChaosLatency,
+		"targets", cfg.ChaosTargets)
+
+	return &Injector{
+		errorRate:   cfg.ChaosErrorRate,
+		latencyRate: cfg.ChaosLatencyRate,
+		latency:     cfg.ChaosLatency,
+		targets:     targets,
+		logger:      logger,
+	}
+}
+
+// Inject вызывается перед операцией op вида "storage.Save".
+// Может задержать выполнение и/или вернуть ErrInjected.
+func (i *Injector) Inject(ctx context.Context, op string) error {
+	if i == nil || !i.applies(op) {
+		return nil
+	}
+
+	if i.latency > 0 && rand.Float64() < i.latencyRate {
+		i.logger.DebugContext(ctx, "Внедрена задержка", "op", op, "latency", i.latency)
+		select {
+		case <-time.After(i.latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if rand.Float64() < i.errorRate {
+		i.logger.DebugContext(ctx, "Внедрена ошибка", "op", op)
+		return fmt.Errorf("%s: %w", op, ErrInjected)
+	}
+	return nil
+}
+
+func (i *Injector) applies(op string) bool {
+	if len(i.targets) == 0 {
+		return true
+	}
+	target, _, _ := strings.Cut(op, ".")
+	return i.targets[target]
+}
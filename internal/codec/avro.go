@@ -0,0 +1,203 @@
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// avroSchema — схема конверта. Новые поля добавляются в конец со значением
+// по умолчанию, чтобы реестр принимал версию как обратно совместимую.
+const avroSchema = `{"type":"record","name":"Event","namespace":"io.gometeo","fields":[` +
+	`{"name":"schema_version","type":"int"},` +
+	`{"name":"type","type":"string"},` +
+	`{"name":"source","type":"string"},` +
+	`{"name":"occurred_at","type":{"type":"long","logicalType":"timestamp-micros"}},` +
+	`{"name":"payload","type":"string"}]}`
+
+var errAvroShort = errors.New("сообщение Avro обрывается")
+
+// Avro пишет конверт в бинарном Avro со схемой из реестра
+type Avro struct {
+	registry *Registry
+}
+
+func (*Avro) Format() string { return FormatAvro }
+
+func (a *Avro) Encode(ctx context.Context, topic string, event model.Event) ([]byte, error) {
+	id, err := a.registry.Register(ctx, subject(topic), schemaAvro, avroSchema)
+	if err != nil {
+		return nil, err
+	}
+	body := binary.AppendVarint(nil, int64(event.SchemaVersion))
+	body = appendAvroString(body, event.Type)
+	body = appendAvroString(body, event.Source)
+	body = binary.AppendVarint(body, micros(event.OccurredAt))
+	body = appendAvroString(body, string(event.Payload))
+	return frame(id, body), nil
+}
+
+func (a *Avro) Decode(ctx context.Context, _ string, data []byte) (model.Event, error) {
+	return decode(ctx, a.registry, data)
+}
+
+func appendAvroString(b []byte, s string) []byte {
+	b = binary.AppendVarint(b, int64(len(s)))
+	return append(b, s...)
+}
+
+// avroRecord — разобранная схема записи. Поддерживаются поля простых типов
+// и объединения с null: этого достаточно для конверта.
+type avroRecord struct {
+	fields []avroField
+}
+
+type avroField struct {
+	name    string
+	types   []string // больше одного — объединение
+	logical string
+}
+
+func parseAvroSchema(source string) (*avroRecord, error) {
+	var schema struct {
+		Type   string `json:"type"`
+		Fields []struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(source), &schema); err != nil {
+		return nil, fmt.Errorf("ошибка разбора схемы Avro: %w", err)
+	}
+	if schema.Type != "record" {
+		return nil, fmt.Errorf("схема Avro должна быть записью, а не %s", schema.Type)
+	}
+
+	record := &avroRecord{fields: make([]avroField, 0, len(schema.Fields))}
+	for _, f := range schema.Fields {
+		field := avroField{name: f.Name}
+		var name string
+		var logical struct {
+			Type        string `json:"type"`
+			LogicalType string `json:"logicalType"`
+		}
+		switch {
+		case json.Unmarshal(f.Type, &name) == nil:
+			field.types = []string{name}
+		case json.Unmarshal(f.Type, &field.types) == nil:
+		case json.Unmarshal(f.Type, &logical) == nil:
+			field.types = []string{logical.Type}
+			field.logical = logical.LogicalType
+		}
+		for _, t := range field.types {
+			switch t {
+			case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			default:
+				return nil, fmt.Errorf("поле %s: неподдерживаемый тип Avro %s", f.Name, string(f.Type))
+			}
+		}
+		if len(field.types) == 0 {
+			return nil, fmt.Errorf("поле %s: неподдерживаемый тип Avro %s", f.Name, string(f.Type))
+		}
+		record.fields = append(record.fields, field)
+	}
+	return record, nil
+}
+
+// decodeAvro читает конверт по схеме, с которой он записан: поля
+// сопоставляются по имени, незнакомые пропускаются, отсутствующие остаются
+// пустыми
+func decodeAvro(schema *Schema, data []byte) (model.Event, error) {
+	var event model.Event
+	for _, field := range schema.avro.fields {
+		typ := field.types[0]
+		if len(field.types) > 1 {
+			branch, n := binary.Varint(data)
+			if n <= 0 || branch < 0 || int(branch) >= len(field.types) {
+				return model.Event{}, fmt.Errorf("поле %s: неверная ветка объединения", field.name)
+			}
+			data = data[n:]
+			typ = field.types[branch]
+		}
+
+		value, rest, err := readAvro(typ, data)
+		if err != nil {
+			return model.Event{}, fmt.Errorf("поле %s: %w", field.name, err)
+		}
+		data = rest
+
+		switch v := value.(type) {
+		case int64:
+			switch field.name {
+			case "schema_version":
+				event.SchemaVersion = int(v)
+			case "occurred_at":
+				event.OccurredAt = fromMicros(v)
+				if field.logical == "timestamp-millis" {
+					event.OccurredAt = time.UnixMilli(v).UTC()
+				}
+			}
+		case string:
+			switch field.name {
+			case "type":
+				event.Type = v
+			case "source":
+				event.Source = v
+			case "payload":
+				event.Payload = json.RawMessage(v)
+			}
+		case []byte:
+			if field.name == "payload" {
+				event.Payload = json.RawMessage(v)
+			}
+		}
+	}
+	return event, nil
+}
+
+// readAvro читает значение простого типа; int и long возвращаются как int64,
+// bytes — как []byte
+func readAvro(typ string, data []byte) (any, []byte, error) {
+	switch typ {
+	case "null":
+		return nil, data, nil
+	case "boolean":
+		if len(data) < 1 {
+			return nil, nil, errAvroShort
+		}
+		return data[0] != 0, data[1:], nil
+	case "int", "long":
+		v, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, errAvroShort
+		}
+		return v, data[n:], nil
+	case "float":
+		if len(data) < 4 {
+			return nil, nil, errAvroShort
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), data[4:], nil
+	case "double":
+		if len(data) < 8 {
+			return nil, nil, errAvroShort
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), data[8:], nil
+	case "bytes", "string":
+		size, n := binary.Varint(data)
+		if n <= 0 || size < 0 || int64(len(data)-n) < size {
+			return nil, nil, errAvroShort
+		}
+		value := data[n : n+int(size)]
+		if typ == "string" {
+			return string(value), data[n+int(size):], nil
+		}
+		return append([]byte(nil), value...), data[n+int(size):], nil
+	}
+	return nil, nil, fmt.Errorf("неподдерживаемый тип Avro %s", typ)
+}
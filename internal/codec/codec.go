@@ -0,0 +1,147 @@
+// Package codec сериализует конверты событий для Kafka: JSON, Avro или
+// Protobuf со схемой в Confluent Schema Registry. Коллектор пишет в формате
+// из KAFKA_CODEC, агрегатор читает любой из них: формат сообщения виден по
+// первому байту, поэтому смена формата не требует остановки конвейера.
+//
+// Схема описывает конверт; payload внутри остается JSON, его совместимость
+// обеспечивают необязательные поля моделей.
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// Форматы сообщений
+const (
+	FormatJSON     = "json"
+	FormatAvro     = "avro"
+	FormatProtobuf = "protobuf"
+)
+
+// Типы схем в реестре
+const (
+	schemaAvro     = "AVRO"
+	schemaProtobuf = "PROTOBUF"
+)
+
+// ErrNoRegistry — сообщение со схемой из реестра, а реестр не настроен
+var ErrNoRegistry = errors.New("сообщение записано со схемой из Schema Registry, а реестр не настроен")
+
+// magicByte открывает сообщения в формате Confluent: за ним 4 байта ID схемы
+const magicByte = 0
+
+// Codec упаковывает конверт события в тело сообщения топика и обратно
+type Codec interface {
+	// Format — формат, в котором пишет Encode
+	Format() string
+	Encode(ctx context.Context, topic string, event model.Event) ([]byte, error)
+	// Decode читает сообщение любого формата
+	Decode(ctx context.Context, topic string, data []byte) (model.Event, error)
+}
+
+// FromConfig создает кодек из KAFKA_CODEC. Реестр подключается и к JSON,
+// если задан адрес: так читаются сообщения, записанные до возврата на JSON.
+func FromConfig(cfg *config.Config) (Codec, error) {
+	var registry *Registry
+	if cfg.SchemaRegistryURL != "" {
+		registry = NewRegistry(cfg.SchemaRegistryURL, cfg.SchemaRegistryUser, cfg.SchemaRegistryPassword, cfg.SchemaRegistryTimeout)
+	}
+
+	switch format := strings.ToLower(cfg.KafkaCodec); format {
+	case FormatJSON, "":
+		return JSON{Registry: registry}, nil
+	case FormatAvro, FormatProtobuf:
+		if registry == nil {
+			return nil, fmt.Errorf("для формата %s нужен SCHEMA_REGISTRY_URL", format)
+		}
+		if format == FormatAvro {
+			return &Avro{registry: registry}, nil
+		}
+		return &Protobuf{registry: registry}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный формат сообщений: %s", cfg.KafkaCodec)
+	}
+}
+
+// JSON — конверт в JSON, как его пишет model.Event.Marshal
+type JSON struct {
+	// Registry нужен только для чтения сообщений в Avro и Protobuf; nil — такие
+	// сообщения возвращают ErrNoRegistry
+	Registry *Registry
+}
+
+func (JSON) Format() string { return FormatJSON }
+
+func (JSON) Encode(_ context.Context, _ string, event model.Event) ([]byte, error) {
+	return event.Marshal()
+}
+
+func (j JSON) Decode(ctx context.Context, _ string, data []byte) (model.Event, error) {
+	return decode(ctx, j.Registry, data)
+}
+
+// decode определяет формат сообщения: Confluent начинается с нулевого байта,
+// JSON — с '{'. Формат тела Confluent берется из типа схемы в реестре.
+func decode(ctx context.Context, registry *Registry, data []byte) (model.Event, error) {
+	if len(data) == 0 || data[0] != magicByte {
+		return model.UnmarshalEvent(data)
+	}
+	if registry == nil {
+		return model.Event{}, ErrNoRegistry
+	}
+	if len(data) < 5 {
+		return model.Event{}, fmt.Errorf("ошибка разбора события: сообщение короче заголовка схемы")
+	}
+	id := int(binary.BigEndian.Uint32(data[1:5]))
+	schema, err := registry.Schema(ctx, id)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	var event model.Event
+	switch schema.Type {
+	case schemaAvro:
+		event, err = decodeAvro(schema, data[5:])
+	case schemaProtobuf:
+		event, err = decodeProtobuf(data[5:])
+	default:
+		return model.Event{}, fmt.Errorf("схема %d: неподдерживаемый тип %s", id, schema.Type)
+	}
+	if err != nil {
+		return model.Event{}, fmt.Errorf("ошибка разбора события по схеме %d: %w", id, err)
+	}
+	if event.SchemaVersion > model.EventSchemaVersion {
+		return model.Event{}, fmt.Errorf("%w: %d", model.ErrUnsupportedSchema, event.SchemaVersion)
+	}
+	return event, nil
+}
+
+// frame добавляет заголовок Confluent к телу сообщения
+func frame(id int, body []byte) []byte {
+	out := make([]byte, 5, 5+len(body))
+	out[0] = magicByte
+	binary.BigEndian.PutUint32(out[1:], uint32(id))
+	return append(out, body...)
+}
+
+// subject — имя схемы по стратегии TopicNameStrategy
+func subject(topic string) string {
+	return topic + "-value"
+}
+
+// micros и fromMicros переводят время конверта в микросекунды Unix и обратно
+func micros(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+func fromMicros(us int64) time.Time {
+	return time.UnixMicro(us).UTC()
+}
@@ -0,0 +1,193 @@
+package codec_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gometeo/app/internal/codec"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// registry — Schema Registry в памяти: ID схемы выдается по порядку регистрации
+type registry struct {
+	url string
+
+	mu            sync.Mutex
+	schemas       []map[string]string
+	registrations int
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/versions"):
+		var body map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.registrations++
+		for i, s := range r.schemas {
+			if s["schema"] == body["schema"] {
+				json.NewEncoder(w).Encode(map[string]int{"id": i + 1})
+				return
+			}
+		}
+		// Реестр не хранит тип для Avro
+		if body["schemaType"] == "AVRO" {
+			delete(body, "schemaType")
+		}
+		r.schemas = append(r.schemas, body)
+		json.NewEncoder(w).Encode(map[string]int{"id": len(r.schemas)})
+	case req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/schemas/ids/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/schemas/ids/"))
+		if id < 1 || id > len(r.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Schema not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(r.schemas[id-1])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newCodec(t *testing.T, format string) (codec.Codec, *registry) {
+	t.Helper()
+	reg := &registry{}
+	srv := httptest.NewServer(reg)
+	t.Cleanup(srv.Close)
+	reg.url = srv.URL
+
+	cfg := config.Load()
+	cfg.KafkaCodec = format
+	cfg.SchemaRegistryURL = srv.URL
+	c, err := codec.FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, reg
+}
+
+var events = map[string]model.Event{
+	"все поля": {
+		SchemaVersion: model.EventSchemaVersion,
+		Type:          model.EventWeatherObserved,
+		Source:        "collector-1",
+		OccurredAt:    time.Date(2026, 1, 15, 12, 30, 45, 123456000, time.UTC),
+		Payload:       json.RawMessage(`{"city":"Москва","temperature":-3.5}`),
+	},
+	"нулевые значения": {},
+	"отрицательные значения": {
+		SchemaVersion: -1,
+		OccurredAt:    time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC),
+	},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{codec.FormatAvro, codec.FormatProtobuf} {
+		for name, event := range events {
+			t.Run(format+"/"+name, func(t *testing.T) {
+				c, _ := newCodec(t, format)
+				ctx := context.Background()
+
+				data, err := c.Encode(ctx, "weather_data", event)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// Заголовок Confluent: нулевой байт и ID схемы big-endian
+				if len(data) < 5 || data[0] != 0 {
+					t.Fatalf("нет заголовка Confluent: % x", data)
+				}
+				if id := binary.BigEndian.Uint32(data[1:5]); id != 1 {
+					t.Errorf("ID схемы %d, ожидался 1", id)
+				}
+
+				got, err := c.Decode(ctx, "weather_data", data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.SchemaVersion != event.SchemaVersion || got.Type != event.Type || got.Source != event.Source ||
+					!got.OccurredAt.Equal(event.OccurredAt) || string(got.Payload) != string(event.Payload) {
+					t.Errorf("прочитано %+v, ожидалось %+v", got, event)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeRegistersSchemaOnce(t *testing.T) {
+	c, reg := newCodec(t, codec.FormatProtobuf)
+	for range 3 {
+		if _, err := c.Encode(context.Background(), "weather_data", events["все поля"]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reg.registrations != 1 {
+		t.Errorf("схема зарегистрирована %d раз, ожидался один", reg.registrations)
+	}
+}
+
+func TestJSONReadsRegistryFormats(t *testing.T) {
+	avro, reg := newCodec(t, codec.FormatAvro)
+	event := events["все поля"]
+	data, err := avro.Encode(context.Background(), "weather_data", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Без реестра сообщение Avro не читается
+	if _, err := (codec.JSON{}).Decode(context.Background(), "weather_data", data); !errors.Is(err, codec.ErrNoRegistry) {
+		t.Errorf("чтение без реестра: %v, ожидалась ErrNoRegistry", err)
+	}
+
+	// Кодек JSON с тем же реестром читает Avro, записанный до смены формата
+	jsonCodec := codec.JSON{Registry: codec.NewRegistry(reg.url, "", "", time.Second)}
+	got, err := jsonCodec.Decode(context.Background(), "weather_data", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != event.Type || string(got.Payload) != string(event.Payload) {
+		t.Errorf("прочитано %+v, ожидалось %+v", got, event)
+	}
+
+	// И по-прежнему читает JSON
+	plain, err := event.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := jsonCodec.Decode(context.Background(), "weather_data", plain); err != nil || got.Source != event.Source {
+		t.Errorf("чтение JSON: %+v (%v)", got, err)
+	}
+}
+
+func TestDecodeRejectsNewerSchema(t *testing.T) {
+	c, _ := newCodec(t, codec.FormatAvro)
+	event := events["все поля"]
+	event.SchemaVersion = model.EventSchemaVersion + 1
+	data, err := c.Encode(context.Background(), "weather_data", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Decode(context.Background(), "weather_data", data); !errors.Is(err, model.ErrUnsupportedSchema) {
+		t.Errorf("чтение версии %d: %v, ожидалась ErrUnsupportedSchema", event.SchemaVersion, err)
+	}
+}
+
+func TestDecodeTruncatedHeader(t *testing.T) {
+	c, _ := newCodec(t, codec.FormatProtobuf)
+	if _, err := c.Decode(context.Background(), "weather_data", []byte{0, 0, 1}); err == nil {
+		t.Error("прочитано сообщение короче заголовка")
+	}
+}
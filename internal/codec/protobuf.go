@@ -0,0 +1,118 @@
+package codec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gometeo/app/internal/model"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoSchema — схема конверта. Номера полей не переиспользуются: удаленное
+// поле помечается reserved.
+const protoSchema = `syntax = "proto3";
+package io.gometeo;
+
+message Event {
+  int32 schema_version = 1;
+  string type = 2;
+  string source = 3;
+  int64 occurred_at_micros = 4;
+  bytes payload = 5;
+}
+`
+
+// Номера полей конверта
+const (
+	protoSchemaVersion protowire.Number = 1
+	protoType          protowire.Number = 2
+	protoSource        protowire.Number = 3
+	protoOccurredAt    protowire.Number = 4
+	protoPayload       protowire.Number = 5
+)
+
+// Protobuf пишет конверт в Protobuf со схемой из реестра
+type Protobuf struct {
+	registry *Registry
+}
+
+func (*Protobuf) Format() string { return FormatProtobuf }
+
+func (p *Protobuf) Encode(ctx context.Context, topic string, event model.Event) ([]byte, error) {
+	id, err := p.registry.Register(ctx, subject(topic), schemaProtobuf, protoSchema)
+	if err != nil {
+		return nil, err
+	}
+	// Индекс сообщения в схеме: 0 — первое сообщение файла
+	body := []byte{0}
+	body = protowire.AppendTag(body, protoSchemaVersion, protowire.VarintType)
+	body = protowire.AppendVarint(body, uint64(int32(event.SchemaVersion)))
+	body = protowire.AppendTag(body, protoType, protowire.BytesType)
+	body = protowire.AppendString(body, event.Type)
+	body = protowire.AppendTag(body, protoSource, protowire.BytesType)
+	body = protowire.AppendString(body, event.Source)
+	body = protowire.AppendTag(body, protoOccurredAt, protowire.VarintType)
+	body = protowire.AppendVarint(body, uint64(micros(event.OccurredAt)))
+	body = protowire.AppendTag(body, protoPayload, protowire.BytesType)
+	body = protowire.AppendBytes(body, event.Payload)
+	return frame(id, body), nil
+}
+
+func (p *Protobuf) Decode(ctx context.Context, _ string, data []byte) (model.Event, error) {
+	return decode(ctx, p.registry, data)
+}
+
+// decodeProtobuf читает конверт; незнакомые поля новых версий пропускаются
+func decodeProtobuf(data []byte) (model.Event, error) {
+	// Индексы сообщения: число индексов и сами индексы, zigzag
+	count, n := protowire.ConsumeVarint(data)
+	if n < 0 {
+		return model.Event{}, protowire.ParseError(n)
+	}
+	data = data[n:]
+	for range protowire.DecodeZigZag(count) {
+		if _, n = protowire.ConsumeVarint(data); n < 0 {
+			return model.Event{}, protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+
+	var event model.Event
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return model.Event{}, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch {
+		case num == protoSchemaVersion && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(data)
+			event.SchemaVersion = int(int32(v))
+		case num == protoOccurredAt && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(data)
+			event.OccurredAt = fromMicros(int64(v))
+		case (num == protoType || num == protoSource || num == protoPayload) && typ == protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(data)
+			switch num {
+			case protoType:
+				event.Type = string(v)
+			case protoSource:
+				event.Source = string(v)
+			default:
+				event.Payload = json.RawMessage(append([]byte(nil), v...))
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return model.Event{}, fmt.Errorf("поле %d: %w", num, protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+	return event, nil
+}
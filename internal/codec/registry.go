@@ -0,0 +1,147 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentType — тип тела запросов Schema Registry
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// Schema — схема из реестра
+type Schema struct {
+	ID     int
+	Type   string // AVRO или PROTOBUF
+	Source string
+
+	avro *avroRecord // разобранная схема Avro, чтобы не разбирать на каждое сообщение
+}
+
+// Registry — клиент Confluent Schema Registry. Схемы кэшируются: ID по
+// подписи регистрируется один раз, схема по ID не меняется.
+type Registry struct {
+	baseURL  string
+	user     string
+	password string
+	client   *http.Client
+
+	mu      sync.Mutex
+	ids     map[string]int // subject + схема → ID
+	schemas map[int]*Schema
+}
+
+// NewRegistry создает клиент; user и password — ключ API для Confluent Cloud
+func NewRegistry(baseURL, user, password string, timeout time.Duration) *Registry {
+	return &Registry{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+		ids:      make(map[string]int),
+		schemas:  make(map[int]*Schema),
+	}
+}
+
+// Register регистрирует схему в subject и возвращает ее ID. Несовместимую
+// версию реестр отклоняет по правилам совместимости subject.
+func (r *Registry) Register(ctx context.Context, subject, schemaType, source string) (int, error) {
+	key := subject + "\x00" + source
+	r.mu.Lock()
+	id, ok := r.ids[key]
+	r.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	body, _ := json.Marshal(map[string]string{"schema": source, "schemaType": schemaType})
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", body, &resp); err != nil {
+		return 0, fmt.Errorf("ошибка регистрации схемы %s: %w", subject, err)
+	}
+
+	r.mu.Lock()
+	r.ids[key] = resp.ID
+	r.mu.Unlock()
+	return resp.ID, nil
+}
+
+// Schema возвращает схему по ID
+func (r *Registry) Schema(ctx context.Context, id int) (*Schema, error) {
+	r.mu.Lock()
+	schema, ok := r.schemas[id]
+	r.mu.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	var resp struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := r.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp); err != nil {
+		return nil, fmt.Errorf("ошибка получения схемы %d: %w", id, err)
+	}
+	schema = &Schema{ID: id, Type: resp.SchemaType, Source: resp.Schema}
+	if schema.Type == "" {
+		// Реестр не указывает тип для Avro
+		schema.Type = schemaAvro
+	}
+	if schema.Type == schemaAvro {
+		record, err := parseAvroSchema(schema.Source)
+		if err != nil {
+			return nil, fmt.Errorf("схема %d: %w", id, err)
+		}
+		schema.avro = record
+	}
+
+	r.mu.Lock()
+	r.schemas[id] = schema
+	r.mu.Unlock()
+	return schema, nil
+}
+
+func (r *Registry) do(ctx context.Context, method, path string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.user != "" {
+		req.SetBasicAuth(r.user, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к Schema Registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var payload struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4<<10)).Decode(&payload)
+		return fmt.Errorf("Schema Registry вернул %d: %s", resp.StatusCode, payload.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ошибка разбора ответа Schema Registry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// SetCities задает города из настроек: записи "Moscow" или "Moscow=30s" и файл
+// с такими же записями по одной на строку (# — комментарий). Файл важнее
+// списка; без обоих опрашивается справочник. interval — для записей без
+// своего интервала и для городов справочника. Вызывается до Run.
+func (c *Collector) SetCities(entries []string, file string, interval time.Duration) {
+	c.cityEntries = entries
+	c.citiesFile = file
+	if interval >= time.Second {
+		c.interval = interval
+	}
+}
+
+// SetCitiesRefresh задает, как часто Run перечитывает файл городов или
+// справочник; вызывается до Run, 0 — список читается при старте и по SIGHUP
+func (c *Collector) SetCitiesRefresh(refresh time.Duration) {
+	c.citiesRefresh = refresh
+}
+
+// SetCitySource включает чтение списка городов из справочника. Можно вызвать
+// во время Run, например когда БД подключилась в фоне: справочник будет
+// прочитан при следующем обновлении списка.
+func (c *Collector) SetCitySource(source CitySource) {
+	c.cities.Store(&citySourceRef{source})
+}
+
+// pollCities возвращает расписания опроса городов. Если источник недоступен,
+// опрашивается прежний список, а до первого успешного чтения —
+// model.DefaultCities. Время последнего опроса переносится по названию города,
+// чтобы перечитывание не вызывало внеочередной опрос.
+func (c *Collector) pollCities(ctx context.Context, current []model.CitySchedule) (schedules []model.CitySchedule) {
+	defer func() { c.status.cities.Store(int64(len(schedules))) }()
+
+	if current == nil {
+		current = c.schedules(model.DefaultCities)
+	}
+
+	loaded, err := c.loadCities(ctx)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Не удалось прочитать список городов, опрос по прежнему списку", "error", err)
+		return current
+	}
+	if loaded == nil {
+		return current
+	}
+
+	last := make(map[string]*time.Time, len(current))
+	for _, schedule := range current {
+		last[schedule.City] = schedule.LastRequestedAt
+	}
+	for i := range loaded {
+		loaded[i].LastRequestedAt = last[loaded[i].City]
+	}
+	if len(loaded) != len(current) {
+		c.logger.InfoContext(ctx, "Список городов обновлен", "cities", len(loaded))
+	}
+	return loaded
+}
+
+// loadCities читает города из источника с наибольшим приоритетом;
+// nil без ошибки — источника пока нет
+func (c *Collector) loadCities(ctx context.Context) ([]model.CitySchedule, error) {
+	switch {
+	case c.citiesFile != "":
+		entries, err := readCityEntries(c.citiesFile)
+		if err != nil {
+			return nil, err
+		}
+		return c.parseCityEntries(ctx, entries), nil
+	case len(c.cityEntries) > 0:
+		return c.parseCityEntries(ctx, c.cityEntries), nil
+	}
+
+	ref := c.cities.Load()
+	if ref == nil {
+		return nil, nil
+	}
+	all, err := ref.ListCities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enabled := make([]model.City, 0, len(all))
+	for _, city := range all {
+		if city.Enabled {
+			enabled = append(enabled, city)
+		}
+	}
+	// Координаты из справочника, чтобы новые города не искались во внешнем геокодере
+	c.geocoder.Seed(enabled)
+	return c.schedules(enabled), nil
+}
+
+// schedules строит расписания городов с интервалом по умолчанию
+func (c *Collector) schedules(cities []model.City) []model.CitySchedule {
+	out := make([]model.CitySchedule, 0, len(cities))
+	for _, city := range cities {
+		out = append(out, model.CitySchedule{
+			City:     city.Name,
+			Interval: c.interval,
+			Enabled:  true,
+			Lat:      city.Lat,
+			Lon:      city.Lon,
+		})
+	}
+	return out
+}
+
+// parseCityEntries разбирает записи городов; неверные пропускаются с
+// предупреждением, повторы города — тоже
+func (c *Collector) parseCityEntries(ctx context.Context, entries []string) []model.CitySchedule {
+	out := make([]model.CitySchedule, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		city, interval, err := parseCityEntry(entry, c.interval)
+		if err != nil {
+			c.logger.WarnContext(ctx, "Неверная запись города пропущена", "entry", entry, "error", err)
+			continue
+		}
+		if seen[city] {
+			c.logger.WarnContext(ctx, "Повтор города в списке пропущен", "entry", entry)
+			continue
+		}
+		seen[city] = true
+		out = append(out, model.CitySchedule{City: city, Interval: interval, Enabled: true})
+	}
+	return out
+}
+
+// parseCityEntry разбирает запись вида "Moscow" или "Moscow=30s"
+func parseCityEntry(entry string, fallback time.Duration) (string, time.Duration, error) {
+	city, value, hasInterval := strings.Cut(strings.TrimSpace(entry), "=")
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return "", 0, fmt.Errorf("ожидается город или город=интервал")
+	}
+	if !hasInterval {
+		return city, fallback, nil
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || interval < time.Second {
+		return "", 0, fmt.Errorf("неверный интервал, нужно не меньше 1s: %s", value)
+	}
+	return city, interval, nil
+}
+
+// readCityEntries читает записи городов из файла, пропуская пустые строки
+// и комментарии
+func readCityEntries(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла городов: %w", err)
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла городов: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,463 @@
+// Package collector получает погоду у провайдеров и публикует замеры
+// в топик weather_data, а прогнозы — в отдельный топик. Запускается
+// как отдельный сервис (cmd/collector) или внутри монолита (cmd/gometeo).
+package collector
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/codec"
+	"github.com/gometeo/app/internal/errreport"
+	"github.com/gometeo/app/internal/geocode"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/provider"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Topic — топик замеров погоды
+	Topic       = "weather_data"
+	eventSource = "collector"
+
+	// HeaderProvider — заголовок сообщения с названием провайдера
+	HeaderProvider = "provider"
+)
+
+var tracer = tracing.Tracer("github.com/gometeo/app/internal/collector")
+
+// CitySource — справочник городов для опроса; реализуется storage.WeatherStorage
+type CitySource interface {
+	ListCities(ctx context.Context) ([]model.City, error)
+}
+
+// citySourceRef позволяет хранить интерфейс в atomic.Pointer
+type citySourceRef struct {
+	CitySource
+}
+
+// Collector собирает погоду и публикует ее в шину
+type Collector struct {
+	logger    *slog.Logger
+	producer  bus.AsyncPublisher
+	codec     codec.Codec
+	reporter  errreport.Reporter
+	published metric.Int64Counter
+	skipped   metric.Int64Counter
+	faults    *chaos.Injector
+	geocoder  *geocode.Resolver
+
+	// Провайдеры погоды, опрашиваются параллельно
+	providers []provider.Provider
+
+	// Прогнозы провайдера; пустой топик — прогнозы не запрашиваются
+	forecastTopic    string
+	forecastInterval time.Duration
+	forecastDays     int
+
+	// Задания по расписанию crontab; пусто — города опрашиваются по интервалам
+	jobs []job
+
+	// Состояние для /status и /healthz
+	status statusTracker
+
+	// Города из настроек; без них — справочник, а без справочника — model.DefaultCities
+	cityEntries []string
+	citiesFile  string
+	interval    time.Duration // для городов без своего интервала
+	cities      atomic.Pointer[citySourceRef]
+	// Как часто перечитываются файл и справочник; SIGHUP — внеочередное чтение
+	citiesRefresh time.Duration
+}
+
+// New создает коллектор. Сообщения отправляются асинхронно: сбор не ждет
+// подтверждения Kafka, а producer.Close при остановке дожидается отправки
+// всех сообщений. Синхронную шину оборачивает bus.Immediate.
+func New(producer bus.AsyncPublisher, reporter errreport.Reporter, faults *chaos.Injector, geocoder *geocode.Resolver, logger *slog.Logger) *Collector {
+	published, _ := metrics.Meter("github.com/gometeo/app/cmd/collector").Int64Counter(
+		"collector.messages.published",
+		metric.WithDescription("Количество отправленных в Kafka сообщений по результату"))
+	skipped, _ := metrics.Meter("github.com/gometeo/app/cmd/collector").Int64Counter(
+		"collector.provider.skipped",
+		metric.WithDescription("Запросы к провайдерам, пропущенные из-за лимита в минуту или суточной квоты"))
+
+	c := &Collector{
+		logger:    logger,
+		producer:  producer,
+		codec:     codec.JSON{},
+		reporter:  reporter,
+		published: published,
+		skipped:   skipped,
+		faults:    faults,
+		geocoder:  geocoder,
+		interval:  time.Minute,
+	}
+	c.status.startedAt = time.Now()
+	c.status.providers = make(map[string]*providerStats)
+	return c
+}
+
+// SetProviders задает провайдеров погоды. Без провайдеров коллектор ничего не
+// собирает; эмулятор для разработки передается явно, см. provider.FromConfig.
+func (c *Collector) SetProviders(providers []provider.Provider) {
+	c.providers = providers
+}
+
+// SetCodec задает формат сообщений; по умолчанию JSON
+func (c *Collector) SetCodec(codec codec.Codec) {
+	c.codec = codec
+}
+
+// SetForecasts включает сбор прогнозов на days дней каждые interval в топик topic.
+// interval задает расписание Run; в режиме воркера прогнозы не запрашиваются.
+func (c *Collector) SetForecasts(topic string, interval time.Duration, days int) {
+	c.forecastTopic = topic
+	c.forecastInterval = interval
+	c.forecastDays = min(max(days, 1), model.MaxForecastDays)
+}
+
+// Run опрашивает города по заданиям crontab, а без них — каждый город по его
+// интервалу, и отправляет погоду в шину до отмены ctx
+func (c *Collector) Run(ctx context.Context) {
+	// Такт проверки расписаний; интервалы городов не короче секунды
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	// Прогноз меняется редко: все города одним проходом по своему таймеру,
+	// если прогнозы не входят в задания
+	var forecasts <-chan time.Time
+	if c.forecastTopic != "" && c.forecastInterval > 0 && len(c.jobs) == 0 {
+		forecastTicker := time.NewTicker(c.forecastInterval)
+		defer forecastTicker.Stop()
+		forecasts = forecastTicker.C
+	}
+
+	// Список перечитывается, чтобы добавленные города опрашивались без
+	// перезапуска; без интервала обновления — только по SIGHUP
+	var refresh <-chan time.Time
+	if c.citiesRefresh > 0 {
+		refreshTicker := time.NewTicker(c.citiesRefresh)
+		defer refreshTicker.Stop()
+		refresh = refreshTicker.C
+	}
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	schedules := c.pollCities(ctx, nil)
+	c.tick(time.Now())
+
+	c.logger.InfoContext(ctx, "Начинаем сбор данных...", "cities", len(schedules), "jobs", len(c.jobs))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh:
+			schedules = c.pollCities(ctx, schedules)
+		case <-hangup:
+			c.logger.InfoContext(ctx, "Получен SIGHUP, перечитываем список городов")
+			schedules = c.pollCities(ctx, schedules)
+		case now := <-ticker.C:
+			c.tick(now)
+			if len(c.jobs) > 0 {
+				c.runJobs(ctx, schedules, now)
+			} else {
+				c.collectDue(ctx, schedules, now)
+			}
+		case <-forecasts:
+			for _, schedule := range schedules {
+				c.CollectForecast(ctx, schedule.City)
+			}
+		}
+	}
+}
+
+// collectDue параллельно опрашивает города, которым подошел срок, и сдвигает
+// их расписания
+func (c *Collector) collectDue(ctx context.Context, schedules []model.CitySchedule, now time.Time) {
+	var wg sync.WaitGroup
+	for i := range schedules {
+		if !schedules[i].Due(now) {
+			continue
+		}
+		schedules[i].LastRequestedAt = &now
+		wg.Add(1)
+		go func(city string) {
+			defer wg.Done()
+			c.Collect(ctx, city)
+		}(schedules[i].City)
+	}
+	wg.Wait()
+}
+
+// Collect получает погоду для города у всех провайдеров и публикует ее в шину
+func (c *Collector) Collect(ctx context.Context, city string) {
+	c.collect(ctx, city, c.providers)
+}
+
+func (c *Collector) collect(ctx context.Context, city string, providers []provider.Provider) {
+	// Идентификатор замера: команда планировщика приносит свой, иначе новый.
+	// По нему замер находится в логах коллектора, агрегатора и API.
+	ctx, _ = logging.EnsureRequestID(ctx)
+	// Каноническое название: команды и конфигурация могут прислать синоним
+	place := c.resolveCity(ctx, city)
+
+	// Каждый провайдер публикует свой замер: агрегатор хранит их раздельно
+	// и строит по ним консенсус
+	var wg sync.WaitGroup
+	for _, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.collectFrom(ctx, p, place)
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *Collector) collectFrom(ctx context.Context, p provider.Provider, place model.City) {
+	data, err := p.Fetch(ctx, place)
+	if err != nil {
+		c.fetchFailed(ctx, p.Name(), place.Name, "weather", err)
+		return
+	}
+	c.stats(p.Name()).success(time.Now())
+	// Замер привязан к каноническому городу и помечен провайдером
+	data.City = place.Name
+	data.Provider = p.Name()
+
+	c.publish(ctx, Topic, data.City, data.Provider, model.EventWeatherObserved, data.Timestamp, data, func(partition int32, offset int64) {
+		c.logger.InfoContext(ctx, "Погода отправлена",
+			"city", data.City,
+			"provider", data.Provider,
+			"temp", int(data.Temp),
+			"partition", partition,
+			"offset", offset)
+	})
+}
+
+// CollectForecast получает прогноз провайдера для города и публикует его
+// в топик прогнозов. Ничего не делает, если прогнозы не включены.
+func (c *Collector) CollectForecast(ctx context.Context, city string) {
+	c.collectForecast(ctx, city, c.providers)
+}
+
+func (c *Collector) collectForecast(ctx context.Context, city string, providers []provider.Provider) {
+	if c.forecastTopic == "" {
+		return
+	}
+	ctx, _ = logging.EnsureRequestID(ctx)
+	place := c.resolveCity(ctx, city)
+
+	var wg sync.WaitGroup
+	for _, p := range providers {
+		forecaster, ok := p.(provider.Forecaster)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.forecastFrom(ctx, forecaster, place)
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *Collector) forecastFrom(ctx context.Context, p provider.Forecaster, place model.City) {
+	batch, err := p.Forecast(ctx, place, c.forecastDays)
+	if err != nil {
+		c.fetchFailed(ctx, p.Name(), place.Name, "forecast", err)
+		return
+	}
+	c.stats(p.Name()).success(time.Now())
+	batch.City = place.Name
+	batch.Provider = p.Name()
+	for i := range batch.Forecasts {
+		batch.Forecasts[i].City = place.Name
+		batch.Forecasts[i].Provider = p.Name()
+	}
+
+	c.publish(ctx, c.forecastTopic, place.Name, batch.Provider, model.EventForecastIssued, batch.IssuedAt, batch, func(partition int32, offset int64) {
+		c.logger.InfoContext(ctx, "Прогноз отправлен",
+			"city", place.Name,
+			"provider", batch.Provider,
+			"points", len(batch.Forecasts),
+			"partition", partition,
+			"offset", offset)
+	})
+}
+
+// resolveCity находит город в справочнике или геокодером; без результата
+// запрос к провайдеру идет по исходному названию
+func (c *Collector) resolveCity(ctx context.Context, name string) model.City {
+	if c.geocoder != nil {
+		city, err := c.geocoder.Resolve(ctx, name)
+		if err == nil {
+			return *city
+		}
+		if !errors.Is(err, geocode.ErrNotFound) {
+			c.logger.WarnContext(ctx, "Ошибка геокодирования", "query", name, "error", err)
+		}
+	}
+	return model.City{Name: name}
+}
+
+// fetchFailed логирует ошибку провайдера. Превышение лимита — штатная ситуация,
+// в репортер уходят только остальные ошибки.
+func (c *Collector) fetchFailed(ctx context.Context, name, city, kind string, err error) {
+	// Бюджет ключа исчерпан: запрос не отправлялся, цикл для провайдера
+	// пропускается. Предупреждение — один раз, дальше только метрика.
+	var budget *provider.BudgetError
+	if errors.As(err, &budget) {
+		c.stats(name).skip()
+		c.skipped.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("provider", name),
+			attribute.String("reason", budget.Reason)))
+		if budget.First {
+			c.logger.WarnContext(ctx, "Бюджет запросов провайдера исчерпан, сбор пропускается", "provider", name, "reason", budget.Reason)
+		} else {
+			c.logger.DebugContext(ctx, "Бюджет запросов провайдера исчерпан, город пропущен", "provider", name, "city", city, "kind", kind)
+		}
+		return
+	}
+	if errors.Is(err, provider.ErrCircuitOpen) {
+		c.stats(name).skip()
+		// Размыкание предохранителя уже залогировано, отчет пишется в health
+		c.logger.DebugContext(ctx, "Провайдер приостановлен, город пропущен", "provider", name, "city", city, "kind", kind)
+		return
+	}
+	c.stats(name).failure(time.Now(), err)
+	if errors.Is(err, provider.ErrRateLimited) || errors.Is(err, provider.ErrNoCoordinates) {
+		c.logger.WarnContext(ctx, "Провайдер пропустил город", "provider", name, "city", city, "kind", kind, "error", err)
+		return
+	}
+	c.logger.ErrorContext(ctx, "Ошибка получения данных провайдера", "provider", name, "city", city, "kind", kind, "error", err)
+	c.reporter.CaptureError(ctx, err, map[string]string{"provider": name, "city": city, "stage": "fetch", "kind": kind})
+}
+
+// publish упаковывает payload в конверт и ставит в очередь отправки в топик
+// внутри спана продюсера. Спан закрывается по подтверждению Kafka, тогда же
+// вызывается sent. Ошибки логируются и отправляются в репортер здесь же.
+func (c *Collector) publish(ctx context.Context, topic, city, source, eventType string, occurredAt time.Time, payload any, sent func(partition int32, offset int64)) {
+	// Упаковка в конверт и сериализация
+	event, err := model.NewEvent(eventType, eventSource, occurredAt, payload)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Ошибка JSON", "error", err)
+		return
+	}
+	bytes, err := c.codec.Encode(ctx, topic, event)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Ошибка сериализации события", "format", c.codec.Format(), "error", err)
+		c.reporter.CaptureError(ctx, err, map[string]string{"city": city, "stage": "encode"})
+		return
+	}
+
+	// Отправка в Kafka
+	// Ключ — каноническое название города: хэш-партиционер кладет все замеры
+	// и прогнозы города в одну партицию, и агрегатор получает их по порядку
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(city),
+		Value: sarama.ByteEncoder(bytes),
+		// Провайдер в заголовке: потребители фильтруют без разбора тела
+		Headers: []sarama.RecordHeader{{Key: []byte(HeaderProvider), Value: []byte(source)}},
+	}
+
+	// Спан продюсера, его контекст уходит в заголовках сообщения
+	spanCtx, span := tracer.Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("weather.city", city),
+			attribute.String("weather.provider", source),
+		))
+	tracing.InjectKafka(spanCtx, msg)
+
+	// Результат приходит из горутины продюсера, когда пачка подтверждена
+	done := func(partition int32, offset int64, err error) {
+		c.status.kafka.record(time.Now(), err)
+		tracing.RecordError(span, err)
+		span.End()
+		result := "ok"
+		if err != nil {
+			result = "failed"
+		}
+		c.published.Add(spanCtx, 1, metric.WithAttributes(
+			attribute.String("result", result),
+			attribute.String("topic", topic)))
+
+		if err != nil {
+			c.logger.ErrorContext(spanCtx, "Не удалось отправить сообщение", "topic", topic, "error", err)
+			c.reporter.CaptureError(spanCtx, err, map[string]string{"city": city, "stage": "publish"})
+			return
+		}
+		sent(partition, offset)
+	}
+
+	if err := c.faults.Inject(spanCtx, "kafka.publish"); err != nil {
+		done(0, 0, err)
+		return
+	}
+	c.producer.Publish(msg, done)
+}
+
+// Worker возвращает обработчик команд fetch.requested от планировщика
+func (c *Collector) Worker() sarama.ConsumerGroupHandler {
+	return &fetchWorker{c: c}
+}
+
+// fetchWorker выполняет команды fetch.requested от планировщика
+type fetchWorker struct {
+	c *Collector
+}
+
+func (w *fetchWorker) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (w *fetchWorker) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+
+func (w *fetchWorker) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		ctx := tracing.ExtractKafka(sess.Context(), msg)
+
+		event, err := model.UnmarshalEvent(msg.Value)
+		if err != nil {
+			w.c.logger.ErrorContext(ctx, "Битая команда", "offset", msg.Offset, "error", err)
+			sess.MarkMessage(msg, "")
+			continue
+		}
+		if event.Type != model.EventFetchRequested {
+			w.c.logger.WarnContext(ctx, "Неизвестный тип команды", "type", event.Type)
+			sess.MarkMessage(msg, "")
+			continue
+		}
+
+		var req model.FetchRequest
+		if err := event.DecodePayload(&req); err != nil {
+			w.c.logger.ErrorContext(ctx, "Битая команда", "offset", msg.Offset, "error", err)
+			sess.MarkMessage(msg, "")
+			continue
+		}
+
+		// Команда отмечается, когда замеры в очереди продюсера: их отправку
+		// при остановке гарантирует закрытие продюсера
+		w.c.Collect(ctx, req.City)
+		sess.MarkMessage(msg, "")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/cron"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/provider"
+)
+
+// Виды заданий сбора
+const (
+	jobWeather  = "weather"
+	jobForecast = "forecast"
+)
+
+// job — задание сбора по расписанию crontab для группы провайдеров и городов
+type job struct {
+	kind      string
+	schedule  cron.Schedule
+	providers []provider.Provider // выбранные из настроенных коллектору
+	cities    map[string]bool     // в нижнем регистре; nil — все города списка
+	next      time.Time
+}
+
+// SetSchedules включает задания по расписанию вместо интервалов городов и
+// таймера прогнозов. Запись: "вид | расписание | провайдеры | города", где
+// вид — weather или forecast, расписание — crontab ("*/5 * * * *", "@hourly"),
+// провайдеры и города из списка коллектора — через запятую или "*";
+// последние два поля можно опустить. Провайдеры, которые не настроены,
+// пропускаются с предупреждением. Вызывается после SetProviders и до Run.
+func (c *Collector) SetSchedules(entries []string) error {
+	jobs := make([]job, 0, len(entries))
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		j, err := c.parseJob(entry)
+		if err != nil {
+			return fmt.Errorf("неверное задание сбора %q: %w", entry, err)
+		}
+		if len(j.providers) == 0 {
+			c.logger.Warn("Для задания сбора нет настроенных провайдеров, оно не выполняется", "entry", entry)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	c.jobs = jobs
+	return nil
+}
+
+func (c *Collector) parseJob(entry string) (job, error) {
+	parts := strings.Split(entry, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	if len(parts) < 2 || len(parts) > 4 {
+		return job{}, fmt.Errorf("ожидается вид | расписание | провайдеры | города")
+	}
+
+	j := job{kind: strings.ToLower(parts[0])}
+	if j.kind != jobWeather && j.kind != jobForecast {
+		return job{}, fmt.Errorf("неизвестный вид задания: %s", parts[0])
+	}
+	schedule, err := cron.Parse(parts[1])
+	if err != nil {
+		return job{}, err
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return job{}, fmt.Errorf("расписание никогда не срабатывает: %s", parts[1])
+	}
+	j.schedule = schedule
+
+	j.providers = c.providers
+	if len(parts) > 2 && parts[2] != "*" && parts[2] != "" {
+		j.providers = nil
+		for _, name := range strings.Split(parts[2], ",") {
+			name = strings.TrimSpace(name)
+			p := c.providerByName(name)
+			if p == nil {
+				c.logger.Warn("Провайдер задания сбора не настроен, пропущен", "provider", name, "entry", entry)
+				continue
+			}
+			j.providers = append(j.providers, p)
+		}
+	}
+	if j.kind == jobForecast {
+		// Прогноз умеют отдавать не все провайдеры
+		forecasters := j.providers[:0:0]
+		for _, p := range j.providers {
+			if _, ok := p.(provider.Forecaster); ok {
+				forecasters = append(forecasters, p)
+			}
+		}
+		j.providers = forecasters
+	}
+
+	if len(parts) > 3 && parts[3] != "*" && parts[3] != "" {
+		j.cities = make(map[string]bool)
+		for _, city := range strings.Split(parts[3], ",") {
+			if city = strings.TrimSpace(city); city != "" {
+				j.cities[strings.ToLower(city)] = true
+			}
+		}
+	}
+	return j, nil
+}
+
+// providerByName ищет настроенного провайдера без учета регистра
+func (c *Collector) providerByName(name string) provider.Provider {
+	for _, p := range c.providers {
+		if strings.EqualFold(p.Name(), name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// runJobs выполняет задания, которым подошел срок, и планирует следующий запуск
+func (c *Collector) runJobs(ctx context.Context, schedules []model.CitySchedule, now time.Time) {
+	var wg sync.WaitGroup
+	for i := range c.jobs {
+		j := &c.jobs[i]
+		if j.next.IsZero() {
+			j.next = j.schedule.Next(now)
+		}
+		if now.Before(j.next) {
+			continue
+		}
+		j.next = j.schedule.Next(now)
+
+		for _, schedule := range schedules {
+			if j.cities != nil && !j.cities[strings.ToLower(schedule.City)] {
+				continue
+			}
+			wg.Add(1)
+			go func(city string) {
+				defer wg.Done()
+				if j.kind == jobForecast {
+					c.collectForecast(ctx, city, j.providers)
+				} else {
+					c.collect(ctx, city, j.providers)
+				}
+			}(schedule.City)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,284 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gometeo/app/internal/provider"
+)
+
+// Состояния коллектора в /status
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // провайдер приостановлен или Kafka не принимает сообщения
+	StatusStalled  = "stalled"  // цикл сбора или отправка стоят дольше StallTimeout
+)
+
+// ProviderStatus — итоги опроса провайдера с момента запуска
+type ProviderStatus struct {
+	Name        string     `json:"name"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	Successes   int64      `json:"successes"`
+	Errors      int64      `json:"errors"`
+	Skipped     int64      `json:"skipped"` // бюджет исчерпан или предохранитель разомкнут
+	Circuit     string     `json:"circuit,omitempty"`
+	RetryAt     *time.Time `json:"circuit_retry_at,omitempty"`
+}
+
+// KafkaStatus — итоги отправки сообщений в Kafka
+type KafkaStatus struct {
+	Connected   bool       `json:"connected"` // последняя отправка прошла
+	LastSent    *time.Time `json:"last_sent,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	Sent        int64      `json:"sent"`
+	Failed      int64      `json:"failed"`
+}
+
+// Status — ответ /status коллектора
+type Status struct {
+	Status    string           `json:"status"`
+	StartedAt time.Time        `json:"started_at"`
+	LastCycle *time.Time       `json:"last_cycle,omitempty"` // такт цикла сбора; пусто в режиме воркера
+	Cities    int              `json:"cities"`
+	Providers []ProviderStatus `json:"providers"`
+	Kafka     KafkaStatus      `json:"kafka"`
+}
+
+// providerStats — счетчики одного провайдера
+type providerStats struct {
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+	successes   int64
+	errors      int64
+	skipped     int64
+}
+
+func (s *providerStats) success(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSuccess = now
+	s.successes++
+}
+
+func (s *providerStats) failure(now time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err.Error()
+	s.lastErrorAt = now
+	s.errors++
+}
+
+func (s *providerStats) skip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skipped++
+}
+
+// kafkaStats — счетчики отправки; failingSince — первая ошибка после
+// последней удачной отправки
+type kafkaStats struct {
+	mu           sync.Mutex
+	lastSent     time.Time
+	lastError    string
+	lastErrorAt  time.Time
+	failingSince time.Time
+	sent         int64
+	failed       int64
+}
+
+func (s *kafkaStats) record(now time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.lastSent = now
+		s.failingSince = time.Time{}
+		s.sent++
+		return
+	}
+	s.lastError = err.Error()
+	s.lastErrorAt = now
+	if s.failingSince.IsZero() {
+		s.failingSince = now
+	}
+	s.failed++
+}
+
+// statusTracker собирает сведения о цикле сбора, провайдерах и Kafka
+type statusTracker struct {
+	startedAt    time.Time
+	stallTimeout time.Duration
+	lastCycle    atomic.Int64 // UnixNano такта цикла сбора; 0 — Run не запущен
+	cities       atomic.Int64
+	kafka        kafkaStats
+
+	mu        sync.RWMutex
+	providers map[string]*providerStats
+}
+
+// SetStallTimeout задает, сколько цикл сбора или отправка в Kafka могут
+// стоять, прежде чем коллектор считается зависшим; 0 — не проверяется
+func (c *Collector) SetStallTimeout(timeout time.Duration) {
+	c.status.stallTimeout = timeout
+}
+
+// stats возвращает счетчики провайдера, создавая их при первом обращении
+func (c *Collector) stats(name string) *providerStats {
+	c.status.mu.RLock()
+	s, ok := c.status.providers[name]
+	c.status.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	c.status.mu.Lock()
+	defer c.status.mu.Unlock()
+	if s, ok = c.status.providers[name]; !ok {
+		s = &providerStats{}
+		c.status.providers[name] = s
+	}
+	return s
+}
+
+// tick отмечает такт цикла сбора: без тактов дольше StallTimeout цикл завис
+func (c *Collector) tick(now time.Time) {
+	c.status.lastCycle.Store(now.UnixNano())
+}
+
+// Status возвращает состояние коллектора
+func (c *Collector) Status() Status {
+	now := time.Now()
+	st := Status{
+		Status:    StatusOK,
+		StartedAt: c.status.startedAt,
+		Cities:    int(c.status.cities.Load()),
+		Providers: make([]ProviderStatus, 0, len(c.providers)),
+	}
+	if ns := c.status.lastCycle.Load(); ns != 0 {
+		st.LastCycle = timePtr(time.Unix(0, ns))
+	}
+
+	for _, p := range c.providers {
+		s := c.stats(p.Name())
+		s.mu.Lock()
+		ps := ProviderStatus{
+			Name:        p.Name(),
+			LastSuccess: timePtr(s.lastSuccess),
+			LastError:   s.lastError,
+			LastErrorAt: timePtr(s.lastErrorAt),
+			Successes:   s.successes,
+			Errors:      s.errors,
+			Skipped:     s.skipped,
+		}
+		s.mu.Unlock()
+		if circuit, ok := provider.Circuit(p); ok {
+			ps.Circuit = circuit.State
+			ps.RetryAt = timePtr(circuit.RetryAt)
+			if circuit.State == provider.CircuitOpen {
+				st.Status = StatusDegraded
+			}
+		}
+		st.Providers = append(st.Providers, ps)
+	}
+
+	k := &c.status.kafka
+	k.mu.Lock()
+	st.Kafka = KafkaStatus{
+		Connected:   k.failingSince.IsZero(),
+		LastSent:    timePtr(k.lastSent),
+		LastError:   k.lastError,
+		LastErrorAt: timePtr(k.lastErrorAt),
+		Sent:        k.sent,
+		Failed:      k.failed,
+	}
+	failingSince := k.failingSince
+	k.mu.Unlock()
+	if !st.Kafka.Connected {
+		st.Status = StatusDegraded
+	}
+
+	if timeout := c.status.stallTimeout; timeout > 0 {
+		loopStuck := st.LastCycle != nil && now.Sub(*st.LastCycle) > timeout
+		kafkaStuck := !failingSince.IsZero() && now.Sub(failingSince) > timeout
+		if loopStuck || kafkaStuck {
+			st.Status = StatusStalled
+		}
+	}
+	return st
+}
+
+// StatusHandler отдает Status; 503, если коллектор завис
+func (c *Collector) StatusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		st := c.Status()
+		writeStatus(w, st.Status, st)
+	}
+}
+
+// HealthzHandler — проба для оркестратора: 200, пока цикл сбора идет и
+// сообщения уходят в Kafka, иначе 503. Сбои провайдеров не делают коллектор
+// больным: перезапуск их не вылечит.
+func (c *Collector) HealthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		st := c.Status()
+		writeStatus(w, st.Status, struct {
+			Status    string     `json:"status"`
+			LastCycle *time.Time `json:"last_cycle,omitempty"`
+			LastSent  *time.Time `json:"last_sent,omitempty"`
+		}{st.Status, st.LastCycle, st.Kafka.LastSent})
+	}
+}
+
+// ServeStatus поднимает на addr сервер с /healthz и /status до отмены ctx;
+// пустой addr — сервер не нужен
+func (c *Collector) ServeStatus(ctx context.Context, addr string) {
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", c.HealthzHandler())
+	mux.Handle("/status", c.StatusHandler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		c.logger.Info("Эндпоинт состояния коллектора запущен", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.logger.Error("Ошибка сервера состояния коллектора", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+}
+
+func writeStatus(w http.ResponseWriter, state string, report any) {
+	code := http.StatusOK
+	if state == StatusStalled {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
+
+// timePtr возвращает nil для нулевого времени, чтобы поле не попало в JSON
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
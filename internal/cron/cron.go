@@ -0,0 +1,251 @@
+// Package cron разбирает расписания в формате crontab: пять полей (минута,
+// час, день месяца, месяц, день недели) или макросы @hourly, @daily и т.п.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// macros — сокращения crontab
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field — допустимые значения поля и названия вместо чисел
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var fields = [5]field{
+	{name: "минута", min: 0, max: 59},
+	{name: "час", min: 0, max: 23},
+	{name: "день месяца", min: 1, max: 31},
+	{name: "месяц", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	// 7 — тоже воскресенье
+	{name: "день недели", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// Schedule — разобранное расписание; значения полей хранятся битовыми масками
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// Ограничены ли дни месяца и недели: если оба, подходит любой из них
+	domRestricted, dowRestricted bool
+	// Минута и час заданы без "*": такое задание при переводе часов
+	// выполняется ровно один раз, см. Next
+	fixedTime bool
+	spec      string
+}
+
+// Parse разбирает выражение вроде "*/5 * * * *" или "@hourly"
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	expr := spec
+	if strings.HasPrefix(expr, "@") {
+		macro, ok := macros[strings.ToLower(expr)]
+		if !ok {
+			return Schedule{}, fmt.Errorf("неизвестный макрос: %s", spec)
+		}
+		expr = macro
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return Schedule{}, fmt.Errorf("ожидается 5 полей, получено %d: %s", len(parts), spec)
+	}
+
+	var masks [5]uint64
+	for i, part := range parts {
+		mask, err := fields[i].parse(part)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("поле %q: %w", fields[i].name, err)
+		}
+		masks[i] = mask
+	}
+	// Воскресенье как 7 приводится к 0
+	if masks[4]&(1<<7) != 0 {
+		masks[4] = masks[4]&^(1<<7) | 1
+	}
+
+	return Schedule{
+		minute:        masks[0],
+		hour:          masks[1],
+		dom:           masks[2],
+		month:         masks[3],
+		dow:           masks[4],
+		// Как в crontab, поле с "*" в начале ("*/2") не ограничивает день
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+		fixedTime:     !strings.HasPrefix(parts[0], "*") && !strings.HasPrefix(parts[1], "*"),
+		spec:          spec,
+	}, nil
+}
+
+// String возвращает исходное выражение
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// Next возвращает первую минуту строго после after, подходящую под расписание,
+// в часовом поясе after; нулевое время — совпадений нет в ближайшие 5 лет
+// (например, 30 февраля).
+//
+// Перевод часов обрабатывается как в cron: задания с "*" в минуте или часе
+// идут по реальному времени, а задания на фиксированное время выполняются
+// один раз — в повторившийся час только в первый проход, а время из
+// пропущенного часа — в первую минуту после перевода.
+func (s Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.fixedTime && s.skippedByGap(t) {
+			return t
+		}
+		if s.fixedTime && repeated(t) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// skippedByGap сообщает, что t — первая минута после перевода часов вперед
+// и расписание совпадало с одной из пропущенных минут
+func (s Schedule) skippedByGap(t time.Time) bool {
+	prev := t.Add(-time.Minute)
+	_, before := prev.Zone()
+	_, offset := t.Zone()
+	if offset <= before {
+		return false
+	}
+	// Пропущенное настенное время: от prev+1 минута до t, не включая t
+	wall := time.Date(prev.Year(), prev.Month(), prev.Day(), prev.Hour(), prev.Minute(), 0, 0, time.UTC)
+	for range (offset - before) / 60 {
+		wall = wall.Add(time.Minute)
+		if has(s.hour, wall.Hour()) && has(s.minute, wall.Minute()) {
+			return true
+		}
+	}
+	return false
+}
+
+// repeated сообщает, что настенное время t уже наступало до перевода часов назад
+func repeated(t time.Time) bool {
+	_, offset := t.Zone()
+	_, earlier := t.Add(-time.Hour).Zone()
+	if earlier <= offset {
+		return false
+	}
+	prev := t.Add(-time.Duration(earlier-offset) * time.Second)
+	return prev.Hour() == t.Hour() && prev.Minute() == t.Minute()
+}
+
+// dayMatches сравнивает день по правилу crontab: если ограничены и день
+// месяца, и день недели, достаточно совпадения одного из них
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func has(mask uint64, value int) bool {
+	return mask&(1<<uint(value)) != 0
+}
+
+// parse разбирает поле: списки через запятую из *, чисел, диапазонов и шагов
+func (f field) parse(part string) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(part, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("неверный шаг: %s", item)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			loStr, hiStr, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(loStr); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(hiStr); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("неверный диапазон: %s", item)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			// "5/15" — от 5 до конца с шагом 15
+			if !hasStep {
+				hi = lo
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// value разбирает число или название в пределах поля
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("неверное значение: %s", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("значение %d вне диапазона %d–%d", v, f.min, f.max)
+	}
+	return v, nil
+}
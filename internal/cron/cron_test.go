@@ -0,0 +1,107 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gometeo/app/internal/cron"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{"* * * * *", false},
+		{"*/5 * * * *", false},
+		{"1,2,3-5/2 * * * *", false},
+		{"0 9-17 * * mon-fri", false},
+		{"0 0 1 JAN,jul *", false},
+		{"0 0 * * 7", false},
+		{"@hourly", false},
+		{"@DAILY", false},
+		{"  @weekly  ", false},
+		{"", true},
+		{"* * * *", true},
+		{"* * * * * *", true},
+		{"@reboot", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 8", true},
+		{"*/0 * * * *", true},
+		{"*/x * * * *", true},
+		{"10-5 * * * *", true},
+		{"* * * foo *", true},
+		{"a * * * *", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := cron.Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q): ошибка %v, ожидалась ошибка: %v", tt.spec, err, tt.wantErr)
+			}
+			if err == nil && s.String() == "" {
+				t.Errorf("Parse(%q): пустое выражение в String", tt.spec)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("нет базы часовых поясов: %v", err)
+	}
+	utc := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+	// Зона задается смещением: настенное время при переводе часов неоднозначно
+	local := func(offset int, year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour-offset, min, 0, 0, time.UTC).In(berlin)
+	}
+
+	tests := []struct {
+		name  string
+		spec  string
+		after time.Time
+		want  time.Time
+	}{
+		{"шаг минут", "*/15 * * * *", utc(2026, 1, 15, 10, 7).Add(30 * time.Second), utc(2026, 1, 15, 10, 15)},
+		{"строго после", "*/15 * * * *", utc(2026, 1, 15, 10, 15), utc(2026, 1, 15, 10, 30)},
+		{"шаг от значения", "5/20 * * * *", utc(2026, 1, 15, 10, 6), utc(2026, 1, 15, 10, 25)},
+		{"диапазон с шагом", "0 9-17/4 * * *", utc(2026, 1, 15, 13, 0), utc(2026, 1, 15, 17, 0)},
+		{"диапазон на следующий день", "0 9-17/4 * * *", utc(2026, 1, 15, 17, 0), utc(2026, 1, 16, 9, 0)},
+		{"список", "0 6,18 * * *", utc(2026, 1, 15, 7, 0), utc(2026, 1, 15, 18, 0)},
+		{"названия месяцев", "0 0 1 jan-mar *", utc(2026, 3, 1, 0, 0), utc(2027, 1, 1, 0, 0)},
+		{"31 число пропускает короткие месяцы", "0 0 31 * *", utc(2026, 4, 15, 0, 0), utc(2026, 5, 31, 0, 0)},
+		{"конец месяца", "@monthly", utc(2026, 1, 31, 23, 59), utc(2026, 2, 1, 0, 0)},
+		{"конец года", "30 23 31 12 *", utc(2026, 12, 31, 23, 30), utc(2027, 12, 31, 23, 30)},
+		{"29 февраля", "0 0 29 2 *", utc(2026, 3, 1, 0, 0), utc(2028, 2, 29, 0, 0)},
+		{"30 февраля", "0 0 30 2 *", utc(2026, 1, 1, 0, 0), time.Time{}},
+		{"воскресенье как 7", "0 0 * * 7", utc(2026, 6, 1, 0, 0), utc(2026, 6, 7, 0, 0)},
+		{"день месяца или недели", "0 12 15 * mon", utc(2026, 6, 2, 0, 0), utc(2026, 6, 8, 12, 0)},
+		{"шаг в дне месяца не ограничивает день", "0 0 */2 * mon", utc(2026, 6, 1, 0, 0), utc(2026, 6, 15, 0, 0)},
+		{"шаг в дне недели не ограничивает день", "0 0 1 * */2", utc(2026, 6, 1, 0, 0), utc(2026, 8, 1, 0, 0)},
+
+		// Europe/Berlin: 29.03.2026 02:00 CET -> 03:00 CEST, 25.10.2026 03:00 CEST -> 02:00 CET
+		{"весной шаг идет по реальному времени", "*/30 * * * *", local(1, 2026, 3, 29, 1, 45), local(2, 2026, 3, 29, 3, 0)},
+		{"пропущенный час выполняется после перевода", "30 2 * * *", local(1, 2026, 3, 29, 0, 0), local(2, 2026, 3, 29, 3, 0)},
+		{"после пропущенного часа — следующий день", "30 2 * * *", local(2, 2026, 3, 29, 3, 0), local(2, 2026, 3, 30, 2, 30)},
+		{"осенью шаг идет по реальному времени", "*/30 * * * *", local(2, 2026, 10, 25, 2, 30), local(1, 2026, 10, 25, 2, 0)},
+		{"повторившийся час не выполняется дважды", "30 2 * * *", local(2, 2026, 10, 25, 2, 30), local(1, 2026, 10, 26, 2, 30)},
+		{"час после повтора", "0 3 * * *", local(2, 2026, 10, 25, 0, 0), local(1, 2026, 10, 25, 3, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := cron.Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) для %q = %s, ожидалось %s", tt.after, tt.spec, got, tt.want)
+			}
+		})
+	}
+}
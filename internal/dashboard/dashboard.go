@@ -0,0 +1,30 @@
+// Package dashboard отдает встроенную страницу мониторинга: текущая погода по городам,
+// свежесть данных, доля попаданий в кэш и состояние зависимостей.
+// Страница сама опрашивает JSON-эндпоинты API и /metrics.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler отдает index.html и статические файлы панели
+func Handler() http.Handler {
+	root, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // каталог встроен при сборке и всегда существует
+	}
+	files := http.FileServer(http.FS(root))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Общий middleware проставляет application/json; FileServer не переопределяет
+		// уже заданный тип, поэтому сбрасываем его и даем определить по расширению
+		w.Header().Del("Content-Type")
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
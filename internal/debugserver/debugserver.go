@@ -0,0 +1,166 @@
+// Package debugserver поднимает на отдельном внутреннем порту net/http/pprof
+// и статистику рантайма в стиле expvar. Доступ — только по JWT администратора,
+// чтобы профили и дампы горутин не утекли, даже если порт оказался открыт.
+package debugserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/auth"
+	"github.com/gometeo/app/internal/buildinfo"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/ipfilter"
+)
+
+var (
+	started     = time.Now()
+	publishOnce sync.Once
+)
+
+// RuntimeStats — снимок рантайма для /debug/runtime
+type RuntimeStats struct {
+	Service       string         `json:"service"`
+	Build         buildinfo.Info `json:"build"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	Goroutines    int            `json:"goroutines"`
+	GOMAXPROCS    int            `json:"gomaxprocs"`
+	NumCPU        int            `json:"num_cpu"`
+	CgoCalls      int64          `json:"cgo_calls"`
+	HeapAlloc     uint64         `json:"heap_alloc_bytes"`
+	HeapInuse     uint64         `json:"heap_inuse_bytes"`
+	HeapObjects   uint64         `json:"heap_objects"`
+	StackInuse    uint64         `json:"stack_inuse_bytes"`
+	Sys           uint64         `json:"sys_bytes"`
+	TotalAlloc    uint64         `json:"total_alloc_bytes"`
+	NumGC         uint32         `json:"num_gc"`
+	PauseTotal    float64        `json:"gc_pause_total_seconds"`
+	LastGC        *time.Time     `json:"last_gc,omitempty"`
+}
+
+func readStats(service string) RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := RuntimeStats{
+		Service:       service,
+		Build:         buildinfo.Get(),
+		UptimeSeconds: int64(time.Since(started).Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumCPU:        runtime.NumCPU(),
+		CgoCalls:      runtime.NumCgoCall(),
+		HeapAlloc:     mem.HeapAlloc,
+		HeapInuse:     mem.HeapInuse,
+		HeapObjects:   mem.HeapObjects,
+		StackInuse:    mem.StackInuse,
+		Sys:           mem.Sys,
+		TotalAlloc:    mem.TotalAlloc,
+		NumGC:         mem.NumGC,
+		PauseTotal:    time.Duration(mem.PauseTotalNs).Seconds(),
+	}
+	if mem.LastGC > 0 {
+		last := time.Unix(0, int64(mem.LastGC)).UTC()
+		stats.LastGC = &last
+	}
+	return stats
+}
+
+// Handler возвращает маршруты отладки, закрытые проверкой подсети клиента
+// и JWT администратора; filter nil — подсети не ограничены
+func Handler(service string, tokens *auth.Tokens, filter *ipfilter.Filter) http.Handler {
+	// expvar глобален: переменные публикуются один раз на процесс
+	publishOnce.Do(func() {
+		expvar.Publish("runtime", expvar.Func(func() any { return readStats(service) }))
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(readStats(service))
+	})
+	return requireNetwork(filter, requireAdmin(tokens, mux))
+}
+
+// requireNetwork пропускает только адреса из разрешенных подсетей
+func requireNetwork(filter *ipfilter.Filter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !filter.Allows(filter.ClientIP(r)) {
+			http.Error(w, "адрес клиента не входит в разрешенные подсети", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAdmin пропускает только запросы с действующим токеном роли admin
+func requireAdmin(tokens *auth.Tokens, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := auth.BearerToken(r.Header.Get(auth.HeaderAuthorization))
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "требуется токен администратора", http.StatusUnauthorized)
+			return
+		}
+		principal, err := tokens.Parse(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if !principal.Role.Allows(auth.RoleAdmin) {
+			http.Error(w, "нужна роль admin", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Serve запускает сервер отладки на cfg.DebugAddr до отмены ctx. Пустой адрес
+// выключает сервер; без JWT_SECRET он тоже не поднимается, потому что
+// проверить администратора нечем.
+func Serve(ctx context.Context, cfg *config.Config, service string, logger *slog.Logger) {
+	if cfg.DebugAddr == "" {
+		return
+	}
+	tokens := auth.NewTokens(cfg)
+	if tokens == nil {
+		logger.Warn("Сервер отладки не запущен: без JWT_SECRET доступ администратора не проверить", "addr", cfg.DebugAddr)
+		return
+	}
+	filter, err := ipfilter.FromConfig(cfg)
+	if err != nil {
+		logger.Error("Сервер отладки не запущен: неверные подсети доступа", "addr", cfg.DebugAddr, "error", err)
+		return
+	}
+
+	// Без WriteTimeout: профиль CPU и трасса пишутся столько секунд, сколько запрошено
+	server := &http.Server{Addr: cfg.DebugAddr, Handler: Handler(service, tokens, filter), ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		logger.Info("Сервер отладки запущен", "addr", cfg.DebugAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Ошибка сервера отладки", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+}
@@ -0,0 +1,65 @@
+package dlq
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/bus"
+)
+
+// Заголовки, которыми помечается сообщение в DLQ
+const (
+	HeaderReason         = "x-dlq-reason"
+	HeaderError          = "x-dlq-error"
+	HeaderOriginalTopic  = "x-dlq-original-topic"
+	HeaderOriginalPart   = "x-dlq-original-partition"
+	HeaderOriginalOffset = "x-dlq-original-offset"
+)
+
+// Причины попадания сообщения в DLQ
+const (
+	ReasonPanic = "panic"
+)
+
+// Publisher отправляет необработанные сообщения в отдельный топик
+type Publisher struct {
+	producer bus.Publisher
+	topic    string
+}
+
+func NewPublisher(producer bus.Publisher, topic string) *Publisher {
+	return &Publisher{producer: producer, topic: topic}
+}
+
+// Send копирует исходное сообщение в DLQ вместе с его заголовками и причиной
+func (p *Publisher) Send(msg *sarama.ConsumerMessage, reason string, cause string) error {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+5)
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers = append(headers, *h)
+		}
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(HeaderReason), Value: []byte(reason)},
+		sarama.RecordHeader{Key: []byte(HeaderError), Value: []byte(cause)},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalTopic), Value: []byte(msg.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalPart), Value: []byte(strconv.Itoa(int(msg.Partition)))},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalOffset), Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+
+	_, _, err := p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   p.topic,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка отправки в DLQ %s: %w", p.topic, err)
+	}
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.producer.Close()
+}
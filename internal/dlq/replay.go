@@ -0,0 +1,153 @@
+package dlq
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// HeaderReplayed помечает сообщение, возвращенное из DLQ в исходный топик
+const HeaderReplayed = "x-dlq-replayed"
+
+// Replayer возвращает сообщения из DLQ в исходные топики.
+// Прогресс хранится как смещения группы, повторный запуск продолжает с места остановки.
+type Replayer struct {
+	client   sarama.Client
+	producer sarama.SyncProducer
+	topic    string
+	group    string
+	logger   *slog.Logger
+}
+
+func NewReplayer(client sarama.Client, producer sarama.SyncProducer, topic, group string, logger *slog.Logger) *Replayer {
+	return &Replayer{client: client, producer: producer, topic: topic, group: group, logger: logger}
+}
+
+// Replay переотправляет не более limit сообщений (0 — без ограничения),
+// накопленных в DLQ к моменту вызова. Возвращает число переотправленных.
+func (r *Replayer) Replay(ctx context.Context, limit int) (int, error) {
+	partitions, err := r.client.Partitions(r.topic)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения партиций %s: %w", r.topic, err)
+	}
+
+	offsets, err := sarama.NewOffsetManagerFromClient(r.group, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания менеджера смещений: %w", err)
+	}
+	defer offsets.Close()
+
+	consumer, err := sarama.NewConsumerFromClient(r.client)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	replayed := 0
+	for _, partition := range partitions {
+		if limit > 0 && replayed >= limit {
+			break
+		}
+		n, err := r.replayPartition(ctx, consumer, offsets, partition, limit-replayed, limit > 0)
+		replayed += n
+		if err != nil {
+			return replayed, err
+		}
+	}
+	return replayed, nil
+}
+
+func (r *Replayer) replayPartition(ctx context.Context, consumer sarama.Consumer, offsets sarama.OffsetManager, partition int32, remaining int, limited bool) (int, error) {
+	pom, err := offsets.ManagePartition(r.topic, partition)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка смещений партиции %d: %w", partition, err)
+	}
+	defer pom.Close()
+
+	// Граница фиксируется заранее: то, что попадет в DLQ во время переотправки, ждет следующего запуска
+	end, err := r.client.GetOffset(r.topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения смещения партиции %d: %w", partition, err)
+	}
+
+	next, _ := pom.NextOffset()
+	if next < 0 {
+		if next, err = r.client.GetOffset(r.topic, partition, sarama.OffsetOldest); err != nil {
+			return 0, fmt.Errorf("ошибка получения смещения партиции %d: %w", partition, err)
+		}
+	}
+	if next >= end {
+		return 0, nil
+	}
+
+	pc, err := consumer.ConsumePartition(r.topic, partition, next)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения партиции %d: %w", partition, err)
+	}
+	defer pc.Close()
+
+	replayed := 0
+	for {
+		if limited && replayed >= remaining {
+			return replayed, nil
+		}
+
+		var msg *sarama.ConsumerMessage
+		select {
+		case <-ctx.Done():
+			return replayed, ctx.Err()
+		case msg = <-pc.Messages():
+		}
+		if msg == nil {
+			return replayed, nil
+		}
+
+		if err := r.resend(msg); err != nil {
+			return replayed, err
+		}
+		pom.MarkOffset(msg.Offset+1, "")
+		replayed++
+
+		if msg.Offset+1 >= end {
+			return replayed, nil
+		}
+	}
+}
+
+// resend отправляет сообщение в исходный топик без служебных заголовков DLQ
+func (r *Replayer) resend(msg *sarama.ConsumerMessage) error {
+	var original string
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+1)
+	for _, h := range msg.Headers {
+		if h == nil {
+			continue
+		}
+		key := string(h.Key)
+		if key == HeaderOriginalTopic {
+			original = string(h.Value)
+		}
+		if strings.HasPrefix(key, "x-dlq-") {
+			continue
+		}
+		headers = append(headers, *h)
+	}
+	if original == "" {
+		r.logger.Warn("Сообщение DLQ без исходного топика пропущено", "partition", msg.Partition, "offset", msg.Offset)
+		return nil
+	}
+	headers = append(headers, sarama.RecordHeader{Key: []byte(HeaderReplayed), Value: []byte("true")})
+
+	_, _, err := r.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   original,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка переотправки в %s: %w", original, err)
+	}
+	return nil
+}
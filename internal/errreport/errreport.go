@@ -0,0 +1,81 @@
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gometeo/app/internal/config"
+)
+
+// Reporter отправляет непредвиденные ошибки и паники во внешнюю систему агрегации
+type Reporter interface {
+	CaptureError(ctx context.Context, err error, tags map[string]string)
+	CapturePanic(ctx context.Context, recovered any, tags map[string]string)
+	Flush(timeout time.Duration)
+}
+
+// New создает репортер по настройкам; без DSN возвращается пустая реализация
+func New(cfg *config.Config, service string, logger *slog.Logger) (Reporter, error) {
+	if cfg.SentryDSN == "" {
+		return Nop{}, nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.SentryDSN,
+		Environment:      cfg.Environment,
+		ServerName:       service,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации Sentry: %w", err)
+	}
+
+	logger.Info("Отправка ошибок в Sentry включена", "environment", cfg.Environment)
+	return &sentryReporter{service: service}, nil
+}
+
+// Nop ничего не отправляет
+type Nop struct{}
+
+func (Nop) CaptureError(context.Context, error, map[string]string) {}
+func (Nop) CapturePanic(context.Context, any, map[string]string)   {}
+func (Nop) Flush(time.Duration)                                    {}
+
+type sentryReporter struct {
+	service string
+}
+
+func (r *sentryReporter) hub(ctx context.Context) *sentry.Hub {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		return hub
+	}
+	return sentry.CurrentHub().Clone()
+}
+
+func (r *sentryReporter) CaptureError(ctx context.Context, err error, tags map[string]string) {
+	if err == nil {
+		return
+	}
+	hub := r.hub(ctx)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("service", r.service)
+		scope.SetTags(tags)
+		hub.CaptureException(err)
+	})
+}
+
+func (r *sentryReporter) CapturePanic(ctx context.Context, recovered any, tags map[string]string) {
+	hub := r.hub(ctx)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("service", r.service)
+		scope.SetTags(tags)
+		hub.RecoverWithContext(ctx, recovered)
+	})
+}
+
+func (r *sentryReporter) Flush(timeout time.Duration) {
+	sentry.Flush(timeout)
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// pathVars — переменные шаблона пути объекта
+type pathVars struct {
+	Date  string // 2006-01-02
+	Year  string
+	Month string
+	Day   string
+	City  string // безопасное для пути название города
+}
+
+// Exporter выгружает историю наблюдений за сутки в S3-совместимое хранилище
+type Exporter struct {
+	store  *storage.WeatherStorage
+	client *minio.Client
+	bucket string
+	path   *template.Template
+	logger *slog.Logger
+}
+
+// New создает экспортер по настройкам S3 из конфигурации
+func New(cfg *config.Config, store *storage.WeatherStorage, logger *slog.Logger) (*Exporter, error) {
+	path, err := template.New("path").Option("missingkey=error").Parse(cfg.ExportPathTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный шаблон пути выгрузки: %w", err)
+	}
+
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки S3 клиента: %w", err)
+	}
+
+	return &Exporter{
+		store:  store,
+		client: client,
+		bucket: cfg.S3Bucket,
+		path:   path,
+		logger: logger,
+	}, nil
+}
+
+// ExportDay выгружает замеры за сутки day (UTC). Строки раскладываются по объектам
+// согласно шаблону пути: с {{.City}} получится файл на каждый город.
+// Повторная выгрузка того же дня перезаписывает объекты.
+func (e *Exporter) ExportDay(ctx context.Context, day time.Time) error {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	history, err := e.store.ListHistory(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		e.logger.InfoContext(ctx, "Нет данных для выгрузки", "date", from.Format(time.DateOnly))
+		return nil
+	}
+
+	objects := make(map[string][]model.WeatherData)
+	for _, d := range history {
+		key, err := e.objectKey(from, d.City)
+		if err != nil {
+			return err
+		}
+		objects[key] = append(objects[key], d)
+	}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		body, err := encodeParquet(objects[key])
+		if err != nil {
+			return err
+		}
+
+		_, err = e.client.PutObject(ctx, e.bucket, key, bytes.NewReader(body), int64(len(body)),
+			minio.PutObjectOptions{ContentType: "application/vnd.apache.parquet"})
+		if err != nil {
+			return fmt.Errorf("ошибка загрузки %s в S3: %w", key, err)
+		}
+		e.logger.InfoContext(ctx, "Файл выгружен",
+			"bucket", e.bucket,
+			"key", key,
+			"rows", len(objects[key]),
+			"bytes", len(body))
+	}
+	return nil
+}
+
+// Ping проверяет доступность бакета
+func (e *Exporter) Ping(ctx context.Context) error {
+	ok, err := e.client.BucketExists(ctx, e.bucket)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("бакет %s не найден", e.bucket)
+	}
+	return nil
+}
+
+func (e *Exporter) objectKey(day time.Time, city string) (string, error) {
+	vars := pathVars{
+		Date:  day.Format(time.DateOnly),
+		Year:  day.Format("2006"),
+		Month: day.Format("01"),
+		Day:   day.Format("02"),
+		City:  pathSafe(city),
+	}
+
+	var buf bytes.Buffer
+	if err := e.path.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("ошибка шаблона пути выгрузки: %w", err)
+	}
+	return strings.TrimPrefix(buf.String(), "/"), nil
+}
+
+// pathSafe приводит название города к виду, пригодному для сегмента пути
+func pathSafe(city string) string {
+	city = strings.ToLower(strings.TrimSpace(city))
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '/', '\\', '=', '?', '#', '%':
+			return '_'
+		}
+		return r
+	}, city)
+}
+
+// NextRun возвращает ближайший момент ежедневного запуска в at ("HH:MM", UTC)
+func NextRun(now time.Time, at string) (time.Time, error) {
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректное время запуска %q: %w", at, err)
+	}
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
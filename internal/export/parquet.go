@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// row — строка Parquet-файла истории наблюдений
+type row struct {
+	City          string  `parquet:"name=city, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Temperature   float64 `parquet:"name=temperature, type=DOUBLE"`
+	Condition     string  `parquet:"name=condition, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ConditionCode string  `parquet:"name=condition_code, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Provider      string  `parquet:"name=provider, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ObservedAt    int64   `parquet:"name=observed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
+
+// encodeParquet сериализует замеры в Parquet со сжатием Snappy
+func encodeParquet(data []model.WeatherData) ([]byte, error) {
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&buf, new(row), 1)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания Parquet writer: %w", err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	for _, d := range data {
+		r := row{
+			City:          d.City,
+			Temperature:   d.Temp,
+			Condition:     d.Condition,
+			ConditionCode: string(d.ConditionCode),
+			Provider:      d.Provider,
+			ObservedAt:    d.Timestamp.UTC().UnixMilli(),
+		}
+		if err := pw.Write(r); err != nil {
+			return nil, fmt.Errorf("ошибка записи строки Parquet: %w", err)
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		return nil, fmt.Errorf("ошибка завершения Parquet файла: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,177 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// ErrNotFound — место не найдено ни в справочнике, ни у провайдера
+var ErrNotFound = errors.New("место не найдено")
+
+// maxMemo ограничивает число запросов, запомненных в памяти процесса
+const maxMemo = 10000
+
+// Provider ищет место по произвольному названию
+type Provider interface {
+	Search(ctx context.Context, query string) (*model.City, error)
+}
+
+// Store — справочник городов и сохраненные результаты геокодирования;
+// реализуется storage.WeatherStorage
+type Store interface {
+	GetCity(ctx context.Context, name string) (*model.City, error)
+	GetGeocoded(ctx context.Context, query string) (*model.City, error)
+	SaveGeocoded(ctx context.Context, query string, city model.City) error
+}
+
+var _ Store = (*storage.WeatherStorage)(nil)
+
+// Resolver приводит произвольное название ("NYC", "Москва") к городу справочника.
+// Порядок поиска: справочник городов и синонимов, Redis, таблица geocode_cache,
+// внешний провайдер. Хранилище, кэш и провайдер необязательны.
+type Resolver struct {
+	provider Provider
+	store    Store
+	cache    *cache.WeatherCache
+	ttl      time.Duration
+	logger   *slog.Logger
+
+	mu   sync.Mutex
+	memo map[string]model.City // для сервисов без БД и Redis
+}
+
+func New(provider Provider, store Store, cache *cache.WeatherCache, ttl time.Duration, logger *slog.Logger) *Resolver {
+	return &Resolver{
+		provider: provider,
+		store:    store,
+		cache:    cache,
+		ttl:      ttl,
+		logger:   logger,
+		memo:     make(map[string]model.City),
+	}
+}
+
+// FromConfig создает резолвер; при выключенном геокодировании ищет только в справочнике
+func FromConfig(cfg *config.Config, store Store, cache *cache.WeatherCache, logger *slog.Logger) *Resolver {
+	var provider Provider
+	if cfg.GeocodeEnabled && cfg.GeocodeURL != "" {
+		provider = NewNominatim(cfg.GeocodeURL, cfg.GeocodeUserAgent)
+	}
+	return New(provider, store, cache, cfg.GeocodeCacheTTL, logger)
+}
+
+// Resolve возвращает канонический город для запроса
+func (r *Resolver) Resolve(ctx context.Context, query string) (*model.City, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrNotFound
+	}
+	key := strings.ToLower(query)
+
+	r.mu.Lock()
+	if city, ok := r.memo[key]; ok {
+		r.mu.Unlock()
+		return &city, nil
+	}
+	r.mu.Unlock()
+
+	if r.store != nil {
+		if city, err := r.store.GetCity(ctx, query); err == nil {
+			return r.remember(key, city), nil
+		}
+	}
+
+	if r.cache != nil {
+		city, err := r.cache.GetPlace(ctx, cache.GeocodeKey(query))
+		if err != nil {
+			r.logger.WarnContext(ctx, "Ошибка чтения кэша геокодирования", "query", query, "error", err)
+		}
+		if city != nil {
+			return r.remember(key, city), nil
+		}
+	}
+
+	if r.store != nil {
+		city, err := r.store.GetGeocoded(ctx, query)
+		if err != nil {
+			r.logger.WarnContext(ctx, "Ошибка чтения сохраненного геокодирования", "query", query, "error", err)
+		}
+		if city != nil {
+			r.cachePlace(ctx, query, *city)
+			return r.remember(key, city), nil
+		}
+	}
+
+	if r.provider == nil {
+		return nil, ErrNotFound
+	}
+
+	city, err := r.provider.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	r.logger.InfoContext(ctx, "Место найдено геокодером", "query", query, "city", city.Name, "country", city.Country)
+
+	if r.store != nil {
+		if err := r.store.SaveGeocoded(ctx, query, *city); err != nil {
+			r.logger.WarnContext(ctx, "Не удалось сохранить геокодирование", "query", query, "error", err)
+		}
+	}
+	r.cachePlace(ctx, query, *city)
+	return r.remember(key, city), nil
+}
+
+// Canonical возвращает каноническое название или исходную строку, если место не найдено
+func (r *Resolver) Canonical(ctx context.Context, query string) string {
+	if r == nil {
+		return query
+	}
+	city, err := r.Resolve(ctx, query)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			r.logger.WarnContext(ctx, "Ошибка геокодирования", "query", query, "error", err)
+		}
+		return query
+	}
+	return city.Name
+}
+
+func (r *Resolver) cachePlace(ctx context.Context, query string, city model.City) {
+	if r.cache == nil {
+		return
+	}
+	if err := r.cache.SetPlace(ctx, cache.GeocodeKey(query), city, r.ttl); err != nil {
+		r.logger.WarnContext(ctx, "Не удалось закэшировать геокодирование", "query", query, "error", err)
+	}
+}
+
+// Seed заранее кладет в память известные города вместе с синонимами
+func (r *Resolver) Seed(cities []model.City) {
+	for i := range cities {
+		r.remember(strings.ToLower(cities[i].Name), &cities[i])
+		for _, alias := range cities[i].Aliases {
+			r.remember(strings.ToLower(alias), &cities[i])
+		}
+	}
+}
+
+// remember держит результат в памяти процесса: справочник меняется редко.
+// При переполнении память сбрасывается целиком, дальше помогают Redis и БД.
+func (r *Resolver) remember(key string, city *model.City) *model.City {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.memo) >= maxMemo {
+		r.memo = make(map[string]model.City)
+	}
+	r.memo[key] = *city
+	return city
+}
@@ -0,0 +1,107 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// Nominatim ищет места через API OpenStreetMap Nominatim или совместимый сервис.
+// Публичный сервер допускает не больше одного запроса в секунду.
+type Nominatim struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+func NewNominatim(baseURL, userAgent string) *Nominatim {
+	return &Nominatim{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		userAgent: userAgent,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type nominatimPlace struct {
+	Lat     string `json:"lat"`
+	Lon     string `json:"lon"`
+	Name    string `json:"name"`
+	Address struct {
+		City        string `json:"city"`
+		Town        string `json:"town"`
+		Village     string `json:"village"`
+		CountryCode string `json:"country_code"`
+	} `json:"address"`
+}
+
+func (n *Nominatim) Search(ctx context.Context, query string) (*model.City, error) {
+	params := url.Values{
+		"q":               {query},
+		"format":          {"jsonv2"},
+		"limit":           {"1"},
+		"addressdetails":  {"1"},
+		"accept-language": {"en"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("User-Agent", n.userAgent)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к геокодеру: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("геокодер вернул %d", resp.StatusCode)
+	}
+
+	var places []nominatimPlace
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ответа геокодера: %w", err)
+	}
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+
+	p := places[0]
+	lat, err := strconv.ParseFloat(p.Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная широта %q: %w", p.Lat, err)
+	}
+	lon, err := strconv.ParseFloat(p.Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная долгота %q: %w", p.Lon, err)
+	}
+
+	name := firstNonEmpty(p.Address.City, p.Address.Town, p.Address.Village, p.Name)
+	if name == "" {
+		return nil, ErrNotFound
+	}
+
+	return &model.City{
+		Name:    name,
+		Country: strings.ToUpper(p.Address.CountryCode),
+		Lat:     lat,
+		Lon:     lon,
+	}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
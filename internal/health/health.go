@@ -0,0 +1,244 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Статусы компонентов и сервиса в целом
+const (
+	StatusOK        = "ok"
+	StatusDegraded  = "degraded"
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+)
+
+// CheckFunc проверяет доступность одной зависимости
+type CheckFunc func(ctx context.Context) error
+
+// DetailFunc проверяет зависимость и возвращает сведения о ней для отчета:
+// память Redis, время последней записи и т.п. Сведения при ошибке не выводятся.
+type DetailFunc func(ctx context.Context) (map[string]string, error)
+
+// ComponentStatus — результат проверки одной зависимости
+type ComponentStatus struct {
+	Status    string            `json:"status"`
+	LatencyMs int64             `json:"latency_ms"`
+	Error     string            `json:"error,omitempty"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Optional  bool              `json:"optional,omitempty"` // сбой не снимает готовность
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// Report — стандартный ответ health-эндпоинта всех сервисов. Status: ok — все
+// компоненты здоровы, degraded — сбой только у необязательных, unhealthy — сбой
+// обязательного компонента.
+type Report struct {
+	Service    string                     `json:"service"`
+	Status     string                     `json:"status"`
+	Time       string                     `json:"time"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+type check struct {
+	name string
+	fn   DetailFunc
+
+	mu     sync.Mutex
+	last   ComponentStatus
+	cached bool
+}
+
+// Registry хранит именованные проверки, выполняет их с таймаутом
+// и кэширует результаты, чтобы частые пробы не нагружали зависимости.
+type Registry struct {
+	service  string
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu       sync.RWMutex
+	checks   []*check
+	optional map[string]bool
+}
+
+func New(service string, timeout, cacheTTL time.Duration) *Registry {
+	return &Registry{service: service, timeout: timeout, cacheTTL: cacheTTL}
+}
+
+// Register добавляет проверку зависимости под именем name
+func (r *Registry) Register(name string, fn CheckFunc) {
+	r.RegisterDetailed(name, func(ctx context.Context) (map[string]string, error) {
+		return nil, fn(ctx)
+	})
+}
+
+// RegisterDetailed добавляет проверку, которая дополняет отчет сведениями о зависимости
+func (r *Registry) RegisterDetailed(name string, fn DetailFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &check{name: name, fn: fn})
+}
+
+// RegisterOptional добавляет необязательную проверку: ее сбой дает degraded,
+// но не снимает готовность. Вызывается после SetOptional.
+func (r *Registry) RegisterOptional(name string, fn DetailFunc) {
+	r.RegisterDetailed(name, fn)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Новая карта: Run читает прежнюю без блокировки
+	optional := make(map[string]bool, len(r.optional)+1)
+	for k := range r.optional {
+		optional[k] = true
+	}
+	optional[name] = true
+	r.optional = optional
+}
+
+// SetOptional задает компоненты, сбой которых не снимает готовность сервиса:
+// например, без Redis API отвечает из БД, а без БД — уже нет
+func (r *Registry) SetOptional(names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.optional = make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			r.optional[name] = true
+		}
+	}
+}
+
+// Run выполняет все проверки параллельно и собирает отчет
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]*check, len(r.checks))
+	copy(checks, r.checks)
+	optional := r.optional
+	r.mu.RUnlock()
+
+	report := Report{
+		Service:    r.service,
+		Status:     StatusOK,
+		Time:       time.Now().Format(time.RFC3339),
+		Components: make(map[string]ComponentStatus, len(checks)),
+	}
+
+	results := make([]ComponentStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		result := results[i]
+		result.Optional = optional[c.name]
+		report.Components[c.name] = result
+
+		switch {
+		case result.Status == StatusHealthy:
+		case result.Optional:
+			if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		default:
+			report.Status = StatusUnhealthy
+		}
+	}
+	return report
+}
+
+func (r *Registry) runCheck(ctx context.Context, c *check) ComponentStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached && time.Since(c.last.CheckedAt) < r.cacheTTL {
+		return c.last
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := c.fn(checkCtx)
+	status := ComponentStatus{
+		Status:    StatusHealthy,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: start,
+		Details:   details,
+	}
+	if err != nil {
+		status.Status = StatusUnhealthy
+		status.Error = err.Error()
+		status.Details = nil
+	}
+
+	c.last = status
+	c.cached = true
+	return status
+}
+
+// LiveReport — ответ пробы живости; зависимости в нее не входят
+type LiveReport struct {
+	Service string `json:"service"`
+	Status  string `json:"status"`
+	Time    string `json:"time"`
+}
+
+// LiveHandler — проба живости для Kubernetes: процесс отвечает, значит жив.
+// Зависимости не проверяются, чтобы сбой Redis или Postgres не приводил
+// к перезапуску пода, который перезапуск не вылечит.
+func (r *Registry) LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(LiveReport{
+			Service: r.service,
+			Status:  StatusOK,
+			Time:    time.Now().Format(time.RFC3339),
+		})
+	}
+}
+
+// ReadyHandler — проба готовности: проверяет все зарегистрированные
+// зависимости и отдает их состояние и задержку; при сбое под выводится
+// из балансировки, но не перезапускается
+func (r *Registry) ReadyHandler() http.HandlerFunc {
+	return r.Handler()
+}
+
+// Handler отдает отчет: 200, пока здоровы все обязательные компоненты, иначе 503
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context())
+
+		status := http.StatusOK
+		if report.Status == StatusUnhealthy {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// TCPCheck проверяет, что адрес принимает TCP-соединения (брокер Kafka, провайдер)
+func TCPCheck(addr string) CheckFunc {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,152 @@
+// Package ipfilter определяет адрес клиента за доверенными прокси и
+// проверяет его по спискам разрешенных и запрещенных подсетей.
+package ipfilter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gometeo/app/internal/config"
+)
+
+// HeaderForwardedFor — цепочка адресов, которую дописывают прокси
+const HeaderForwardedFor = "X-Forwarded-For"
+
+// Filter разбирает X-Forwarded-For только от доверенных прокси и пускает
+// к служебным маршрутам адреса из разрешенных подсетей
+type Filter struct {
+	proxies []netip.Prefix
+	allow   []netip.Prefix // пусто — разрешены все, кроме deny
+	deny    []netip.Prefix // запрет сильнее разрешения
+}
+
+// FromConfig собирает фильтр из TRUSTED_PROXIES, ADMIN_ALLOW_CIDRS и
+// ADMIN_DENY_CIDRS. Неверная запись — ошибка: пропуск строки из запрещенных
+// незаметно открыл бы доступ.
+func FromConfig(cfg *config.Config) (*Filter, error) {
+	proxies, err := ParsePrefixes(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+	allow, err := ParsePrefixes(cfg.AdminAllowCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("ADMIN_ALLOW_CIDRS: %w", err)
+	}
+	deny, err := ParsePrefixes(cfg.AdminDenyCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("ADMIN_DENY_CIDRS: %w", err)
+	}
+	return &Filter{proxies: proxies, allow: allow, deny: deny}, nil
+}
+
+// ParsePrefixes разбирает подсети вида 10.0.0.0/8; одиночный адрес — подсеть из него одного
+func ParsePrefixes(entries []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("неверный адрес %q: %w", entry, err)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("неверная подсеть %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP возвращает адрес клиента. X-Forwarded-For учитывается, только
+// если запрос пришел от доверенного прокси: цепочка читается справа налево
+// до первого адреса не из доверенных. Без доверенных прокси заголовок
+// игнорируется — его может подделать сам клиент.
+func (f *Filter) ClientIP(r *http.Request) netip.Addr {
+	remote := remoteAddr(r)
+	if f == nil || !remote.IsValid() || !contains(f.proxies, remote) {
+		return remote
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values(HeaderForwardedFor) {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Дальше цепочке верить нельзя
+			break
+		}
+		client = addr.Unmap()
+		if !contains(f.proxies, client) {
+			break
+		}
+	}
+	return client
+}
+
+// Allows проверяет адрес по спискам; nil-фильтр пропускает всех
+func (f *Filter) Allows(addr netip.Addr) bool {
+	if f == nil {
+		return true
+	}
+	if !addr.IsValid() {
+		return len(f.allow) == 0 && len(f.deny) == 0
+	}
+	if contains(f.deny, addr) {
+		return false
+	}
+	return len(f.allow) == 0 || contains(f.allow, addr)
+}
+
+// Restricted сообщает, заданы ли списки подсетей
+func (f *Filter) Restricted() bool {
+	return f != nil && (len(f.allow) > 0 || len(f.deny) > 0)
+}
+
+func remoteAddr(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+type ctxKey struct{}
+
+// WithClientIP сохраняет адрес клиента в контексте запроса
+func WithClientIP(ctx context.Context, addr netip.Addr) context.Context {
+	return context.WithValue(ctx, ctxKey{}, addr)
+}
+
+// FromContext возвращает адрес клиента, определенный middleware API
+func FromContext(ctx context.Context) (netip.Addr, bool) {
+	addr, ok := ctx.Value(ctxKey{}).(netip.Addr)
+	return addr, ok && addr.IsValid()
+}
@@ -0,0 +1,113 @@
+package ipfilter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/ipfilter"
+)
+
+func newFilter(t *testing.T, proxies, allow, deny []string) *ipfilter.Filter {
+	t.Helper()
+	cfg := config.Load()
+	cfg.TrustedProxies = proxies
+	cfg.AdminAllowCIDRs = allow
+	cfg.AdminDenyCIDRs = deny
+	f, err := ipfilter.FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "192.168.1.1"}
+	tests := []struct {
+		name    string
+		proxies []string
+		remote  string
+		xff     []string
+		want    string
+	}{
+		{"без заголовка", proxies, "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"без доверенных прокси заголовок игнорируется", nil, "10.0.0.1:5000", []string{"198.51.100.1"}, "10.0.0.1"},
+		{"запрос не от прокси", proxies, "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"один прокси", proxies, "10.0.0.1:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"цепочка прокси", proxies, "10.0.0.1:5000", []string{"198.51.100.1, 10.1.1.1, 192.168.1.1"}, "198.51.100.1"},
+		{"подделка слева не учитывается", proxies, "10.0.0.1:5000", []string{"1.2.3.4, 198.51.100.1, 10.1.1.1"}, "198.51.100.1"},
+		{"несколько заголовков", proxies, "10.0.0.1:5000", []string{"1.2.3.4", "198.51.100.1, 10.1.1.1"}, "198.51.100.1"},
+		{"прокси не из списка", proxies, "10.0.0.1:5000", []string{"198.51.100.1, 192.168.1.2"}, "192.168.1.2"},
+		{"неверная запись справа", proxies, "10.0.0.1:5000", []string{"198.51.100.1, garbage"}, "10.0.0.1"},
+		{"неверная запись за прокси", proxies, "10.0.0.1:5000", []string{"garbage, 10.1.1.1"}, "10.1.1.1"},
+		{"адрес с портом", proxies, "10.0.0.1:5000", []string{"198.51.100.1:1234"}, "10.0.0.1"},
+		{"пустая запись", proxies, "10.0.0.1:5000", []string{"198.51.100.1, "}, "10.0.0.1"},
+		{"все адреса доверенные", proxies, "10.0.0.1:5000", []string{"10.2.2.2, 10.1.1.1"}, "10.2.2.2"},
+		{"IPv4 в IPv6", proxies, "[::ffff:10.0.0.1]:5000", []string{"::ffff:198.51.100.1"}, "198.51.100.1"},
+		{"IPv6", []string{"2001:db8::/32"}, "[2001:db8::1]:5000", []string{"2001:db8:ffff::5, 2001:db8::2"}, "2001:db8:ffff::5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFilter(t, tt.proxies, nil, nil)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				r.Header.Add(ipfilter.HeaderForwardedFor, v)
+			}
+			if got := f.ClientIP(r); got != netip.MustParseAddr(tt.want) {
+				t.Errorf("ClientIP = %s, ожидался %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	f := newFilter(t, nil, []string{"10.0.0.0/8", "2001:db8::/32"}, []string{"10.6.6.0/24"})
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"10.6.6.6", false},
+		{"203.0.113.7", false},
+		{"2001:db8::1", true},
+		{"::1", false},
+	}
+	for _, tt := range tests {
+		if got := f.Allows(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Allows(%s) = %v, ожидалось %v", tt.addr, got, tt.want)
+		}
+	}
+	if f.Allows(netip.Addr{}) {
+		t.Error("неизвестный адрес пропущен при заданных списках")
+	}
+
+	var open *ipfilter.Filter
+	if !open.Allows(netip.MustParseAddr("203.0.113.7")) || open.Restricted() {
+		t.Error("nil-фильтр должен пропускать всех")
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ipfilter.ParsePrefixes([]string{" 10.1.2.3/8 ", "", "192.168.1.1", "::ffff:172.16.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10.0.0.0/8", "192.168.1.1/32", "172.16.0.1/32"}
+	if len(prefixes) != len(want) {
+		t.Fatalf("разобрано %v, ожидалось %v", prefixes, want)
+	}
+	for i, p := range prefixes {
+		if p.String() != want[i] {
+			t.Errorf("подсеть %d: %s, ожидалась %s", i, p, want[i])
+		}
+	}
+
+	for _, entry := range []string{"10.0.0.0/33", "10.0.0", "example.com", "10.0.0.0/x"} {
+		if _, err := ipfilter.ParsePrefixes([]string{entry}); err == nil {
+			t.Errorf("%q разобрано без ошибки", entry)
+		}
+	}
+}
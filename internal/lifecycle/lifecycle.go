@@ -0,0 +1,216 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Phase задает порядок остановки: меньшие фазы выполняются раньше
+type Phase int
+
+const (
+	PhaseStopIntake   Phase = iota // перестать принимать HTTP-запросы и новые задачи
+	PhaseDrain                     // дочитать и зафиксировать сообщения Kafka
+	PhaseFlush                     // сбросить буферы, батчи, метрики и трассы
+	PhaseCloseCache                // закрыть Redis
+	PhaseCloseStorage              // закрыть БД
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseStopIntake:
+		return "stop_intake"
+	case PhaseDrain:
+		return "drain"
+	case PhaseFlush:
+		return "flush"
+	case PhaseCloseCache:
+		return "close_cache"
+	case PhaseCloseStorage:
+		return "close_storage"
+	default:
+		return "unknown"
+	}
+}
+
+// ParsePhase разбирает название фазы, как его возвращает String
+func ParsePhase(s string) (Phase, error) {
+	for p := PhaseStopIntake; p <= PhaseCloseStorage; p++ {
+		if p.String() == strings.ToLower(strings.TrimSpace(s)) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("неизвестная фаза остановки: %s", s)
+}
+
+type hook struct {
+	phase Phase
+	name  string
+	fn    func(context.Context) error
+	seq   int
+}
+
+// Manager собирает хуки остановки компонентов и выполняет их по фазам
+// с общим дедлайном. Хукам фазы можно задать свой таймаут, чтобы зависший
+// компонент не отнимал время у закрытия остальных.
+type Manager struct {
+	logger   *slog.Logger
+	deadline time.Duration
+
+	mu       sync.Mutex
+	hooks    []hook
+	timeouts map[Phase]time.Duration
+	once     sync.Once
+}
+
+func New(logger *slog.Logger, deadline time.Duration) *Manager {
+	return &Manager{logger: logger, deadline: deadline}
+}
+
+// Register добавляет хук. Внутри одной фазы хуки выполняются
+// в обратном порядке регистрации, как defer.
+func (m *Manager) Register(phase Phase, name string, fn func(context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{phase: phase, name: name, fn: fn, seq: len(m.hooks)})
+}
+
+// SetPhaseTimeout ограничивает время каждого хука фазы; общий дедлайн
+// остановки по-прежнему действует. 0 — только общий дедлайн.
+func (m *Manager) SetPhaseTimeout(phase Phase, timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.timeouts == nil {
+		m.timeouts = make(map[Phase]time.Duration)
+	}
+	m.timeouts[phase] = timeout
+}
+
+// SetPhaseTimeouts применяет записи вида "close_storage=5s";
+// неверные записи пропускаются с предупреждением
+func (m *Manager) SetPhaseTimeouts(entries []string) {
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			m.logger.Warn("Неверный таймаут фазы остановки пропущен", "entry", entry)
+			continue
+		}
+		phase, err := ParsePhase(name)
+		if err != nil {
+			m.logger.Warn("Неверный таймаут фазы остановки пропущен", "entry", entry, "error", err)
+			continue
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout < 0 {
+			m.logger.Warn("Неверный таймаут фазы остановки пропущен", "entry", entry, "error", err)
+			continue
+		}
+		m.SetPhaseTimeout(phase, timeout)
+	}
+}
+
+// RegisterFunc — вариант Register для функций без контекста и ошибки
+func (m *Manager) RegisterFunc(phase Phase, name string, fn func()) {
+	m.Register(phase, name, func(context.Context) error {
+		fn()
+		return nil
+	})
+}
+
+// Wait блокируется до SIGINT/SIGTERM или отмены ctx
+func (m *Manager) Wait(ctx context.Context) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	select {
+	case sig := <-sigChan:
+		m.logger.Info("Получен сигнал завершения", "signal", sig.String())
+	case <-ctx.Done():
+		m.logger.Info("Остановка по отмене контекста")
+	}
+}
+
+// Shutdown выполняет все хуки по фазам. Повторные вызовы ничего не делают.
+// Ошибки хуков логируются и объединяются, остановка продолжается.
+func (m *Manager) Shutdown() error {
+	var result error
+	m.once.Do(func() {
+		result = m.shutdown()
+	})
+	return result
+}
+
+func (m *Manager) shutdown() error {
+	m.mu.Lock()
+	hooks := make([]hook, len(m.hooks))
+	copy(hooks, m.hooks)
+	timeouts := make(map[Phase]time.Duration, len(m.timeouts))
+	for phase, timeout := range m.timeouts {
+		timeouts[phase] = timeout
+	}
+	m.mu.Unlock()
+
+	sort.Slice(hooks, func(i, j int) bool {
+		if hooks[i].phase != hooks[j].phase {
+			return hooks[i].phase < hooks[j].phase
+		}
+		return hooks[i].seq > hooks[j].seq
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.deadline)
+	defer cancel()
+
+	start := time.Now()
+	var errs []error
+	for _, h := range hooks {
+		if ctx.Err() != nil {
+			m.logger.Error("Дедлайн остановки истек, хук пропущен", "phase", h.phase.String(), "hook", h.name)
+			errs = append(errs, ctx.Err())
+			continue
+		}
+
+		hookStart := time.Now()
+		if err := runHook(ctx, h, timeouts[h.phase]); err != nil {
+			m.logger.Error("Ошибка при остановке компонента",
+				"phase", h.phase.String(), "hook", h.name, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		m.logger.Debug("Компонент остановлен",
+			"phase", h.phase.String(), "hook", h.name,
+			"duration_ms", time.Since(hookStart).Milliseconds())
+	}
+
+	m.logger.Info("Остановка завершена", "duration_ms", time.Since(start).Milliseconds())
+	return errors.Join(errs...)
+}
+
+// runHook выполняет хук с его таймаутом. Хук, который не смотрит на контекст,
+// не задерживает остановку дольше таймаута: его горутина остается, а
+// остановка переходит к следующему.
+func runHook(ctx context.Context, h hook, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- h.fn(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("хук не уложился в таймаут: %w", ctx.Err())
+	}
+}
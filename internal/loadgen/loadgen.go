@@ -0,0 +1,166 @@
+// Package loadgen генерирует синтетическую нагрузку на конвейер и API:
+// замеры в Kafka и запросы к HTTP API с заданным числом городов и темпом.
+// Выбор городов детерминирован сидом, поэтому прогоны воспроизводимы.
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// Options — параметры прогона
+type Options struct {
+	Cities   int           // число разных городов; сверх справочника — синтетические
+	Rate     float64       // операций в секунду; 0 — без ограничения
+	Duration time.Duration // длительность прогона
+	Workers  int           // параллельных исполнителей
+	Seed     uint64        // сид выбора городов и значений
+}
+
+// Target выполняет одну операцию нагрузки для города
+type Target func(ctx context.Context, city string, rnd *rand.Rand) error
+
+// Report — итог прогона
+type Report struct {
+	Requests   int
+	Errors     int
+	Elapsed    time.Duration
+	Throughput float64 // успешных операций в секунду
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+	FirstError error
+}
+
+// Cities возвращает n названий: сначала справочник, затем синтетические города
+func Cities(n int) []string {
+	if n < 1 {
+		n = 1
+	}
+	out := make([]string, 0, n)
+	for _, c := range model.DefaultCities {
+		if len(out) == n {
+			return out
+		}
+		out = append(out, c.Name)
+	}
+	for i := len(out); i < n; i++ {
+		out = append(out, fmt.Sprintf("Loadtown-%05d", i))
+	}
+	return out
+}
+
+// Run выполняет target с заданным темпом до истечения Duration или отмены ctx
+func Run(ctx context.Context, opts Options, target Target) (*Report, error) {
+	if opts.Duration <= 0 {
+		return nil, errors.New("длительность прогона должна быть положительной")
+	}
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	cities := Cities(opts.Cities)
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	// Темп задается раздачей разрешений: исполнители берут их из общего канала
+	permits := make(chan struct{}, opts.Workers)
+	go pace(ctx, opts.Rate, permits)
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		report    Report
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rnd := rand.New(rand.NewPCG(opts.Seed, uint64(w)))
+			for range permits {
+				city := cities[rnd.IntN(len(cities))]
+				began := time.Now()
+				err := target(ctx, city, rnd)
+				took := time.Since(began)
+				if err != nil && ctx.Err() != nil {
+					// Операция прервана окончанием прогона, в статистику не идет
+					return
+				}
+
+				mu.Lock()
+				report.Requests++
+				if err != nil {
+					report.Errors++
+					if report.FirstError == nil {
+						report.FirstError = err
+					}
+				} else {
+					latencies = append(latencies, took)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report.Elapsed = time.Since(start)
+	if secs := report.Elapsed.Seconds(); secs > 0 {
+		report.Throughput = float64(report.Requests-report.Errors) / secs
+	}
+	slices.Sort(latencies)
+	report.P50 = percentile(latencies, 0.50)
+	report.P95 = percentile(latencies, 0.95)
+	report.P99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		report.Max = latencies[len(latencies)-1]
+	}
+	return &report, nil
+}
+
+// pace раздает разрешения с темпом rate в секунду и закрывает канал по отмене ctx
+func pace(ctx context.Context, rate float64, permits chan<- struct{}) {
+	defer close(permits)
+	if rate <= 0 {
+		for {
+			select {
+			case permits <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			select {
+			case permits <- struct{}{}:
+			default:
+				// Все исполнители заняты: разрешение теряется, фактический темп ниже заданного
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// percentile — значение квантиля q по отсортированной выборке
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(q*float64(len(sorted)-1) + 0.5)
+	return sorted[i]
+}
@@ -0,0 +1,71 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/account"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/model"
+)
+
+// eventSource — источник синтетических событий, отличает их от боевых в логах
+const eventSource = "loadgen"
+
+// KafkaTarget публикует синтетические замеры weather.observed в topic
+func KafkaTarget(producer bus.Publisher, topic string) Target {
+	return func(_ context.Context, city string, rnd *rand.Rand) error {
+		data := model.WeatherData{
+			City:      city,
+			Temp:      float64(rnd.IntN(40)-10) + rnd.Float64(),
+			Condition: "Cloudy",
+			Provider:  eventSource,
+			Timestamp: time.Now(),
+		}
+		event, err := model.NewEvent(model.EventWeatherObserved, eventSource, data.Timestamp, data)
+		if err != nil {
+			return err
+		}
+		bytes, err := event.Marshal()
+		if err != nil {
+			return err
+		}
+		_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+			Topic: topic,
+			Key:   sarama.StringEncoder(city),
+			Value: sarama.ByteEncoder(bytes),
+		})
+		return err
+	}
+}
+
+// HTTPTarget запрашивает погоду города у API по адресу base
+func HTTPTarget(client *http.Client, base, apiKey string) Target {
+	return func(ctx context.Context, city string, _ *rand.Rand) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/api/v1/weather/"+url.PathEscape(city), nil)
+		if err != nil {
+			return err
+		}
+		if apiKey != "" {
+			req.Header.Set(account.HeaderAPIKey, apiKey)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		// 404 — города нет в данных, для нагрузки это штатный ответ
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("API вернул %s", resp.Status)
+		}
+		return nil
+	}
+}
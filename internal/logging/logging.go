@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gometeo/app/internal/config"
+)
+
+// Options описывает формат и подробность логов
+type Options struct {
+	Level     string // debug, info, warn, error
+	Format    string // text или json
+	AddSource bool   // добавлять файл и строку вызова
+}
+
+// New создает логгер с заданными уровнем и форматом, вывод в stdout.
+// Записи дополняются trace_id, span_id и request_id из контекста.
+func New(opts Options) *slog.Logger {
+	return slog.New(NewContextHandler(NewHandler(os.Stdout, opts)))
+}
+
+// NewHandler создает базовый обработчик slog для writer
+func NewHandler(w io.Writer, opts Options) slog.Handler {
+	handlerOpts := &slog.HandlerOptions{
+		Level:     ParseLevel(opts.Level),
+		AddSource: opts.AddSource,
+	}
+
+	if strings.EqualFold(opts.Format, "json") {
+		return slog.NewJSONHandler(w, handlerOpts)
+	}
+	return slog.NewTextHandler(w, handlerOpts)
+}
+
+// FromConfig создает логгер по настройкам сервиса
+func FromConfig(cfg *config.Config) *slog.Logger {
+	return New(Options{
+		Level:     cfg.LogLevel,
+		Format:    cfg.LogFormat,
+		AddSource: cfg.LogSource,
+	})
+}
+
+// ParseLevel переводит строку в уровень slog, по умолчанию Info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Provider объединяет экспорт метрик: Prometheus-скрейпинг всегда,
+// OTLP push — если в конфигурации задан эндпоинт коллектора.
+type Provider struct {
+	registry *prometheus.Registry
+	provider *sdkmetric.MeterProvider
+}
+
+// Setup настраивает глобальный MeterProvider для сервиса
+func Setup(ctx context.Context, cfg *config.Config, serviceName string) (*Provider, error) {
+	registry := prometheus.NewRegistry()
+
+	promExporter, err := otelprom.New(otelprom.WithRegisterer(registry))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания Prometheus экспортера: %w", err)
+	}
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdkmetric.WithReader(promExporter),
+	}
+
+	if cfg.OTLPMetricsEndpoint != "" {
+		otlpExporter, err := otlpmetrichttp.New(ctx,
+			otlpmetrichttp.WithEndpointURL(cfg.OTLPMetricsEndpoint),
+			otlpmetrichttp.WithHeaders(parseHeaders(cfg.OTLPMetricsHeaders)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания OTLP экспортера метрик: %w", err)
+		}
+		opts = append(opts, sdkmetric.WithReader(
+			sdkmetric.NewPeriodicReader(otlpExporter, sdkmetric.WithInterval(cfg.MetricsPushInterval)),
+		))
+	}
+
+	provider := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(provider)
+
+	if err := startRuntimeMetrics(); err != nil {
+		return nil, err
+	}
+
+	return &Provider{registry: registry, provider: provider}, nil
+}
+
+// Handler отдает метрики в формате Prometheus
+func (p *Provider) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+// Serve поднимает отдельный HTTP-сервер с /metrics для фоновых сервисов,
+// extra добавляет служебные маршруты (например, /health).
+// Сервер останавливается при отмене ctx.
+func (p *Provider) Serve(ctx context.Context, addr string, logger *slog.Logger, extra map[string]http.Handler) {
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", p.Handler())
+	for pattern, handler := range extra {
+		mux.Handle(pattern, handler)
+	}
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		logger.Info("Эндпоинт метрик запущен", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Ошибка сервера метрик", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+}
+
+// Shutdown отправляет накопленные метрики и останавливает экспортеры
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.provider.Shutdown(ctx)
+}
+
+// Meter возвращает именованный Meter из глобального провайдера
+func Meter(name string) metric.Meter {
+	return otel.Meter(name)
+}
+
+// parseHeaders разбирает строку вида "key1=value1,key2=value2"
+func parseHeaders(raw string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			headers[key] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
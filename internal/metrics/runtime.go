@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+	"time"
+
+	otelruntime "go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const runtimeMeterName = "github.com/gometeo/app/internal/metrics"
+
+// startRuntimeMetrics включает стандартные метрики Go (горутины, память, планировщик)
+// и добавляет паузы GC, которых нет в новом наборе OTel.
+func startRuntimeMetrics() error {
+	if err := otelruntime.Start(otelruntime.WithMinimumReadMemStatsInterval(15 * time.Second)); err != nil {
+		return fmt.Errorf("ошибка запуска runtime-метрик: %w", err)
+	}
+
+	meter := Meter(runtimeMeterName)
+	pauseTotal, err := meter.Float64ObservableCounter("go.gc.pause.total",
+		metric.WithDescription("Суммарное время пауз сборщика мусора"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	lastPause, err := meter.Float64ObservableGauge("go.gc.pause.last",
+		metric.WithDescription("Длительность последней паузы сборщика мусора"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	gcCount, err := meter.Int64ObservableCounter("go.gc.count",
+		metric.WithDescription("Количество завершенных циклов GC"))
+	if err != nil {
+		return err
+	}
+	heapObjects, err := meter.Int64ObservableGauge("go.memory.heap.objects",
+		metric.WithDescription("Количество живых объектов в куче"))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+
+		o.ObserveFloat64(pauseTotal, time.Duration(ms.PauseTotalNs).Seconds())
+		if ms.NumGC > 0 {
+			o.ObserveFloat64(lastPause, time.Duration(ms.PauseNs[(ms.NumGC+255)%256]).Seconds())
+		}
+		o.ObserveInt64(gcCount, int64(ms.NumGC))
+		o.ObserveInt64(heapObjects, int64(ms.HeapObjects))
+		return nil
+	}, pauseTotal, lastPause, gcCount, heapObjects)
+	return err
+}
+
+// RegisterDBStats публикует состояние пула соединений sql.DB
+func RegisterDBStats(pool string, stats func() sql.DBStats) error {
+	meter := Meter(runtimeMeterName)
+	attrs := metric.WithAttributes(attribute.String("db.client.connection.pool.name", pool))
+
+	maxOpen, err := meter.Int64ObservableGauge("db.client.connection.max",
+		metric.WithDescription("Максимальное число открытых соединений"))
+	if err != nil {
+		return err
+	}
+	open, err := meter.Int64ObservableGauge("db.client.connection.count",
+		metric.WithDescription("Открытые соединения по состоянию"))
+	if err != nil {
+		return err
+	}
+	waitCount, err := meter.Int64ObservableCounter("db.client.connection.wait.count",
+		metric.WithDescription("Сколько раз пришлось ждать свободное соединение"))
+	if err != nil {
+		return err
+	}
+	waitDuration, err := meter.Float64ObservableCounter("db.client.connection.wait.duration",
+		metric.WithDescription("Суммарное время ожидания свободного соединения"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+
+	usedAttrs := metric.WithAttributes(
+		attribute.String("db.client.connection.pool.name", pool),
+		attribute.String("db.client.connection.state", "used"),
+	)
+	idleAttrs := metric.WithAttributes(
+		attribute.String("db.client.connection.pool.name", pool),
+		attribute.String("db.client.connection.state", "idle"),
+	)
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := stats()
+		o.ObserveInt64(maxOpen, int64(s.MaxOpenConnections), attrs)
+		o.ObserveInt64(open, int64(s.InUse), usedAttrs)
+		o.ObserveInt64(open, int64(s.Idle), idleAttrs)
+		o.ObserveInt64(waitCount, s.WaitCount, attrs)
+		o.ObserveFloat64(waitDuration, s.WaitDuration.Seconds(), attrs)
+		return nil
+	}, maxOpen, open, waitCount, waitDuration)
+	if err != nil {
+		return fmt.Errorf("ошибка регистрации метрик пула %s: %w", pool, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// Account — внешний потребитель API, идентифицируемый ключом
+type Account struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Plan         string    `json:"plan"`
+	MonthlyQuota int64     `json:"monthly_quota"` // 0 — без ограничения
+	Active       bool      `json:"active"`        // false — доступ приостановлен (например, неоплата)
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UsageResponse — расход квоты за текущий месяц
+type UsageResponse struct {
+	Account   string    `json:"account"`
+	Plan      string    `json:"plan"`
+	Month     string    `json:"month"` // YYYY-MM (UTC)
+	Requests  int64     `json:"requests"`
+	Quota     int64     `json:"quota"`
+	Remaining int64     `json:"remaining"` // -1 при безлимитном тарифе
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// Preferences — настройки аккаунта для ответов API
+type Preferences struct {
+	Units     Units     `json:"units"`
+	Language  Language  `json:"language"`
+	Timezone  string    `json:"timezone,omitempty"` // IANA; пусто — часовой пояс города
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultPreferences — настройки аккаунта, который их не менял
+func DefaultPreferences() Preferences {
+	return Preferences{Units: UnitsMetric, Language: LanguageRU}
+}
+
+// FavoritesResponse — избранные города аккаунта в порядке добавления
+type FavoritesResponse struct {
+	Cities []string `json:"cities"`
+	Total  int      `json:"total"`
+}
+
+// MyWeatherResponse — погода всех избранных городов с учетом настроек
+type MyWeatherResponse struct {
+	Preferences Preferences       `json:"preferences"`
+	Weather     []WeatherResponse `json:"weather"`
+	Missing     []string          `json:"missing,omitempty"` // избранные города без данных
+}
+
+// CreateKeyRequest — тело POST /api/v1/keys
+type CreateKeyRequest struct {
+	Name         string `json:"name"`
+	Plan         string `json:"plan"`          // пусто — free
+	MonthlyQuota int64  `json:"monthly_quota"` // 0 — без ограничения
+}
+
+// KeyResponse — созданный аккаунт и его ключ; ключ показывается один раз
+type KeyResponse struct {
+	Account Account `json:"account"`
+	Key     string  `json:"key"`
+}
+
+// Validate проверяет название, тариф и квоту аккаунта
+func (a Account) Validate() error {
+	var errs ValidationErrors
+	switch {
+	case a.Name == "":
+		errs.add("name", "обязательное поле")
+	case len(a.Name) > 100:
+		errs.add("name", "не длиннее 100 символов")
+	}
+	if len(a.Plan) > 32 {
+		errs.add("plan", "не длиннее 32 символов")
+	}
+	if a.MonthlyQuota < 0 {
+		errs.add("monthly_quota", "не может быть отрицательной")
+	}
+	return errs.err()
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// EventAirQualityObserved — тип события с замером качества воздуха
+const EventAirQualityObserved = "air_quality.observed"
+
+// AQICategory — категория индекса качества воздуха (шкала US EPA)
+type AQICategory string
+
+const (
+	AQIGood               AQICategory = "good"
+	AQIModerate           AQICategory = "moderate"
+	AQIUnhealthySensitive AQICategory = "unhealthy_for_sensitive_groups"
+	AQIUnhealthy          AQICategory = "unhealthy"
+	AQIVeryUnhealthy      AQICategory = "very_unhealthy"
+	AQIHazardous          AQICategory = "hazardous"
+)
+
+// AirQuality — замер качества воздуха, концентрации в мкг/м³
+type AirQuality struct {
+	City      string      `json:"city"`
+	PM25      float64     `json:"pm2_5"`
+	PM10      float64     `json:"pm10"`
+	NO2       float64     `json:"no2"`
+	O3        float64     `json:"o3"`
+	AQI       int         `json:"aqi"`
+	Category  AQICategory `json:"category"`
+	Provider  string      `json:"provider"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+type AirQualityResponse struct {
+	AirQuality
+	Cached     bool  `json:"cached"`
+	Stale      bool  `json:"stale"`       // замер старше допустимого возраста
+	AgeSeconds int64 `json:"age_seconds"` // возраст замера на момент ответа
+}
+
+type AirQualityHistoryResponse struct {
+	City     string       `json:"city"`
+	Readings []AirQuality `json:"readings"`
+	Total    int          `json:"total"`
+}
+
+// AirQualityStatsResponse — сводка по замерам качества воздуха за период
+type AirQualityStatsResponse struct {
+	City       string              `json:"city"`
+	From       time.Time           `json:"from"`
+	To         time.Time           `json:"to"`
+	Count      int                 `json:"count"`
+	AQIMin     int                 `json:"aqi_min"`
+	AQIMax     int                 `json:"aqi_max"`
+	AQIAvg     float64             `json:"aqi_avg"`
+	PM25Avg    float64             `json:"pm2_5_avg"`
+	PM10Avg    float64             `json:"pm10_avg"`
+	Categories map[AQICategory]int `json:"categories"` // число замеров по категориям
+}
+
+// SummarizeAirQuality считает сводку по замерам за период
+func SummarizeAirQuality(city string, readings []AirQuality, from, to time.Time) AirQualityStatsResponse {
+	stats := AirQualityStatsResponse{
+		City:       city,
+		From:       from,
+		To:         to,
+		Count:      len(readings),
+		Categories: make(map[AQICategory]int),
+	}
+	if len(readings) == 0 {
+		return stats
+	}
+
+	stats.AQIMin, stats.AQIMax = readings[0].AQI, readings[0].AQI
+	var aqiSum, pm25Sum, pm10Sum float64
+	for _, r := range readings {
+		stats.AQIMin = min(stats.AQIMin, r.AQI)
+		stats.AQIMax = max(stats.AQIMax, r.AQI)
+		aqiSum += float64(r.AQI)
+		pm25Sum += r.PM25
+		pm10Sum += r.PM10
+		stats.Categories[r.Category]++
+	}
+	n := float64(len(readings))
+	stats.AQIAvg = math.Round(aqiSum/n*10) / 10
+	stats.PM25Avg = math.Round(pm25Sum/n*10) / 10
+	stats.PM10Avg = math.Round(pm10Sum/n*10) / 10
+	return stats
+}
+
+// aqiBreakpoint — отрезок линейной интерполяции индекса по концентрации
+type aqiBreakpoint struct {
+	concLow, concHigh float64
+	aqiLow, aqiHigh   int
+}
+
+// pm25Breakpoints — таблица EPA для PM2.5 (среднее за 24 часа)
+var pm25Breakpoints = []aqiBreakpoint{
+	{0.0, 9.0, 0, 50},
+	{9.1, 35.4, 51, 100},
+	{35.5, 55.4, 101, 150},
+	{55.5, 125.4, 151, 200},
+	{125.5, 225.4, 201, 300},
+	{225.5, 325.4, 301, 500},
+}
+
+// AQIFromPM25 рассчитывает индекс по концентрации PM2.5
+func AQIFromPM25(pm25 float64) int {
+	c := math.Floor(pm25*10) / 10
+	if c <= 0 {
+		return 0
+	}
+	for _, bp := range pm25Breakpoints {
+		if c <= bp.concHigh {
+			ratio := float64(bp.aqiHigh-bp.aqiLow) / (bp.concHigh - bp.concLow)
+			return int(math.Round(ratio*(c-bp.concLow))) + bp.aqiLow
+		}
+	}
+	return 500
+}
+
+// CategoryForAQI возвращает категорию по значению индекса
+func CategoryForAQI(aqi int) AQICategory {
+	switch {
+	case aqi <= 50:
+		return AQIGood
+	case aqi <= 100:
+		return AQIModerate
+	case aqi <= 150:
+		return AQIUnhealthySensitive
+	case aqi <= 200:
+		return AQIUnhealthy
+	case aqi <= 300:
+		return AQIVeryUnhealthy
+	default:
+		return AQIHazardous
+	}
+}
+
+// Normalize дозаполняет индекс и категорию, если провайдер их не прислал
+func (a *AirQuality) Normalize() {
+	if a.AQI == 0 && a.PM25 > 0 {
+		a.AQI = AQIFromPM25(a.PM25)
+	}
+	if a.Category == "" {
+		a.Category = CategoryForAQI(a.AQI)
+	}
+}
+
+// Validate проверяет обязательные поля и неотрицательность концентраций
+func (a AirQuality) Validate() error {
+	var errs ValidationErrors
+
+	city := strings.TrimSpace(a.City)
+	switch {
+	case city == "":
+		errs.add("city", "обязательное поле")
+	case len(city) > MaxCityLength:
+		errs.add("city", "слишком длинное название")
+	}
+
+	pollutants := []struct {
+		field string
+		value float64
+	}{
+		{"pm2_5", a.PM25},
+		{"pm10", a.PM10},
+		{"no2", a.NO2},
+		{"o3", a.O3},
+	}
+	for _, p := range pollutants {
+		if math.IsNaN(p.value) || p.value < 0 {
+			errs.add(p.field, "концентрация должна быть неотрицательной")
+		}
+	}
+	if a.AQI < 0 || a.AQI > 500 {
+		errs.add("aqi", "значение вне допустимого диапазона")
+	}
+	if len(a.Provider) > MaxProviderLen {
+		errs.add("provider", "слишком длинное значение")
+	}
+
+	validateTimestamp(&errs, "timestamp", a.Timestamp)
+
+	return errs.err()
+}
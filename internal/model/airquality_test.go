@@ -0,0 +1,86 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+func TestAQIFromPM25(t *testing.T) {
+	// Границы отрезков таблицы EPA 2024 и точки внутри них
+	tests := []struct {
+		pm25 float64
+		want int
+	}{
+		{-1, 0},
+		{0, 0},
+		{4.5, 25},
+		{9.0, 50},
+		{9.09, 50}, // концентрация усекается до десятых
+		{9.1, 51},
+		{12.0, 56},
+		{35.4, 100},
+		{35.5, 101},
+		{55.4, 150},
+		{55.5, 151},
+		{125.4, 200},
+		{125.5, 201},
+		{225.4, 300},
+		{225.5, 301},
+		{325.4, 500},
+		{600, 500},
+	}
+	for _, tt := range tests {
+		if got := model.AQIFromPM25(tt.pm25); got != tt.want {
+			t.Errorf("AQIFromPM25(%v) = %d, ожидалось %d", tt.pm25, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryForAQI(t *testing.T) {
+	tests := []struct {
+		aqi  int
+		want model.AQICategory
+	}{
+		{0, model.AQIGood},
+		{50, model.AQIGood},
+		{51, model.AQIModerate},
+		{100, model.AQIModerate},
+		{101, model.AQIUnhealthySensitive},
+		{150, model.AQIUnhealthySensitive},
+		{151, model.AQIUnhealthy},
+		{200, model.AQIUnhealthy},
+		{201, model.AQIVeryUnhealthy},
+		{300, model.AQIVeryUnhealthy},
+		{301, model.AQIHazardous},
+		{500, model.AQIHazardous},
+	}
+	for _, tt := range tests {
+		if got := model.CategoryForAQI(tt.aqi); got != tt.want {
+			t.Errorf("CategoryForAQI(%d) = %s, ожидалось %s", tt.aqi, got, tt.want)
+		}
+	}
+}
+
+func TestAirQualityNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       model.AirQuality
+		aqi      int
+		category model.AQICategory
+	}{
+		{"индекс по PM2.5", model.AirQuality{PM25: 35.5}, 101, model.AQIUnhealthySensitive},
+		{"индекс провайдера сохраняется", model.AirQuality{PM25: 35.5, AQI: 42}, 42, model.AQIGood},
+		{"категория провайдера сохраняется", model.AirQuality{AQI: 42, Category: model.AQIModerate}, 42, model.AQIModerate},
+		{"без данных", model.AirQuality{}, 0, model.AQIGood},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.in
+			a.Normalize()
+			if a.AQI != tt.aqi || a.Category != tt.category {
+				t.Errorf("индекс %d (%s), ожидался %d (%s)", a.AQI, a.Category, tt.aqi, tt.category)
+			}
+		})
+	}
+}
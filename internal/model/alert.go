@@ -0,0 +1,170 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// EventAlertTriggered — тип события о сработавшем правиле оповещения
+const EventAlertTriggered = "alert.triggered"
+
+// AlertMetric — показатель, по которому проверяется правило
+type AlertMetric string
+
+const (
+	MetricTemperature AlertMetric = "temperature"
+	MetricAQI         AlertMetric = "aqi"
+)
+
+// AlertOperator — оператор сравнения значения с порогом
+type AlertOperator string
+
+const (
+	OpLess         AlertOperator = "<"
+	OpLessEqual    AlertOperator = "<="
+	OpGreater      AlertOperator = ">"
+	OpGreaterEqual AlertOperator = ">="
+	OpEqual        AlertOperator = "=="
+)
+
+// Каналы доставки оповещений
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+	ChannelSlack    = "slack"
+	ChannelWebhook  = "webhook"
+)
+
+var knownChannels = map[string]bool{
+	ChannelEmail:    true,
+	ChannelTelegram: true,
+	ChannelSlack:    true,
+	ChannelWebhook:  true,
+}
+
+// Compare применяет оператор к значению и порогу
+func (op AlertOperator) Compare(value, threshold float64) bool {
+	switch op {
+	case OpLess:
+		return value < threshold
+	case OpLessEqual:
+		return value <= threshold
+	case OpGreater:
+		return value > threshold
+	case OpGreaterEqual:
+		return value >= threshold
+	case OpEqual:
+		return value == threshold
+	default:
+		return false
+	}
+}
+
+func (op AlertOperator) IsKnown() bool {
+	switch op {
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual, OpEqual:
+		return true
+	}
+	return false
+}
+
+// AlertRule — пользовательское правило вида "Moscow temperature < -20"
+type AlertRule struct {
+	ID        int64         `json:"id"`
+	AccountID int64         `json:"-"` // Владелец правила
+	City      string        `json:"city"`
+	Metric    AlertMetric   `json:"metric"`
+	Operator  AlertOperator `json:"operator"`
+	Threshold float64       `json:"threshold"`
+	Channels  []string      `json:"channels"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// AlertEvent — факт срабатывания правила на конкретном замере
+type AlertEvent struct {
+	ID          int64         `json:"id"`
+	RuleID      int64         `json:"rule_id"`
+	City        string        `json:"city"`
+	Metric      AlertMetric   `json:"metric"`
+	Operator    AlertOperator `json:"operator"`
+	Threshold   float64       `json:"threshold"`
+	Value       float64       `json:"value"`
+	Channels    []string      `json:"channels"`
+	TriggeredAt time.Time     `json:"triggered_at"`
+}
+
+type AlertRulesResponse struct {
+	Rules []AlertRule `json:"rules"`
+	Total int         `json:"total"`
+}
+
+type AlertEventsResponse struct {
+	Events []AlertEvent `json:"events"`
+	Total  int          `json:"total"`
+}
+
+// Validate проверяет правило перед сохранением
+func (r AlertRule) Validate() error {
+	var errs ValidationErrors
+
+	city := strings.TrimSpace(r.City)
+	switch {
+	case city == "":
+		errs.add("city", "обязательное поле")
+	case len(city) > MaxCityLength:
+		errs.add("city", "слишком длинное название")
+	}
+
+	switch r.Metric {
+	case MetricTemperature, MetricAQI:
+	default:
+		errs.add("metric", "неизвестный показатель")
+	}
+
+	if !r.Operator.IsKnown() {
+		errs.add("operator", "неизвестный оператор")
+	}
+
+	if len(r.Channels) == 0 {
+		errs.add("channels", "нужен хотя бы один канал")
+	}
+	for _, ch := range r.Channels {
+		if !knownChannels[ch] {
+			errs.add("channels", "неизвестный канал: "+ch)
+		}
+	}
+
+	return errs.err()
+}
+
+// Evaluate проверяет замер погоды и возвращает событие, если правило сработало
+func (r AlertRule) Evaluate(data WeatherData) (AlertEvent, bool) {
+	if !strings.EqualFold(r.City, data.City) || r.Metric != MetricTemperature {
+		return AlertEvent{}, false
+	}
+	return r.trigger(data.Temp, data.Timestamp)
+}
+
+// EvaluateAirQuality проверяет замер качества воздуха
+func (r AlertRule) EvaluateAirQuality(data AirQuality) (AlertEvent, bool) {
+	if !strings.EqualFold(r.City, data.City) || r.Metric != MetricAQI {
+		return AlertEvent{}, false
+	}
+	return r.trigger(float64(data.AQI), data.Timestamp)
+}
+
+func (r AlertRule) trigger(value float64, at time.Time) (AlertEvent, bool) {
+	if !r.Operator.Compare(value, r.Threshold) {
+		return AlertEvent{}, false
+	}
+	return AlertEvent{
+		RuleID:      r.ID,
+		City:        r.City,
+		Metric:      r.Metric,
+		Operator:    r.Operator,
+		Threshold:   r.Threshold,
+		Value:       value,
+		Channels:    r.Channels,
+		TriggeredAt: at,
+	}, true
+}
@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// MoonPhase — фаза Луны
+type MoonPhase string
+
+const (
+	MoonNew            MoonPhase = "new"
+	MoonWaxingCrescent MoonPhase = "waxing_crescent"
+	MoonFirstQuarter   MoonPhase = "first_quarter"
+	MoonWaxingGibbous  MoonPhase = "waxing_gibbous"
+	MoonFull           MoonPhase = "full"
+	MoonWaningGibbous  MoonPhase = "waning_gibbous"
+	MoonLastQuarter    MoonPhase = "last_quarter"
+	MoonWaningCrescent MoonPhase = "waning_crescent"
+)
+
+// moonPhaseLabels — названия фаз Луны по языкам
+var moonPhaseLabels = map[Language]map[MoonPhase]string{
+	LanguageRU: {
+		MoonNew:            "Новолуние",
+		MoonWaxingCrescent: "Растущий серп",
+		MoonFirstQuarter:   "Первая четверть",
+		MoonWaxingGibbous:  "Растущая Луна",
+		MoonFull:           "Полнолуние",
+		MoonWaningGibbous:  "Убывающая Луна",
+		MoonLastQuarter:    "Последняя четверть",
+		MoonWaningCrescent: "Убывающий серп",
+	},
+	LanguageEN: {
+		MoonNew:            "New moon",
+		MoonWaxingCrescent: "Waxing crescent",
+		MoonFirstQuarter:   "First quarter",
+		MoonWaxingGibbous:  "Waxing gibbous",
+		MoonFull:           "Full moon",
+		MoonWaningGibbous:  "Waning gibbous",
+		MoonLastQuarter:    "Last quarter",
+		MoonWaningCrescent: "Waning crescent",
+	},
+	LanguageDE: {
+		MoonNew:            "Neumond",
+		MoonWaxingCrescent: "Zunehmende Sichel",
+		MoonFirstQuarter:   "Erstes Viertel",
+		MoonWaxingGibbous:  "Zunehmender Mond",
+		MoonFull:           "Vollmond",
+		MoonWaningGibbous:  "Abnehmender Mond",
+		MoonLastQuarter:    "Letztes Viertel",
+		MoonWaningCrescent: "Abnehmende Sichel",
+	},
+}
+
+// Label возвращает название фазы на языке lang, "" если перевода нет
+func (p MoonPhase) Label(lang Language) string {
+	return moonPhaseLabels[lang][p]
+}
+
+// Astro — астрономические данные города на дату. Во время полярного дня
+// или ночи восхода и захода нет, соответствующие поля пусты.
+type Astro struct {
+	Date             string     `json:"date"` // YYYY-MM-DD в часовом поясе города
+	Sunrise          *time.Time `json:"sunrise,omitempty"`
+	Sunset           *time.Time `json:"sunset,omitempty"`
+	SolarNoon        time.Time  `json:"solar_noon"`
+	DayLengthSeconds int64      `json:"day_length_seconds"`
+	PolarDay         bool       `json:"polar_day,omitempty"`
+	PolarNight       bool       `json:"polar_night,omitempty"`
+	MoonPhase        MoonPhase  `json:"moon_phase"`
+	MoonPhaseName    string     `json:"moon_phase_name,omitempty"`
+	MoonIllumination float64    `json:"moon_illumination"` // доля освещенного диска, 0..1
+	MoonAgeDays      float64    `json:"moon_age_days"`     // дней после новолуния
+}
+
+// Translate заполняет название фазы Луны на языке lang
+func (a *Astro) Translate(lang Language) {
+	a.MoonPhaseName = a.MoonPhase.Label(lang)
+}
+
+// AstroResponse — ответ /astro/{city}
+type AstroResponse struct {
+	City     string  `json:"city"`
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	Timezone string  `json:"timezone"`
+	Astro
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// City — справочные данные о городе: координаты, часовой пояс и синонимы
+type City struct {
+	Name     string   `json:"name"`
+	Country  string   `json:"country,omitempty"`
+	Lat      float64  `json:"lat"`
+	Lon      float64  `json:"lon"`
+	Timezone string   `json:"timezone,omitempty"` // IANA, например Europe/Moscow
+	Aliases  []string `json:"aliases,omitempty"`
+	Enabled  bool     `json:"enabled"` // false — коллектор и планировщик город не опрашивают
+}
+
+// CityDirectoryResponse — справочник городов для администратора
+type CityDirectoryResponse struct {
+	Cities []City `json:"cities"`
+	Total  int    `json:"total"`
+}
+
+// DefaultCities — стартовый набор городов для сбора и заполнения справочника
+var DefaultCities = []City{
+	{Name: "Moscow", Country: "RU", Lat: 55.7558, Lon: 37.6173, Timezone: "Europe/Moscow", Aliases: []string{"Москва", "MSK"}, Enabled: true},
+	{Name: "London", Country: "GB", Lat: 51.5074, Lon: -0.1278, Timezone: "Europe/London", Aliases: []string{"Лондон"}, Enabled: true},
+	{Name: "New York", Country: "US", Lat: 40.7128, Lon: -74.0060, Timezone: "America/New_York", Aliases: []string{"NYC", "Нью-Йорк"}, Enabled: true},
+	{Name: "Berlin", Country: "DE", Lat: 52.5200, Lon: 13.4050, Timezone: "Europe/Berlin", Aliases: []string{"Берлин"}, Enabled: true},
+	{Name: "Tokyo", Country: "JP", Lat: 35.6762, Lon: 139.6503, Timezone: "Asia/Tokyo", Aliases: []string{"Токио"}, Enabled: true},
+}
+
+// Location возвращает часовой пояс города, UTC если он не задан
+func (c City) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный часовой пояс %s: %w", c.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Matches сообщает, совпадает ли имя с названием или одним из синонимов
+func (c City) Matches(name string) bool {
+	if strings.EqualFold(c.Name, name) {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPrefix сообщает, начинается ли название или один из синонимов с prefix
+// без учета регистра
+func (c City) HasPrefix(prefix string) bool {
+	prefix = strings.ToLower(prefix)
+	if strings.HasPrefix(strings.ToLower(c.Name), prefix) {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if strings.HasPrefix(strings.ToLower(alias), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет название, координаты и часовой пояс
+func (c City) Validate() error {
+	var errs ValidationErrors
+
+	name := strings.TrimSpace(c.Name)
+	switch {
+	case name == "":
+		errs.add("name", "обязательное поле")
+	case len(name) > MaxCityLength:
+		errs.add("name", "слишком длинное название")
+	}
+
+	if c.Lat < -90 || c.Lat > 90 {
+		errs.add("lat", "широта вне диапазона [-90, 90]")
+	}
+	if c.Lon < -180 || c.Lon > 180 {
+		errs.add("lon", "долгота вне диапазона [-180, 180]")
+	}
+	if len(c.Country) > 2 {
+		errs.add("country", "ожидается код страны ISO 3166-1 alpha-2")
+	}
+	if _, err := c.Location(); err != nil {
+		errs.add("timezone", "неизвестный часовой пояс")
+	}
+
+	return errs.err()
+}
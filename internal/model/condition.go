@@ -0,0 +1,150 @@
+package model
+
+import (
+	"strings"
+)
+
+// ConditionCode — нормализованное машинно-читаемое описание погоды
+type ConditionCode string
+
+const (
+	ConditionClear        ConditionCode = "clear"
+	ConditionPartlyCloudy ConditionCode = "partly_cloudy"
+	ConditionCloudy       ConditionCode = "cloudy"
+	ConditionRain         ConditionCode = "rain"
+	ConditionSnow         ConditionCode = "snow"
+	ConditionSleet        ConditionCode = "sleet"
+	ConditionStorm        ConditionCode = "storm"
+	ConditionFog          ConditionCode = "fog"
+	ConditionUnknown      ConditionCode = "unknown"
+)
+
+// ConditionCodes — все известные коды в порядке объявления
+var ConditionCodes = []ConditionCode{
+	ConditionClear,
+	ConditionPartlyCloudy,
+	ConditionCloudy,
+	ConditionRain,
+	ConditionSnow,
+	ConditionSleet,
+	ConditionStorm,
+	ConditionFog,
+	ConditionUnknown,
+}
+
+// IsKnown сообщает, входит ли код в таксономию
+func (c ConditionCode) IsKnown() bool {
+	for _, known := range ConditionCodes {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// providerConditions — таблицы соответствия сырых строк провайдеров кодам.
+// Ключи провайдеров и строк хранятся в нижнем регистре.
+var providerConditions = map[string]map[string]ConditionCode{
+	"openweathermap": {
+		"clear":        ConditionClear,
+		"clear sky":    ConditionClear,
+		"few clouds":   ConditionPartlyCloudy,
+		"clouds":       ConditionCloudy,
+		"cloudy":       ConditionCloudy,
+		"drizzle":      ConditionRain,
+		"rain":         ConditionRain,
+		"thunderstorm": ConditionStorm,
+		"snow":         ConditionSnow,
+		"mist":         ConditionFog,
+		"fog":          ConditionFog,
+		"haze":         ConditionFog,
+	},
+	"weatherapi": {
+		"sunny":                       ConditionClear,
+		"clear":                       ConditionClear,
+		"partly cloudy":               ConditionPartlyCloudy,
+		"cloudy":                      ConditionCloudy,
+		"overcast":                    ConditionCloudy,
+		"patchy rain possible":        ConditionRain,
+		"light rain shower":           ConditionRain,
+		"light drizzle":               ConditionRain,
+		"moderate rain":               ConditionRain,
+		"heavy rain":                  ConditionRain,
+		"light sleet":                 ConditionSleet,
+		"light snow":                  ConditionSnow,
+		"heavy snow":                  ConditionSnow,
+		"blizzard":                    ConditionSnow,
+		"thundery outbreaks possible": ConditionStorm,
+		"mist":                        ConditionFog,
+		"fog":                         ConditionFog,
+	},
+	"met.no": {
+		"clearsky":     ConditionClear,
+		"fair":         ConditionPartlyCloudy,
+		"partlycloudy": ConditionPartlyCloudy,
+		"cloudy":       ConditionCloudy,
+		"lightrain":    ConditionRain,
+		"rain":         ConditionRain,
+		"heavyrain":    ConditionRain,
+		"rainshowers":  ConditionRain,
+		"sleet":        ConditionSleet,
+		"snow":         ConditionSnow,
+		"fog":          ConditionFog,
+	},
+}
+
+// conditionKeywords — запасное сопоставление по ключевым словам, порядок важен
+var conditionKeywords = []struct {
+	keyword string
+	code    ConditionCode
+}{
+	{"thunder", ConditionStorm},
+	{"storm", ConditionStorm},
+	{"sleet", ConditionSleet},
+	{"snow", ConditionSnow},
+	{"blizzard", ConditionSnow},
+	{"drizzle", ConditionRain},
+	{"shower", ConditionRain},
+	{"rain", ConditionRain},
+	{"fog", ConditionFog},
+	{"mist", ConditionFog},
+	{"haze", ConditionFog},
+	{"partly", ConditionPartlyCloudy},
+	{"few clouds", ConditionPartlyCloudy},
+	{"overcast", ConditionCloudy},
+	{"cloud", ConditionCloudy},
+	{"clear", ConditionClear},
+	{"sun", ConditionClear},
+}
+
+// NormalizeCondition переводит сырую строку провайдера в код таксономии
+func NormalizeCondition(provider, raw string) ConditionCode {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return ConditionUnknown
+	}
+
+	if table, ok := providerConditions[strings.ToLower(provider)]; ok {
+		if code, ok := table[value]; ok {
+			return code
+		}
+	}
+
+	if code := ConditionCode(value); code.IsKnown() {
+		return code
+	}
+
+	for _, kw := range conditionKeywords {
+		if strings.Contains(value, kw.keyword) {
+			return kw.code
+		}
+	}
+	return ConditionUnknown
+}
+
+// NormalizeCondition заполняет ConditionCode, если провайдер его не прислал
+func (d *WeatherData) NormalizeCondition() {
+	if d.ConditionCode == "" {
+		d.ConditionCode = NormalizeCondition(d.Provider, d.Condition)
+	}
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventSchemaVersion — текущая версия формата конверта
+const EventSchemaVersion = 1
+
+// Типы событий, передаваемых через Kafka
+const (
+	EventWeatherObserved = "weather.observed"
+)
+
+// ErrUnsupportedSchema возвращается для конвертов новее, чем умеет читать сервис
+var ErrUnsupportedSchema = errors.New("неподдерживаемая версия схемы события")
+
+// Event — версионированный конверт для всех сообщений в Kafka
+type Event struct {
+	SchemaVersion int             `json:"schema_version"`
+	Type          string          `json:"type"`
+	Source        string          `json:"source"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewEvent упаковывает payload в конверт текущей версии
+func NewEvent(eventType, source string, occurredAt time.Time, payload any) (Event, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("ошибка сериализации payload: %w", err)
+	}
+
+	return Event{
+		SchemaVersion: EventSchemaVersion,
+		Type:          eventType,
+		Source:        source,
+		OccurredAt:    occurredAt.UTC(),
+		Payload:       raw,
+	}, nil
+}
+
+// Marshal сериализует конверт для отправки
+func (e Event) Marshal() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalEvent разбирает сообщение и согласует версию схемы.
+// Сообщения без конверта (версия 0) — это голый WeatherData от старых коллекторов.
+func UnmarshalEvent(data []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Event{}, fmt.Errorf("ошибка разбора события: %w", err)
+	}
+
+	switch {
+	case e.SchemaVersion == 0 && e.Type == "":
+		var legacy WeatherData
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return Event{}, fmt.Errorf("ошибка разбора устаревшего сообщения: %w", err)
+		}
+		return Event{
+			SchemaVersion: 0,
+			Type:          EventWeatherObserved,
+			OccurredAt:    legacy.Timestamp,
+			Payload:       json.RawMessage(data),
+		}, nil
+	case e.SchemaVersion > EventSchemaVersion:
+		return Event{}, fmt.Errorf("%w: %d", ErrUnsupportedSchema, e.SchemaVersion)
+	}
+
+	return e, nil
+}
+
+// DecodePayload разбирает полезную нагрузку события в v
+func (e Event) DecodePayload(v any) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("ошибка разбора payload события %s: %w", e.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// WeatherRecord — плоская запись замера для выгрузки в CSV и XML.
+// Астрономические данные в выгрузку не попадают.
+type WeatherRecord struct {
+	XMLName       xml.Name `xml:"weather"`
+	City          string   `xml:"city"`
+	Temperature   float64  `xml:"temperature"`
+	Units         Units    `xml:"units,omitempty"`
+	Condition     string   `xml:"condition"`
+	ConditionCode string   `xml:"condition_code,omitempty"`
+	Provider      string   `xml:"provider"`
+	Timestamp     string   `xml:"timestamp"`
+	TimestampUTC  string   `xml:"timestamp_utc"`
+	Timezone      string   `xml:"timezone"`
+	Cached        bool     `xml:"cached"`
+}
+
+var weatherCSVHeader = []string{
+	"city", "temperature", "units", "condition", "condition_code",
+	"provider", "timestamp", "timestamp_utc", "timezone", "cached",
+}
+
+// Record возвращает плоскую запись замера; время в RFC3339, как в JSON
+func (r WeatherResponse) Record() WeatherRecord {
+	utc := r.TimestampUTC
+	if utc.IsZero() {
+		utc = r.Timestamp
+	}
+	return WeatherRecord{
+		City:          r.City,
+		Temperature:   r.Temp,
+		Units:         r.Units,
+		Condition:     r.Condition,
+		ConditionCode: string(r.ConditionCode),
+		Provider:      r.Provider,
+		Timestamp:     r.Timestamp.Format(time.RFC3339),
+		TimestampUTC:  utc.UTC().Format(time.RFC3339),
+		Timezone:      r.Timezone,
+		Cached:        r.Cached,
+	}
+}
+
+func (rec WeatherRecord) csvRow() []string {
+	return []string{
+		rec.City,
+		strconv.FormatFloat(rec.Temperature, 'f', -1, 64),
+		string(rec.Units),
+		rec.Condition,
+		rec.ConditionCode,
+		rec.Provider,
+		rec.Timestamp,
+		rec.TimestampUTC,
+		rec.Timezone,
+		strconv.FormatBool(rec.Cached),
+	}
+}
+
+// CSV возвращает заголовок и строку замера
+func (r WeatherResponse) CSV() ([]string, [][]string) {
+	return weatherCSVHeader, [][]string{r.Record().csvRow()}
+}
+
+// XML возвращает документ <weather>
+func (r WeatherResponse) XML() any {
+	return r.Record()
+}
+
+// historyXML — документ <history> с замерами за период
+type historyXML struct {
+	XMLName  xml.Name        `xml:"history"`
+	City     string          `xml:"city,attr"`
+	From     string          `xml:"from,attr"`
+	To       string          `xml:"to,attr"`
+	Total    int             `xml:"total,attr"`
+	Readings []WeatherRecord `xml:"weather"`
+}
+
+// CSV возвращает заголовок и по строке на замер
+func (r HistoryResponse) CSV() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Readings))
+	for _, reading := range r.Readings {
+		rows = append(rows, reading.Record().csvRow())
+	}
+	return weatherCSVHeader, rows
+}
+
+// XML возвращает документ <history>
+func (r HistoryResponse) XML() any {
+	doc := historyXML{
+		City:     r.City,
+		From:     r.From.Format(time.RFC3339),
+		To:       r.To.Format(time.RFC3339),
+		Total:    r.Total,
+		Readings: make([]WeatherRecord, 0, len(r.Readings)),
+	}
+	for _, reading := range r.Readings {
+		doc.Readings = append(doc.Readings, reading.Record())
+	}
+	return doc
+}
+
+// citiesXML — документ <cities>; атрибуты страницы только для постраничного запроса
+type citiesXML struct {
+	XMLName  xml.Name `xml:"cities"`
+	Total    int      `xml:"total,attr"`
+	Page     int      `xml:"page,attr,omitempty"`
+	PerPage  int      `xml:"per_page,attr,omitempty"`
+	NextPage *int     `xml:"next_page,attr,omitempty"`
+	Cities   []string `xml:"city"`
+}
+
+// CSV возвращает столбец city
+func (r CitiesResponse) CSV() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Cities))
+	for _, city := range r.Cities {
+		rows = append(rows, []string{city})
+	}
+	return []string{"city"}, rows
+}
+
+// XML возвращает документ <cities>
+func (r CitiesResponse) XML() any {
+	return citiesXML{
+		Total:    r.Total,
+		Page:     r.Page,
+		PerPage:  r.PerPage,
+		NextPage: r.NextPage,
+		Cities:   r.Cities,
+	}
+}
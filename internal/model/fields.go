@@ -0,0 +1,29 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// WeatherFields — поля JSON-ответа о погоде, доступные в ?fields=
+var WeatherFields = []string{
+	"city", "temperature", "condition", "condition_code", "provider",
+	"timestamp", "timestamp_utc", "timezone", "cached", "units", "astro",
+}
+
+// ParseFields разбирает список полей через запятую; пустая строка — все поля
+func ParseFields(s string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(s, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if !slices.Contains(WeatherFields, field) {
+			return nil, fmt.Errorf("неизвестное поле %q, допустимо: %s", field, strings.Join(WeatherFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
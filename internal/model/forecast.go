@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ProviderInternal — прогноз построен самим сервисом, а не внешним провайдером
+const ProviderInternal = "internal"
+
+// EventForecastIssued — провайдер выпустил прогноз для города
+const EventForecastIssued = "forecast.issued"
+
+// MaxForecastDays — наибольшая глубина прогноза в API и от провайдеров
+const MaxForecastDays = 16
+
+// Forecast — прогноз показателей на момент ForecastFor
+type Forecast struct {
+	City          string        `json:"city"`
+	Provider      string        `json:"provider"`
+	ForecastFor   time.Time     `json:"forecast_for"`
+	Temp          float64       `json:"temperature"`
+	ConditionCode ConditionCode `json:"condition_code,omitempty"`
+	IssuedAt      time.Time     `json:"issued_at"`
+}
+
+// ForecastBatch — полный прогноз провайдера для города; заменяет предыдущий
+type ForecastBatch struct {
+	City      string     `json:"city"`
+	Provider  string     `json:"provider"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	Forecasts []Forecast `json:"forecasts"`
+}
+
+// Validate проверяет город, провайдера и точки прогноза
+func (b ForecastBatch) Validate() error {
+	var errs ValidationErrors
+
+	city := strings.TrimSpace(b.City)
+	switch {
+	case city == "":
+		errs.add("city", "обязательное поле")
+	case len(city) > MaxCityLength:
+		errs.add("city", "слишком длинное название")
+	}
+	switch {
+	case b.Provider == "":
+		errs.add("provider", "обязательное поле")
+	case b.Provider == ProviderInternal:
+		errs.add("provider", "провайдер internal зарезервирован за внутренним прогнозом")
+	case len(b.Provider) > MaxProviderLen:
+		errs.add("provider", "слишком длинное значение")
+	}
+	validateTimestamp(&errs, "issued_at", b.IssuedAt)
+
+	horizon := b.IssuedAt.Add(MaxForecastDays * 24 * time.Hour)
+	for i, f := range b.Forecasts {
+		field := fmt.Sprintf("forecasts[%d]", i)
+		if f.ForecastFor.IsZero() || f.ForecastFor.After(horizon) {
+			errs.add(field+".forecast_for", "время вне горизонта прогноза")
+		}
+		if math.IsNaN(f.Temp) || f.Temp < MinTemperature || f.Temp > MaxTemperature {
+			errs.add(field+".temperature", "значение вне допустимого диапазона")
+		}
+		if f.ConditionCode != "" && !f.ConditionCode.IsKnown() {
+			errs.add(field+".condition_code", "неизвестный код погоды")
+		}
+	}
+
+	return errs.err()
+}
+
+type ForecastResponse struct {
+	City      string     `json:"city"`
+	Days      int        `json:"days"`
+	Forecasts []Forecast `json:"forecasts"`
+	Total     int        `json:"total"`
+	Units     Units      `json:"units,omitempty"`
+}
+
+// ConvertUnits переводит температуры из канонических (metric) в систему u
+func (r *ForecastResponse) ConvertUnits(u Units) {
+	for i := range r.Forecasts {
+		r.Forecasts[i].Temp = Temperature(r.Forecasts[i].Temp).In(u)
+	}
+	r.Units = u
+}
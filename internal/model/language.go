@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Language — язык текстовых полей ответа
+type Language string
+
+const (
+	LanguageRU Language = "ru"
+	LanguageEN Language = "en"
+	LanguageDE Language = "de"
+)
+
+// ParseLanguage разбирает код языка, пустая строка — ru
+func ParseLanguage(s string) (Language, error) {
+	switch l := Language(strings.ToLower(strings.TrimSpace(s))); l {
+	case "":
+		return LanguageRU, nil
+	case LanguageRU, LanguageEN, LanguageDE:
+		return l, nil
+	default:
+		return "", fmt.Errorf("неподдерживаемый язык: %s", s)
+	}
+}
+
+// conditionLabels — названия состояний погоды по языкам
+var conditionLabels = map[Language]map[ConditionCode]string{
+	LanguageRU: {
+		ConditionClear:        "Ясно",
+		ConditionPartlyCloudy: "Переменная облачность",
+		ConditionCloudy:       "Облачно",
+		ConditionRain:         "Дождь",
+		ConditionSnow:         "Снег",
+		ConditionSleet:        "Мокрый снег",
+		ConditionStorm:        "Гроза",
+		ConditionFog:          "Туман",
+	},
+	LanguageEN: {
+		ConditionClear:        "Clear",
+		ConditionPartlyCloudy: "Partly cloudy",
+		ConditionCloudy:       "Cloudy",
+		ConditionRain:         "Rain",
+		ConditionSnow:         "Snow",
+		ConditionSleet:        "Sleet",
+		ConditionStorm:        "Thunderstorm",
+		ConditionFog:          "Fog",
+	},
+	LanguageDE: {
+		ConditionClear:        "Klar",
+		ConditionPartlyCloudy: "Teilweise bewölkt",
+		ConditionCloudy:       "Bewölkt",
+		ConditionRain:         "Regen",
+		ConditionSnow:         "Schnee",
+		ConditionSleet:        "Schneeregen",
+		ConditionStorm:        "Gewitter",
+		ConditionFog:          "Nebel",
+	},
+}
+
+// Label возвращает название состояния на языке lang, "" если перевода нет
+func (c ConditionCode) Label(lang Language) string {
+	return conditionLabels[lang][c]
+}
@@ -0,0 +1,28 @@
+package model
+
+// Действия клиента в WebSocket /ws
+const (
+	LiveSubscribe   = "subscribe"
+	LiveUnsubscribe = "unsubscribe"
+)
+
+// Типы сообщений сервера в WebSocket /ws
+const (
+	LiveSubscribed = "subscribed" // текущий список подписок после изменения
+	LiveWeather    = "weather"    // новый замер города
+	LiveError      = "error"      // ошибка в сообщении клиента, соединение не закрывается
+)
+
+// LiveRequest — сообщение клиента: подписка или отписка от городов
+type LiveRequest struct {
+	Action string   `json:"action"`
+	Cities []string `json:"cities"`
+}
+
+// LiveMessage — сообщение сервера; заполнено поле, соответствующее Type
+type LiveMessage struct {
+	Type    string           `json:"type"`
+	Cities  []string         `json:"cities,omitempty"`
+	Weather *WeatherResponse `json:"weather,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
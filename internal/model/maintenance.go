@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// DefaultMaintenanceRetryAfter — Retry-After режима обслуживания, если администратор его не задал
+const DefaultMaintenanceRetryAfter = 60
+
+// Maintenance — включенный режим обслуживания: API отвечает 503 всем, кроме
+// проверок здоровья и администратора
+type Maintenance struct {
+	Message           string     `json:"message,omitempty"`
+	RetryAfterSeconds int        `json:"retry_after_seconds"`
+	Since             time.Time  `json:"since"`
+	Until             *time.Time `json:"until,omitempty"` // режим снимется сам; nil — до выключения
+	By                string     `json:"by,omitempty"`    // администратор, включивший режим
+}
+
+// MaintenanceRequest — тело PUT /admin/maintenance
+type MaintenanceRequest struct {
+	Message           string `json:"message"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"` // 0 — DefaultMaintenanceRetryAfter
+	DurationSeconds   int    `json:"duration_seconds"`    // 0 — до явного выключения
+}
+
+// MaintenanceStatus — состояние режима обслуживания
+type MaintenanceStatus struct {
+	Enabled     bool         `json:"enabled"`
+	Maintenance *Maintenance `json:"maintenance,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// DeliveryStatus — итог доставки оповещения в канал
+type DeliveryStatus string
+
+const (
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+	DeliverySkipped   DeliveryStatus = "skipped" // канал не настроен
+)
+
+// Delivery — запись о доставке одного оповещения в один канал
+type Delivery struct {
+	ID          int64          `json:"id"`
+	AlertID     int64          `json:"alert_id"`
+	RuleID      int64          `json:"rule_id"`
+	City        string         `json:"city"`
+	Channel     string         `json:"channel"`
+	Status      DeliveryStatus `json:"status"`
+	Attempts    int            `json:"attempts"`
+	LastError   string         `json:"last_error,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeliveredAt *time.Time     `json:"delivered_at,omitempty"`
+}
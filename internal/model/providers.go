@@ -0,0 +1,35 @@
+package model
+
+// ProviderConsensus — согласованное значение по последним замерам провайдеров
+type ProviderConsensus struct {
+	Temp          float64       `json:"temperature"` // медиана температур
+	Condition     string        `json:"condition"`   // самое частое состояние
+	ConditionCode ConditionCode `json:"condition_code,omitempty"`
+	Spread        float64       `json:"spread"` // разница между максимальной и минимальной температурой
+	Providers     int           `json:"providers"`
+	Units         Units         `json:"units,omitempty"` // Единицы значений; пусто — metric
+}
+
+// ConvertUnits переводит значения из канонических (metric) в систему u.
+// Разброс — разность температур, поэтому сдвиг шкалы к нему не применяется.
+func (c *ProviderConsensus) ConvertUnits(u Units) {
+	c.Temp = Temperature(c.Temp).In(u)
+	if u == UnitsImperial {
+		c.Spread = c.Spread * 9 / 5
+	}
+	c.Units = u
+}
+
+// Translate заменяет строку состояния названием на языке lang, если код известен
+func (c *ProviderConsensus) Translate(lang Language) {
+	if label := c.ConditionCode.Label(lang); label != "" {
+		c.Condition = label
+	}
+}
+
+// ProvidersResponse — последние замеры каждого провайдера города и их консенсус
+type ProvidersResponse struct {
+	City      string            `json:"city"`
+	Readings  []WeatherResponse `json:"readings"`
+	Consensus ProviderConsensus `json:"consensus"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Признаки подозрительного замера
+const (
+	QualityOutlier      = "outlier"      // z-оценка относительно скользящей истории выше порога
+	QualityDisagreement = "disagreement" // расхождение с другими провайдерами выше порога
+)
+
+// Итоговое состояние потока данных города
+const (
+	QualityOK      = "ok"
+	QualitySuspect = "suspect"
+	QualityBad     = "bad"
+	QualityStale   = "stale" // замеров давно не было
+	QualityUnknown = "unknown"
+)
+
+// QualityScore — оценка одного замера
+type QualityScore struct {
+	City         string    `json:"city"`
+	Provider     string    `json:"provider"`
+	Temp         float64   `json:"temperature"`
+	ZScore       float64   `json:"z_score"`
+	Disagreement float64   `json:"disagreement"` // |t - медиана других провайдеров|, °C
+	Flags        []string  `json:"flags,omitempty"`
+	ObservedAt   time.Time `json:"observed_at"`
+}
+
+// Anomalous — замер помечен хотя бы одним признаком
+func (s QualityScore) Anomalous() bool {
+	return len(s.Flags) > 0
+}
+
+// QualityReport — сводка качества данных города за окно
+type QualityReport struct {
+	City          string         `json:"city"`
+	Status        string         `json:"status"`
+	WindowFrom    time.Time      `json:"window_from"`
+	WindowTo      time.Time      `json:"window_to"`
+	Readings      int            `json:"readings"`
+	Anomalies     int            `json:"anomalies"`
+	AnomalyRate   float64        `json:"anomaly_rate"`
+	ByFlag        map[string]int `json:"by_flag"`
+	LastReadingAt *time.Time     `json:"last_reading_at,omitempty"`
+	Recent        []QualityScore `json:"recent_anomalies"`
+}
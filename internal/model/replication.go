@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// EventWeatherUpserted — текущее состояние города записано в БД основного региона.
+// Payload — WeatherData, где Timestamp равен updated_at записанной строки.
+const EventWeatherUpserted = "weather.upserted"
+
+// Статусы сравнения свежести регионов
+const (
+	ReplicaInSync  = "in_sync"
+	ReplicaLagging = "lagging"
+	ReplicaMissing = "missing" // города нет в одном из регионов
+)
+
+// ReplicaFreshness — время последнего обновления города в двух регионах
+type ReplicaFreshness struct {
+	City       string     `json:"city"`
+	LocalAt    *time.Time `json:"local_updated_at,omitempty"`
+	PeerAt     *time.Time `json:"peer_updated_at,omitempty"`
+	LagSeconds float64    `json:"lag_seconds"` // на сколько отстает более старая копия
+	Status     string     `json:"status"`
+}
+
+type ReplicationStatusResponse struct {
+	Region        string             `json:"region"`
+	Cities        []ReplicaFreshness `json:"cities"`
+	Total         int                `json:"total"`
+	MaxLagSeconds float64            `json:"max_lag_seconds"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// EventFetchRequested — команда воркеру-коллектору собрать погоду для города
+const EventFetchRequested = "fetch.requested"
+
+// DefaultFetchInterval — период опроса города, если он не задан в расписании
+const DefaultFetchInterval = time.Minute
+
+// FetchRequest — полезная нагрузка команды fetch.requested
+type FetchRequest struct {
+	City        string    `json:"city"`
+	Lat         float64   `json:"lat"`
+	Lon         float64   `json:"lon"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// CitySchedule — расписание опроса города, хранится в БД планировщика
+type CitySchedule struct {
+	City            string        `json:"city"`
+	Interval        time.Duration `json:"interval"`
+	Enabled         bool          `json:"enabled"`
+	LastRequestedAt *time.Time    `json:"last_requested_at,omitempty"`
+	Lat             float64       `json:"lat"`
+	Lon             float64       `json:"lon"`
+}
+
+// Due сообщает, пора ли отправить команду на сбор
+func (s CitySchedule) Due(now time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	if s.LastRequestedAt == nil {
+		return true
+	}
+	return !now.Before(s.LastRequestedAt.Add(s.Interval))
+}
+
+// FetchRequest строит команду на сбор по расписанию
+func (s CitySchedule) FetchRequest(at time.Time) FetchRequest {
+	return FetchRequest{
+		City:        s.City,
+		Lat:         s.Lat,
+		Lon:         s.Lon,
+		ScheduledAt: at.UTC(),
+	}
+}
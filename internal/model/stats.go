@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// StatsPeriods — периоды сводки температуры
+var StatsPeriods = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// DefaultStatsPeriod — период сводки, если он не указан
+const DefaultStatsPeriod = "24h"
+
+// ParseStatsPeriod разбирает период сводки, пустая строка — DefaultStatsPeriod
+func ParseStatsPeriod(s string) (string, time.Duration, error) {
+	if s == "" {
+		s = DefaultStatsPeriod
+	}
+	d, ok := StatsPeriods[s]
+	if !ok {
+		return "", 0, fmt.Errorf("неизвестный период %q, допустимо: 24h, 7d, 30d", s)
+	}
+	return s, d, nil
+}
+
+// WeatherStats — сводка температуры города по истории замеров за период.
+// При Count = 0 минимум, максимум и среднее не заполняются.
+type WeatherStats struct {
+	City    string    `json:"city"`
+	Period  string    `json:"period"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Count   int       `json:"count"`
+	TempMin *float64  `json:"temp_min,omitempty"`
+	TempMax *float64  `json:"temp_max,omitempty"`
+	TempAvg *float64  `json:"temp_avg,omitempty"`
+	Units   Units     `json:"units,omitempty"`
+}
+
+// ConvertUnits переводит температуры из канонических (metric) в систему u
+func (s *WeatherStats) ConvertUnits(u Units) {
+	for _, t := range []*float64{s.TempMin, s.TempMax, s.TempAvg} {
+		if t != nil {
+			*t = math.Round(Temperature(*t).In(u)*10) / 10
+		}
+	}
+	s.Units = u
+}
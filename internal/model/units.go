@@ -0,0 +1,170 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Units — система единиц измерения, в которой отдаются значения
+type Units string
+
+const (
+	UnitsMetric   Units = "metric"   // °C, км/ч, гПа
+	UnitsImperial Units = "imperial" // °F, mph, inHg
+	UnitsKelvin   Units = "kelvin"   // K, м/с, гПа
+)
+
+// ParseUnits разбирает название системы единиц, пустая строка — metric
+func ParseUnits(s string) (Units, error) {
+	switch u := Units(strings.ToLower(strings.TrimSpace(s))); u {
+	case "":
+		return UnitsMetric, nil
+	case UnitsMetric, UnitsImperial, UnitsKelvin:
+		return u, nil
+	default:
+		return "", fmt.Errorf("неизвестная система единиц: %s", s)
+	}
+}
+
+// TemperatureSymbol возвращает обозначение единицы температуры
+func (u Units) TemperatureSymbol() string {
+	switch u {
+	case UnitsImperial:
+		return "°F"
+	case UnitsKelvin:
+		return "K"
+	default:
+		return "°C"
+	}
+}
+
+// SpeedSymbol возвращает обозначение единицы скорости
+func (u Units) SpeedSymbol() string {
+	switch u {
+	case UnitsImperial:
+		return "mph"
+	case UnitsKelvin:
+		return "m/s"
+	default:
+		return "km/h"
+	}
+}
+
+// PressureSymbol возвращает обозначение единицы давления
+func (u Units) PressureSymbol() string {
+	if u == UnitsImperial {
+		return "inHg"
+	}
+	return "hPa"
+}
+
+// Temperature хранится канонически в градусах Цельсия
+type Temperature float64
+
+func TemperatureFromFahrenheit(f float64) Temperature {
+	return Temperature((f - 32) * 5 / 9)
+}
+
+func TemperatureFromKelvin(k float64) Temperature {
+	return Temperature(k - 273.15)
+}
+
+func (t Temperature) Celsius() float64 {
+	return float64(t)
+}
+
+func (t Temperature) Fahrenheit() float64 {
+	return float64(t)*9/5 + 32
+}
+
+func (t Temperature) Kelvin() float64 {
+	return float64(t) + 273.15
+}
+
+// In возвращает значение в указанной системе единиц
+func (t Temperature) In(u Units) float64 {
+	switch u {
+	case UnitsImperial:
+		return t.Fahrenheit()
+	case UnitsKelvin:
+		return t.Kelvin()
+	default:
+		return t.Celsius()
+	}
+}
+
+// Speed хранится канонически в км/ч
+type Speed float64
+
+const (
+	kmhPerMPS = 3.6
+	kmhPerMPH = 1.609344
+)
+
+func SpeedFromMetersPerSecond(mps float64) Speed {
+	return Speed(mps * kmhPerMPS)
+}
+
+func SpeedFromMilesPerHour(mph float64) Speed {
+	return Speed(mph * kmhPerMPH)
+}
+
+func (s Speed) KilometersPerHour() float64 {
+	return float64(s)
+}
+
+func (s Speed) MetersPerSecond() float64 {
+	return float64(s) / kmhPerMPS
+}
+
+func (s Speed) MilesPerHour() float64 {
+	return float64(s) / kmhPerMPH
+}
+
+// In возвращает значение в указанной системе единиц
+func (s Speed) In(u Units) float64 {
+	switch u {
+	case UnitsImperial:
+		return s.MilesPerHour()
+	case UnitsKelvin:
+		return s.MetersPerSecond()
+	default:
+		return s.KilometersPerHour()
+	}
+}
+
+// Pressure хранится канонически в гектопаскалях
+type Pressure float64
+
+const (
+	hPaPerInHg = 33.8639
+	hPaPerMmHg = 1.33322
+)
+
+func PressureFromInchesOfMercury(inHg float64) Pressure {
+	return Pressure(inHg * hPaPerInHg)
+}
+
+func PressureFromMillimetersOfMercury(mmHg float64) Pressure {
+	return Pressure(mmHg * hPaPerMmHg)
+}
+
+func (p Pressure) Hectopascals() float64 {
+	return float64(p)
+}
+
+func (p Pressure) InchesOfMercury() float64 {
+	return float64(p) / hPaPerInHg
+}
+
+func (p Pressure) MillimetersOfMercury() float64 {
+	return float64(p) / hPaPerMmHg
+}
+
+// In возвращает значение в указанной системе единиц
+func (p Pressure) In(u Units) float64 {
+	if u == UnitsImperial {
+		return p.InchesOfMercury()
+	}
+	return p.Hectopascals()
+}
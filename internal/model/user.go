@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// User — пользователь API, получающий токен по логину и паролю
+type User struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	AccountID *int64    `json:"account_id,omitempty"` // аккаунт API, от имени которого работает пользователь
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TokenRequest — тело POST /api/v1/auth/token
+type TokenRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// TokenResponse — выпущенный токен доступа
+type TokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"` // секунд
+	ExpiresAt   time.Time `json:"expires_at"`
+	Role        string    `json:"role"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// CityEntry — город списка API v2 с источником последнего замера
+type CityEntry struct {
+	City      string    `json:"city"`
+	Provider  string    `json:"provider"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProviderInfo — метаданные источника замера в ответах API v2
+type ProviderInfo struct {
+	Name       string    `json:"name"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	AgeSeconds int64     `json:"age_seconds"`
+}
+
+// NewProviderInfo описывает источник замера data на момент now
+func NewProviderInfo(data WeatherData, now time.Time) ProviderInfo {
+	return ProviderInfo{
+		Name:       data.Provider,
+		UpdatedAt:  data.Timestamp.UTC(),
+		AgeSeconds: int64(max(now.Sub(data.Timestamp), 0).Seconds()),
+	}
+}
+
+// CitiesV2Response — список городов API v2: каждый город со своим провайдером
+type CitiesV2Response struct {
+	Cities    []CityEntry `json:"cities"`
+	Total     int         `json:"total"`
+	Providers []string    `json:"providers"` // Провайдеры данных списка по алфавиту
+	Cached    bool        `json:"cached"`
+}
+
+// WeatherV2Response — текущая погода API v2: замер и его источник отдельно
+type WeatherV2Response struct {
+	Weather  WeatherResponse `json:"weather"`
+	Provider ProviderInfo    `json:"provider"`
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Допустимые границы значений для валидации
+const (
+	MinTemperature  = -100.0
+	MaxTemperature  = 70.0
+	MaxCityLength   = 100
+	MaxProviderLen  = 100
+	MaxConditionLen = 255
+	MaxFutureSkew   = 5 * time.Minute
+)
+
+// MinTimestamp — самая ранняя допустимая отметка времени наблюдения
+var MinTimestamp = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FieldError описывает ошибку валидации конкретного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors — набор ошибок валидации по полям
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, fe := range e {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return "ошибка валидации: " + strings.Join(parts, "; ")
+}
+
+// add добавляет ошибку поля
+func (e *ValidationErrors) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// err возвращает nil, если ошибок нет
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate проверяет обязательные поля, диапазоны и адекватность времени
+func (d WeatherData) Validate() error {
+	var errs ValidationErrors
+
+	city := strings.TrimSpace(d.City)
+	switch {
+	case city == "":
+		errs.add("city", "обязательное поле")
+	case len(city) > MaxCityLength:
+		errs.add("city", "слишком длинное название")
+	}
+
+	if math.IsNaN(d.Temp) || math.IsInf(d.Temp, 0) {
+		errs.add("temperature", "некорректное число")
+	} else if d.Temp < MinTemperature || d.Temp > MaxTemperature {
+		errs.add("temperature", "значение вне допустимого диапазона")
+	}
+
+	if len(d.Condition) > MaxConditionLen {
+		errs.add("condition", "слишком длинное значение")
+	}
+	if d.ConditionCode != "" && !d.ConditionCode.IsKnown() {
+		errs.add("condition_code", "неизвестный код погоды")
+	}
+	if len(d.Provider) > MaxProviderLen {
+		errs.add("provider", "слишком длинное значение")
+	}
+
+	validateTimestamp(&errs, "timestamp", d.Timestamp)
+
+	return errs.err()
+}
+
+// MaxBatchSize — наибольшее число замеров в одном пакетном запросе
+const MaxBatchSize = 1000
+
+// ValidateBatch проверяет пакет замеров; поля ошибок получают префикс [i]
+func ValidateBatch(batch []WeatherData) error {
+	var errs ValidationErrors
+	if len(batch) == 0 {
+		errs.add("", "пустой пакет")
+	}
+	if len(batch) > MaxBatchSize {
+		errs.add("", fmt.Sprintf("не больше %d замеров за запрос", MaxBatchSize))
+	}
+	for i, data := range batch {
+		var item ValidationErrors
+		if errors.As(data.Validate(), &item) {
+			for _, fe := range item {
+				errs.add(fmt.Sprintf("[%d].%s", i, fe.Field), fe.Message)
+			}
+		}
+	}
+	return errs.err()
+}
+
+// validateTimestamp проверяет, что время задано и не уходит далеко в будущее или прошлое
+func validateTimestamp(errs *ValidationErrors, field string, ts time.Time) {
+	now := time.Now()
+	switch {
+	case ts.IsZero():
+		errs.add(field, "обязательное поле")
+	case ts.After(now.Add(MaxFutureSkew)):
+		errs.add(field, "время в будущем")
+	case ts.Before(MinTimestamp):
+		errs.add(field, "слишком старое время")
+	}
+}
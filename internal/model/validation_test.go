@@ -0,0 +1,111 @@
+package model_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+func validReading() model.WeatherData {
+	return model.WeatherData{
+		City:          "Moscow",
+		Temp:          -3.5,
+		Condition:     "Snow",
+		ConditionCode: model.ConditionSnow,
+		Provider:      "owm",
+		Timestamp:     time.Now().Add(-time.Minute),
+	}
+}
+
+// fields возвращает поля ошибок валидации по порядку
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var errs model.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ошибка %v не ValidationErrors", err)
+	}
+	out := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		out = append(out, fe.Field)
+	}
+	return out
+}
+
+func TestWeatherDataValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*model.WeatherData)
+		want   []string
+	}{
+		{"корректный замер", func(*model.WeatherData) {}, nil},
+		{"без города", func(d *model.WeatherData) { d.City = "" }, []string{"city"}},
+		{"город из пробелов", func(d *model.WeatherData) { d.City = "   " }, []string{"city"}},
+		{"длинный город", func(d *model.WeatherData) { d.City = strings.Repeat("a", model.MaxCityLength+1) }, []string{"city"}},
+		{"город на границе длины", func(d *model.WeatherData) { d.City = strings.Repeat("a", model.MaxCityLength) }, nil},
+		{"нижняя граница температуры", func(d *model.WeatherData) { d.Temp = model.MinTemperature }, nil},
+		{"верхняя граница температуры", func(d *model.WeatherData) { d.Temp = model.MaxTemperature }, nil},
+		{"слишком холодно", func(d *model.WeatherData) { d.Temp = model.MinTemperature - 0.1 }, []string{"temperature"}},
+		{"слишком жарко", func(d *model.WeatherData) { d.Temp = model.MaxTemperature + 0.1 }, []string{"temperature"}},
+		{"NaN", func(d *model.WeatherData) { d.Temp = math.NaN() }, []string{"temperature"}},
+		{"бесконечность", func(d *model.WeatherData) { d.Temp = math.Inf(-1) }, []string{"temperature"}},
+		{"длинное описание", func(d *model.WeatherData) { d.Condition = strings.Repeat("a", model.MaxConditionLen+1) }, []string{"condition"}},
+		{"неизвестный код погоды", func(d *model.WeatherData) { d.ConditionCode = "volcano" }, []string{"condition_code"}},
+		{"без кода погоды", func(d *model.WeatherData) { d.ConditionCode = "" }, nil},
+		{"длинный провайдер", func(d *model.WeatherData) { d.Provider = strings.Repeat("a", model.MaxProviderLen+1) }, []string{"provider"}},
+		{"без времени", func(d *model.WeatherData) { d.Timestamp = time.Time{} }, []string{"timestamp"}},
+		{"время в будущем", func(d *model.WeatherData) { d.Timestamp = time.Now().Add(2 * model.MaxFutureSkew) }, []string{"timestamp"}},
+		{"расхождение часов", func(d *model.WeatherData) { d.Timestamp = time.Now().Add(model.MaxFutureSkew / 2) }, nil},
+		{"слишком старое время", func(d *model.WeatherData) { d.Timestamp = model.MinTimestamp.Add(-time.Second) }, []string{"timestamp"}},
+		{"несколько ошибок по порядку", func(d *model.WeatherData) {
+			d.City = ""
+			d.Temp = 1000
+			d.Timestamp = time.Time{}
+		}, []string{"city", "temperature", "timestamp"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := validReading()
+			tt.modify(&data)
+			got := fields(t, data.Validate())
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ошибки в полях %v, ожидались %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	invalid := validReading()
+	invalid.City = ""
+	invalid.Temp = 1000
+	oversized := make([]model.WeatherData, model.MaxBatchSize+1)
+	for i := range oversized {
+		oversized[i] = validReading()
+	}
+
+	tests := []struct {
+		name  string
+		batch []model.WeatherData
+		want  []string
+	}{
+		{"корректный пакет", []model.WeatherData{validReading(), validReading()}, nil},
+		{"пустой пакет", nil, []string{""}},
+		{"слишком большой пакет", oversized, []string{""}},
+		{"индекс замера в поле", []model.WeatherData{validReading(), invalid}, []string{"[1].city", "[1].temperature"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(t, model.ValidateBatch(tt.batch))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ошибки в полях %v, ожидались %v", got, tt.want)
+			}
+		})
+	}
+}
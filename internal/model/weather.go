@@ -1,28 +1,148 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 )
 
 type WeatherData struct {
-	City      string    `json:"city"`
-	Temp      float64   `json:"temperature"`
-	Condition string    `json:"condition"`
-	Provider  string    `json:"provider"`
-	Timestamp time.Time `json:"timestamp"`
+	City          string        `json:"city"`
+	Temp          float64       `json:"temperature"`
+	Condition     string        `json:"condition"`                // Сырая строка провайдера
+	ConditionCode ConditionCode `json:"condition_code,omitempty"` // Нормализованный код погоды
+	Provider      string        `json:"provider"`
+	Timestamp     time.Time     `json:"timestamp"`
 }
 
 type WeatherResponse struct {
 	WeatherData
-	Cached bool `json:"cached"` // Флаг, указывающий откуда данные
+	TimestampUTC time.Time `json:"timestamp_utc"`
+	Timezone     string    `json:"timezone"`
+	Cached       bool      `json:"cached"`          // Флаг, указывающий откуда данные
+	Units        Units     `json:"units,omitempty"` // Единицы значений; пусто — metric
+	Astro        *Astro    `json:"astro,omitempty"` // Только с ?include=astro
+
+	// Поля JSON-ответа при ?fields=; пусто — все поля
+	Fields []string `json:"-"`
+}
+
+// Localize переводит время замера в часовой пояс loc и заполняет UTC-поле
+func (r *WeatherResponse) Localize(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	r.TimestampUTC = r.Timestamp.UTC()
+	r.Timestamp = r.Timestamp.In(loc)
+	r.Timezone = loc.String()
+}
+
+// ConvertUnits переводит значения из канонических (metric) в систему u
+func (r *WeatherResponse) ConvertUnits(u Units) {
+	r.Temp = Temperature(r.Temp).In(u)
+	r.Units = u
+}
+
+// Translate заменяет строку состояния названием на языке lang, если код известен
+func (r *WeatherResponse) Translate(lang Language) {
+	if label := r.ConditionCode.Label(lang); label != "" {
+		r.Condition = label
+	}
+}
+
+// MarshalJSON выводит время замера в RFC3339 с локальным смещением города и в UTC
+func (r WeatherResponse) MarshalJSON() ([]byte, error) {
+	type alias WeatherResponse
+	utc := r.TimestampUTC
+	if utc.IsZero() {
+		utc = r.Timestamp
+	}
+	data, err := json.Marshal(struct {
+		alias
+		Timestamp    string `json:"timestamp"`
+		TimestampUTC string `json:"timestamp_utc"`
+	}{
+		alias:        alias(r),
+		Timestamp:    r.Timestamp.Format(time.RFC3339),
+		TimestampUTC: utc.UTC().Format(time.RFC3339),
+	})
+	if err != nil || len(r.Fields) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	selected := make(map[string]json.RawMessage, len(r.Fields))
+	for _, field := range r.Fields {
+		if v, ok := all[field]; ok {
+			selected[field] = v
+		}
+	}
+	return json.Marshal(selected)
+}
+
+// BatchResponse — погода нескольких городов в порядке запроса
+type BatchResponse struct {
+	Results     []WeatherResponse `json:"results"`
+	NotFound    []string          `json:"not_found,omitempty"`
+	Unavailable []string          `json:"unavailable,omitempty"` // нет в кэше, а БД не подключена
+	Total       int               `json:"total"`
+}
+
+// HistoryResponse — замеры города за период по возрастанию времени
+type HistoryResponse struct {
+	City     string            `json:"city"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Readings []WeatherResponse `json:"readings"`
+	Total    int               `json:"total"`
+}
+
+// ChangesResponse — города, измененные после курсора. Следующий запрос
+// передает Cursor в ?since=; HasMore — изменения не уместились в страницу.
+type ChangesResponse struct {
+	Changes []WeatherResponse `json:"changes"`
+	Cursor  string            `json:"cursor"`
+	HasMore bool              `json:"has_more"`
+	Total   int               `json:"total"`
+}
+
+// CitySearchResponse — города справочника, подходящие под запрос автодополнения
+type CitySearchResponse struct {
+	Query  string `json:"query"`
+	Cities []City `json:"cities"`
+	Total  int    `json:"total"`
 }
 
 type CitiesResponse struct {
-	Cities []string `json:"cities"`
-	Total  int      `json:"total"`
+	Cities   []string `json:"cities"`
+	Total    int      `json:"total"`              // всего городов, не только на странице
+	Page     int      `json:"page,omitempty"`     // только для постраничного запроса
+	PerPage  int      `json:"per_page,omitempty"` // только для постраничного запроса
+	NextPage *int     `json:"next_page,omitempty"`
 }
 
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-}
\ No newline at end of file
+	Code    string       `json:"code"` // Машиночитаемый код из каталога internal/api/errcode
+	Error   string       `json:"error"`
+	Message string       `json:"message,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"` // Ошибки валидации по полям
+	// Идентификатор запроса из X-Request-ID: по нему ошибку находят в логах
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Способ получения погоды на момент времени
+const (
+	PointExact        = "exact"        // замер ровно на запрошенный момент
+	PointNearest      = "nearest"      // ближайший по времени замер
+	PointInterpolated = "interpolated" // линейная интерполяция между соседними замерами
+)
+
+// PointInTimeResponse — погода города на запрошенный момент по истории замеров
+type PointInTimeResponse struct {
+	RequestedAt   time.Time       `json:"requested_at"`
+	Method        string          `json:"method"`
+	OffsetSeconds int64           `json:"offset_seconds"` // до ближайшего использованного замера
+	Reading       WeatherResponse `json:"reading"`
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// EventWeatherUpdated — тип доставки webhook о сохраненном замере
+const EventWeatherUpdated = "weather.updated"
+
+// maxWebhookURLLength — ограничение длины адреса webhook
+const maxWebhookURLLength = 2048
+
+// Webhook — адрес, на который агрегатор отправляет новые замеры города.
+// Пустой City — замеры всех городов.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"-"` // Владелец webhook
+	URL       string    `json:"url"`
+	City      string    `json:"city,omitempty"`
+	Secret    string    `json:"secret,omitempty"` // Ключ подписи; отдается только при создании
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+	Total    int       `json:"total"`
+}
+
+// Validate проверяет webhook перед сохранением
+func (w Webhook) Validate() error {
+	var errs ValidationErrors
+
+	switch u, err := url.Parse(w.URL); {
+	case strings.TrimSpace(w.URL) == "":
+		errs.add("url", "обязательное поле")
+	case len(w.URL) > maxWebhookURLLength:
+		errs.add("url", "слишком длинный адрес")
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		errs.add("url", "ожидается абсолютный адрес http или https")
+	default:
+		// Имя хоста здесь не разрешается: это делает обработчик API перед
+		// сохранением и клиент рассылки при каждом соединении
+		if ip := net.ParseIP(u.Hostname()); ip != nil && !PublicIP(ip) {
+			errs.add("url", "адрес во внутренней сети")
+		}
+	}
+
+	if len(w.City) > MaxCityLength {
+		errs.add("city", "слишком длинное название")
+	}
+
+	return errs.err()
+}
+
+// Matches сообщает, нужно ли отправить на webhook замер города city
+func (w Webhook) Matches(city string) bool {
+	return w.City == "" || strings.EqualFold(w.City, city)
+}
+
+// reservedNets — служебные сети, которых нет среди проверок net.IP:
+// «эта сеть» и общие адреса провайдеров, где бывают метаданные облака
+var reservedNets = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// PublicIP сообщает, что адрес не относится к локальной, частной или
+// служебной сети
+func PublicIP(ip net.IP) bool {
+	if addr, ok := netip.AddrFromSlice(ip); ok {
+		for _, prefix := range reservedNets {
+			if prefix.Contains(addr.Unmap()) {
+				return false
+			}
+		}
+	}
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
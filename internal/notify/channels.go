@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Email отправляет оповещения по SMTP
+type Email struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+func NewEmail(addr, username, password, from string, to []string) *Email {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &Email{addr: addr, auth: auth, from: from, to: to}
+}
+
+func (e *Email) Send(_ context.Context, msg Message) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.WriteString(msg.Body)
+
+	if err := smtp.SendMail(e.addr, e.auth, e.from, e.to, buf.Bytes()); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	return nil
+}
+
+// Telegram отправляет оповещения через Bot API
+type Telegram struct {
+	url    string
+	chatID string
+}
+
+func NewTelegram(token, chatID string) *Telegram {
+	return &Telegram{
+		url:    "https://api.telegram.org/bot" + token + "/sendMessage",
+		chatID: chatID,
+	}
+}
+
+func (t *Telegram) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, t.url, map[string]string{
+		"chat_id": t.chatID,
+		"text":    msg.Body,
+	})
+}
+
+// Slack отправляет оповещения во входящий webhook
+type Slack struct {
+	webhookURL string
+}
+
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{webhookURL: webhookURL}
+}
+
+func (s *Slack) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, s.webhookURL, map[string]string{"text": msg.Body})
+}
+
+// postJSON отправляет payload и считает ошибкой любой ответ кроме 2xx.
+// Адрес не попадает в ошибку: в нем может быть токен.
+func postJSON(ctx context.Context, endpoint string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("неожиданный ответ %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
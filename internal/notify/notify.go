@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// ErrNotConfigured — для канала из правила нет настроек доставки
+var ErrNotConfigured = errors.New("канал доставки не настроен")
+
+// Message — готовое к отправке оповещение
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Channel доставляет сообщение одним способом: почта, Telegram, Slack
+type Channel interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notifier рендерит шаблоны и доставляет оповещения с повторными попытками
+type Notifier struct {
+	channels    map[string]Channel
+	templates   *Templates
+	maxAttempts int
+	backoff     time.Duration
+	logger      *slog.Logger
+}
+
+func New(channels map[string]Channel, templates *Templates, maxAttempts int, backoff time.Duration, logger *slog.Logger) *Notifier {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Notifier{
+		channels:    channels,
+		templates:   templates,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		logger:      logger,
+	}
+}
+
+// FromConfig подключает только те каналы, для которых заданы настройки
+func FromConfig(cfg *config.Config, logger *slog.Logger) (*Notifier, error) {
+	templates, err := LoadTemplates(cfg.NotifyTemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make(map[string]Channel)
+	if cfg.SMTPAddr != "" && len(cfg.NotifyEmailTo) > 0 {
+		channels[model.ChannelEmail] = NewEmail(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.NotifyEmailTo)
+	}
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		channels[model.ChannelTelegram] = NewTelegram(cfg.TelegramBotToken, cfg.TelegramChatID)
+	}
+	if cfg.SlackWebhookURL != "" {
+		channels[model.ChannelSlack] = NewSlack(cfg.SlackWebhookURL)
+	}
+
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	logger.Info("Каналы доставки оповещений", "channels", strings.Join(names, ","))
+
+	return New(channels, templates, cfg.NotifyMaxAttempts, cfg.NotifyRetryBackoff, logger), nil
+}
+
+// Deliver отправляет событие во все каналы правила и возвращает итог по каждому
+func (n *Notifier) Deliver(ctx context.Context, event model.AlertEvent) []model.Delivery {
+	deliveries := make([]model.Delivery, 0, len(event.Channels))
+	for _, name := range event.Channels {
+		d := model.Delivery{
+			AlertID:   event.ID,
+			RuleID:    event.RuleID,
+			City:      event.City,
+			Channel:   name,
+			CreatedAt: time.Now().UTC(),
+		}
+
+		ch, ok := n.channels[name]
+		if !ok {
+			d.Status = model.DeliverySkipped
+			d.LastError = ErrNotConfigured.Error()
+			n.logger.WarnContext(ctx, "Канал доставки не настроен", "channel", name, "rule_id", event.RuleID)
+			deliveries = append(deliveries, d)
+			continue
+		}
+
+		msg, err := n.templates.Render(name, event)
+		if err != nil {
+			d.Status = model.DeliveryFailed
+			d.LastError = err.Error()
+			n.logger.ErrorContext(ctx, "Ошибка шаблона оповещения", "channel", name, "error", err)
+			deliveries = append(deliveries, d)
+			continue
+		}
+
+		d.Attempts, err = n.send(ctx, ch, msg)
+		if err != nil {
+			d.Status = model.DeliveryFailed
+			d.LastError = err.Error()
+			n.logger.ErrorContext(ctx, "Оповещение не доставлено",
+				"channel", name, "city", event.City, "attempts", d.Attempts, "error", err)
+		} else {
+			now := time.Now().UTC()
+			d.Status = model.DeliveryDelivered
+			d.DeliveredAt = &now
+			n.logger.InfoContext(ctx, "Оповещение доставлено",
+				"channel", name, "city", event.City, "attempts", d.Attempts)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries
+}
+
+// send повторяет отправку с экспоненциальной паузой, возвращает число попыток
+func (n *Notifier) send(ctx context.Context, ch Channel, msg Message) (int, error) {
+	var err error
+	pause := n.backoff
+	for attempt := 1; ; attempt++ {
+		if err = ch.Send(ctx, msg); err == nil {
+			return attempt, nil
+		}
+		if attempt == n.maxAttempts {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(pause)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, fmt.Errorf("доставка прервана: %w", err)
+		}
+		pause *= 2
+	}
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// defaultSubject — тема оповещения, используется почтой
+const defaultSubject = `Погодное оповещение: {{.City}}`
+
+// defaultBodies — шаблоны текста по каналам, переопределяются файлами <канал>.tmpl
+var defaultBodies = map[string]string{
+	model.ChannelEmail: `Сработало правило #{{.RuleID}} для города {{.City}}.
+
+Показатель: {{.Metric}} {{.Operator}} {{.Threshold}}
+Текущее значение: {{.Value}}
+Время: {{.TriggeredAt.Format "2006-01-02 15:04:05 MST"}}
+`,
+	model.ChannelTelegram: `⚠️ {{.City}}: {{.Metric}} = {{.Value}} ({{.Operator}} {{.Threshold}})`,
+	model.ChannelSlack:    `:warning: *{{.City}}*: {{.Metric}} = {{.Value}} (правило #{{.RuleID}}: {{.Operator}} {{.Threshold}})`,
+}
+
+// Templates хранит тему и шаблоны текста для каждого канала
+type Templates struct {
+	subject *template.Template
+	bodies  map[string]*template.Template
+}
+
+// LoadTemplates разбирает встроенные шаблоны и переопределения из dir, если он задан
+func LoadTemplates(dir string) (*Templates, error) {
+	subject, err := template.New("subject").Parse(defaultSubject)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шаблона темы: %w", err)
+	}
+
+	t := &Templates{subject: subject, bodies: make(map[string]*template.Template)}
+	for channel, text := range defaultBodies {
+		if dir != "" {
+			data, err := os.ReadFile(filepath.Join(dir, channel+".tmpl"))
+			switch {
+			case err == nil:
+				text = string(data)
+			case !errors.Is(err, os.ErrNotExist):
+				return nil, fmt.Errorf("ошибка чтения шаблона %s: %w", channel, err)
+			}
+		}
+
+		body, err := template.New(channel).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка шаблона %s: %w", channel, err)
+		}
+		t.bodies[channel] = body
+	}
+	return t, nil
+}
+
+// Render строит сообщение для канала
+func (t *Templates) Render(channel string, event model.AlertEvent) (Message, error) {
+	body, ok := t.bodies[channel]
+	if !ok {
+		return Message{}, fmt.Errorf("нет шаблона для канала %s", channel)
+	}
+
+	var subject, text bytes.Buffer
+	if err := t.subject.Execute(&subject, event); err != nil {
+		return Message{}, fmt.Errorf("ошибка рендера темы: %w", err)
+	}
+	if err := body.Execute(&text, event); err != nil {
+		return Message{}, fmt.Errorf("ошибка рендера шаблона %s: %w", channel, err)
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    text.String(),
+	}, nil
+}
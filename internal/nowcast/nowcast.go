@@ -0,0 +1,98 @@
+package nowcast
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// Options — параметры сглаживания и горизонт прогноза
+type Options struct {
+	Alpha     float64       // вес нового замера в уровне, 0..1
+	Beta      float64       // вес нового наклона в тренде, 0..1
+	Damping   float64       // затухание тренда на каждый час горизонта, 0..1
+	Horizon   time.Duration // насколько вперед строится прогноз
+	Step      time.Duration // шаг точек прогноза
+	MinPoints int           // меньше замеров — прогноз не строится
+}
+
+// OptionsFromConfig собирает параметры из конфигурации
+func OptionsFromConfig(cfg *config.Config) Options {
+	return Options{
+		Alpha:     cfg.NowcastAlpha,
+		Beta:      cfg.NowcastBeta,
+		Damping:   0.9,
+		Horizon:   cfg.NowcastHorizon,
+		Step:      cfg.NowcastStep,
+		MinPoints: 3,
+	}
+}
+
+// Predict строит краткосрочный прогноз температуры по недавней истории
+// двойным экспоненциальным сглаживанием (метод Хольта) с неравными интервалами.
+// Состояние погоды берется из последнего замера.
+func Predict(history []model.WeatherData, now time.Time, opts Options) []model.Forecast {
+	if len(history) < opts.MinPoints || len(history) < 2 || opts.Step <= 0 {
+		return nil
+	}
+
+	points := make([]model.WeatherData, len(history))
+	copy(points, history)
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+
+	level := points[0].Temp
+	trend := 0.0 // °C в час
+	prevAt := points[0].Timestamp
+	for _, p := range points[1:] {
+		dt := p.Timestamp.Sub(prevAt).Hours()
+		prevAt = p.Timestamp
+		if dt <= 0 {
+			level = opts.Alpha*p.Temp + (1-opts.Alpha)*level
+			continue
+		}
+		prev := level
+		level = opts.Alpha*p.Temp + (1-opts.Alpha)*(level+trend*dt)
+		trend = opts.Beta*(level-prev)/dt + (1-opts.Beta)*trend
+	}
+
+	last := points[len(points)-1]
+	city := last.City
+	code := last.ConditionCode
+	if code == "" {
+		code = model.NormalizeCondition(last.Provider, last.Condition)
+	}
+
+	issued := now.UTC()
+	var forecasts []model.Forecast
+	for at := last.Timestamp.Add(opts.Step); !at.After(now.Add(opts.Horizon)); at = at.Add(opts.Step) {
+		if !at.After(now) {
+			continue
+		}
+		hours := at.Sub(last.Timestamp).Hours()
+		temp := level + trend*dampedHours(hours, opts.Damping)
+		forecasts = append(forecasts, model.Forecast{
+			City:          city,
+			Provider:      model.ProviderInternal,
+			ForecastFor:   at.UTC(),
+			Temp:          clamp(math.Round(temp*10)/10, model.MinTemperature, model.MaxTemperature),
+			ConditionCode: code,
+			IssuedAt:      issued,
+		})
+	}
+	return forecasts
+}
+
+// dampedHours — сумма φ^k по часам горизонта, чтобы тренд не уходил в бесконечность
+func dampedHours(hours, phi float64) float64 {
+	if phi <= 0 || phi >= 1 {
+		return hours
+	}
+	return phi * (1 - math.Pow(phi, hours)) / (1 - phi)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
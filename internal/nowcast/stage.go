@@ -0,0 +1,99 @@
+package nowcast
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+//go:generate go tool mockgen -destination=../testutil/mocks/nowcast.go -package=mocks -mock_names=Store=MockNowcastStore . Store
+
+// Store — операции с БД, нужные стадии; реализуется storage.WeatherStorage
+type Store interface {
+	HasProviderForecast(ctx context.Context, city string, after time.Time) (bool, error)
+	GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error)
+	ReplaceForecasts(ctx context.Context, city, provider string, forecasts []model.Forecast) error
+}
+
+// Stage пересчитывает внутренний прогноз после новых замеров.
+// Города с прогнозом внешнего провайдера пропускаются.
+// Нулевой указатель безопасен и ничего не делает.
+type Stage struct {
+	store    Store
+	opts     Options
+	window   time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu   sync.Mutex
+	last map[string]time.Time // последний пересчет по городу
+}
+
+// NewStage создает стадию по конфигурации, nil если прогноз выключен
+func NewStage(cfg *config.Config, store Store, logger *slog.Logger) *Stage {
+	if !cfg.NowcastEnabled {
+		return nil
+	}
+	return &Stage{
+		store:    store,
+		opts:     OptionsFromConfig(cfg),
+		window:   cfg.NowcastWindow,
+		interval: cfg.NowcastInterval,
+		logger:   logger,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Observe вызывается после сохранения замера
+func (s *Stage) Observe(ctx context.Context, data model.WeatherData) error {
+	if s == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if !s.due(strings.ToLower(data.City), now) {
+		return nil
+	}
+
+	hasProvider, err := s.store.HasProviderForecast(ctx, data.City, now)
+	if err != nil {
+		return err
+	}
+	if hasProvider {
+		return nil
+	}
+
+	history, err := s.store.GetHistory(ctx, data.City, now.Add(-s.window), now)
+	if err != nil {
+		return err
+	}
+
+	forecasts := Predict(history, now, s.opts)
+	if len(forecasts) == 0 {
+		s.logger.DebugContext(ctx, "Недостаточно истории для прогноза", "city", data.City, "points", len(history))
+		return nil
+	}
+
+	if err := s.store.ReplaceForecasts(ctx, data.City, model.ProviderInternal, forecasts); err != nil {
+		return err
+	}
+	s.logger.DebugContext(ctx, "Внутренний прогноз обновлен", "city", data.City, "points", len(forecasts))
+	return nil
+}
+
+// due отмечает пересчет, если с прошлого прошло не меньше interval
+func (s *Stage) due(city string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.last[city]; ok && now.Sub(last) < s.interval {
+		return false
+	}
+	s.last[city] = now
+	return true
+}
@@ -0,0 +1,62 @@
+package nowcast_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/nowcast"
+	"github.com/gometeo/app/internal/testutil"
+	"github.com/gometeo/app/internal/testutil/mocks"
+)
+
+func newStage(t *testing.T) (*nowcast.Stage, *mocks.MockNowcastStore) {
+	cfg := config.Load()
+	cfg.NowcastEnabled = true
+	cfg.NowcastHorizon = 3 * time.Hour
+	cfg.NowcastStep = time.Hour
+	cfg.NowcastInterval = 10 * time.Minute
+	store := mocks.NewMockNowcastStore(gomock.NewController(t))
+	return nowcast.NewStage(cfg, store, testutil.Logger(t)), store
+}
+
+func TestStageReplacesInternalForecast(t *testing.T) {
+	stage, store := newStage(t)
+
+	now := time.Now()
+	var history []model.WeatherData
+	for i := range 4 {
+		history = append(history, model.WeatherData{
+			City:          "Moscow",
+			Temp:          float64(10 + i),
+			ConditionCode: model.ConditionClear,
+			Timestamp:     now.Add(-time.Duration(4-i) * time.Hour),
+		})
+	}
+
+	// Пересчет не чаще раза в интервал: второй замер не обращается к БД
+	store.EXPECT().HasProviderForecast(gomock.Any(), "Moscow", gomock.Any()).Return(false, nil)
+	store.EXPECT().GetHistory(gomock.Any(), "Moscow", gomock.Any(), gomock.Any()).Return(history, nil)
+	store.EXPECT().ReplaceForecasts(gomock.Any(), "Moscow", model.ProviderInternal, gomock.Len(3)).Return(nil)
+
+	reading := model.WeatherData{City: "Moscow", Temp: 13, Timestamp: now}
+	for range 2 {
+		if err := stage.Observe(context.Background(), reading); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStageSkipsCityWithProviderForecast(t *testing.T) {
+	stage, store := newStage(t)
+
+	store.EXPECT().HasProviderForecast(gomock.Any(), "Moscow", gomock.Any()).Return(true, nil)
+
+	if err := stage.Observe(context.Background(), model.WeatherData{City: "Moscow", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// ErrBudgetExhausted — исчерпан лимит запросов в минуту или суточная квота
+var ErrBudgetExhausted = errors.New("исчерпан бюджет запросов провайдера")
+
+// Причины отказа бюджета
+const (
+	BudgetMinute = "minute"
+	BudgetDay    = "day"
+)
+
+// BudgetError — запрос не отправлен, чтобы не выйти за лимиты ключа
+type BudgetError struct {
+	Provider string
+	Reason   string // BudgetMinute или BudgetDay
+	// First — первый отказ после разрешенного запроса; по нему пишется
+	// предупреждение, остальные отказы цикла только считаются
+	First bool
+}
+
+func (e *BudgetError) Error() string {
+	if e.Reason == BudgetDay {
+		return fmt.Sprintf("%s: суточная квота исчерпана", e.Provider)
+	}
+	return fmt.Sprintf("%s: лимит запросов в минуту исчерпан", e.Provider)
+}
+
+func (e *BudgetError) Unwrap() error {
+	return ErrBudgetExhausted
+}
+
+// Budget — лимит запросов к провайдеру: корзина токенов на минуту и счетчик
+// на сутки UTC (так сбрасываются квоты бесплатных ключей)
+type Budget struct {
+	perMinute int // 0 — без лимита
+	perDay    int // 0 — без квоты
+
+	mu        sync.Mutex
+	tokens    float64
+	refilled  time.Time
+	day       string // дата UTC текущих суток квоты
+	used      int
+	rejecting bool
+}
+
+// NewBudget создает бюджет; nil — оба лимита выключены
+func NewBudget(perMinute, perDay int) *Budget {
+	if perMinute <= 0 && perDay <= 0 {
+		return nil
+	}
+	return &Budget{perMinute: max(perMinute, 0), perDay: max(perDay, 0), tokens: float64(perMinute)}
+}
+
+// take расходует один запрос; пустая причина — запрос разрешен
+func (b *Budget) take(now time.Time) (reason string, first bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if day := now.UTC().Format(time.DateOnly); day != b.day {
+		b.day, b.used = day, 0
+	}
+	if b.perMinute > 0 && !b.refilled.IsZero() {
+		rate := float64(b.perMinute) / 60
+		b.tokens = min(float64(b.perMinute), b.tokens+now.Sub(b.refilled).Seconds()*rate)
+	}
+	b.refilled = now
+
+	switch {
+	case b.perDay > 0 && b.used >= b.perDay:
+		reason = BudgetDay
+	case b.perMinute > 0 && b.tokens < 1:
+		reason = BudgetMinute
+	}
+	if reason != "" {
+		first = !b.rejecting
+		b.rejecting = true
+		return reason, first
+	}
+
+	if b.perMinute > 0 {
+		b.tokens--
+	}
+	b.used++
+	b.rejecting = false
+	return "", false
+}
+
+// WithBudget ограничивает запросы провайдера бюджетом; nil-бюджет — без ограничений
+func WithBudget(p Provider, budget *Budget) Provider {
+	if budget == nil {
+		return p
+	}
+	limited := &budgeted{Provider: p, budget: budget}
+	if f, ok := p.(Forecaster); ok {
+		return &budgetedForecaster{budgeted: limited, forecaster: f}
+	}
+	return limited
+}
+
+type budgeted struct {
+	Provider
+	budget *Budget
+}
+
+func (b *budgeted) check() error {
+	if reason, first := b.budget.take(time.Now()); reason != "" {
+		return &BudgetError{Provider: b.Name(), Reason: reason, First: first}
+	}
+	return nil
+}
+
+func (b *budgeted) Fetch(ctx context.Context, city model.City) (model.WeatherData, error) {
+	if err := b.check(); err != nil {
+		return model.WeatherData{}, err
+	}
+	return b.Provider.Fetch(ctx, city)
+}
+
+type budgetedForecaster struct {
+	*budgeted
+	forecaster Forecaster
+}
+
+func (b *budgetedForecaster) Forecast(ctx context.Context, city model.City, days int) (model.ForecastBatch, error) {
+	if err := b.check(); err != nil {
+		return model.ForecastBatch{}, err
+	}
+	return b.forecaster.Forecast(ctx, city, days)
+}
+
+// parseBudgets разбирает записи "OpenWeatherMap=60:1000" (в минуту:в сутки,
+// 0 — без лимита); неверные пропускаются с предупреждением. Ключ — название
+// провайдера в нижнем регистре.
+func parseBudgets(entries []string, logger *slog.Logger) map[string]*Budget {
+	budgets := make(map[string]*Budget, len(entries))
+	for _, entry := range entries {
+		name, perMinute, perDay, err := parseBudget(entry)
+		if err != nil {
+			logger.Warn("Неверный лимит провайдера пропущен", "entry", entry, "error", err)
+			continue
+		}
+		budgets[strings.ToLower(name)] = NewBudget(perMinute, perDay)
+	}
+	return budgets
+}
+
+func parseBudget(entry string) (string, int, int, error) {
+	name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", 0, 0, fmt.Errorf("ожидается провайдер=в_минуту:в_сутки")
+	}
+	minuteStr, dayStr, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return "", 0, 0, fmt.Errorf("ожидается в_минуту:в_сутки: %s", value)
+	}
+	perMinute, err := strconv.Atoi(strings.TrimSpace(minuteStr))
+	if err != nil || perMinute < 0 {
+		return "", 0, 0, fmt.Errorf("неверный лимит в минуту: %s", minuteStr)
+	}
+	perDay, err := strconv.Atoi(strings.TrimSpace(dayStr))
+	if err != nil || perDay < 0 {
+		return "", 0, 0, fmt.Errorf("неверная суточная квота: %s", dayStr)
+	}
+	return name, perMinute, perDay, nil
+}
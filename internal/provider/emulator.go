@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// NameEmulator — название провайдера эмулированных замеров
+const NameEmulator = "emulator"
+
+// Emulator выдает случайную погоду для локальной разработки. Включается явно
+// через PROVIDER_EMULATOR или --dev и только если внешние провайдеры не настроены.
+type Emulator struct{}
+
+func (Emulator) Name() string {
+	return NameEmulator
+}
+
+func (Emulator) Fetch(_ context.Context, city model.City) (model.WeatherData, error) {
+	return model.WeatherData{
+		City:      city.Name,
+		Temp:      float64(rand.Intn(40)-10) + rand.Float64(), // Случайная темп.
+		Condition: "Cloudy",
+		Provider:  NameEmulator,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// Forecast эмулирует прогноз: точки каждые 3 часа с суточным ходом температуры
+func (Emulator) Forecast(_ context.Context, city model.City, days int) (model.ForecastBatch, error) {
+	now := time.Now()
+	base := float64(rand.Intn(30)-5) + rand.Float64()
+	codes := []model.ConditionCode{model.ConditionClear, model.ConditionPartlyCloudy, model.ConditionCloudy, model.ConditionRain}
+	batch := model.ForecastBatch{City: city.Name, Provider: NameEmulator, IssuedAt: now}
+	start := now.Truncate(3 * time.Hour).Add(3 * time.Hour)
+	for at := start; at.Before(now.Add(time.Duration(days) * 24 * time.Hour)); at = at.Add(3 * time.Hour) {
+		batch.Forecasts = append(batch.Forecasts, model.Forecast{
+			City:          city.Name,
+			Provider:      batch.Provider,
+			ForecastFor:   at,
+			Temp:          base + 5*math.Sin(float64(at.Hour()-9)*math.Pi/12) + rand.Float64()*2 - 1,
+			ConditionCode: codes[rand.Intn(len(codes))],
+			IssuedAt:      now,
+		})
+	}
+	return batch, nil
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// NameMetNo — название провайдера в замерах
+const NameMetNo = "met.no"
+
+// MetNo получает погоду и прогноз через Locationforecast норвежского
+// метеоинститута. Ключ не нужен, но API требует User-Agent с контактом
+// и ищет только по координатам.
+type MetNo struct {
+	*httpClient
+	baseURL string
+}
+
+// NewMetNo создает клиента по настройкам; nil — провайдер выключен
+func NewMetNo(cfg *config.Config) *MetNo {
+	if !cfg.MetNoEnabled {
+		return nil
+	}
+	return &MetNo{
+		httpClient: newHTTPClient(NameMetNo, cfg.MetNoUserAgent, cfg.ProviderTimeout),
+		baseURL:    strings.TrimRight(cfg.MetNoURL, "/"),
+	}
+}
+
+func (m *MetNo) Name() string {
+	return NameMetNo
+}
+
+type metNoSummary struct {
+	Summary struct {
+		SymbolCode string `json:"symbol_code"`
+	} `json:"summary"`
+}
+
+type metNoResponse struct {
+	Properties struct {
+		Timeseries []struct {
+			Time time.Time `json:"time"`
+			Data struct {
+				Instant struct {
+					Details struct {
+						AirTemperature float64 `json:"air_temperature"`
+					} `json:"details"`
+				} `json:"instant"`
+				Next1Hours *metNoSummary `json:"next_1_hours"`
+				Next6Hours *metNoSummary `json:"next_6_hours"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"properties"`
+}
+
+// Fetch возвращает ближайшую точку ряда как текущую погоду
+func (m *MetNo) Fetch(ctx context.Context, city model.City) (model.WeatherData, error) {
+	resp, err := m.get(ctx, city)
+	if err != nil {
+		return model.WeatherData{}, err
+	}
+	series := resp.Properties.Timeseries
+	if len(series) == 0 {
+		return model.WeatherData{}, errors.New("met.no вернул пустой ряд")
+	}
+
+	point := series[0]
+	data := model.WeatherData{
+		City:      city.Name,
+		Temp:      point.Data.Instant.Details.AirTemperature,
+		Condition: metNoSymbol(point.Data.Next1Hours, point.Data.Next6Hours),
+		Provider:  NameMetNo,
+		Timestamp: point.Time,
+	}
+	data.NormalizeCondition()
+	return data, nil
+}
+
+// Forecast возвращает прогноз на days дней с шагом 3 часа
+func (m *MetNo) Forecast(ctx context.Context, city model.City, days int) (model.ForecastBatch, error) {
+	resp, err := m.get(ctx, city)
+	if err != nil {
+		return model.ForecastBatch{}, err
+	}
+
+	now := time.Now()
+	horizon := now.Add(time.Duration(days) * 24 * time.Hour)
+	batch := model.ForecastBatch{City: city.Name, Provider: NameMetNo, IssuedAt: now}
+	for _, point := range resp.Properties.Timeseries {
+		// Ряд почасовой первые дни и шестичасовой дальше; берем общую сетку
+		if !point.Time.After(now) || point.Time.After(horizon) || point.Time.UTC().Hour()%3 != 0 {
+			continue
+		}
+		symbol := metNoSymbol(point.Data.Next1Hours, point.Data.Next6Hours)
+		batch.Forecasts = append(batch.Forecasts, model.Forecast{
+			City:          city.Name,
+			Provider:      NameMetNo,
+			ForecastFor:   point.Time,
+			Temp:          point.Data.Instant.Details.AirTemperature,
+			ConditionCode: model.NormalizeCondition(NameMetNo, symbol),
+			IssuedAt:      now,
+		})
+	}
+	return batch, nil
+}
+
+func (m *MetNo) get(ctx context.Context, city model.City) (metNoResponse, error) {
+	var resp metNoResponse
+	if !hasCoordinates(city) {
+		return resp, ErrNoCoordinates
+	}
+	params := url.Values{
+		"lat": {strconv.FormatFloat(city.Lat, 'f', 4, 64)},
+		"lon": {strconv.FormatFloat(city.Lon, 'f', 4, 64)},
+	}
+	err := m.getJSON(ctx, m.baseURL+"/locationforecast/2.0/compact?"+params.Encode(), &resp, textMessage)
+	return resp, err
+}
+
+// metNoSymbol возвращает символ погоды без суффикса времени суток:
+// clearsky_day → clearsky
+func metNoSymbol(summaries ...*metNoSummary) string {
+	for _, s := range summaries {
+		if s != nil && s.Summary.SymbolCode != "" {
+			symbol, _, _ := strings.Cut(s.Summary.SymbolCode, "_")
+			return symbol
+		}
+	}
+	return ""
+}
+
+// textMessage возвращает тело ответа как текст ошибки
+func textMessage(body io.Reader) string {
+	raw, _ := io.ReadAll(body)
+	return strings.TrimSpace(string(raw))
+}
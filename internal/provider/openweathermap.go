@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// NameOpenWeatherMap — название провайдера в замерах
+const NameOpenWeatherMap = "OpenWeatherMap"
+
+// owmForecastDays — бесплатный прогноз OpenWeatherMap: 5 дней с шагом 3 часа
+const owmForecastDays = 5
+
+// OpenWeatherMap получает текущую погоду и прогноз через API OpenWeatherMap
+type OpenWeatherMap struct {
+	*httpClient
+	baseURL string
+	apiKey  string
+	units   string
+	lang    string
+}
+
+// NewOpenWeatherMap создает клиента по настройкам; nil — ключ API не задан
+func NewOpenWeatherMap(cfg *config.Config) *OpenWeatherMap {
+	if cfg.OpenWeatherMapAPIKey == "" {
+		return nil
+	}
+	units := strings.ToLower(cfg.OpenWeatherMapUnits)
+	switch units {
+	case "metric", "imperial", "standard":
+	default:
+		units = "metric"
+	}
+	return &OpenWeatherMap{
+		httpClient: newHTTPClient(NameOpenWeatherMap, "", cfg.ProviderTimeout),
+		baseURL:    strings.TrimRight(cfg.OpenWeatherMapURL, "/"),
+		apiKey:     cfg.OpenWeatherMapAPIKey,
+		units:      units,
+		lang:       cfg.OpenWeatherMapLang,
+	}
+}
+
+func (o *OpenWeatherMap) Name() string {
+	return NameOpenWeatherMap
+}
+
+type owmCondition struct {
+	ID          int    `json:"id"`
+	Main        string `json:"main"`
+	Description string `json:"description"`
+}
+
+type owmCurrent struct {
+	Weather []owmCondition `json:"weather"`
+	Main    struct {
+		Temp float64 `json:"temp"`
+	} `json:"main"`
+	Dt   int64  `json:"dt"`
+	Name string `json:"name"`
+}
+
+type owmForecast struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			Temp float64 `json:"temp"`
+		} `json:"main"`
+		Weather []owmCondition `json:"weather"`
+	} `json:"list"`
+}
+
+// Fetch возвращает текущую погоду города. Запрос идет по координатам
+// справочника, без них — по названию.
+func (o *OpenWeatherMap) Fetch(ctx context.Context, city model.City) (model.WeatherData, error) {
+	var resp owmCurrent
+	if err := o.getJSON(ctx, o.url("/weather", city), &resp, jsonMessage); err != nil {
+		return model.WeatherData{}, err
+	}
+
+	data := model.WeatherData{
+		City:      city.Name,
+		Temp:      o.celsius(resp.Main.Temp),
+		Provider:  NameOpenWeatherMap,
+		Timestamp: time.Now(),
+	}
+	if resp.Dt > 0 {
+		data.Timestamp = time.Unix(resp.Dt, 0)
+	}
+	if len(resp.Weather) > 0 {
+		// Описание на языке из настроек; код — по идентификатору состояния,
+		// он не зависит от языка
+		data.Condition = resp.Weather[0].Description
+		data.ConditionCode = owmConditionCode(resp.Weather[0].ID)
+	}
+	return data, nil
+}
+
+// Forecast возвращает прогноз на days дней вперед, не больше owmForecastDays
+func (o *OpenWeatherMap) Forecast(ctx context.Context, city model.City, days int) (model.ForecastBatch, error) {
+	var resp owmForecast
+	if err := o.getJSON(ctx, o.url("/forecast", city), &resp, jsonMessage); err != nil {
+		return model.ForecastBatch{}, err
+	}
+
+	now := time.Now()
+	horizon := now.Add(time.Duration(min(days, owmForecastDays)) * 24 * time.Hour)
+	batch := model.ForecastBatch{City: city.Name, Provider: NameOpenWeatherMap, IssuedAt: now}
+	for _, point := range resp.List {
+		at := time.Unix(point.Dt, 0)
+		if !at.After(now) || at.After(horizon) {
+			continue
+		}
+		forecast := model.Forecast{
+			City:          city.Name,
+			Provider:      NameOpenWeatherMap,
+			ForecastFor:   at,
+			Temp:          o.celsius(point.Main.Temp),
+			ConditionCode: model.ConditionUnknown,
+			IssuedAt:      now,
+		}
+		if len(point.Weather) > 0 {
+			forecast.ConditionCode = owmConditionCode(point.Weather[0].ID)
+		}
+		batch.Forecasts = append(batch.Forecasts, forecast)
+	}
+	return batch, nil
+}
+
+// url собирает адрес запроса по координатам или названию города
+func (o *OpenWeatherMap) url(path string, city model.City) string {
+	params := url.Values{
+		"appid": {o.apiKey},
+		"units": {o.units},
+	}
+	if o.lang != "" {
+		params.Set("lang", o.lang)
+	}
+	if hasCoordinates(city) {
+		params.Set("lat", strconv.FormatFloat(city.Lat, 'f', 4, 64))
+		params.Set("lon", strconv.FormatFloat(city.Lon, 'f', 4, 64))
+	} else {
+		q := city.Name
+		if city.Country != "" {
+			q += "," + city.Country
+		}
+		params.Set("q", q)
+	}
+	return o.baseURL + path + "?" + params.Encode()
+}
+
+// celsius переводит температуру из единиц запроса в градусы Цельсия
+func (o *OpenWeatherMap) celsius(t float64) float64 {
+	switch o.units {
+	case "imperial":
+		return (t - 32) * 5 / 9
+	case "standard":
+		return t - 273.15
+	default:
+		return t
+	}
+}
+
+// owmConditionCode переводит идентификатор состояния OpenWeatherMap в код
+// таксономии: https://openweathermap.org/weather-conditions
+func owmConditionCode(id int) model.ConditionCode {
+	switch {
+	case id >= 200 && id < 300:
+		return model.ConditionStorm
+	case id >= 300 && id < 400:
+		return model.ConditionRain
+	case id == 511:
+		return model.ConditionSleet
+	case id >= 500 && id < 600:
+		return model.ConditionRain
+	case id >= 611 && id <= 616:
+		return model.ConditionSleet
+	case id >= 600 && id < 700:
+		return model.ConditionSnow
+	case id == 701 || id == 711 || id == 721 || id == 741:
+		return model.ConditionFog
+	case id == 800:
+		return model.ConditionClear
+	case id == 801 || id == 802:
+		return model.ConditionPartlyCloudy
+	case id == 803 || id == 804:
+		return model.ConditionCloudy
+	default:
+		return model.ConditionUnknown
+	}
+}
@@ -0,0 +1,176 @@
+// Package provider — клиенты внешних погодных API. Коллектор опрашивает все
+// включенные провайдеры параллельно и публикует замер каждого отдельно.
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// Provider — источник текущей погоды
+type Provider interface {
+	// Name — название провайдера в поле provider замера
+	Name() string
+	// Fetch возвращает текущую погоду города в градусах Цельсия
+	Fetch(ctx context.Context, city model.City) (model.WeatherData, error)
+}
+
+// Forecaster — провайдер, который умеет отдавать прогноз
+type Forecaster interface {
+	Provider
+	Forecast(ctx context.Context, city model.City, days int) (model.ForecastBatch, error)
+}
+
+// ErrRateLimited — провайдер ответил 429; запросы не отправляются до RetryAfter
+var ErrRateLimited = errors.New("превышен лимит запросов провайдера")
+
+// ErrNoCoordinates — провайдер ищет только по координатам, а у города их нет
+var ErrNoCoordinates = errors.New("у города нет координат")
+
+// Error — ответ провайдера с кодом ошибки
+type Error struct {
+	Provider   string
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // только для 429
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s вернул %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// Temporary сообщает, есть ли смысл повторить запрос позже: лимит или сбой провайдера
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// FromConfig создает провайдеров, для которых заданы ключи или которые включены
+// явно, с лимитами запросов из PROVIDER_RATE_LIMITS, повторами и
+// предохранителем. Эмулятор добавляется, только если провайдеры не настроены
+// и PROVIDER_EMULATOR включен; пустой список — коллектор запускать не с чем.
+func FromConfig(cfg *config.Config, logger *slog.Logger) []Provider {
+	var providers []Provider
+	if owm := NewOpenWeatherMap(cfg); owm != nil {
+		providers = append(providers, owm)
+	}
+	if wapi := NewWeatherAPI(cfg); wapi != nil {
+		providers = append(providers, wapi)
+	}
+	if metno := NewMetNo(cfg); metno != nil {
+		providers = append(providers, metno)
+	}
+
+	budgets := parseBudgets(cfg.ProviderRateLimits, logger)
+	resilience := ResilienceFromConfig(cfg)
+	for i, p := range providers {
+		providers[i] = WithResilience(WithBudget(p, budgets[strings.ToLower(p.Name())]), resilience, logger)
+	}
+	if len(providers) == 0 && cfg.ProviderEmulator {
+		providers = append(providers, Emulator{})
+	}
+	return providers
+}
+
+// httpClient — общая часть клиентов: таймаут, User-Agent и пауза после 429,
+// чтобы не сжечь суточную квоту ключа
+type httpClient struct {
+	name      string
+	userAgent string
+	client    *http.Client
+
+	blockedUntil atomic.Int64 // unix nano; 0 — запросы разрешены
+}
+
+func newHTTPClient(name, userAgent string, timeout time.Duration) *httpClient {
+	return &httpClient{name: name, userAgent: userAgent, client: &http.Client{Timeout: timeout}}
+}
+
+// getJSON выполняет запрос и разбирает ответ в out. message достает текст
+// ошибки из тела ответа провайдера.
+func (c *httpClient) getJSON(ctx context.Context, target string, out any, message func(io.Reader) string) error {
+	if until := c.blockedUntil.Load(); until > 0 {
+		if wait := time.Until(time.Unix(0, until)); wait > 0 {
+			return &Error{Provider: c.name, StatusCode: http.StatusTooManyRequests,
+				Message: "ожидание после 429", RetryAfter: wait}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к %s: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		perr := &Error{Provider: c.name, StatusCode: resp.StatusCode, Message: message(io.LimitReader(resp.Body, 4<<10))}
+		if perr.Message == "" {
+			perr.Message = "нет описания"
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			perr.RetryAfter = retryAfter(resp.Header.Get("Retry-After"), time.Minute)
+			c.blockedUntil.Store(time.Now().Add(perr.RetryAfter).UnixNano())
+		}
+		return perr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ошибка разбора ответа %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// jsonMessage достает текст ошибки из поля message тела ответа
+func jsonMessage(body io.Reader) string {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	json.NewDecoder(body).Decode(&payload)
+	return payload.Message
+}
+
+// retryAfter разбирает Retry-After в секундах или как дату HTTP
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	if header == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return fallback
+}
+
+// hasCoordinates — координаты города известны справочнику или геокодеру
+func hasCoordinates(city model.City) bool {
+	return city.Lat != 0 || city.Lon != 0
+}
@@ -0,0 +1,287 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// ErrCircuitOpen — провайдер подряд не отвечал, запросы к нему приостановлены
+var ErrCircuitOpen = errors.New("запросы к провайдеру приостановлены после серии сбоев")
+
+// Состояния предохранителя
+const (
+	CircuitClosed   = "closed"    // запросы идут как обычно
+	CircuitOpen     = "open"      // запросы не отправляются до конца паузы
+	CircuitHalfOpen = "half_open" // пробный запрос решает, закрыться или снова открыться
+)
+
+// Resilience — повторы с экспоненциальной паузой и предохранитель
+type Resilience struct {
+	Retries    int           // повторов после первой попытки
+	Backoff    time.Duration // пауза перед первым повтором
+	BackoffMax time.Duration // верхняя граница паузы
+	Threshold  int           // сбоев подряд до размыкания; 0 — предохранитель выключен
+	Cooldown   time.Duration // пауза разомкнутого предохранителя до пробного запроса
+}
+
+// ResilienceFromConfig собирает параметры из конфигурации
+func ResilienceFromConfig(cfg *config.Config) Resilience {
+	return Resilience{
+		Retries:    max(cfg.ProviderRetries, 0),
+		Backoff:    cfg.ProviderRetryBackoff,
+		BackoffMax: cfg.ProviderRetryBackoffMax,
+		Threshold:  max(cfg.ProviderBreakerThreshold, 0),
+		Cooldown:   cfg.ProviderBreakerCooldown,
+	}
+}
+
+// delay возвращает паузу перед повтором attempt (с 1) с джиттером ±20%
+func (r Resilience) delay(attempt int) time.Duration {
+	d := r.Backoff
+	for i := 1; i < attempt && d < r.BackoffMax; i++ {
+		d *= 2
+	}
+	d = min(d, r.BackoffMax)
+	return d + time.Duration((rand.Float64()*0.4-0.2)*float64(d))
+}
+
+// CircuitState — состояние предохранителя для health-отчета
+type CircuitState struct {
+	State    string
+	Failures int       // сбоев подряд
+	RetryAt  time.Time // когда разомкнутый предохранитель пропустит пробный запрос
+	LastErr  string
+}
+
+// breaker — предохранитель одного провайдера
+type breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	retryAt  time.Time
+	lastErr  string
+	probing  bool
+}
+
+// allow сообщает, можно ли отправить запрос; в полуоткрытом состоянии
+// пропускается один пробный запрос
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if now.Before(b.retryAt) {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != CircuitClosed {
+		b.logger.Info("Провайдер снова отвечает, предохранитель замкнут", "provider", b.name)
+	}
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+	b.lastErr = ""
+}
+
+func (b *breaker) failure(now time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.lastErr = err.Error()
+	b.probing = false
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		if b.state != CircuitOpen {
+			b.logger.Warn("Провайдер не отвечает, запросы приостановлены",
+				"provider", b.name, "failures", b.failures, "cooldown", b.cooldown.String(), "error", err)
+		}
+		b.state = CircuitOpen
+		b.retryAt = now.Add(b.cooldown)
+	}
+}
+
+// release снимает пробный запрос, который не дошел до провайдера: отказ без
+// запроса ничего не говорит о доступности, пробу нужно повторить
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) snapshot() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := CircuitState{State: b.state, Failures: b.failures, LastErr: b.lastErr}
+	if b.state == CircuitOpen {
+		state.RetryAt = b.retryAt
+	}
+	return state
+}
+
+// WithResilience повторяет временные сбои провайдера и размыкает
+// предохранитель после Threshold сбоев подряд. Каждый повтор расходует
+// бюджет, поэтому оборачивается провайдер уже с бюджетом.
+func WithResilience(p Provider, r Resilience, logger *slog.Logger) Provider {
+	if r.Retries == 0 && r.Threshold == 0 {
+		return p
+	}
+	wrapped := &resilient{Provider: p, policy: r}
+	if r.Threshold > 0 {
+		wrapped.breaker = &breaker{name: p.Name(), threshold: r.Threshold, cooldown: r.Cooldown, logger: logger, state: CircuitClosed}
+	}
+	if f, ok := p.(Forecaster); ok {
+		return &resilientForecaster{resilient: wrapped, forecaster: f}
+	}
+	return wrapped
+}
+
+type resilient struct {
+	Provider
+	policy  Resilience
+	breaker *breaker // nil — предохранитель выключен
+}
+
+// Circuit возвращает состояние предохранителя
+func (r *resilient) Circuit() CircuitState {
+	if r.breaker == nil {
+		return CircuitState{State: CircuitClosed}
+	}
+	return r.breaker.snapshot()
+}
+
+func (r *resilient) Fetch(ctx context.Context, city model.City) (model.WeatherData, error) {
+	var data model.WeatherData
+	err := r.do(ctx, func() error {
+		var err error
+		data, err = r.Provider.Fetch(ctx, city)
+		return err
+	})
+	return data, err
+}
+
+// do выполняет запрос с повторами под контролем предохранителя
+func (r *resilient) do(ctx context.Context, call func() error) error {
+	if r.breaker != nil && !r.breaker.allow(time.Now()) {
+		return fmt.Errorf("%s: %w", r.Name(), ErrCircuitOpen)
+	}
+
+	err := call()
+	for attempt := 1; err != nil && retryable(err) && attempt <= r.policy.Retries; attempt++ {
+		timer := time.NewTimer(r.policy.delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		err = call()
+	}
+
+	if r.breaker != nil {
+		switch {
+		case err == nil:
+			r.breaker.success()
+		case countsAsFailure(err):
+			r.breaker.failure(time.Now(), err)
+		default:
+			r.breaker.release()
+		}
+	}
+	return err
+}
+
+type resilientForecaster struct {
+	*resilient
+	forecaster Forecaster
+}
+
+func (r *resilientForecaster) Forecast(ctx context.Context, city model.City, days int) (model.ForecastBatch, error) {
+	var batch model.ForecastBatch
+	err := r.do(ctx, func() error {
+		var err error
+		batch, err = r.forecaster.Forecast(ctx, city, days)
+		return err
+	})
+	return batch, err
+}
+
+// retryable — сбой, который может пройти при повторе: сеть, таймаут или 5xx.
+// 429 не повторяется: клиент сам ждет Retry-After.
+func retryable(err error) bool {
+	var perr *Error
+	if errors.As(err, &perr) {
+		return perr.StatusCode >= 500
+	}
+	return countsAsFailure(err)
+}
+
+// countsAsFailure — ошибка говорит о недоступности провайдера, а не о
+// лимитах, данных города или отмене запроса
+func countsAsFailure(err error) bool {
+	switch {
+	case errors.Is(err, ErrBudgetExhausted), errors.Is(err, ErrRateLimited),
+		errors.Is(err, ErrNoCoordinates), errors.Is(err, context.Canceled):
+		return false
+	}
+	return true
+}
+
+// Circuit возвращает состояние предохранителя провайдера; false — провайдер
+// без предохранителя
+func Circuit(p Provider) (CircuitState, bool) {
+	r, ok := p.(interface{ Circuit() CircuitState })
+	if !ok {
+		return CircuitState{}, false
+	}
+	return r.Circuit(), true
+}
+
+// CircuitCheck возвращает проверку для health-отчета: сведения о
+// предохранителе и ошибку, пока он разомкнут; nil — предохранителя нет
+func CircuitCheck(p Provider) func(context.Context) (map[string]string, error) {
+	if _, ok := Circuit(p); !ok {
+		return nil
+	}
+	return func(context.Context) (map[string]string, error) {
+		state, _ := Circuit(p)
+		details := map[string]string{
+			"state":    state.State,
+			"failures": strconv.Itoa(state.Failures),
+		}
+		if state.State == CircuitOpen {
+			return details, fmt.Errorf("%w до %s: %s", ErrCircuitOpen, state.RetryAt.Format(time.RFC3339), state.LastErr)
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// NameWeatherAPI — название провайдера в замерах
+const NameWeatherAPI = "WeatherAPI"
+
+// WeatherAPI получает текущую погоду через API weatherapi.com
+type WeatherAPI struct {
+	*httpClient
+	baseURL string
+	apiKey  string
+}
+
+// NewWeatherAPI создает клиента по настройкам; nil — ключ API не задан
+func NewWeatherAPI(cfg *config.Config) *WeatherAPI {
+	if cfg.WeatherAPIKey == "" {
+		return nil
+	}
+	return &WeatherAPI{
+		httpClient: newHTTPClient(NameWeatherAPI, "", cfg.ProviderTimeout),
+		baseURL:    strings.TrimRight(cfg.WeatherAPIURL, "/"),
+		apiKey:     cfg.WeatherAPIKey,
+	}
+}
+
+func (a *WeatherAPI) Name() string {
+	return NameWeatherAPI
+}
+
+type weatherAPICurrent struct {
+	Current struct {
+		LastUpdated int64   `json:"last_updated_epoch"`
+		TempC       float64 `json:"temp_c"`
+		Condition   struct {
+			Text string `json:"text"`
+		} `json:"condition"`
+	} `json:"current"`
+}
+
+// Fetch возвращает текущую погоду города по координатам или названию
+func (a *WeatherAPI) Fetch(ctx context.Context, city model.City) (model.WeatherData, error) {
+	q := city.Name
+	if hasCoordinates(city) {
+		q = strconv.FormatFloat(city.Lat, 'f', 4, 64) + "," + strconv.FormatFloat(city.Lon, 'f', 4, 64)
+	}
+	params := url.Values{
+		"key": {a.apiKey},
+		"q":   {q},
+		"aqi": {"no"},
+	}
+
+	var resp weatherAPICurrent
+	if err := a.getJSON(ctx, a.baseURL+"/current.json?"+params.Encode(), &resp, weatherAPIMessage); err != nil {
+		return model.WeatherData{}, err
+	}
+
+	data := model.WeatherData{
+		City:      city.Name,
+		Temp:      resp.Current.TempC,
+		Condition: resp.Current.Condition.Text,
+		Provider:  NameWeatherAPI,
+		Timestamp: time.Now(),
+	}
+	if resp.Current.LastUpdated > 0 {
+		data.Timestamp = time.Unix(resp.Current.LastUpdated, 0)
+	}
+	// Код по таблице провайдера: тексты weatherapi.com стабильны на английском
+	data.NormalizeCondition()
+	return data, nil
+}
+
+// weatherAPIMessage достает текст ошибки из тела {"error":{"code":...,"message":...}}
+func weatherAPIMessage(body io.Reader) string {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	json.NewDecoder(body).Decode(&payload)
+	return payload.Error.Message
+}
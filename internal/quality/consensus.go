@@ -0,0 +1,58 @@
+package quality
+
+import (
+	"slices"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// Consensus сводит последние замеры разных провайдеров в одно значение:
+// медиана температуры устойчива к одному сбойному провайдеру, состояние —
+// самое частое среди провайдеров. При равенстве голосов выбирается код,
+// объявленный в таксономии раньше, чтобы ответ не зависел от порядка замеров.
+func Consensus(readings []model.WeatherData) model.ProviderConsensus {
+	c := model.ProviderConsensus{Providers: len(readings)}
+	if len(readings) == 0 {
+		return c
+	}
+
+	temps := make([]float64, 0, len(readings))
+	votes := make(map[model.ConditionCode]int)
+	raw := make(map[model.ConditionCode]string)
+	for _, r := range readings {
+		temps = append(temps, r.Temp)
+		votes[r.ConditionCode]++
+		if _, ok := raw[r.ConditionCode]; !ok {
+			raw[r.ConditionCode] = r.Condition
+		}
+	}
+
+	c.Temp = round2(median(temps))
+	c.Spread = round2(slices.Max(temps) - slices.Min(temps))
+
+	best := -1
+	for code, n := range votes {
+		if n > best || n == best && before(code, c.ConditionCode) {
+			best = n
+			c.ConditionCode = code
+		}
+	}
+	c.Condition = raw[c.ConditionCode]
+	return c
+}
+
+// before сравнивает коды по позиции в таксономии; неизвестные и пустой код
+// идут последними в алфавитном порядке
+func before(a, b model.ConditionCode) bool {
+	ra, rb := slices.Index(model.ConditionCodes, a), slices.Index(model.ConditionCodes, b)
+	if ra < 0 {
+		ra = len(model.ConditionCodes)
+	}
+	if rb < 0 {
+		rb = len(model.ConditionCodes)
+	}
+	if ra != rb {
+		return ra < rb
+	}
+	return a < b
+}
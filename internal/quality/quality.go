@@ -0,0 +1,180 @@
+// Package quality оценивает входящие замеры: z-оценка относительно скользящей
+// истории города и расхождение с другими провайдерами.
+package quality
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+)
+
+// maxRecent — сколько последних аномалий попадает в отчет
+const maxRecent = 20
+
+// Options — пороги оценки и сводки
+type Options struct {
+	Window          time.Duration // скользящее окно истории и отчета
+	PeerWindow      time.Duration // замеры других провайдеров не старше этого учитываются в сравнении
+	MinPoints       int           // меньше точек — z-оценка не считается
+	ZThreshold      float64
+	DisagreementMax float64 // °C
+	SuspectRate     float64 // доля аномалий для статуса suspect
+	BadRate         float64 // доля аномалий для статуса bad
+	StaleAfter      time.Duration
+	MinReportPoints int // меньше замеров в окне — статус по доле не выставляется
+}
+
+// OptionsFromConfig собирает параметры из конфигурации
+func OptionsFromConfig(cfg *config.Config) Options {
+	return Options{
+		Window:          cfg.QualityWindow,
+		PeerWindow:      30 * time.Minute,
+		MinPoints:       10,
+		ZThreshold:      cfg.QualityZThreshold,
+		DisagreementMax: cfg.QualityDisagreementMax,
+		SuspectRate:     cfg.QualitySuspectRate,
+		BadRate:         cfg.QualityBadRate,
+		StaleAfter:      cfg.QualityStaleAfter,
+		MinReportPoints: 5,
+	}
+}
+
+// Score оценивает замер по истории города. Сам замер в истории может быть:
+// точка того же провайдера с тем же временем исключается.
+func Score(reading model.WeatherData, history []model.WeatherData, opts Options) model.QualityScore {
+	score := model.QualityScore{
+		City:       reading.City,
+		Provider:   reading.Provider,
+		Temp:       reading.Temp,
+		ObservedAt: reading.Timestamp,
+	}
+
+	var temps []float64
+	latest := make(map[string]model.WeatherData) // последний замер каждого другого провайдера
+	for _, h := range history {
+		if h.Provider == reading.Provider && h.Timestamp.Equal(reading.Timestamp) {
+			continue
+		}
+		temps = append(temps, h.Temp)
+
+		if h.Provider == reading.Provider || reading.Timestamp.Sub(h.Timestamp).Abs() > opts.PeerWindow {
+			continue
+		}
+		if prev, ok := latest[h.Provider]; !ok || h.Timestamp.After(prev.Timestamp) {
+			latest[h.Provider] = h
+		}
+	}
+
+	if len(temps) >= opts.MinPoints {
+		mean, std := meanStd(temps)
+		// Нулевой разброс (постоянная температура) не должен давать бесконечную оценку
+		std = math.Max(std, 0.1)
+		score.ZScore = round2((reading.Temp - mean) / std)
+		if math.Abs(score.ZScore) > opts.ZThreshold {
+			score.Flags = append(score.Flags, model.QualityOutlier)
+		}
+	}
+
+	if len(latest) > 0 {
+		peers := make([]float64, 0, len(latest))
+		for _, p := range latest {
+			peers = append(peers, p.Temp)
+		}
+		score.Disagreement = round2(math.Abs(reading.Temp - median(peers)))
+		if score.Disagreement > opts.DisagreementMax {
+			score.Flags = append(score.Flags, model.QualityDisagreement)
+		}
+	}
+	return score
+}
+
+// Summarize строит отчет по оценкам города за окно [now-Window, now]
+func Summarize(city string, scores []model.QualityScore, now time.Time, opts Options) model.QualityReport {
+	report := model.QualityReport{
+		City:       city,
+		WindowFrom: now.Add(-opts.Window),
+		WindowTo:   now,
+		ByFlag:     make(map[string]int),
+		Recent:     []model.QualityScore{},
+	}
+
+	sort.Slice(scores, func(i, j int) bool { return scores[i].ObservedAt.After(scores[j].ObservedAt) })
+	for _, s := range scores {
+		report.Readings++
+		if !s.Anomalous() {
+			continue
+		}
+		report.Anomalies++
+		for _, f := range s.Flags {
+			report.ByFlag[f]++
+		}
+		if len(report.Recent) < maxRecent {
+			report.Recent = append(report.Recent, s)
+		}
+	}
+	if len(scores) > 0 {
+		last := scores[0].ObservedAt
+		report.LastReadingAt = &last
+		report.City = scores[0].City
+		report.AnomalyRate = round2(float64(report.Anomalies) / float64(report.Readings))
+	}
+
+	report.Status = status(report, now, opts)
+	return report
+}
+
+// status: нет замеров дольше StaleAfter — stale, иначе по доле аномалий
+func status(r model.QualityReport, now time.Time, opts Options) string {
+	switch {
+	case r.LastReadingAt == nil:
+		return model.QualityUnknown
+	case now.Sub(*r.LastReadingAt) > opts.StaleAfter:
+		return model.QualityStale
+	default:
+		return rateStatus(r.Readings, r.AnomalyRate, opts)
+	}
+}
+
+// rateStatus переводит долю аномалий среди n замеров в статус
+func rateStatus(n int, rate float64, opts Options) string {
+	switch {
+	case n < opts.MinReportPoints:
+		return model.QualityUnknown
+	case rate >= opts.BadRate:
+		return model.QualityBad
+	case rate >= opts.SuspectRate:
+		return model.QualitySuspect
+	default:
+		return model.QualityOK
+	}
+}
+
+func meanStd(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,170 @@
+package quality
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// recentSize — по скольким последним замерам города считается доля аномалий в метриках
+const recentSize = 50
+
+//go:generate go tool mockgen -destination=../testutil/mocks/quality.go -package=mocks -mock_names=Store=MockQualityStore . Store
+
+// Store — операции с БД, нужные стадии; реализуется storage.WeatherStorage
+type Store interface {
+	GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error)
+	SaveQualityScore(ctx context.Context, score model.QualityScore) error
+}
+
+// Stage оценивает каждый сохраненный замер и пишет оценку в БД.
+// Нулевой указатель безопасен и ничего не делает.
+type Stage struct {
+	store  Store
+	opts   Options
+	logger *slog.Logger
+
+	readings  metric.Int64Counter
+	anomalies metric.Int64Counter
+
+	mu     sync.Mutex
+	recent map[string]*window // по городу в нижнем регистре
+}
+
+// window — кольцо признаков аномальности последних замеров города
+type window struct {
+	city    string
+	flags   [recentSize]bool
+	n, next int
+	status  string
+}
+
+func (w *window) add(anomalous bool) {
+	w.flags[w.next] = anomalous
+	w.next = (w.next + 1) % recentSize
+	w.n = min(w.n+1, recentSize)
+}
+
+func (w *window) rate() float64 {
+	if w.n == 0 {
+		return 0
+	}
+	bad := 0
+	for i := 0; i < w.n; i++ {
+		if w.flags[i] {
+			bad++
+		}
+	}
+	return float64(bad) / float64(w.n)
+}
+
+// NewStage создает стадию по конфигурации, nil если оценка выключена
+func NewStage(cfg *config.Config, store Store, logger *slog.Logger) *Stage {
+	if !cfg.QualityEnabled {
+		return nil
+	}
+
+	meter := metrics.Meter("github.com/gometeo/app/internal/quality")
+	readings, _ := meter.Int64Counter("quality.readings",
+		metric.WithDescription("Количество оцененных замеров по результату"))
+	anomalies, _ := meter.Int64Counter("quality.anomalies",
+		metric.WithDescription("Количество аномальных замеров по признаку"))
+
+	s := &Stage{
+		store:     store,
+		opts:      OptionsFromConfig(cfg),
+		logger:    logger,
+		readings:  readings,
+		anomalies: anomalies,
+		recent:    make(map[string]*window),
+	}
+
+	rate, _ := meter.Float64ObservableGauge("quality.anomaly_rate",
+		metric.WithDescription("Доля аномалий среди последних замеров города"))
+	bad, _ := meter.Int64ObservableGauge("quality.feeds.bad",
+		metric.WithDescription("Количество городов со статусом bad"))
+	_, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var badCount int64
+		for _, w := range s.recent {
+			o.ObserveFloat64(rate, w.rate(), metric.WithAttributes(attribute.String("city", w.city)))
+			if w.status == model.QualityBad {
+				badCount++
+			}
+		}
+		o.ObserveInt64(bad, badCount)
+		return nil
+	}, rate, bad)
+	if err != nil {
+		logger.Warn("Метрики качества данных недоступны", "error", err)
+	}
+	return s
+}
+
+// Observe вызывается после сохранения замера в историю
+func (s *Stage) Observe(ctx context.Context, data model.WeatherData) error {
+	if s == nil {
+		return nil
+	}
+
+	history, err := s.store.GetHistory(ctx, data.City, data.Timestamp.Add(-s.opts.Window), data.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	score := Score(data, history, s.opts)
+	if err := s.store.SaveQualityScore(ctx, score); err != nil {
+		return err
+	}
+
+	result := "ok"
+	if score.Anomalous() {
+		result = "anomalous"
+		for _, f := range score.Flags {
+			s.anomalies.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("flag", f),
+				attribute.String("provider", data.Provider)))
+		}
+		s.logger.WarnContext(ctx, "Подозрительный замер",
+			"city", data.City,
+			"provider", data.Provider,
+			"temp", data.Temp,
+			"z_score", score.ZScore,
+			"disagreement", score.Disagreement,
+			"flags", score.Flags)
+	}
+	s.readings.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+
+	s.track(ctx, data.City, score.Anomalous())
+	return nil
+}
+
+// track обновляет скользящую долю аномалий и сообщает о смене статуса
+func (s *Stage) track(ctx context.Context, city string, anomalous bool) {
+	s.mu.Lock()
+	w, ok := s.recent[strings.ToLower(city)]
+	if !ok {
+		w = &window{city: city, status: model.QualityUnknown}
+		s.recent[strings.ToLower(city)] = w
+	}
+	w.add(anomalous)
+
+	status := rateStatus(w.n, w.rate(), s.opts)
+	prev := w.status
+	w.status = status
+	s.mu.Unlock()
+
+	if status != prev && (status == model.QualityBad || prev == model.QualityBad) {
+		s.logger.WarnContext(ctx, "Статус качества данных города изменился",
+			"city", city, "from", prev, "to", status)
+	}
+}
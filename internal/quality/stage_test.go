@@ -0,0 +1,86 @@
+package quality_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/quality"
+	"github.com/gometeo/app/internal/testutil"
+	"github.com/gometeo/app/internal/testutil/mocks"
+)
+
+func newStage(t *testing.T) (*quality.Stage, *mocks.MockQualityStore, *config.Config) {
+	cfg := config.Load()
+	cfg.QualityEnabled = true
+	store := mocks.NewMockQualityStore(gomock.NewController(t))
+	return quality.NewStage(cfg, store, testutil.Logger(t)), store, cfg
+}
+
+func TestStageObserveSavesScore(t *testing.T) {
+	stage, store, cfg := newStage(t)
+
+	observedAt := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	reading := model.WeatherData{City: "Moscow", Temp: 35, Provider: "owm", Timestamp: observedAt}
+	var history []model.WeatherData
+	for i := range 12 {
+		history = append(history, model.WeatherData{
+			City:      "Moscow",
+			Temp:      float64(-5 + i%2),
+			Provider:  "owm",
+			Timestamp: observedAt.Add(-time.Duration(i+1) * time.Hour),
+		})
+	}
+	history = append(history,
+		model.WeatherData{City: "Moscow", Temp: -4, Provider: "metno", Timestamp: observedAt.Add(-10 * time.Minute)},
+		reading)
+
+	store.EXPECT().GetHistory(gomock.Any(), "Moscow", observedAt.Add(-cfg.QualityWindow), observedAt).Return(history, nil)
+	store.EXPECT().SaveQualityScore(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, score model.QualityScore) error {
+			if score.City != "Moscow" || score.Provider != "owm" || !score.ObservedAt.Equal(observedAt) {
+				t.Errorf("оценка не того замера: %+v", score)
+			}
+			if !slices.Contains(score.Flags, model.QualityOutlier) || !slices.Contains(score.Flags, model.QualityDisagreement) {
+				t.Errorf("признаки %v, ожидались %s и %s", score.Flags, model.QualityOutlier, model.QualityDisagreement)
+			}
+			if score.Disagreement != 39 {
+				t.Errorf("расхождение %.2f, ожидалось 39", score.Disagreement)
+			}
+			return nil
+		})
+
+	if err := stage.Observe(context.Background(), reading); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStageObserveHistoryError(t *testing.T) {
+	stage, store, _ := newStage(t)
+
+	// Без истории оценка не пишется: gomock провалит тест на вызове SaveQualityScore
+	store.EXPECT().GetHistory(gomock.Any(), "Moscow", gomock.Any(), gomock.Any()).Return(nil, errors.New("соединение разорвано"))
+
+	err := stage.Observe(context.Background(), model.WeatherData{City: "Moscow", Temp: 1, Timestamp: time.Now()})
+	if err == nil {
+		t.Fatal("ожидалась ошибка чтения истории")
+	}
+}
+
+func TestStageDisabled(t *testing.T) {
+	cfg := config.Load()
+	cfg.QualityEnabled = false
+	stage := quality.NewStage(cfg, nil, testutil.Logger(t))
+	if stage != nil {
+		t.Fatal("выключенная стадия должна быть nil")
+	}
+	if err := stage.Observe(context.Background(), model.WeatherData{City: "Moscow"}); err != nil {
+		t.Fatalf("nil-стадия вернула ошибку: %v", err)
+	}
+}
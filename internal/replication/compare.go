@@ -0,0 +1,49 @@
+package replication
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// Compare сопоставляет время обновления городов в локальном и соседнем регионе.
+// Расхождение не больше tolerance считается синхронным состоянием.
+func Compare(local, peer map[string]time.Time, tolerance time.Duration) []model.ReplicaFreshness {
+	cities := make(map[string]struct{}, len(local))
+	for city := range local {
+		cities[city] = struct{}{}
+	}
+	for city := range peer {
+		cities[city] = struct{}{}
+	}
+
+	result := make([]model.ReplicaFreshness, 0, len(cities))
+	for city := range cities {
+		f := model.ReplicaFreshness{City: city, Status: model.ReplicaMissing}
+		localAt, hasLocal := local[city]
+		peerAt, hasPeer := peer[city]
+		if hasLocal {
+			f.LocalAt = &localAt
+		}
+		if hasPeer {
+			f.PeerAt = &peerAt
+		}
+
+		if hasLocal && hasPeer {
+			lag := localAt.Sub(peerAt)
+			if lag < 0 {
+				lag = -lag
+			}
+			f.LagSeconds = lag.Seconds()
+			f.Status = model.ReplicaInSync
+			if lag > tolerance {
+				f.Status = model.ReplicaLagging
+			}
+		}
+		result = append(result, f)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].City < result[j].City })
+	return result
+}
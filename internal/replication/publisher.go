@@ -0,0 +1,80 @@
+// Package replication переносит состояние погоды в резервный регион через Kafka:
+// агрегатор основного региона публикует weather.upserted после каждой записи,
+// а cmd/replicator резервного региона применяет события к своей БД.
+// Тот же Publisher рассылает обновления экземплярам API (см. internal/cachepush).
+package replication
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/gometeo/app/internal/bus"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/gometeo/app/internal/replication")
+
+// Publisher отправляет события weather.upserted. Нулевой указатель — репликация
+// выключена, Publish ничего не делает.
+type Publisher struct {
+	producer bus.Publisher
+	topic    string
+	source   string
+}
+
+func NewPublisher(producer bus.Publisher, topic, source string) *Publisher {
+	return &Publisher{producer: producer, topic: topic, source: source}
+}
+
+// Publish отправляет состояние города, записанное в updatedAt
+func (p *Publisher) Publish(ctx context.Context, data model.WeatherData, updatedAt time.Time) error {
+	if p == nil {
+		return nil
+	}
+
+	data.Timestamp = updatedAt
+	event, err := model.NewEvent(model.EventWeatherUpserted, p.source, updatedAt, data)
+	if err != nil {
+		return err
+	}
+	payload, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// Ключ по городу сохраняет порядок обновлений одного города
+	msg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(data.City),
+		Value: sarama.ByteEncoder(payload),
+	}
+
+	ctx, span := tracer.Start(ctx, p.topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", p.topic),
+			attribute.String("weather.city", data.City),
+		))
+	defer span.End()
+	tracing.InjectKafka(ctx, msg)
+
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		tracing.RecordError(span, err)
+		return fmt.Errorf("ошибка отправки события репликации: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает producer
+func (p *Publisher) Close() error {
+	if p == nil {
+		return nil
+	}
+	return p.producer.Close()
+}
@@ -0,0 +1,88 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+)
+
+// ErrGaveUp возвращается, если зависимость не поднялась за MaxWait
+var ErrGaveUp = errors.New("зависимость недоступна")
+
+// Backoff — параметры ожидания зависимости при старте
+type Backoff struct {
+	Initial time.Duration // пауза перед второй попыткой
+	Max     time.Duration // верхняя граница паузы
+	MaxWait time.Duration // общий лимит ожидания, 0 — ждать до отмены ctx
+}
+
+// BackoffFromConfig собирает параметры из конфигурации
+func BackoffFromConfig(cfg *config.Config) Backoff {
+	return Backoff{
+		Initial: cfg.StartupBackoffInitial,
+		Max:     cfg.StartupBackoffMax,
+		MaxWait: cfg.StartupMaxWait,
+	}
+}
+
+// Unbounded возвращает копию параметров без общего лимита ожидания
+func (b Backoff) Unbounded() Backoff {
+	b.MaxWait = 0
+	return b
+}
+
+// delay возвращает паузу перед попыткой attempt (с 1) с джиттером ±20%
+func (b Backoff) delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 1; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	jitter := time.Duration((rand.Float64()*0.4 - 0.2) * float64(d))
+	return d + jitter
+}
+
+// Wait вызывает connect с экспоненциальной паузой, пока он не вернет
+// результат без ошибки, не истечет MaxWait или не будет отменен ctx.
+func Wait[T any](ctx context.Context, logger *slog.Logger, name string, b Backoff, connect func(context.Context) (T, error)) (T, error) {
+	if b.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.MaxWait)
+		defer cancel()
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		result, err := connect(ctx)
+		if err == nil {
+			logger.Info("Зависимость доступна",
+				"dependency", name,
+				"attempt", attempt,
+				"waited_ms", time.Since(start).Milliseconds())
+			return result, nil
+		}
+
+		pause := b.delay(attempt)
+		logger.Warn("Зависимость недоступна, повторная попытка",
+			"dependency", name,
+			"attempt", attempt,
+			"retry_in", pause.String(),
+			"error", err)
+
+		timer := time.NewTimer(pause)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, fmt.Errorf("%w: %s после %d попыток: %w", ErrGaveUp, name, attempt, err)
+		}
+	}
+}
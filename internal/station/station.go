@@ -0,0 +1,83 @@
+package station
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// ProviderPrefix — замеры станций помечаются провайдером "station:<id>"
+const ProviderPrefix = "station:"
+
+// ErrNoStationID — ID станции нет ни в топике, ни в сообщении
+var ErrNoStationID = errors.New("не удалось определить ID станции")
+
+// Reading — сообщение персональной метеостанции.
+// Температура передается в °C (temperature) или °F (temperature_f).
+type Reading struct {
+	StationID    string   `json:"station_id"`
+	City         string   `json:"city"`
+	Temperature  *float64 `json:"temperature"`
+	TemperatureF *float64 `json:"temperature_f"`
+	Condition    string   `json:"condition"`
+	Timestamp    int64    `json:"ts"` // Unix-время в секундах, 0 — время получения
+}
+
+// Parse разбирает сообщение станции и приводит его к WeatherData.
+// ID из топика приоритетнее ID из тела: станция не может выдать себя за другую.
+func Parse(topicStationID string, payload []byte, received time.Time) (model.WeatherData, error) {
+	var r Reading
+	if err := json.Unmarshal(payload, &r); err != nil {
+		return model.WeatherData{}, fmt.Errorf("ошибка разбора сообщения станции: %w", err)
+	}
+
+	id := topicStationID
+	if id == "" {
+		id = strings.TrimSpace(r.StationID)
+	}
+	if id == "" {
+		return model.WeatherData{}, ErrNoStationID
+	}
+
+	data := model.WeatherData{
+		City:      strings.TrimSpace(r.City),
+		Condition: r.Condition,
+		Provider:  ProviderPrefix + id,
+		Timestamp: received.UTC(),
+	}
+	if r.Timestamp > 0 {
+		data.Timestamp = time.Unix(r.Timestamp, 0).UTC()
+	}
+
+	switch {
+	case r.Temperature != nil:
+		data.Temp = *r.Temperature
+	case r.TemperatureF != nil:
+		data.Temp = float64(model.TemperatureFromFahrenheit(*r.TemperatureF))
+	default:
+		return model.WeatherData{}, model.ValidationErrors{{Field: "temperature", Message: "обязательное поле"}}
+	}
+
+	data.NormalizeCondition()
+	if err := data.Validate(); err != nil {
+		return model.WeatherData{}, err
+	}
+	return data, nil
+}
+
+// IDFromTopic извлекает ID станции из уровня топика, совпавшего с первым "+" фильтра.
+// Для фильтра без "+" возвращает пустую строку.
+func IDFromTopic(filter, topic string) string {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "+" && i < len(topicLevels) {
+			return topicLevels[i]
+		}
+	}
+	return ""
+}
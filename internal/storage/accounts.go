@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// CreateAccount сохраняет аккаунт с хэшем ключа и возвращает его ID
+func (s *WeatherStorage) CreateAccount(ctx context.Context, account model.Account, keyHash string) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.CreateAccount"); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO api_keys (key_hash, name, plan, monthly_quota, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		keyHash,
+		account.Name,
+		account.Plan,
+		account.MonthlyQuota,
+		account.Active,
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания аккаунта %s: %w", account.Name, err)
+	}
+	return id, nil
+}
+
+// GetAccountByKeyHash возвращает аккаунт по хэшу ключа, nil если ключ неизвестен
+func (s *WeatherStorage) GetAccountByKeyHash(ctx context.Context, keyHash string) (*model.Account, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAccountByKeyHash"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, name, plan, monthly_quota, active, created_at
+		FROM api_keys
+		WHERE key_hash = $1
+	`
+
+	var account model.Account
+	err := s.db.QueryRowContext(ctx, query, keyHash).Scan(
+		&account.ID,
+		&account.Name,
+		&account.Plan,
+		&account.MonthlyQuota,
+		&account.Active,
+		&account.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения аккаунта: %w", err)
+	}
+	return &account, nil
+}
+
+// GetAccountByID возвращает аккаунт по ID, nil если аккаунта нет
+func (s *WeatherStorage) GetAccountByID(ctx context.Context, id int64) (*model.Account, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAccountByID"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, name, plan, monthly_quota, active, created_at
+		FROM api_keys
+		WHERE id = $1
+	`
+
+	var account model.Account
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&account.ID,
+		&account.Name,
+		&account.Plan,
+		&account.MonthlyQuota,
+		&account.Active,
+		&account.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения аккаунта: %w", err)
+	}
+	return &account, nil
+}
+
+// SaveUsage сохраняет счетчик запросов аккаунта за месяц. Счетчик только растет:
+// меньшее значение (например, после потери данных Redis) не перетирает сохраненное.
+func (s *WeatherStorage) SaveUsage(ctx context.Context, accountID int64, month string, requests int64) error {
+	if err := s.faults.Inject(ctx, "storage.SaveUsage"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_usage (account_id, month, requests, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account_id, month) DO UPDATE
+		SET requests = GREATEST(api_usage.requests, EXCLUDED.requests),
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, accountID, month, requests, time.Now()); err != nil {
+		return fmt.Errorf("ошибка сохранения расхода аккаунта %d: %w", accountID, err)
+	}
+	return nil
+}
+
+// GetUsage возвращает сохраненный счетчик запросов за месяц, 0 если записи нет
+func (s *WeatherStorage) GetUsage(ctx context.Context, accountID int64, month string) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.GetUsage"); err != nil {
+		return 0, err
+	}
+
+	var requests int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT requests FROM api_usage WHERE account_id = $1 AND month = $2`,
+		accountID, month,
+	).Scan(&requests)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения расхода аккаунта %d: %w", accountID, err)
+	}
+	return requests, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// ListChanges возвращает города, измененные после ревизии afterRevision
+// (и после updatedAfter, если время задано), по возрастанию ревизии.
+// Второе значение — ревизия последней строки или afterRevision, если изменений нет.
+func (s *WeatherStorage) ListChanges(ctx context.Context, afterRevision int64, updatedAfter time.Time, limit int) ([]model.WeatherData, int64, error) {
+	if err := s.faults.Inject(ctx, "storage.ListChanges"); err != nil {
+		return nil, 0, err
+	}
+
+	var after any
+	if !updatedAfter.IsZero() {
+		after = updatedAfter
+	}
+
+	query := `
+		SELECT city, temp, condition, COALESCE(condition_code, ''), provider, updated_at, revision
+		FROM weather
+		WHERE revision > $1
+		  AND ($2::timestamp IS NULL OR updated_at > $2)
+		ORDER BY revision
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, afterRevision, after, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения изменений: %w", err)
+	}
+	defer rows.Close()
+
+	last := afterRevision
+	changes := make([]model.WeatherData, 0, limit)
+	for rows.Next() {
+		var data model.WeatherData
+		if err := rows.Scan(
+			&data.City,
+			&data.Temp,
+			&data.Condition,
+			&data.ConditionCode,
+			&data.Provider,
+			&data.Timestamp,
+			&last,
+		); err != nil {
+			return nil, 0, fmt.Errorf("ошибка чтения изменений: %w", err)
+		}
+		changes = append(changes, data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка чтения изменений: %w", err)
+	}
+	return changes, last, nil
+}
@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pgTypes нужен для сканирования массивов Postgres через database/sql
+var pgTypes = pgtype.NewMap()
+
+// UpsertCity создает или обновляет справочные данные города
+func (s *WeatherStorage) UpsertCity(ctx context.Context, city model.City) error {
+	if err := s.faults.Inject(ctx, "storage.UpsertCity"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cities (name, country, lat, lon, timezone, aliases)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name) DO UPDATE
+		SET country = EXCLUDED.country,
+		    lat = EXCLUDED.lat,
+		    lon = EXCLUDED.lon,
+		    timezone = EXCLUDED.timezone,
+		    aliases = EXCLUDED.aliases;
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		city.Name,
+		city.Country,
+		city.Lat,
+		city.Lon,
+		city.Timezone,
+		aliasesOrEmpty(city.Aliases),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения города %s: %w", city.Name, err)
+	}
+	return nil
+}
+
+// execer — общий интерфейс для *sql.DB и *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertCity добавляет город, не трогая уже существующую запись.
+// Город сохраняется с city.Enabled: созданные по замеру или геокодеру
+// записи выключены, пока администратор не включит опрос.
+func insertCity(ctx context.Context, db execer, city model.City) error {
+	query := `
+		INSERT INTO cities (name, country, lat, lon, timezone, aliases, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING;
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		city.Name,
+		city.Country,
+		city.Lat,
+		city.Lon,
+		city.Timezone,
+		aliasesOrEmpty(city.Aliases),
+		city.Enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка добавления города %s: %w", city.Name, err)
+	}
+	return nil
+}
+
+// GetCity ищет город по названию или синониму без учета регистра
+func (s *WeatherStorage) GetCity(ctx context.Context, name string) (*model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.GetCity"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT name, country, lat, lon, timezone, aliases, enabled
+		FROM cities
+		WHERE LOWER(name) = LOWER($1)
+		   OR EXISTS (SELECT 1 FROM unnest(aliases) AS a WHERE LOWER(a) = LOWER($1))
+		ORDER BY LOWER(name) = LOWER($1) DESC
+		LIMIT 1
+	`
+
+	city, err := scanCity(s.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("город %s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения города: %w", err)
+	}
+	return city, nil
+}
+
+// ListCities возвращает весь справочник городов
+func (s *WeatherStorage) ListCities(ctx context.Context) ([]model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.ListCities"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT name, country, lat, lon, timezone, aliases, enabled
+		FROM cities
+		ORDER BY name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения справочника городов: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []model.City
+	for rows.Next() {
+		city, err := scanCity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		cities = append(cities, *city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+
+	return cities, nil
+}
+
+// CreateCity добавляет город в справочник вместе с расписанием опроса по
+// умолчанию. ErrAlreadyExists — город с таким названием уже есть.
+func (s *WeatherStorage) CreateCity(ctx context.Context, city model.City) error {
+	if err := s.faults.Inject(ctx, "storage.CreateCity"); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO cities (name, country, lat, lon, timezone, aliases, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT DO NOTHING;
+	`
+	res, err := tx.ExecContext(ctx, query,
+		city.Name,
+		city.Country,
+		city.Lat,
+		city.Lon,
+		city.Timezone,
+		aliasesOrEmpty(city.Aliases),
+		city.Enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка добавления города %s: %w", city.Name, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("город %s: %w", city.Name, ErrAlreadyExists)
+	}
+	if err := insertSchedule(ctx, tx, city.Name, model.DefaultFetchInterval); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// UpdateCity меняет данные города name; название не меняется, чтобы не
+// потерять связь с замерами и расписанием. ErrNotFound — города нет.
+func (s *WeatherStorage) UpdateCity(ctx context.Context, name string, city model.City) (*model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.UpdateCity"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE cities
+		SET country = $2, lat = $3, lon = $4, timezone = $5, aliases = $6, enabled = $7
+		WHERE LOWER(name) = LOWER($1)
+		RETURNING name, country, lat, lon, timezone, aliases, enabled
+	`
+	updated, err := scanCity(s.db.QueryRowContext(ctx, query,
+		name,
+		city.Country,
+		city.Lat,
+		city.Lon,
+		city.Timezone,
+		aliasesOrEmpty(city.Aliases),
+		city.Enabled,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("город %s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления города %s: %w", name, err)
+	}
+	return updated, nil
+}
+
+// DeleteCity удаляет город из справочника вместе с расписанием опроса.
+// Накопленные замеры остаются. false — города не было.
+func (s *WeatherStorage) DeleteCity(ctx context.Context, name string) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.DeleteCity"); err != nil {
+		return false, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stored string
+	err = tx.QueryRowContext(ctx, `DELETE FROM cities WHERE LOWER(name) = LOWER($1) RETURNING name`, name).Scan(&stored)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления города %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM city_schedules WHERE city = $1`, stored); err != nil {
+		return false, fmt.Errorf("ошибка удаления расписания %s: %w", stored, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return true, nil
+}
+
+// SearchCities ищет города по началу названия или синонима и по похожести
+// (pg_trgm) для автодополнения. Совпадения по началу идут первыми.
+func (s *WeatherStorage) SearchCities(ctx context.Context, q string, limit int) ([]model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.SearchCities"); err != nil {
+		return nil, err
+	}
+
+	q = strings.ToLower(q)
+	prefix := likeEscaper.Replace(q) + "%"
+
+	query := `
+		SELECT name, country, lat, lon, timezone, aliases, enabled
+		FROM cities
+		WHERE LOWER(name) LIKE $2
+		   OR LOWER(name) % $1
+		   OR EXISTS (SELECT 1 FROM unnest(aliases) AS a WHERE LOWER(a) LIKE $2 OR LOWER(a) % $1)
+		ORDER BY LOWER(name) LIKE $2 DESC,
+		         GREATEST(similarity(LOWER(name), $1),
+		                  COALESCE((SELECT MAX(similarity(LOWER(a), $1)) FROM unnest(aliases) AS a), 0)) DESC,
+		         name
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, q, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска городов: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []model.City
+	for rows.Next() {
+		city, err := scanCity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		cities = append(cities, *city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+
+	return cities, nil
+}
+
+// likeEscaper экранирует спецсимволы LIKE в пользовательском вводе
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// rowScanner — общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCity(row rowScanner) (*model.City, error) {
+	var city model.City
+	err := row.Scan(
+		&city.Name,
+		&city.Country,
+		&city.Lat,
+		&city.Lon,
+		&city.Timezone,
+		pgTypes.SQLScanner(&city.Aliases),
+		&city.Enabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &city, nil
+}
+
+func aliasesOrEmpty(aliases []string) []string {
+	if aliases == nil {
+		return []string{}
+	}
+	return aliases
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// SaveDelivery записывает итог доставки оповещения и возвращает его ID
+func (s *WeatherStorage) SaveDelivery(ctx context.Context, d model.Delivery) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.SaveDelivery"); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO notification_deliveries
+			(alert_id, rule_id, city, channel, status, attempts, last_error, created_at, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		d.AlertID,
+		d.RuleID,
+		d.City,
+		d.Channel,
+		string(d.Status),
+		d.Attempts,
+		d.LastError,
+		d.CreatedAt,
+		d.DeliveredAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения доставки для %s/%s: %w", d.City, d.Channel, err)
+	}
+	return id, nil
+}
+
+// ListDeliveries возвращает доставки по оповещению, новые первыми
+func (s *WeatherStorage) ListDeliveries(ctx context.Context, alertID int64) ([]model.Delivery, error) {
+	if err := s.faults.Inject(ctx, "storage.ListDeliveries"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, alert_id, rule_id, city, channel, status, attempts, last_error, created_at, delivered_at
+		FROM notification_deliveries
+		WHERE alert_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, alertID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения доставок: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []model.Delivery
+	for rows.Next() {
+		var d model.Delivery
+		if err := rows.Scan(
+			&d.ID,
+			&d.AlertID,
+			&d.RuleID,
+			&d.City,
+			&d.Channel,
+			&d.Status,
+			&d.Attempts,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return deliveries, nil
+}
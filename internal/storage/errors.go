@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/gometeo/app/internal/chaos"
+)
+
+// ErrNotFound — запрошенной записи нет в базе. Методы оборачивают его
+// в ошибку с подробностями, проверять нужно через errors.Is.
+var ErrNotFound = errors.New("запись не найдена")
+
+// ErrAlreadyExists — запись с таким ключом уже есть
+var ErrAlreadyExists = errors.New("запись уже существует")
+
+// IsUnavailable сообщает, что база недоступна: соединение не установлено или
+// оборвано, либо отказ внедрен chaos. Такие ошибки временные, в отличие от
+// ошибок запроса.
+func IsUnavailable(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, chaos.ErrInjected) ||
+		errors.As(err, &netErr)
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// GetGeocoded возвращает сохраненный результат геокодирования, nil если запрос не встречался
+func (s *WeatherStorage) GetGeocoded(ctx context.Context, query string) (*model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.GetGeocoded"); err != nil {
+		return nil, err
+	}
+
+	q := `
+		SELECT c.name, c.country, c.lat, c.lon, c.timezone, c.aliases
+		FROM geocode_cache g
+		JOIN cities c ON LOWER(c.name) = LOWER(g.city)
+		WHERE g.query = $1
+	`
+
+	city, err := scanCity(s.db.QueryRowContext(ctx, q, normalizeQuery(query)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения кэша геокодирования: %w", err)
+	}
+	return city, nil
+}
+
+// SaveGeocoded сохраняет город в справочник и запоминает, к какому городу ведет запрос
+func (s *WeatherStorage) SaveGeocoded(ctx context.Context, query string, city model.City) error {
+	if err := s.faults.Inject(ctx, "storage.SaveGeocoded"); err != nil {
+		return err
+	}
+
+	if err := insertCity(ctx, s.db, city); err != nil {
+		return err
+	}
+
+	q := `
+		INSERT INTO geocode_cache (query, city, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (query) DO UPDATE
+		SET city = EXCLUDED.city,
+		    created_at = EXCLUDED.created_at;
+	`
+	if _, err := s.db.ExecContext(ctx, q, normalizeQuery(query), city.Name, time.Now().UTC()); err != nil {
+		return fmt.Errorf("ошибка сохранения геокодирования %q: %w", query, err)
+	}
+	return nil
+}
+
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// AppendHistory добавляет замер в историю наблюдений
+func (s *WeatherStorage) AppendHistory(ctx context.Context, data model.WeatherData) error {
+	if err := s.faults.Inject(ctx, "storage.AppendHistory"); err != nil {
+		return err
+	}
+	return appendHistory(ctx, s.db, data)
+}
+
+func appendHistory(ctx context.Context, db execer, data model.WeatherData) error {
+	query := `
+		INSERT INTO weather_history (city, temp, condition, condition_code, provider, observed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		data.City,
+		data.Temp,
+		data.Condition,
+		string(data.ConditionCode),
+		data.Provider,
+		data.Timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка записи истории для %s: %w", data.City, err)
+	}
+	return nil
+}
+
+// GetHistory возвращает замеры города за [from, to] по возрастанию времени
+func (s *WeatherStorage) GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetHistory"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, temp, condition, condition_code, provider, observed_at
+		FROM weather_history
+		WHERE LOWER(city) = LOWER($1) AND observed_at BETWEEN $2 AND $3
+		ORDER BY observed_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории: %w", err)
+	}
+	defer rows.Close()
+
+	var history []model.WeatherData
+	for rows.Next() {
+		var data model.WeatherData
+		if err := rows.Scan(
+			&data.City,
+			&data.Temp,
+			&data.Condition,
+			&data.ConditionCode,
+			&data.Provider,
+			&data.Timestamp,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		history = append(history, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return history, nil
+}
+
+// ListHistory возвращает замеры всех городов за [from, to) для выгрузок
+func (s *WeatherStorage) ListHistory(ctx context.Context, from, to time.Time) ([]model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.ListHistory"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, temp, condition, condition_code, provider, observed_at
+		FROM weather_history
+		WHERE observed_at >= $1 AND observed_at < $2
+		ORDER BY city, observed_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории: %w", err)
+	}
+	defer rows.Close()
+
+	var history []model.WeatherData
+	for rows.Next() {
+		var data model.WeatherData
+		if err := rows.Scan(
+			&data.City,
+			&data.Temp,
+			&data.Condition,
+			&data.ConditionCode,
+			&data.Provider,
+			&data.Timestamp,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		history = append(history, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return history, nil
+}
+
+// ImportHistory добавляет пакет исторических замеров в одной транзакции.
+// Замер пропускается, если в истории уже есть точка того же города, провайдера
+// и времени, поэтому повторный импорт того же файла безопасен.
+// Возвращает число добавленных строк.
+func (s *WeatherStorage) ImportHistory(ctx context.Context, batch []model.WeatherData) (int, error) {
+	if err := s.faults.Inject(ctx, "storage.ImportHistory"); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO weather_history (city, temp, condition, condition_code, provider, observed_at)
+		SELECT $1::VARCHAR, $2::DOUBLE PRECISION, $3::VARCHAR, $4::VARCHAR, $5::VARCHAR, $6::TIMESTAMPTZ
+		WHERE NOT EXISTS (
+			SELECT 1 FROM weather_history
+			WHERE LOWER(city) = LOWER($1) AND observed_at = $6 AND provider = $5
+		)
+	`
+
+	inserted := 0
+	for _, data := range batch {
+		res, err := tx.ExecContext(ctx, query,
+			data.City,
+			data.Temp,
+			data.Condition,
+			string(data.ConditionCode),
+			data.Provider,
+			data.Timestamp,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка импорта истории для %s: %w", data.City, err)
+		}
+		n, _ := res.RowsAffected()
+		inserted += int(n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка фиксации импорта истории: %w", err)
+	}
+	return inserted, nil
+}
+
+// GetHistoryAround возвращает ближайшие замеры города не позже at и позже at.
+// Любой из них nil, если с этой стороны истории нет.
+func (s *WeatherStorage) GetHistoryAround(ctx context.Context, city string, at time.Time) (before, after *model.WeatherData, err error) {
+	if err := s.faults.Inject(ctx, "storage.GetHistoryAround"); err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+		(SELECT city, temp, condition, condition_code, provider, observed_at
+		 FROM weather_history
+		 WHERE LOWER(city) = LOWER($1) AND observed_at <= $2
+		 ORDER BY observed_at DESC LIMIT 1)
+		UNION ALL
+		(SELECT city, temp, condition, condition_code, provider, observed_at
+		 FROM weather_history
+		 WHERE LOWER(city) = LOWER($1) AND observed_at > $2
+		 ORDER BY observed_at LIMIT 1)
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city, at)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка получения истории: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data model.WeatherData
+		if err := rows.Scan(
+			&data.City,
+			&data.Temp,
+			&data.Condition,
+			&data.ConditionCode,
+			&data.Provider,
+			&data.Timestamp,
+		); err != nil {
+			return nil, nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		if data.Timestamp.After(at) {
+			after = &data
+		} else {
+			before = &data
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return before, after, nil
+}
+
+// GetHistoryStats считает минимум, максимум и среднее температуры города за [from, to]
+func (s *WeatherStorage) GetHistoryStats(ctx context.Context, city string, from, to time.Time) (model.WeatherStats, error) {
+	if err := s.faults.Inject(ctx, "storage.GetHistoryStats"); err != nil {
+		return model.WeatherStats{}, err
+	}
+
+	query := `
+		SELECT COUNT(*), MIN(temp), MAX(temp), AVG(temp)
+		FROM weather_history
+		WHERE LOWER(city) = LOWER($1) AND observed_at BETWEEN $2 AND $3
+	`
+
+	stats := model.WeatherStats{City: city, From: from, To: to}
+	var tmin, tmax, tavg sql.NullFloat64
+	if err := s.db.QueryRowContext(ctx, query, city, from, to).Scan(&stats.Count, &tmin, &tmax, &tavg); err != nil {
+		return model.WeatherStats{}, fmt.Errorf("ошибка расчета сводки для %s: %w", city, err)
+	}
+	if stats.Count > 0 {
+		stats.TempMin = &tmin.Float64
+		stats.TempMax = &tmax.Float64
+		stats.TempAvg = &tavg.Float64
+	}
+	return stats, nil
+}
@@ -7,20 +7,229 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/gometeo/app/internal/chaos"
 	"github.com/gometeo/app/internal/model"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/gometeo/app/internal/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// migrations выполняются по порядку при старте и должны быть идемпотентными
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS weather (
+		city VARCHAR(100) PRIMARY KEY,
+		temp DOUBLE PRECISION,
+		condition VARCHAR(255),
+		provider VARCHAR(100),
+		updated_at TIMESTAMP
+	);`,
+	`ALTER TABLE weather ADD COLUMN IF NOT EXISTS condition_code VARCHAR(32);`,
+	`CREATE TABLE IF NOT EXISTS cities (
+		name VARCHAR(100) PRIMARY KEY,
+		country VARCHAR(2) NOT NULL DEFAULT '',
+		lat DOUBLE PRECISION NOT NULL DEFAULT 0,
+		lon DOUBLE PRECISION NOT NULL DEFAULT 0,
+		timezone VARCHAR(64) NOT NULL DEFAULT '',
+		aliases TEXT[] NOT NULL DEFAULT '{}'
+	);`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS cities_lower_name_idx ON cities (LOWER(name));`,
+	// updated_at без зоны читался обратно как UTC, хотя писалось местное время;
+	// старые значения считаются UTC. Проверка типа не дает переписывать таблицу при каждом старте.
+	`DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'weather' AND column_name = 'updated_at'
+			AND data_type = 'timestamp without time zone') THEN
+			ALTER TABLE weather ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+		END IF;
+	END $$;`,
+	`CREATE TABLE IF NOT EXISTS notification_deliveries (
+		id BIGSERIAL PRIMARY KEY,
+		alert_id BIGINT NOT NULL DEFAULT 0,
+		rule_id BIGINT NOT NULL DEFAULT 0,
+		city VARCHAR(100) NOT NULL,
+		channel VARCHAR(32) NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		delivered_at TIMESTAMPTZ
+	);`,
+	`CREATE INDEX IF NOT EXISTS notification_deliveries_alert_idx ON notification_deliveries (alert_id);`,
+	`CREATE TABLE IF NOT EXISTS city_schedules (
+		city VARCHAR(100) PRIMARY KEY,
+		interval_seconds INT NOT NULL DEFAULT 60,
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		last_requested_at TIMESTAMPTZ
+	);`,
+	`CREATE TABLE IF NOT EXISTS weather_history (
+		id BIGSERIAL PRIMARY KEY,
+		city VARCHAR(100) NOT NULL,
+		temp DOUBLE PRECISION NOT NULL,
+		condition VARCHAR(255) NOT NULL DEFAULT '',
+		condition_code VARCHAR(32) NOT NULL DEFAULT '',
+		provider VARCHAR(100) NOT NULL DEFAULT '',
+		observed_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS weather_history_city_time_idx ON weather_history (LOWER(city), observed_at);`,
+	`CREATE TABLE IF NOT EXISTS forecasts (
+		city VARCHAR(100) NOT NULL,
+		provider VARCHAR(100) NOT NULL,
+		forecast_for TIMESTAMPTZ NOT NULL,
+		temp DOUBLE PRECISION NOT NULL,
+		condition_code VARCHAR(32) NOT NULL DEFAULT '',
+		issued_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (city, provider, forecast_for)
+	);`,
+	`CREATE TABLE IF NOT EXISTS geocode_cache (
+		query VARCHAR(255) PRIMARY KEY,
+		city VARCHAR(100) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS quality_scores (
+		id BIGSERIAL PRIMARY KEY,
+		city VARCHAR(100) NOT NULL,
+		provider VARCHAR(100) NOT NULL DEFAULT '',
+		temp DOUBLE PRECISION NOT NULL,
+		z_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+		disagreement DOUBLE PRECISION NOT NULL DEFAULT 0,
+		flags VARCHAR(255) NOT NULL DEFAULT '',
+		observed_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS quality_scores_city_time_idx ON quality_scores (LOWER(city), observed_at);`,
+	`CREATE TABLE IF NOT EXISTS api_keys (
+		id BIGSERIAL PRIMARY KEY,
+		key_hash CHAR(64) NOT NULL UNIQUE,
+		name VARCHAR(100) NOT NULL,
+		plan VARCHAR(32) NOT NULL DEFAULT 'free',
+		monthly_quota BIGINT NOT NULL DEFAULT 0,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS api_usage (
+		account_id BIGINT NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		month CHAR(7) NOT NULL,
+		requests BIGINT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (account_id, month)
+	);`,
+	`CREATE TABLE IF NOT EXISTS account_preferences (
+		account_id BIGINT PRIMARY KEY REFERENCES api_keys (id) ON DELETE CASCADE,
+		units VARCHAR(16) NOT NULL,
+		language VARCHAR(8) NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS account_favorites (
+		account_id BIGINT NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		city VARCHAR(100) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (account_id, city)
+	);`,
+	`ALTER TABLE account_preferences ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';`,
+	`CREATE TABLE IF NOT EXISTS air_quality (
+		city VARCHAR(100) PRIMARY KEY,
+		pm25 DOUBLE PRECISION NOT NULL,
+		pm10 DOUBLE PRECISION NOT NULL,
+		no2 DOUBLE PRECISION NOT NULL,
+		o3 DOUBLE PRECISION NOT NULL,
+		aqi INT NOT NULL,
+		category VARCHAR(40) NOT NULL,
+		provider VARCHAR(100) NOT NULL,
+		observed_at TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS air_quality_history (
+		id BIGSERIAL PRIMARY KEY,
+		city VARCHAR(100) NOT NULL,
+		pm25 DOUBLE PRECISION NOT NULL,
+		pm10 DOUBLE PRECISION NOT NULL,
+		no2 DOUBLE PRECISION NOT NULL,
+		o3 DOUBLE PRECISION NOT NULL,
+		aqi INT NOT NULL,
+		category VARCHAR(40) NOT NULL,
+		provider VARCHAR(100) NOT NULL,
+		observed_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS air_quality_history_city_time_idx ON air_quality_history (LOWER(city), observed_at);`,
+	`CREATE SEQUENCE IF NOT EXISTS weather_revision_seq;`,
+	`ALTER TABLE weather ADD COLUMN IF NOT EXISTS revision BIGINT NOT NULL DEFAULT nextval('weather_revision_seq');`,
+	`CREATE INDEX IF NOT EXISTS weather_revision_idx ON weather (revision);`,
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm;`,
+	`CREATE INDEX IF NOT EXISTS cities_name_trgm_idx ON cities USING gin (LOWER(name) gin_trgm_ops);`,
+	`CREATE TABLE IF NOT EXISTS alert_rules (
+		id BIGSERIAL PRIMARY KEY,
+		account_id BIGINT NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		city VARCHAR(100) NOT NULL,
+		metric VARCHAR(32) NOT NULL,
+		operator VARCHAR(2) NOT NULL,
+		threshold DOUBLE PRECISION NOT NULL,
+		channels TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS alert_rules_city_idx ON alert_rules (LOWER(city));`,
+	`CREATE INDEX IF NOT EXISTS alert_rules_account_idx ON alert_rules (account_id);`,
+	`CREATE TABLE IF NOT EXISTS alerts_triggered (
+		id BIGSERIAL PRIMARY KEY,
+		rule_id BIGINT NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+		city VARCHAR(100) NOT NULL,
+		metric VARCHAR(32) NOT NULL,
+		operator VARCHAR(2) NOT NULL,
+		threshold DOUBLE PRECISION NOT NULL,
+		value DOUBLE PRECISION NOT NULL,
+		channels TEXT[] NOT NULL DEFAULT '{}',
+		triggered_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS alerts_triggered_rule_time_idx ON alerts_triggered (rule_id, triggered_at DESC);`,
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id BIGSERIAL PRIMARY KEY,
+		account_id BIGINT NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		url TEXT NOT NULL,
+		city VARCHAR(100) NOT NULL DEFAULT '',
+		secret VARCHAR(100) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS webhooks_city_idx ON webhooks (LOWER(city));`,
+	`CREATE INDEX IF NOT EXISTS webhooks_account_idx ON webhooks (account_id);`,
+	`CREATE TABLE IF NOT EXISTS weather_providers (
+		city VARCHAR(100) NOT NULL,
+		provider VARCHAR(100) NOT NULL,
+		temp DOUBLE PRECISION NOT NULL,
+		condition VARCHAR(255) NOT NULL DEFAULT '',
+		condition_code VARCHAR(32) NOT NULL DEFAULT '',
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (city, provider)
+	);`,
+	`INSERT INTO weather_providers (city, provider, temp, condition, condition_code, updated_at)
+		SELECT city, provider, temp, COALESCE(condition, ''), COALESCE(condition_code, ''), COALESCE(updated_at, NOW())
+		FROM weather
+		WHERE provider IS NOT NULL AND temp IS NOT NULL
+		ON CONFLICT DO NOTHING;`,
+	`CREATE TABLE IF NOT EXISTS users (
+		id BIGSERIAL PRIMARY KEY,
+		username VARCHAR(100) NOT NULL UNIQUE,
+		password_hash VARCHAR(100) NOT NULL,
+		role VARCHAR(16) NOT NULL,
+		account_id BIGINT REFERENCES api_keys (id) ON DELETE SET NULL,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMPTZ NOT NULL
+	);`,
+	`ALTER TABLE cities ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT TRUE;`,
+}
+
 type WeatherStorage struct {
 	db     *sql.DB
 	logger *slog.Logger
+	faults *chaos.Injector // nil вне режима внедрения сбоев
 }
 
 func New(dsn string, logger *slog.Logger) (*WeatherStorage, error) {
-	db, err := sql.Open("pgx", dsn)
+	connConfig, err := pgx.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка открытия БД: %w", err)
 	}
+	// Спаны запросов внутри трассы HTTP-запроса или сообщения Kafka
+	connConfig.Tracer = tracing.QueryTracer{}
+	db := stdlib.OpenDB(*connConfig)
 
 	// Настройка пула соединений
 	db.SetMaxOpenConns(25)
@@ -32,63 +241,220 @@ func New(dsn string, logger *slog.Logger) (*WeatherStorage, error) {
 	}
 
 	// Автоматическая миграция
-	query := `
-	CREATE TABLE IF NOT EXISTS weather (
-		city VARCHAR(100) PRIMARY KEY,
-		temp DOUBLE PRECISION,
-		condition VARCHAR(255),
-		provider VARCHAR(100),
-		updated_at TIMESTAMP
-	);`
-	
-	if _, err := db.Exec(query); err != nil {
-		return nil, fmt.Errorf("ошибка создания таблицы: %w", err)
+	for _, query := range migrations {
+		if _, err := db.Exec(query); err != nil {
+			return nil, fmt.Errorf("ошибка миграции схемы: %w", err)
+		}
+	}
+
+	store := &WeatherStorage{db: db, logger: logger}
+
+	// Заполнение справочника городов и расписаний стартовым набором
+	for _, city := range model.DefaultCities {
+		if err := insertCity(context.Background(), db, city); err != nil {
+			return nil, err
+		}
+		if err := insertSchedule(context.Background(), db, city.Name, model.DefaultFetchInterval); err != nil {
+			return nil, err
+		}
 	}
 
 	logger.Info("База данных инициализирована")
-	return &WeatherStorage{db: db, logger: logger}, nil
+	return store, nil
 }
 
 func (s *WeatherStorage) Close() {
 	s.db.Close()
 }
 
+// SetFaultInjector включает внедрение сбоев для проверки отказоустойчивости
+func (s *WeatherStorage) SetFaultInjector(faults *chaos.Injector) {
+	s.faults = faults
+}
+
+// Stats возвращает состояние пула соединений
+func (s *WeatherStorage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
 func (s *WeatherStorage) Ping(ctx context.Context) error {
+	if err := s.faults.Inject(ctx, "storage.Ping"); err != nil {
+		return err
+	}
+
 	return s.db.PingContext(ctx)
 }
 
-// Save обновляет погоду или создает новую запись
-func (s *WeatherStorage) Save(ctx context.Context, data model.WeatherData) error {
+// Health проверяет БД и возвращает время последнего сохраненного замера:
+// по нему видно, что агрегатор продолжает писать данные
+func (s *WeatherStorage) Health(ctx context.Context) (map[string]string, error) {
+	if err := s.Ping(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastWrite sql.NullTime
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(updated_at) FROM weather`).Scan(&lastWrite); err != nil {
+		return nil, fmt.Errorf("ошибка получения времени последней записи: %w", err)
+	}
+	if !lastWrite.Valid {
+		return nil, nil
+	}
+	return map[string]string{"last_write": lastWrite.Time.UTC().Format(time.RFC3339)}, nil
+}
+
+// Save обновляет погоду или создает новую запись. Возвращает записанное
+// время обновления: события репликации несут его без изменений.
+func (s *WeatherStorage) Save(ctx context.Context, data model.WeatherData) (time.Time, error) {
+	if err := s.faults.Inject(ctx, "storage.Save"); err != nil {
+		return time.Time{}, err
+	}
+
+	updatedAt, err := saveWeather(ctx, s.db, data)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	s.logger.DebugContext(ctx, "Данные сохранены в БД", "city", data.City)
+	return updatedAt, nil
+}
+
+// SaveWithHistory сохраняет замер как Save и добавляет его в историю в одной
+// транзакции: текущая погода не расходится с историей при сбое между записями
+func (s *WeatherStorage) SaveWithHistory(ctx context.Context, data model.WeatherData) (time.Time, error) {
+	if err := s.faults.Inject(ctx, "storage.SaveWithHistory"); err != nil {
+		return time.Time{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	updatedAt, err := saveWeather(ctx, tx, data)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := appendHistory(ctx, tx, data); err != nil {
+		return time.Time{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка фиксации замера %s: %w", data.City, err)
+	}
+
+	s.logger.DebugContext(ctx, "Данные сохранены в БД", "city", data.City)
+	return updatedAt, nil
+}
+
+// saveWeather обновляет текущую погоду города, замер провайдера и справочник
+func saveWeather(ctx context.Context, db execer, data model.WeatherData) (time.Time, error) {
 	query := `
-		INSERT INTO weather (city, temp, condition, provider, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO weather (city, temp, condition, condition_code, provider, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (city) DO UPDATE 
 		SET temp = EXCLUDED.temp,
 		    condition = EXCLUDED.condition,
+		    condition_code = EXCLUDED.condition_code,
 			provider = EXCLUDED.provider,
-			updated_at = EXCLUDED.updated_at;
+			updated_at = EXCLUDED.updated_at,
+			revision = nextval('weather_revision_seq');
 	`
 
-	_, err := s.db.ExecContext(ctx, query, 
+	// Postgres хранит микросекунды: время округляется, чтобы совпадать с записанным
+	updatedAt := time.Now().UTC().Truncate(time.Microsecond)
+	_, err := db.ExecContext(ctx, query, 
 		data.City, 
 		data.Temp, 
 		data.Condition, 
+		string(data.ConditionCode),
 		data.Provider, 
-		time.Now(),
+		updatedAt,
 	)
 	
 	if err != nil {
-		return fmt.Errorf("ошибка сохранения погоды для %s: %w", data.City, err)
+		return time.Time{}, fmt.Errorf("ошибка сохранения погоды для %s: %w", data.City, err)
+	}
+	if err := saveProviderReading(ctx, db, data, updatedAt); err != nil {
+		return time.Time{}, err
+	}
+
+	// Город без справочных данных все равно попадает в справочник,
+	// но выключенным: опрос включает администратор
+	if err := insertCity(ctx, db, model.City{Name: data.City}); err != nil {
+		return time.Time{}, err
+	}
+	return updatedAt, nil
+}
+
+// SaveBatch обновляет или создает погоду для пакета городов в одной транзакции:
+// либо сохраняются все замеры, либо ни одного. В отличие от Save время
+// обновления берется из замера, чтобы выгрузки сохраняли исходное время.
+func (s *WeatherStorage) SaveBatch(ctx context.Context, batch []model.WeatherData) error {
+	if err := s.faults.Inject(ctx, "storage.SaveBatch"); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO weather (city, temp, condition, condition_code, provider, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (city) DO UPDATE
+		SET temp = EXCLUDED.temp,
+		    condition = EXCLUDED.condition,
+		    condition_code = EXCLUDED.condition_code,
+		    provider = EXCLUDED.provider,
+		    updated_at = EXCLUDED.updated_at,
+		    revision = nextval('weather_revision_seq');
+	`
+
+	for _, data := range batch {
+		updatedAt := data.Timestamp.UTC()
+		if updatedAt.IsZero() {
+			updatedAt = time.Now().UTC()
+		}
+		_, err := tx.ExecContext(ctx, query,
+			data.City,
+			data.Temp,
+			data.Condition,
+			string(data.ConditionCode),
+			data.Provider,
+			updatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения погоды для %s: %w", data.City, err)
+		}
+		if err := saveProviderReading(ctx, tx, data, updatedAt); err != nil {
+			return err
+		}
+
+		// Город без справочных данных все равно попадает в справочник,
+	// но выключенным: опрос включает администратор
+		if err := insertCity(ctx, tx, model.City{Name: data.City}); err != nil {
+			return err
+		}
 	}
 
-	s.logger.Debug("Данные сохранены в БД", "city", data.City)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации пакета погоды: %w", err)
+	}
+
+	s.logger.DebugContext(ctx, "Пакет данных сохранен в БД", "count", len(batch))
 	return nil
 }
 
 // GetByCity возвращает погоду для конкретного города
 func (s *WeatherStorage) GetByCity(ctx context.Context, city string) (*model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetByCity"); err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT city, temp, condition, provider, updated_at
+		SELECT city, temp, condition, COALESCE(condition_code, ''), provider, updated_at
 		FROM weather
 		WHERE city = $1
 	`
@@ -98,12 +464,13 @@ func (s *WeatherStorage) GetByCity(ctx context.Context, city string) (*model.Wea
 		&data.City,
 		&data.Temp,
 		&data.Condition,
+		&data.ConditionCode,
 		&data.Provider,
 		&data.Timestamp,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("город %s не найден", city)
+		return nil, fmt.Errorf("город %s: %w", city, ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения данных: %w", err)
@@ -112,28 +479,182 @@ func (s *WeatherStorage) GetByCity(ctx context.Context, city string) (*model.Wea
 	return &data, nil
 }
 
-// GetAllCities возвращает список всех городов
-func (s *WeatherStorage) GetAllCities(ctx context.Context) ([]string, error) {
-	query := `SELECT city FROM weather ORDER BY city`
-	
-	rows, err := s.db.QueryContext(ctx, query)
+// Delete удаляет текущую погоду города вместе с замерами провайдеров в одной
+// транзакции и выключает город в справочнике, чтобы коллектор не вернул его;
+// история замеров сохраняется. Возвращает false, если города не было.
+func (s *WeatherStorage) Delete(ctx context.Context, city string) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.Delete"); err != nil {
+		return false, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка получения городов: %w", err)
+		return false, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM weather WHERE city = $1`, city)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления города %s: %w", city, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM weather_providers WHERE city = $1`, city); err != nil {
+		return false, fmt.Errorf("ошибка удаления замеров провайдеров города %s: %w", city, err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE cities SET enabled = FALSE WHERE LOWER(name) = LOWER($1)`, city); err != nil {
+		return false, fmt.Errorf("ошибка выключения города %s: %w", city, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка фиксации удаления города %s: %w", city, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetByCities возвращает погоду для нескольких городов одним запросом.
+// Города без данных в результат не попадают.
+func (s *WeatherStorage) GetByCities(ctx context.Context, cities []string) ([]model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetByCities"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, temp, condition, COALESCE(condition_code, ''), provider, updated_at
+		FROM weather
+		WHERE city = ANY($1)
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, cities)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения данных: %w", err)
 	}
 	defer rows.Close()
 
-	var cities []string
+	result := make([]model.WeatherData, 0, len(cities))
 	for rows.Next() {
-		var city string
-		if err := rows.Scan(&city); err != nil {
+		var data model.WeatherData
+		if err := rows.Scan(
+			&data.City,
+			&data.Temp,
+			&data.Condition,
+			&data.ConditionCode,
+			&data.Provider,
+			&data.Timestamp,
+		); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования: %w", err)
 		}
+		result = append(result, data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return result, nil
+}
+
+// CityListOptions — страница и порядок списка городов
+type CityListOptions struct {
+	Sort   string // city, updated_at или temp; "-" в начале — по убыванию
+	Limit  int    // 0 — все города
+	Offset int
+}
+
+// citySorts — допустимые сортировки списка городов; город добавлен для устойчивого порядка
+var citySorts = map[string]string{
+	"city":        "city",
+	"-city":       "city DESC",
+	"updated_at":  "updated_at NULLS FIRST, city",
+	"-updated_at": "updated_at DESC NULLS LAST, city",
+	"temp":        "temp NULLS FIRST, city",
+	"-temp":       "temp DESC NULLS LAST, city",
+}
+
+// ValidCitySort сообщает, поддерживается ли сортировка списка городов
+func ValidCitySort(sort string) bool {
+	_, ok := citySorts[sort]
+	return ok
+}
+
+// GetAllCities возвращает страницу списка городов и общее число городов
+func (s *WeatherStorage) GetAllCities(ctx context.Context, opts CityListOptions) ([]string, int, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAllCities"); err != nil {
+		return nil, 0, err
+	}
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = "city"
+	}
+	order, ok := citySorts[sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("неизвестная сортировка городов: %s", opts.Sort)
+	}
+
+	var limit any
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+	// Порядок выбран из белого списка, подстановка в запрос безопасна
+	query := `SELECT city, COUNT(*) OVER () FROM weather ORDER BY ` + order + ` LIMIT $1 OFFSET $2`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения городов: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		cities []string
+		total  int
+	)
+	for rows.Next() {
+		var city string
+		if err := rows.Scan(&city, &total); err != nil {
+			return nil, 0, fmt.Errorf("ошибка сканирования: %w", err)
+		}
 		cities = append(cities, city)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка итерации: %w", err)
+		return nil, 0, fmt.Errorf("ошибка итерации: %w", err)
 	}
 
+	// Страница за концом списка пуста, но общее число нужно для навигации
+	if len(cities) == 0 && opts.Offset > 0 {
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM weather`).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("ошибка подсчета городов: %w", err)
+		}
+	}
+
+	return cities, total, nil
+}
+
+// ListCityEntries возвращает все города по алфавиту с провайдером и временем
+// последнего замера
+func (s *WeatherStorage) ListCityEntries(ctx context.Context) ([]model.CityEntry, error) {
+	if err := s.faults.Inject(ctx, "storage.ListCityEntries"); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT city, COALESCE(provider, ''), updated_at FROM weather ORDER BY city`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения городов: %w", err)
+	}
+	defer rows.Close()
+
+	cities := []model.CityEntry{}
+	for rows.Next() {
+		var (
+			entry     model.CityEntry
+			updatedAt sql.NullTime
+		)
+		if err := rows.Scan(&entry.City, &entry.Provider, &updatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		entry.UpdatedAt = updatedAt.Time
+		cities = append(cities, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
 	return cities, nil
-}
\ No newline at end of file
+}
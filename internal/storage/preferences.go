@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// GetPreferences возвращает настройки аккаунта, значения по умолчанию если их нет
+func (s *WeatherStorage) GetPreferences(ctx context.Context, accountID int64) (model.Preferences, error) {
+	if err := s.faults.Inject(ctx, "storage.GetPreferences"); err != nil {
+		return model.Preferences{}, err
+	}
+
+	query := `
+		SELECT units, language, timezone, updated_at
+		FROM account_preferences
+		WHERE account_id = $1
+	`
+
+	var prefs model.Preferences
+	err := s.db.QueryRowContext(ctx, query, accountID).Scan(&prefs.Units, &prefs.Language, &prefs.Timezone, &prefs.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return model.DefaultPreferences(), nil
+	}
+	if err != nil {
+		return model.Preferences{}, fmt.Errorf("ошибка получения настроек аккаунта %d: %w", accountID, err)
+	}
+	return prefs, nil
+}
+
+// SavePreferences сохраняет настройки аккаунта
+func (s *WeatherStorage) SavePreferences(ctx context.Context, accountID int64, prefs model.Preferences) error {
+	if err := s.faults.Inject(ctx, "storage.SavePreferences"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO account_preferences (account_id, units, language, timezone, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id)
+		DO UPDATE SET units = EXCLUDED.units, language = EXCLUDED.language,
+			timezone = EXCLUDED.timezone, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := s.db.ExecContext(ctx, query, accountID, string(prefs.Units), string(prefs.Language), prefs.Timezone, prefs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения настроек аккаунта %d: %w", accountID, err)
+	}
+	return nil
+}
+
+// ListFavorites возвращает избранные города аккаунта в порядке добавления
+func (s *WeatherStorage) ListFavorites(ctx context.Context, accountID int64) ([]string, error) {
+	if err := s.faults.Inject(ctx, "storage.ListFavorites"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city
+		FROM account_favorites
+		WHERE account_id = $1
+		ORDER BY created_at, city
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения избранного: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []string
+	for rows.Next() {
+		var city string
+		if err := rows.Scan(&city); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		cities = append(cities, city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return cities, nil
+}
+
+// AddFavorite добавляет город в избранное; повторное добавление ничего не меняет
+func (s *WeatherStorage) AddFavorite(ctx context.Context, accountID int64, city string) error {
+	if err := s.faults.Inject(ctx, "storage.AddFavorite"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO account_favorites (account_id, city, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_id, city) DO NOTHING
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, accountID, city, time.Now()); err != nil {
+		return fmt.Errorf("ошибка добавления %s в избранное: %w", city, err)
+	}
+	return nil
+}
+
+// RemoveFavorite удаляет город из избранного, false если его там не было
+func (s *WeatherStorage) RemoveFavorite(ctx context.Context, accountID int64, city string) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.RemoveFavorite"); err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM account_favorites WHERE account_id = $1 AND city = $2`, accountID, city)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления %s из избранного: %w", city, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
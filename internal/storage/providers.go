@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// saveProviderReading обновляет последний замер провайдера для города.
+// Таблица weather хранит один замер на город, weather_providers — по одному
+// на каждую пару (город, провайдер).
+func saveProviderReading(ctx context.Context, db execer, data model.WeatherData, updatedAt time.Time) error {
+	query := `
+		INSERT INTO weather_providers (city, provider, temp, condition, condition_code, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (city, provider) DO UPDATE
+		SET temp = EXCLUDED.temp,
+		    condition = EXCLUDED.condition,
+		    condition_code = EXCLUDED.condition_code,
+		    updated_at = EXCLUDED.updated_at;
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		data.City,
+		data.Provider,
+		data.Temp,
+		data.Condition,
+		string(data.ConditionCode),
+		updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения замера %s для %s: %w", data.Provider, data.City, err)
+	}
+	return nil
+}
+
+// GetProviderReadings возвращает последние замеры всех провайдеров города,
+// упорядоченные по имени провайдера
+func (s *WeatherStorage) GetProviderReadings(ctx context.Context, city string) ([]model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetProviderReadings"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, temp, condition, condition_code, provider, updated_at
+		FROM weather_providers
+		WHERE city = $1
+		ORDER BY provider
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения замеров провайдеров для %s: %w", city, err)
+	}
+	defer rows.Close()
+
+	readings := make([]model.WeatherData, 0)
+	for rows.Next() {
+		var data model.WeatherData
+		if err := rows.Scan(
+			&data.City,
+			&data.Temp,
+			&data.Condition,
+			&data.ConditionCode,
+			&data.Provider,
+			&data.Timestamp,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения замера провайдера: %w", err)
+		}
+		readings = append(readings, data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения замеров провайдеров: %w", err)
+	}
+	return readings, nil
+}
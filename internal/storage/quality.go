@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// SaveQualityScore сохраняет оценку качества замера
+func (s *WeatherStorage) SaveQualityScore(ctx context.Context, score model.QualityScore) error {
+	if err := s.faults.Inject(ctx, "storage.SaveQualityScore"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO quality_scores (city, provider, temp, z_score, disagreement, flags, observed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		score.City,
+		score.Provider,
+		score.Temp,
+		score.ZScore,
+		score.Disagreement,
+		strings.Join(score.Flags, ","),
+		score.ObservedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка записи оценки качества для %s: %w", score.City, err)
+	}
+	return nil
+}
+
+// ListQualityScores возвращает оценки замеров города за [from, to]
+func (s *WeatherStorage) ListQualityScores(ctx context.Context, city string, from, to time.Time) ([]model.QualityScore, error) {
+	if err := s.faults.Inject(ctx, "storage.ListQualityScores"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, provider, temp, z_score, disagreement, flags, observed_at
+		FROM quality_scores
+		WHERE LOWER(city) = LOWER($1) AND observed_at BETWEEN $2 AND $3
+		ORDER BY observed_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения оценок качества: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []model.QualityScore
+	for rows.Next() {
+		var (
+			score model.QualityScore
+			flags string
+		)
+		if err := rows.Scan(
+			&score.City,
+			&score.Provider,
+			&score.Temp,
+			&score.ZScore,
+			&score.Disagreement,
+			&flags,
+			&score.ObservedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		if flags != "" {
+			score.Flags = strings.Split(flags, ",")
+		}
+		scores = append(scores, score)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return scores, nil
+}
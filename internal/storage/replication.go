@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// ApplyReplicated записывает состояние города из другого региона. Timestamp
+// используется как updated_at, а более старые события не перетирают новые,
+// поэтому повторы и переупорядочивание сообщений безопасны. Время сравнивается
+// в TIMESTAMPTZ, поэтому зона отправителя не важна.
+func (s *WeatherStorage) ApplyReplicated(ctx context.Context, data model.WeatherData) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.ApplyReplicated"); err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO weather (city, temp, condition, condition_code, provider, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (city) DO UPDATE
+		SET temp = EXCLUDED.temp,
+		    condition = EXCLUDED.condition,
+		    condition_code = EXCLUDED.condition_code,
+		    provider = EXCLUDED.provider,
+		    updated_at = EXCLUDED.updated_at,
+		    revision = nextval('weather_revision_seq')
+		WHERE weather.updated_at IS NULL OR weather.updated_at < EXCLUDED.updated_at
+	`
+
+	res, err := s.db.ExecContext(ctx, query,
+		data.City,
+		data.Temp,
+		data.Condition,
+		string(data.ConditionCode),
+		data.Provider,
+		data.Timestamp.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("ошибка репликации погоды для %s: %w", data.City, err)
+	}
+	applied, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка репликации погоды для %s: %w", data.City, err)
+	}
+
+	if err := insertCity(ctx, s.db, model.City{Name: data.City}); err != nil {
+		return false, err
+	}
+	return applied > 0, nil
+}
+
+// ListUpdatedAt возвращает время последнего обновления каждого города
+func (s *WeatherStorage) ListUpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	if err := s.faults.Inject(ctx, "storage.ListUpdatedAt"); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT city, updated_at FROM weather WHERE updated_at IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения времени обновления: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]time.Time)
+	for rows.Next() {
+		var (
+			city string
+			at   time.Time
+		)
+		if err := rows.Scan(&city, &at); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		result[city] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// ListSchedules возвращает расписания опроса вместе с координатами городов.
+// Город, выключенный в справочнике, не опрашивается независимо от расписания.
+func (s *WeatherStorage) ListSchedules(ctx context.Context) ([]model.CitySchedule, error) {
+	if err := s.faults.Inject(ctx, "storage.ListSchedules"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT s.city, s.interval_seconds, s.enabled AND COALESCE(c.enabled, TRUE), s.last_requested_at,
+		       COALESCE(c.lat, 0), COALESCE(c.lon, 0)
+		FROM city_schedules s
+		LEFT JOIN cities c ON c.name = s.city
+		ORDER BY s.city
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписаний: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []model.CitySchedule
+	for rows.Next() {
+		var sc model.CitySchedule
+		var seconds int
+		if err := rows.Scan(&sc.City, &seconds, &sc.Enabled, &sc.LastRequestedAt, &sc.Lat, &sc.Lon); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		sc.Interval = time.Duration(seconds) * time.Second
+		schedules = append(schedules, sc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return schedules, nil
+}
+
+// UpsertSchedule создает или меняет расписание города
+func (s *WeatherStorage) UpsertSchedule(ctx context.Context, sc model.CitySchedule) error {
+	if err := s.faults.Inject(ctx, "storage.UpsertSchedule"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO city_schedules (city, interval_seconds, enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (city) DO UPDATE
+		SET interval_seconds = EXCLUDED.interval_seconds,
+		    enabled = EXCLUDED.enabled;
+	`
+
+	_, err := s.db.ExecContext(ctx, query, sc.City, int(sc.Interval/time.Second), sc.Enabled)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения расписания %s: %w", sc.City, err)
+	}
+	return nil
+}
+
+// MarkRequested запоминает время последней отправленной команды
+func (s *WeatherStorage) MarkRequested(ctx context.Context, city string, at time.Time) error {
+	if err := s.faults.Inject(ctx, "storage.MarkRequested"); err != nil {
+		return err
+	}
+
+	query := `UPDATE city_schedules SET last_requested_at = $2 WHERE city = $1`
+	if _, err := s.db.ExecContext(ctx, query, city, at); err != nil {
+		return fmt.Errorf("ошибка обновления расписания %s: %w", city, err)
+	}
+	return nil
+}
+
+// insertSchedule добавляет расписание по умолчанию, не трогая существующее
+func insertSchedule(ctx context.Context, db execer, city string, interval time.Duration) error {
+	query := `
+		INSERT INTO city_schedules (city, interval_seconds, enabled)
+		VALUES ($1, $2, TRUE)
+		ON CONFLICT DO NOTHING;
+	`
+
+	if _, err := db.ExecContext(ctx, query, city, int(interval/time.Second)); err != nil {
+		return fmt.Errorf("ошибка добавления расписания %s: %w", city, err)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// CreateAccount сохраняет аккаунт с хэшем ключа и возвращает его ID
+func (s *WeatherStorage) CreateAccount(ctx context.Context, account model.Account, keyHash string) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.CreateAccount"); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO api_keys (key_hash, name, plan, monthly_quota, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		keyHash,
+		account.Name,
+		account.Plan,
+		account.MonthlyQuota,
+		account.Active,
+		time.Now().UTC(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания аккаунта %s: %w", account.Name, err)
+	}
+	return id, nil
+}
+
+// GetAccountByKeyHash возвращает аккаунт по хэшу ключа, nil если ключ неизвестен
+func (s *WeatherStorage) GetAccountByKeyHash(ctx context.Context, keyHash string) (*model.Account, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAccountByKeyHash"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, name, plan, monthly_quota, active, created_at
+		FROM api_keys
+		WHERE key_hash = ?
+	`
+	return s.getAccount(ctx, query, keyHash)
+}
+
+// GetAccountByID возвращает аккаунт по ID, nil если аккаунта нет
+func (s *WeatherStorage) GetAccountByID(ctx context.Context, id int64) (*model.Account, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAccountByID"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, name, plan, monthly_quota, active, created_at
+		FROM api_keys
+		WHERE id = ?
+	`
+	return s.getAccount(ctx, query, id)
+}
+
+func (s *WeatherStorage) getAccount(ctx context.Context, query string, arg any) (*model.Account, error) {
+	var account model.Account
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(
+		&account.ID,
+		&account.Name,
+		&account.Plan,
+		&account.MonthlyQuota,
+		&account.Active,
+		&account.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения аккаунта: %w", err)
+	}
+	return &account, nil
+}
+
+// SaveUsage сохраняет счетчик запросов аккаунта за месяц; счетчик только растет
+func (s *WeatherStorage) SaveUsage(ctx context.Context, accountID int64, month string, requests int64) error {
+	if err := s.faults.Inject(ctx, "storage.SaveUsage"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_usage (account_id, month, requests, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (account_id, month) DO UPDATE
+		SET requests = MAX(api_usage.requests, excluded.requests),
+		    updated_at = excluded.updated_at
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, accountID, month, requests, time.Now().UTC()); err != nil {
+		return fmt.Errorf("ошибка сохранения расхода аккаунта %d: %w", accountID, err)
+	}
+	return nil
+}
+
+// GetUsage возвращает сохраненный счетчик запросов за месяц, 0 если записи нет
+func (s *WeatherStorage) GetUsage(ctx context.Context, accountID int64, month string) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.GetUsage"); err != nil {
+		return 0, err
+	}
+
+	var requests int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT requests FROM api_usage WHERE account_id = ? AND month = ?`,
+		accountID, month,
+	).Scan(&requests)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения расхода аккаунта %d: %w", accountID, err)
+	}
+	return requests, nil
+}
+
+// GetPreferences возвращает настройки аккаунта, значения по умолчанию если их нет
+func (s *WeatherStorage) GetPreferences(ctx context.Context, accountID int64) (model.Preferences, error) {
+	if err := s.faults.Inject(ctx, "storage.GetPreferences"); err != nil {
+		return model.Preferences{}, err
+	}
+
+	query := `
+		SELECT units, language, timezone, updated_at
+		FROM account_preferences
+		WHERE account_id = ?
+	`
+
+	var prefs model.Preferences
+	err := s.db.QueryRowContext(ctx, query, accountID).Scan(&prefs.Units, &prefs.Language, &prefs.Timezone, &prefs.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return model.DefaultPreferences(), nil
+	}
+	if err != nil {
+		return model.Preferences{}, fmt.Errorf("ошибка получения настроек аккаунта %d: %w", accountID, err)
+	}
+	return prefs, nil
+}
+
+// SavePreferences сохраняет настройки аккаунта
+func (s *WeatherStorage) SavePreferences(ctx context.Context, accountID int64, prefs model.Preferences) error {
+	if err := s.faults.Inject(ctx, "storage.SavePreferences"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO account_preferences (account_id, units, language, timezone, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (account_id)
+		DO UPDATE SET units = excluded.units, language = excluded.language,
+			timezone = excluded.timezone, updated_at = excluded.updated_at
+	`
+
+	_, err := s.db.ExecContext(ctx, query, accountID, string(prefs.Units), string(prefs.Language), prefs.Timezone, prefs.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения настроек аккаунта %d: %w", accountID, err)
+	}
+	return nil
+}
+
+// ListFavorites возвращает избранные города аккаунта в порядке добавления
+func (s *WeatherStorage) ListFavorites(ctx context.Context, accountID int64) ([]string, error) {
+	if err := s.faults.Inject(ctx, "storage.ListFavorites"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city
+		FROM account_favorites
+		WHERE account_id = ?
+		ORDER BY created_at, city
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения избранного: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []string
+	for rows.Next() {
+		var city string
+		if err := rows.Scan(&city); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		cities = append(cities, city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return cities, nil
+}
+
+// AddFavorite добавляет город в избранное; повторное добавление ничего не меняет
+func (s *WeatherStorage) AddFavorite(ctx context.Context, accountID int64, city string) error {
+	if err := s.faults.Inject(ctx, "storage.AddFavorite"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO account_favorites (account_id, city, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (account_id, city) DO NOTHING
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, accountID, city, time.Now().UTC()); err != nil {
+		return fmt.Errorf("ошибка добавления %s в избранное: %w", city, err)
+	}
+	return nil
+}
+
+// RemoveFavorite удаляет город из избранного, false если его там не было
+func (s *WeatherStorage) RemoveFavorite(ctx context.Context, accountID int64, city string) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.RemoveFavorite"); err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM account_favorites WHERE account_id = ? AND city = ?`, accountID, city)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления %s из избранного: %w", city, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// CreateUser сохраняет пользователя с хэшем пароля и возвращает его ID
+func (s *WeatherStorage) CreateUser(ctx context.Context, user model.User, passwordHash string) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.CreateUser"); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO users (username, password_hash, role, account_id, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		user.Username,
+		passwordHash,
+		user.Role,
+		user.AccountID,
+		user.Active,
+		time.Now().UTC(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания пользователя %s: %w", user.Username, err)
+	}
+	return id, nil
+}
+
+// GetUserByName возвращает пользователя и хэш его пароля; nil, если пользователя нет
+func (s *WeatherStorage) GetUserByName(ctx context.Context, username string) (*model.User, string, error) {
+	if err := s.faults.Inject(ctx, "storage.GetUserByName"); err != nil {
+		return nil, "", err
+	}
+
+	query := `
+		SELECT id, username, password_hash, role, account_id, active, created_at
+		FROM users
+		WHERE username = ?
+	`
+
+	var (
+		user      model.User
+		hash      string
+		accountID sql.NullInt64
+	)
+	err := s.db.QueryRowContext(ctx, query, username).Scan(
+		&user.ID,
+		&user.Username,
+		&hash,
+		&user.Role,
+		&accountID,
+		&user.Active,
+		&user.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка получения пользователя: %w", err)
+	}
+	if accountID.Valid {
+		user.AccountID = &accountID.Int64
+	}
+	return &user, hash, nil
+}
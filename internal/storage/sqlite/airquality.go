@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// SaveAirQuality обновляет текущий замер качества воздуха города и добавляет его в историю.
+// Текущее значение не заменяется более старым замером.
+func (s *WeatherStorage) SaveAirQuality(ctx context.Context, data model.AirQuality) error {
+	if err := s.faults.Inject(ctx, "storage.SaveAirQuality"); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	observedAt := data.Timestamp.UTC()
+	upsert := `
+		INSERT INTO air_quality (city, pm25, pm10, no2, o3, aqi, category, provider, observed_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (city)
+		DO UPDATE SET
+			pm25 = excluded.pm25,
+			pm10 = excluded.pm10,
+			no2 = excluded.no2,
+			o3 = excluded.o3,
+			aqi = excluded.aqi,
+			category = excluded.category,
+			provider = excluded.provider,
+			observed_at = excluded.observed_at,
+			updated_at = excluded.updated_at
+		WHERE air_quality.observed_at <= excluded.observed_at
+	`
+	if _, err := tx.ExecContext(ctx, upsert,
+		data.City, data.PM25, data.PM10, data.NO2, data.O3,
+		data.AQI, string(data.Category), data.Provider, observedAt, time.Now().UTC(),
+	); err != nil {
+		return fmt.Errorf("ошибка сохранения качества воздуха для %s: %w", data.City, err)
+	}
+
+	history := `
+		INSERT INTO air_quality_history (city, pm25, pm10, no2, o3, aqi, category, provider, observed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, history,
+		data.City, data.PM25, data.PM10, data.NO2, data.O3,
+		data.AQI, string(data.Category), data.Provider, observedAt,
+	); err != nil {
+		return fmt.Errorf("ошибка записи истории качества воздуха для %s: %w", data.City, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации качества воздуха: %w", err)
+	}
+	return nil
+}
+
+// GetAirQuality возвращает текущий замер качества воздуха города, nil если замеров нет
+func (s *WeatherStorage) GetAirQuality(ctx context.Context, city string) (*model.AirQuality, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAirQuality"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, pm25, pm10, no2, o3, aqi, category, provider, observed_at
+		FROM air_quality
+		WHERE LOWER(city) = LOWER(?)
+	`
+
+	data, err := scanAirQuality(s.db.QueryRowContext(ctx, query, city))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения качества воздуха: %w", err)
+	}
+	return data, nil
+}
+
+// GetAirQualityHistory возвращает замеры качества воздуха города за [from, to] по возрастанию времени
+func (s *WeatherStorage) GetAirQualityHistory(ctx context.Context, city string, from, to time.Time) ([]model.AirQuality, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAirQualityHistory"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, pm25, pm10, no2, o3, aqi, category, provider, observed_at
+		FROM air_quality_history
+		WHERE LOWER(city) = LOWER(?) AND observed_at BETWEEN ? AND ?
+		ORDER BY observed_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории качества воздуха: %w", err)
+	}
+	defer rows.Close()
+
+	var readings []model.AirQuality
+	for rows.Next() {
+		data, err := scanAirQuality(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		readings = append(readings, *data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return readings, nil
+}
+
+func scanAirQuality(row rowScanner) (*model.AirQuality, error) {
+	var data model.AirQuality
+	err := row.Scan(
+		&data.City, &data.PM25, &data.PM10, &data.NO2, &data.O3,
+		&data.AQI, &data.Category, &data.Provider, &data.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// CreateAlertRule сохраняет правило аккаунта и возвращает его с ID
+func (s *WeatherStorage) CreateAlertRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	if err := s.faults.Inject(ctx, "storage.CreateAlertRule"); err != nil {
+		return model.AlertRule{}, err
+	}
+
+	channels, err := encodeList(rule.Channels)
+	if err != nil {
+		return model.AlertRule{}, err
+	}
+
+	query := `
+		INSERT INTO alert_rules (account_id, city, metric, operator, threshold, channels, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = s.db.QueryRowContext(ctx, query,
+		rule.AccountID,
+		rule.City,
+		string(rule.Metric),
+		string(rule.Operator),
+		rule.Threshold,
+		channels,
+		rule.CreatedAt.UTC(),
+	).Scan(&rule.ID)
+	if err != nil {
+		return model.AlertRule{}, fmt.Errorf("ошибка сохранения правила для %s: %w", rule.City, err)
+	}
+	return rule, nil
+}
+
+// ListAlertRules возвращает правила аккаунта, новые первыми
+func (s *WeatherStorage) ListAlertRules(ctx context.Context, accountID int64) ([]model.AlertRule, error) {
+	if err := s.faults.Inject(ctx, "storage.ListAlertRules"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, account_id, city, metric, operator, threshold, channels, created_at
+		FROM alert_rules
+		WHERE account_id = ?
+		ORDER BY created_at DESC, id DESC
+	`
+	return s.queryAlertRules(ctx, query, accountID)
+}
+
+// ListAlertRulesForCity возвращает правила всех аккаунтов для города без учета регистра
+func (s *WeatherStorage) ListAlertRulesForCity(ctx context.Context, city string) ([]model.AlertRule, error) {
+	if err := s.faults.Inject(ctx, "storage.ListAlertRulesForCity"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, account_id, city, metric, operator, threshold, channels, created_at
+		FROM alert_rules
+		WHERE LOWER(city) = LOWER(?)
+		ORDER BY id
+	`
+	return s.queryAlertRules(ctx, query, city)
+}
+
+func (s *WeatherStorage) queryAlertRules(ctx context.Context, query string, arg any) ([]model.AlertRule, error) {
+	rows, err := s.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения правил оповещений: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.AlertRule{}
+	for rows.Next() {
+		var (
+			rule     model.AlertRule
+			channels string
+		)
+		err := rows.Scan(
+			&rule.ID,
+			&rule.AccountID,
+			&rule.City,
+			&rule.Metric,
+			&rule.Operator,
+			&rule.Threshold,
+			&channels,
+			&rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		if err := json.Unmarshal([]byte(channels), &rule.Channels); err != nil {
+			return nil, fmt.Errorf("неверные каналы правила %d: %w", rule.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteAlertRule удаляет правило аккаунта вместе с его срабатываниями;
+// false — правила нет или оно принадлежит другому аккаунту
+func (s *WeatherStorage) DeleteAlertRule(ctx context.Context, accountID, id int64) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.DeleteAlertRule"); err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = ? AND account_id = ?`, id, accountID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления правила %d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления правила %d: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+// SaveAlertEvent записывает срабатывание правила и возвращает его ID
+func (s *WeatherStorage) SaveAlertEvent(ctx context.Context, event model.AlertEvent) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.SaveAlertEvent"); err != nil {
+		return 0, err
+	}
+
+	channels, err := encodeList(event.Channels)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO alerts_triggered (rule_id, city, metric, operator, threshold, value, channels, triggered_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	var id int64
+	err = s.db.QueryRowContext(ctx, query,
+		event.RuleID,
+		event.City,
+		string(event.Metric),
+		string(event.Operator),
+		event.Threshold,
+		event.Value,
+		channels,
+		event.TriggeredAt.UTC(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сохранения срабатывания правила %d: %w", event.RuleID, err)
+	}
+	return id, nil
+}
+
+// ListAlertEvents возвращает последние срабатывания правил аккаунта, новые первыми
+func (s *WeatherStorage) ListAlertEvents(ctx context.Context, accountID int64, limit int) ([]model.AlertEvent, error) {
+	if err := s.faults.Inject(ctx, "storage.ListAlertEvents"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT t.id, t.rule_id, t.city, t.metric, t.operator, t.threshold, t.value, t.channels, t.triggered_at
+		FROM alerts_triggered t
+		JOIN alert_rules r ON r.id = t.rule_id
+		WHERE r.account_id = ?
+		ORDER BY t.triggered_at DESC, t.id DESC
+		LIMIT ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, accountID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения срабатываний: %w", err)
+	}
+	defer rows.Close()
+
+	events := []model.AlertEvent{}
+	for rows.Next() {
+		var (
+			e        model.AlertEvent
+			channels string
+		)
+		err := rows.Scan(
+			&e.ID,
+			&e.RuleID,
+			&e.City,
+			&e.Metric,
+			&e.Operator,
+			&e.Threshold,
+			&e.Value,
+			&channels,
+			&e.TriggeredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		if err := json.Unmarshal([]byte(channels), &e.Channels); err != nil {
+			return nil, fmt.Errorf("неверные каналы срабатывания %d: %w", e.ID, err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return events, nil
+}
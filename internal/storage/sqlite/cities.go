@@ -0,0 +1,290 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// citySelect — поля города; синонимы хранятся JSON-массивом
+const citySelect = `SELECT name, country, lat, lon, timezone, aliases, enabled FROM cities`
+
+// insertCity добавляет город, не трогая уже существующую запись.
+// Город сохраняется с city.Enabled: созданные по замеру или геокодеру
+// записи выключены, пока администратор не включит опрос.
+func insertCity(ctx context.Context, db execer, city model.City) error {
+	aliases, err := encodeList(city.Aliases)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cities (name, country, lat, lon, timezone, aliases, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`
+	_, err = db.ExecContext(ctx, query,
+		city.Name,
+		city.Country,
+		city.Lat,
+		city.Lon,
+		city.Timezone,
+		aliases,
+		city.Enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка добавления города %s: %w", city.Name, err)
+	}
+	return nil
+}
+
+// GetCity ищет город по названию или синониму без учета регистра
+func (s *WeatherStorage) GetCity(ctx context.Context, name string) (*model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.GetCity"); err != nil {
+		return nil, err
+	}
+
+	query := citySelect + `
+		WHERE LOWER(name) = LOWER(?1)
+		   OR EXISTS (SELECT 1 FROM json_each(aliases) WHERE LOWER(value) = LOWER(?1))
+		ORDER BY LOWER(name) = LOWER(?1) DESC
+		LIMIT 1
+	`
+
+	city, err := scanCity(s.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("город %s: %w", name, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения города: %w", err)
+	}
+	return city, nil
+}
+
+// ListCities возвращает весь справочник городов
+func (s *WeatherStorage) ListCities(ctx context.Context) ([]model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.ListCities"); err != nil {
+		return nil, err
+	}
+
+	return s.queryCities(ctx, citySelect+` ORDER BY name`)
+}
+
+// CreateCity добавляет город в справочник. ErrAlreadyExists — город с таким
+// названием уже есть. Расписание опроса не создается: планировщика в монолите нет.
+func (s *WeatherStorage) CreateCity(ctx context.Context, city model.City) error {
+	if err := s.faults.Inject(ctx, "storage.CreateCity"); err != nil {
+		return err
+	}
+
+	aliases, err := encodeList(city.Aliases)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cities (name, country, lat, lon, timezone, aliases, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`
+	res, err := s.db.ExecContext(ctx, query,
+		city.Name,
+		city.Country,
+		city.Lat,
+		city.Lon,
+		city.Timezone,
+		aliases,
+		city.Enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка добавления города %s: %w", city.Name, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("город %s: %w", city.Name, storage.ErrAlreadyExists)
+	}
+	return nil
+}
+
+// UpdateCity меняет данные города name; название не меняется.
+// ErrNotFound — города нет.
+func (s *WeatherStorage) UpdateCity(ctx context.Context, name string, city model.City) (*model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.UpdateCity"); err != nil {
+		return nil, err
+	}
+
+	aliases, err := encodeList(city.Aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE cities
+		SET country = ?2, lat = ?3, lon = ?4, timezone = ?5, aliases = ?6, enabled = ?7
+		WHERE LOWER(name) = LOWER(?1)
+		RETURNING name, country, lat, lon, timezone, aliases, enabled
+	`
+	updated, err := scanCity(s.db.QueryRowContext(ctx, query,
+		name,
+		city.Country,
+		city.Lat,
+		city.Lon,
+		city.Timezone,
+		aliases,
+		city.Enabled,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("город %s: %w", name, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления города %s: %w", name, err)
+	}
+	return updated, nil
+}
+
+// DeleteCity удаляет город из справочника; накопленные замеры остаются.
+// false — города не было.
+func (s *WeatherStorage) DeleteCity(ctx context.Context, name string) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.DeleteCity"); err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM cities WHERE LOWER(name) = LOWER(?)`, name)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления города %s: %w", name, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SearchCities ищет города по началу названия или синонима и по вхождению
+// в название для автодополнения. Совпадения по началу идут первыми.
+func (s *WeatherStorage) SearchCities(ctx context.Context, q string, limit int) ([]model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.SearchCities"); err != nil {
+		return nil, err
+	}
+
+	q = likeEscaper.Replace(strings.ToLower(q))
+	prefix, contains := q+"%", "%"+q+"%"
+
+	query := citySelect + `
+		WHERE LOWER(name) LIKE ?2 ESCAPE '\'
+		   OR EXISTS (SELECT 1 FROM json_each(aliases) WHERE LOWER(value) LIKE ?1 ESCAPE '\')
+		ORDER BY LOWER(name) LIKE ?1 ESCAPE '\' DESC, name
+		LIMIT ?3
+	`
+
+	return s.queryCities(ctx, query, prefix, contains, limit)
+}
+
+func (s *WeatherStorage) queryCities(ctx context.Context, query string, args ...any) ([]model.City, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения справочника городов: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []model.City
+	for rows.Next() {
+		city, err := scanCity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		cities = append(cities, *city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return cities, nil
+}
+
+// GetGeocoded возвращает сохраненный результат геокодирования, nil если запрос не встречался
+func (s *WeatherStorage) GetGeocoded(ctx context.Context, query string) (*model.City, error) {
+	if err := s.faults.Inject(ctx, "storage.GetGeocoded"); err != nil {
+		return nil, err
+	}
+
+	q := `
+		SELECT c.name, c.country, c.lat, c.lon, c.timezone, c.aliases, c.enabled
+		FROM geocode_cache g
+		JOIN cities c ON LOWER(c.name) = LOWER(g.city)
+		WHERE g.query = ?
+	`
+
+	city, err := scanCity(s.db.QueryRowContext(ctx, q, normalizeQuery(query)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения кэша геокодирования: %w", err)
+	}
+	return city, nil
+}
+
+// SaveGeocoded сохраняет город в справочник и запоминает, к какому городу ведет запрос
+func (s *WeatherStorage) SaveGeocoded(ctx context.Context, query string, city model.City) error {
+	if err := s.faults.Inject(ctx, "storage.SaveGeocoded"); err != nil {
+		return err
+	}
+
+	if err := insertCity(ctx, s.db, city); err != nil {
+		return err
+	}
+
+	q := `
+		INSERT INTO geocode_cache (query, city, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (query) DO UPDATE
+		SET city = excluded.city,
+		    created_at = excluded.created_at
+	`
+	if _, err := s.db.ExecContext(ctx, q, normalizeQuery(query), city.Name, time.Now().UTC()); err != nil {
+		return fmt.Errorf("ошибка сохранения геокодирования %q: %w", query, err)
+	}
+	return nil
+}
+
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
+
+func scanCity(row rowScanner) (*model.City, error) {
+	var (
+		city    model.City
+		aliases string
+	)
+	err := row.Scan(
+		&city.Name,
+		&city.Country,
+		&city.Lat,
+		&city.Lon,
+		&city.Timezone,
+		&aliases,
+		&city.Enabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(aliases), &city.Aliases); err != nil {
+		return nil, fmt.Errorf("неверные синонимы города %s: %w", city.Name, err)
+	}
+	return &city, nil
+}
+
+// encodeList сохраняет список строк JSON-массивом; nil — пустой массив
+func encodeList(list []string) (string, error) {
+	if list == nil {
+		list = []string{}
+	}
+	b, err := json.Marshal(list)
+	if err != nil {
+		return "", fmt.Errorf("ошибка кодирования списка: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// ReplaceForecasts заменяет будущий прогноз провайдера для города новым набором
+func (s *WeatherStorage) ReplaceForecasts(ctx context.Context, city, provider string, forecasts []model.Forecast) error {
+	if err := s.faults.Inject(ctx, "storage.ReplaceForecasts"); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM forecasts WHERE LOWER(city) = LOWER(?) AND provider = ?`, city, provider)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления прогноза для %s: %w", city, err)
+	}
+
+	query := `
+		INSERT INTO forecasts (city, provider, forecast_for, temp, condition_code, issued_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, f := range forecasts {
+		_, err := tx.ExecContext(ctx, query,
+			city,
+			provider,
+			f.ForecastFor.UTC(),
+			f.Temp,
+			string(f.ConditionCode),
+			f.IssuedAt.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения прогноза для %s: %w", city, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации прогноза для %s: %w", city, err)
+	}
+	return nil
+}
+
+// GetForecasts возвращает прогнозы города на интервал [from, to] по всем провайдерам
+func (s *WeatherStorage) GetForecasts(ctx context.Context, city string, from, to time.Time) ([]model.Forecast, error) {
+	if err := s.faults.Inject(ctx, "storage.GetForecasts"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, provider, forecast_for, temp, condition_code, issued_at
+		FROM forecasts
+		WHERE LOWER(city) = LOWER(?) AND forecast_for BETWEEN ? AND ?
+		ORDER BY forecast_for, provider
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения прогноза: %w", err)
+	}
+	defer rows.Close()
+
+	var forecasts []model.Forecast
+	for rows.Next() {
+		var f model.Forecast
+		if err := rows.Scan(
+			&f.City,
+			&f.Provider,
+			&f.ForecastFor,
+			&f.Temp,
+			&f.ConditionCode,
+			&f.IssuedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		forecasts = append(forecasts, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return forecasts, nil
+}
+
+// HasProviderForecast сообщает, есть ли у города будущий прогноз от внешнего провайдера
+func (s *WeatherStorage) HasProviderForecast(ctx context.Context, city string, after time.Time) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.HasProviderForecast"); err != nil {
+		return false, err
+	}
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM forecasts
+			WHERE LOWER(city) = LOWER(?) AND provider <> ? AND forecast_for > ?
+		)
+	`
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, city, model.ProviderInternal, after.UTC()).Scan(&exists); err != nil {
+		return false, fmt.Errorf("ошибка проверки прогноза для %s: %w", city, err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// historySelect — поля замера истории
+const historySelect = `SELECT city, temp, condition, condition_code, provider, observed_at FROM weather_history`
+
+func appendHistory(ctx context.Context, db execer, data model.WeatherData) error {
+	query := `
+		INSERT INTO weather_history (city, temp, condition, condition_code, provider, observed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		data.City,
+		data.Temp,
+		data.Condition,
+		string(data.ConditionCode),
+		data.Provider,
+		data.Timestamp.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка записи истории для %s: %w", data.City, err)
+	}
+	return nil
+}
+
+// GetHistory возвращает замеры города за [from, to] по возрастанию времени
+func (s *WeatherStorage) GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetHistory"); err != nil {
+		return nil, err
+	}
+
+	query := historySelect + `
+		WHERE LOWER(city) = LOWER(?) AND observed_at BETWEEN ? AND ?
+		ORDER BY observed_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории: %w", err)
+	}
+	defer rows.Close()
+
+	var history []model.WeatherData
+	for rows.Next() {
+		data, err := scanWeather(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		history = append(history, *data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return history, nil
+}
+
+// GetHistoryAround возвращает ближайшие замеры города не позже at и позже at.
+// Любой из них nil, если с этой стороны истории нет.
+func (s *WeatherStorage) GetHistoryAround(ctx context.Context, city string, at time.Time) (before, after *model.WeatherData, err error) {
+	if err := s.faults.Inject(ctx, "storage.GetHistoryAround"); err != nil {
+		return nil, nil, err
+	}
+
+	// Два запроса вместо UNION ALL: у столбцов составного запроса драйвер
+	// не видит объявленного типа и не разбирает время
+	queries := []struct {
+		query  string
+		target **model.WeatherData
+	}{
+		{historySelect + ` WHERE LOWER(city) = LOWER(?) AND observed_at <= ? ORDER BY observed_at DESC LIMIT 1`, &before},
+		{historySelect + ` WHERE LOWER(city) = LOWER(?) AND observed_at > ? ORDER BY observed_at LIMIT 1`, &after},
+	}
+	for _, q := range queries {
+		data, err := scanWeather(s.db.QueryRowContext(ctx, q.query, city, at.UTC()))
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка получения истории: %w", err)
+		}
+		*q.target = data
+	}
+	return before, after, nil
+}
+
+// GetHistoryStats считает минимум, максимум и среднее температуры города за [from, to]
+func (s *WeatherStorage) GetHistoryStats(ctx context.Context, city string, from, to time.Time) (model.WeatherStats, error) {
+	if err := s.faults.Inject(ctx, "storage.GetHistoryStats"); err != nil {
+		return model.WeatherStats{}, err
+	}
+
+	query := `
+		SELECT COUNT(*), MIN(temp), MAX(temp), AVG(temp)
+		FROM weather_history
+		WHERE LOWER(city) = LOWER(?) AND observed_at BETWEEN ? AND ?
+	`
+
+	stats := model.WeatherStats{City: city, From: from, To: to}
+	var tmin, tmax, tavg sql.NullFloat64
+	if err := s.db.QueryRowContext(ctx, query, city, from.UTC(), to.UTC()).Scan(&stats.Count, &tmin, &tmax, &tavg); err != nil {
+		return model.WeatherStats{}, fmt.Errorf("ошибка расчета сводки для %s: %w", city, err)
+	}
+	if stats.Count > 0 {
+		stats.TempMin = &tmin.Float64
+		stats.TempMax = &tmax.Float64
+		stats.TempAvg = &tavg.Float64
+	}
+	return stats, nil
+}
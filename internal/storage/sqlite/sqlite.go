@@ -0,0 +1,292 @@
+// Package sqlite — хранилище на SQLite для режима разработки монолита:
+// те же операции, что у storage.WeatherStorage, без отдельного сервера БД.
+// Схема упрощена: нет расширений Postgres, массивы хранятся в JSON, поиск
+// городов идет по подстроке вместо pg_trgm. Таблицы сервисов, которых нет
+// в монолите (расписания планировщика, доставки уведомлений), не создаются.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/chaos"
+	"github.com/gometeo/app/internal/model"
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName — драйвер SQLite с LOWER по правилам Unicode: встроенная
+// функция понимает только ASCII, и поиск "москва" не нашел бы "Москва"
+const driverName = "sqlite3_gometeo"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("lower", strings.ToLower, true)
+		},
+	})
+}
+
+// schema выполняется при старте и должна быть идемпотентной. Время хранится
+// текстом в UTC, поэтому сравнивается и сортируется как строка.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS weather (
+		city TEXT PRIMARY KEY,
+		temp REAL NOT NULL,
+		condition TEXT NOT NULL DEFAULT '',
+		condition_code TEXT NOT NULL DEFAULT '',
+		provider TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP NOT NULL,
+		revision INTEGER NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS weather_revision_idx ON weather (revision);`,
+	// Последовательность ревизий: MAX(revision) повторился бы после удаления города
+	`CREATE TABLE IF NOT EXISTS weather_revision (value INTEGER NOT NULL);`,
+	`INSERT INTO weather_revision (value) SELECT 0 WHERE NOT EXISTS (SELECT 1 FROM weather_revision);`,
+	`CREATE TABLE IF NOT EXISTS weather_providers (
+		city TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		temp REAL NOT NULL,
+		condition TEXT NOT NULL DEFAULT '',
+		condition_code TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (city, provider)
+	);`,
+	`CREATE TABLE IF NOT EXISTS weather_history (
+		id INTEGER PRIMARY KEY,
+		city TEXT NOT NULL,
+		temp REAL NOT NULL,
+		condition TEXT NOT NULL DEFAULT '',
+		condition_code TEXT NOT NULL DEFAULT '',
+		provider TEXT NOT NULL DEFAULT '',
+		observed_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS weather_history_city_time_idx ON weather_history (city, observed_at);`,
+	`CREATE TABLE IF NOT EXISTS cities (
+		name TEXT PRIMARY KEY,
+		country TEXT NOT NULL DEFAULT '',
+		lat REAL NOT NULL DEFAULT 0,
+		lon REAL NOT NULL DEFAULT 0,
+		timezone TEXT NOT NULL DEFAULT '',
+		aliases TEXT NOT NULL DEFAULT '[]',
+		enabled BOOLEAN NOT NULL DEFAULT TRUE
+	);`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS cities_lower_name_idx ON cities (LOWER(name));`,
+	`CREATE TABLE IF NOT EXISTS forecasts (
+		city TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		forecast_for TIMESTAMP NOT NULL,
+		temp REAL NOT NULL,
+		condition_code TEXT NOT NULL DEFAULT '',
+		issued_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (city, provider, forecast_for)
+	);`,
+	`CREATE TABLE IF NOT EXISTS geocode_cache (
+		query TEXT PRIMARY KEY,
+		city TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS quality_scores (
+		id INTEGER PRIMARY KEY,
+		city TEXT NOT NULL,
+		provider TEXT NOT NULL DEFAULT '',
+		temp REAL NOT NULL,
+		z_score REAL NOT NULL DEFAULT 0,
+		disagreement REAL NOT NULL DEFAULT 0,
+		flags TEXT NOT NULL DEFAULT '',
+		observed_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS api_keys (
+		id INTEGER PRIMARY KEY,
+		key_hash TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		plan TEXT NOT NULL DEFAULT 'free',
+		monthly_quota INTEGER NOT NULL DEFAULT 0,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS api_usage (
+		account_id INTEGER NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		month TEXT NOT NULL,
+		requests INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (account_id, month)
+	);`,
+	`CREATE TABLE IF NOT EXISTS account_preferences (
+		account_id INTEGER PRIMARY KEY REFERENCES api_keys (id) ON DELETE CASCADE,
+		units TEXT NOT NULL,
+		language TEXT NOT NULL,
+		timezone TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS account_favorites (
+		account_id INTEGER NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		city TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (account_id, city)
+	);`,
+	`CREATE TABLE IF NOT EXISTS air_quality (
+		city TEXT PRIMARY KEY,
+		pm25 REAL NOT NULL,
+		pm10 REAL NOT NULL,
+		no2 REAL NOT NULL,
+		o3 REAL NOT NULL,
+		aqi INTEGER NOT NULL,
+		category TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		observed_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS air_quality_history (
+		id INTEGER PRIMARY KEY,
+		city TEXT NOT NULL,
+		pm25 REAL NOT NULL,
+		pm10 REAL NOT NULL,
+		no2 REAL NOT NULL,
+		o3 REAL NOT NULL,
+		aqi INTEGER NOT NULL,
+		category TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		observed_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS alert_rules (
+		id INTEGER PRIMARY KEY,
+		account_id INTEGER NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		city TEXT NOT NULL,
+		metric TEXT NOT NULL,
+		operator TEXT NOT NULL,
+		threshold REAL NOT NULL,
+		channels TEXT NOT NULL DEFAULT '[]',
+		created_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS alerts_triggered (
+		id INTEGER PRIMARY KEY,
+		rule_id INTEGER NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+		city TEXT NOT NULL,
+		metric TEXT NOT NULL,
+		operator TEXT NOT NULL,
+		threshold REAL NOT NULL,
+		value REAL NOT NULL,
+		channels TEXT NOT NULL DEFAULT '[]',
+		triggered_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id INTEGER PRIMARY KEY,
+		account_id INTEGER NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+		url TEXT NOT NULL,
+		city TEXT NOT NULL DEFAULT '',
+		secret TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		role TEXT NOT NULL,
+		account_id INTEGER REFERENCES api_keys (id) ON DELETE SET NULL,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL
+	);`,
+}
+
+type WeatherStorage struct {
+	db     *sql.DB
+	logger *slog.Logger
+	faults *chaos.Injector // nil вне режима внедрения сбоев
+}
+
+// New открывает или создает базу в файле path; ":memory:" — база в памяти
+func New(path string, logger *slog.Logger) (*WeatherStorage, error) {
+	db, err := sql.Open(driverName, "file:"+path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия БД: %w", err)
+	}
+
+	// SQLite пишет в одном соединении за раз; база в памяти к тому же
+	// живет только в своем соединении, поэтому оно одно и не закрывается.
+	// Внутри транзакции обращаться к s.db нельзя: запрос ждал бы ее конца.
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
+	}
+
+	for _, query := range schema {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("ошибка миграции схемы: %w", err)
+		}
+	}
+
+	// Заполнение справочника городов стартовым набором
+	for _, city := range model.DefaultCities {
+		if err := insertCity(context.Background(), db, city); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	logger.Info("База данных SQLite инициализирована", "path", path)
+	return &WeatherStorage{db: db, logger: logger}, nil
+}
+
+func (s *WeatherStorage) Close() {
+	s.db.Close()
+}
+
+// SetFaultInjector включает внедрение сбоев для проверки отказоустойчивости
+func (s *WeatherStorage) SetFaultInjector(faults *chaos.Injector) {
+	s.faults = faults
+}
+
+// Stats возвращает состояние пула соединений
+func (s *WeatherStorage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+func (s *WeatherStorage) Ping(ctx context.Context) error {
+	if err := s.faults.Inject(ctx, "storage.Ping"); err != nil {
+		return err
+	}
+
+	return s.db.PingContext(ctx)
+}
+
+// Health проверяет БД и возвращает время последнего сохраненного замера
+func (s *WeatherStorage) Health(ctx context.Context) (map[string]string, error) {
+	if err := s.Ping(ctx); err != nil {
+		return nil, err
+	}
+
+	// Не MAX(updated_at): у агрегата нет объявленного типа, и драйвер вернул бы строку
+	var lastWrite time.Time
+	err := s.db.QueryRowContext(ctx, `SELECT updated_at FROM weather ORDER BY updated_at DESC LIMIT 1`).Scan(&lastWrite)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения времени последней записи: %w", err)
+	}
+	return map[string]string{"last_write": lastWrite.UTC().Format(time.RFC3339)}, nil
+}
+
+// execer — общий интерфейс для *sql.DB и *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// rowScanner — общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// likeEscaper экранирует спецсимволы LIKE в пользовательском вводе;
+// запросы указывают ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
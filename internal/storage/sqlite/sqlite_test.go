@@ -0,0 +1,150 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/gometeo/app/internal/storage/sqlite"
+	"github.com/gometeo/app/internal/testutil"
+)
+
+func newStore(t *testing.T) *sqlite.WeatherStorage {
+	store, err := sqlite.New(":memory:", testutil.Logger(t))
+	if err != nil {
+		t.Fatalf("ошибка открытия БД: %v", err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+func TestSaveWithHistoryAndChanges(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	observedAt := time.Date(2026, 1, 2, 15, 4, 5, 0, time.FixedZone("MSK", 3*3600))
+	for _, city := range []string{"Kazan", "Omsk", "Kazan"} {
+		data := model.WeatherData{City: city, Temp: 1.5, ConditionCode: model.ConditionSnow, Provider: "test", Timestamp: observedAt}
+		if _, err := store.SaveWithHistory(ctx, data); err != nil {
+			t.Fatalf("ошибка сохранения %s: %v", city, err)
+		}
+	}
+
+	// Повторное сохранение Kazan переносит его в конец ленты
+	changes, last, err := store.ListChanges(ctx, 0, time.Time{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].City != "Omsk" || changes[1].City != "Kazan" || last != 3 {
+		t.Errorf("лента изменений %+v, ревизия %d", changes, last)
+	}
+
+	if _, err := store.Delete(ctx, "Kazan"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Save(ctx, model.WeatherData{City: "Tver", Temp: 2, Provider: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	// Ревизия удаленного города не выдается повторно
+	if changes, _, _ := store.ListChanges(ctx, 3, time.Time{}, 10); len(changes) != 1 || changes[0].City != "Tver" {
+		t.Errorf("после удаления лента %+v", changes)
+	}
+
+	if _, err := store.GetByCity(ctx, "Kazan"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("удаленный город: %v", err)
+	}
+
+	// Время в другой зоне сравнивается как момент, а не как текст
+	history, err := store.GetHistory(ctx, "kazan", observedAt.UTC().Add(-time.Second), observedAt.UTC().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || !history[0].Timestamp.Equal(observedAt) {
+		t.Errorf("история %+v, ожидалось два замера на %s", history, observedAt)
+	}
+
+	before, after, err := store.GetHistoryAround(ctx, "Kazan", observedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == nil || after != nil {
+		t.Errorf("замеры вокруг %s: до %v, после %v", observedAt, before, after)
+	}
+}
+
+func TestCityLookupIgnoresUnicodeCase(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	city, err := store.GetCity(ctx, "москва")
+	if err != nil {
+		t.Fatalf("город по синониму: %v", err)
+	}
+	if city.Name != "Moscow" {
+		t.Errorf("найден %s, ожидался Moscow", city.Name)
+	}
+
+	found, err := store.SearchCities(ctx, "МОС", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Name != "Moscow" {
+		t.Errorf("поиск нашел %+v", found)
+	}
+
+	err = store.CreateCity(ctx, model.City{Name: "moscow", Enabled: true})
+	if !errors.Is(err, storage.ErrAlreadyExists) {
+		t.Errorf("повторный город: %v", err)
+	}
+}
+
+func TestUsageOnlyGrows(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	id, err := store.CreateAccount(ctx, model.Account{Name: "dev", Plan: "free", Active: true}, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int64{10, 3} {
+		if err := store.SaveUsage(ctx, id, "2026-01", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := store.GetUsage(ctx, id, "2026-01"); err != nil || n != 10 {
+		t.Errorf("расход %d (%v), ожидалось 10", n, err)
+	}
+
+	account, err := store.GetAccountByKeyHash(ctx, "hash")
+	if err != nil || account == nil || account.ID != id {
+		t.Errorf("аккаунт по ключу: %+v (%v)", account, err)
+	}
+}
+
+func TestAutoCreatedAndDeletedCitiesDisabled(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	for _, city := range []string{"Kazan", "Moscow"} {
+		if _, err := store.Save(ctx, model.WeatherData{City: city, Temp: 1, Provider: "test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Город из замера не опрашивается, пока его не включит администратор
+	if city, err := store.GetCity(ctx, "Kazan"); err != nil || city.Enabled {
+		t.Errorf("город из замера: %+v (%v), ожидался выключенным", city, err)
+	}
+	if city, err := store.GetCity(ctx, "Moscow"); err != nil || !city.Enabled {
+		t.Errorf("город стартового набора: %+v (%v), ожидался включенным", city, err)
+	}
+
+	if deleted, err := store.Delete(ctx, "Moscow"); err != nil || !deleted {
+		t.Fatalf("удаление: %v (%v)", deleted, err)
+	}
+	if city, err := store.GetCity(ctx, "Moscow"); err != nil || city.Enabled {
+		t.Errorf("удаленный город: %+v (%v), ожидался выключенным", city, err)
+	}
+}
@@ -0,0 +1,485 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/storage"
+)
+
+// Save обновляет погоду или создает новую запись. Возвращает записанное
+// время обновления.
+func (s *WeatherStorage) Save(ctx context.Context, data model.WeatherData) (time.Time, error) {
+	if err := s.faults.Inject(ctx, "storage.Save"); err != nil {
+		return time.Time{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	updatedAt := time.Now().UTC()
+	if err := saveWeather(ctx, tx, data, updatedAt); err != nil {
+		return time.Time{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка фиксации замера %s: %w", data.City, err)
+	}
+
+	s.logger.DebugContext(ctx, "Данные сохранены в БД", "city", data.City)
+	return updatedAt, nil
+}
+
+// SaveWithHistory сохраняет замер как Save и добавляет его в историю в одной транзакции
+func (s *WeatherStorage) SaveWithHistory(ctx context.Context, data model.WeatherData) (time.Time, error) {
+	if err := s.faults.Inject(ctx, "storage.SaveWithHistory"); err != nil {
+		return time.Time{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	updatedAt := time.Now().UTC()
+	if err := saveWeather(ctx, tx, data, updatedAt); err != nil {
+		return time.Time{}, err
+	}
+	if err := appendHistory(ctx, tx, data); err != nil {
+		return time.Time{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка фиксации замера %s: %w", data.City, err)
+	}
+
+	s.logger.DebugContext(ctx, "Данные сохранены в БД", "city", data.City)
+	return updatedAt, nil
+}
+
+// SaveBatch обновляет или создает погоду для пакета городов в одной транзакции.
+// Время обновления берется из замера, как в storage.WeatherStorage.
+func (s *WeatherStorage) SaveBatch(ctx context.Context, batch []model.WeatherData) error {
+	if err := s.faults.Inject(ctx, "storage.SaveBatch"); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, data := range batch {
+		updatedAt := data.Timestamp.UTC()
+		if updatedAt.IsZero() {
+			updatedAt = time.Now().UTC()
+		}
+		if err := saveWeather(ctx, tx, data, updatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации пакета погоды: %w", err)
+	}
+
+	s.logger.DebugContext(ctx, "Пакет данных сохранен в БД", "count", len(batch))
+	return nil
+}
+
+// saveWeather обновляет текущую погоду города, замер провайдера и справочник
+func saveWeather(ctx context.Context, db execer, data model.WeatherData, updatedAt time.Time) error {
+	revision, err := nextRevision(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO weather (city, temp, condition, condition_code, provider, updated_at, revision)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (city) DO UPDATE
+		SET temp = excluded.temp,
+		    condition = excluded.condition,
+		    condition_code = excluded.condition_code,
+		    provider = excluded.provider,
+		    updated_at = excluded.updated_at,
+		    revision = excluded.revision
+	`
+	_, err = db.ExecContext(ctx, query,
+		data.City,
+		data.Temp,
+		data.Condition,
+		string(data.ConditionCode),
+		data.Provider,
+		updatedAt,
+		revision,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения погоды для %s: %w", data.City, err)
+	}
+	if err := saveProviderReading(ctx, db, data, updatedAt); err != nil {
+		return err
+	}
+
+	// Город без справочных данных все равно попадает в справочник,
+	// но выключенным: опрос включает администратор
+	return insertCity(ctx, db, model.City{Name: data.City})
+}
+
+// nextRevision выдает следующую ревизию погоды для ленты изменений
+func nextRevision(ctx context.Context, db execer) (int64, error) {
+	var revision int64
+	err := db.QueryRowContext(ctx, `UPDATE weather_revision SET value = value + 1 RETURNING value`).Scan(&revision)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения ревизии: %w", err)
+	}
+	return revision, nil
+}
+
+// saveProviderReading обновляет последний замер провайдера для города
+func saveProviderReading(ctx context.Context, db execer, data model.WeatherData, updatedAt time.Time) error {
+	query := `
+		INSERT INTO weather_providers (city, provider, temp, condition, condition_code, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (city, provider) DO UPDATE
+		SET temp = excluded.temp,
+		    condition = excluded.condition,
+		    condition_code = excluded.condition_code,
+		    updated_at = excluded.updated_at
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		data.City,
+		data.Provider,
+		data.Temp,
+		data.Condition,
+		string(data.ConditionCode),
+		updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения замера %s для %s: %w", data.Provider, data.City, err)
+	}
+	return nil
+}
+
+// GetByCity возвращает погоду для конкретного города
+func (s *WeatherStorage) GetByCity(ctx context.Context, city string) (*model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetByCity"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, temp, condition, condition_code, provider, updated_at
+		FROM weather
+		WHERE city = ?
+	`
+
+	data, err := scanWeather(s.db.QueryRowContext(ctx, query, city))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("город %s: %w", city, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения данных: %w", err)
+	}
+	return data, nil
+}
+
+// Delete удаляет текущую погоду города вместе с замерами провайдеров и
+// выключает город в справочнике, чтобы коллектор не вернул его;
+// история замеров сохраняется. Возвращает false, если города не было.
+func (s *WeatherStorage) Delete(ctx context.Context, city string) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.Delete"); err != nil {
+		return false, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM weather WHERE city = ?`, city)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления города %s: %w", city, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM weather_providers WHERE city = ?`, city); err != nil {
+		return false, fmt.Errorf("ошибка удаления замеров провайдеров города %s: %w", city, err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE cities SET enabled = FALSE WHERE LOWER(name) = LOWER(?)`, city); err != nil {
+		return false, fmt.Errorf("ошибка выключения города %s: %w", city, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка фиксации удаления города %s: %w", city, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetByCities возвращает погоду для нескольких городов одним запросом.
+// Города без данных в результат не попадают.
+func (s *WeatherStorage) GetByCities(ctx context.Context, cities []string) ([]model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetByCities"); err != nil {
+		return nil, err
+	}
+
+	result := make([]model.WeatherData, 0, len(cities))
+	if len(cities) == 0 {
+		return result, nil
+	}
+
+	args := make([]any, len(cities))
+	for i, city := range cities {
+		args[i] = city
+	}
+	query := `
+		SELECT city, temp, condition, condition_code, provider, updated_at
+		FROM weather
+		WHERE city IN (?` + strings.Repeat(", ?", len(cities)-1) + `)
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения данных: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		data, err := scanWeather(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		result = append(result, *data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return result, nil
+}
+
+// GetProviderReadings возвращает последние замеры всех провайдеров города,
+// упорядоченные по имени провайдера
+func (s *WeatherStorage) GetProviderReadings(ctx context.Context, city string) ([]model.WeatherData, error) {
+	if err := s.faults.Inject(ctx, "storage.GetProviderReadings"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT city, temp, condition, condition_code, provider, updated_at
+		FROM weather_providers
+		WHERE city = ?
+		ORDER BY provider
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, city)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения замеров провайдеров для %s: %w", city, err)
+	}
+	defer rows.Close()
+
+	readings := make([]model.WeatherData, 0)
+	for rows.Next() {
+		data, err := scanWeather(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения замера провайдера: %w", err)
+		}
+		readings = append(readings, *data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения замеров провайдеров: %w", err)
+	}
+	return readings, nil
+}
+
+// citySorts — допустимые сортировки списка городов, как в storage.WeatherStorage
+var citySorts = map[string]string{
+	"city":        "city",
+	"-city":       "city DESC",
+	"updated_at":  "updated_at, city",
+	"-updated_at": "updated_at DESC, city",
+	"temp":        "temp, city",
+	"-temp":       "temp DESC, city",
+}
+
+// GetAllCities возвращает страницу списка городов и общее число городов
+func (s *WeatherStorage) GetAllCities(ctx context.Context, opts storage.CityListOptions) ([]string, int, error) {
+	if err := s.faults.Inject(ctx, "storage.GetAllCities"); err != nil {
+		return nil, 0, err
+	}
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = "city"
+	}
+	order, ok := citySorts[sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("неизвестная сортировка городов: %s", opts.Sort)
+	}
+
+	// Отрицательный LIMIT в SQLite — без ограничения
+	limit := -1
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+	// Порядок выбран из белого списка, подстановка в запрос безопасна
+	query := `SELECT city, COUNT(*) OVER () FROM weather ORDER BY ` + order + ` LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения городов: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		cities []string
+		total  int
+	)
+	for rows.Next() {
+		var city string
+		if err := rows.Scan(&city, &total); err != nil {
+			return nil, 0, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		cities = append(cities, city)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка итерации: %w", err)
+	}
+
+	// Страница за концом списка пуста, но общее число нужно для навигации
+	if len(cities) == 0 && opts.Offset > 0 {
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM weather`).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("ошибка подсчета городов: %w", err)
+		}
+	}
+
+	return cities, total, nil
+}
+
+// ListCityEntries возвращает все города по алфавиту с провайдером и временем
+// последнего замера
+func (s *WeatherStorage) ListCityEntries(ctx context.Context) ([]model.CityEntry, error) {
+	if err := s.faults.Inject(ctx, "storage.ListCityEntries"); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT city, provider, updated_at FROM weather ORDER BY city`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения городов: %w", err)
+	}
+	defer rows.Close()
+
+	cities := []model.CityEntry{}
+	for rows.Next() {
+		var entry model.CityEntry
+		if err := rows.Scan(&entry.City, &entry.Provider, &entry.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		cities = append(cities, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return cities, nil
+}
+
+// ListChanges возвращает города, измененные после ревизии afterRevision
+// (и после updatedAfter, если время задано), по возрастанию ревизии.
+// Второе значение — ревизия последней строки или afterRevision, если изменений нет.
+func (s *WeatherStorage) ListChanges(ctx context.Context, afterRevision int64, updatedAfter time.Time, limit int) ([]model.WeatherData, int64, error) {
+	if err := s.faults.Inject(ctx, "storage.ListChanges"); err != nil {
+		return nil, 0, err
+	}
+
+	var after any
+	if !updatedAfter.IsZero() {
+		after = updatedAfter.UTC()
+	}
+
+	query := `
+		SELECT city, temp, condition, condition_code, provider, updated_at, revision
+		FROM weather
+		WHERE revision > ?1
+		  AND (?2 IS NULL OR updated_at > ?2)
+		ORDER BY revision
+		LIMIT ?3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, afterRevision, after, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения изменений: %w", err)
+	}
+	defer rows.Close()
+
+	last := afterRevision
+	changes := make([]model.WeatherData, 0, limit)
+	for rows.Next() {
+		var data model.WeatherData
+		if err := rows.Scan(
+			&data.City,
+			&data.Temp,
+			&data.Condition,
+			&data.ConditionCode,
+			&data.Provider,
+			&data.Timestamp,
+			&last,
+		); err != nil {
+			return nil, 0, fmt.Errorf("ошибка чтения изменений: %w", err)
+		}
+		changes = append(changes, data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка чтения изменений: %w", err)
+	}
+	return changes, last, nil
+}
+
+// ListUpdatedAt возвращает время последнего обновления каждого города
+func (s *WeatherStorage) ListUpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	if err := s.faults.Inject(ctx, "storage.ListUpdatedAt"); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT city, updated_at FROM weather`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения времени обновления: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]time.Time)
+	for rows.Next() {
+		var (
+			city string
+			at   time.Time
+		)
+		if err := rows.Scan(&city, &at); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		result[city] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return result, nil
+}
+
+func scanWeather(row rowScanner) (*model.WeatherData, error) {
+	var data model.WeatherData
+	err := row.Scan(
+		&data.City,
+		&data.Temp,
+		&data.Condition,
+		&data.ConditionCode,
+		&data.Provider,
+		&data.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// CreateUser сохраняет пользователя с хэшем пароля и возвращает его ID
+func (s *WeatherStorage) CreateUser(ctx context.Context, user model.User, passwordHash string) (int64, error) {
+	if err := s.faults.Inject(ctx, "storage.CreateUser"); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO users (username, password_hash, role, account_id, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		user.Username,
+		passwordHash,
+		user.Role,
+		user.AccountID,
+		user.Active,
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания пользователя %s: %w", user.Username, err)
+	}
+	return id, nil
+}
+
+// GetUserByName возвращает пользователя и хэш его пароля; nil, если пользователя нет
+func (s *WeatherStorage) GetUserByName(ctx context.Context, username string) (*model.User, string, error) {
+	if err := s.faults.Inject(ctx, "storage.GetUserByName"); err != nil {
+		return nil, "", err
+	}
+
+	query := `
+		SELECT id, username, password_hash, role, account_id, active, created_at
+		FROM users
+		WHERE username = $1
+	`
+
+	var (
+		user      model.User
+		hash      string
+		accountID sql.NullInt64
+	)
+	err := s.db.QueryRowContext(ctx, query, username).Scan(
+		&user.ID,
+		&user.Username,
+		&hash,
+		&user.Role,
+		&accountID,
+		&user.Active,
+		&user.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка получения пользователя: %w", err)
+	}
+	if accountID.Valid {
+		user.AccountID = &accountID.Int64
+	}
+	return &user, hash, nil
+}
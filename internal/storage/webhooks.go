@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// CreateWebhook сохраняет webhook аккаунта и возвращает его с ID
+func (s *WeatherStorage) CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error) {
+	if err := s.faults.Inject(ctx, "storage.CreateWebhook"); err != nil {
+		return model.Webhook{}, err
+	}
+
+	query := `
+		INSERT INTO webhooks (account_id, url, city, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := s.db.QueryRowContext(ctx, query,
+		hook.AccountID,
+		hook.URL,
+		hook.City,
+		hook.Secret,
+		hook.CreatedAt,
+	).Scan(&hook.ID)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("ошибка сохранения webhook: %w", err)
+	}
+	return hook, nil
+}
+
+// ListWebhooks возвращает webhooks аккаунта без ключей подписи, новые первыми
+func (s *WeatherStorage) ListWebhooks(ctx context.Context, accountID int64) ([]model.Webhook, error) {
+	if err := s.faults.Inject(ctx, "storage.ListWebhooks"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, account_id, url, city, '', created_at
+		FROM webhooks
+		WHERE account_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+	return s.queryWebhooks(ctx, query, accountID)
+}
+
+// ListWebhooksForCity возвращает webhooks всех аккаунтов, подписанные на город
+// (без учета регистра) или на все города; ключи подписи включены
+func (s *WeatherStorage) ListWebhooksForCity(ctx context.Context, city string) ([]model.Webhook, error) {
+	if err := s.faults.Inject(ctx, "storage.ListWebhooksForCity"); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, account_id, url, city, secret, created_at
+		FROM webhooks
+		WHERE city = '' OR LOWER(city) = LOWER($1)
+		ORDER BY id
+	`
+	return s.queryWebhooks(ctx, query, city)
+}
+
+func (s *WeatherStorage) queryWebhooks(ctx context.Context, query string, arg any) ([]model.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []model.Webhook{}
+	for rows.Next() {
+		var h model.Webhook
+		if err := rows.Scan(&h.ID, &h.AccountID, &h.URL, &h.City, &h.Secret, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования: %w", err)
+		}
+		hooks = append(hooks, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка итерации: %w", err)
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook удаляет webhook аккаунта; false — его нет или он
+// принадлежит другому аккаунту
+func (s *WeatherStorage) DeleteWebhook(ctx context.Context, accountID, id int64) (bool, error) {
+	if err := s.faults.Inject(ctx, "storage.DeleteWebhook"); err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1 AND account_id = $2`, id, accountID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления webhook %d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления webhook %d: %w", id, err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,137 @@
+// Package testutil поднимает настоящие Postgres, Redis и Kafka в Docker для
+// интеграционных тестов обработчиков и конвейера. Контейнеры запускает
+// testcontainers-go и удаляет в t.Cleanup; без Docker тест пропускается.
+// Моки интерфейсов хранилища, кэша и шины — в подпакете mocks.
+package testutil
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/gometeo/app/internal/cache"
+	"github.com/gometeo/app/internal/logging"
+	"github.com/gometeo/app/internal/storage"
+	"github.com/testcontainers/testcontainers-go"
+	tclog "github.com/testcontainers/testcontainers-go/log"
+	"github.com/testcontainers/testcontainers-go/modules/kafka"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// Образы совпадают с docker-compose.yml
+const (
+	postgresImage = "postgres:15"
+	redisImage    = "redis:7-alpine"
+	kafkaImage    = "confluentinc/cp-kafka:7.4.0"
+)
+
+// startTimeout — сколько ждать готовности контейнера
+const startTimeout = 90 * time.Second
+
+// Logger пишет логи сервиса в вывод теста
+func Logger(t testing.TB) *slog.Logger {
+	return slog.New(logging.NewHandler(t.Output(), logging.Options{Level: "debug", Format: "text"}))
+}
+
+// Postgres запускает Postgres и возвращает хранилище с примененными миграциями
+func Postgres(t testing.TB) *storage.WeatherStorage {
+	t.Helper()
+	ctx := startContext(t)
+
+	ctr, err := postgres.Run(ctx, postgresImage,
+		postgres.WithDatabase("gometeo"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("test"),
+		postgres.BasicWaitStrategies(),
+		testcontainers.WithLogger(tclog.TestLogger(t)),
+	)
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatalf("не удалось запустить Postgres: %v", err)
+	}
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("не удалось получить адрес Postgres: %v", err)
+	}
+
+	// storage.New применяет миграции и заполняет справочник городов
+	store, err := storage.New(dsn, Logger(t))
+	if err != nil {
+		t.Fatalf("не удалось подключиться к Postgres: %v", err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+// Redis запускает Redis и возвращает подключенный кэш
+func Redis(t testing.TB, ttl time.Duration) *cache.WeatherCache {
+	t.Helper()
+	ctx := startContext(t)
+
+	ctr, err := redis.Run(ctx, redisImage, testcontainers.WithLogger(tclog.TestLogger(t)))
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatalf("не удалось запустить Redis: %v", err)
+	}
+	addr, err := ctr.Endpoint(ctx, "")
+	if err != nil {
+		t.Fatalf("не удалось получить адрес Redis: %v", err)
+	}
+
+	c, err := cache.New(addr, "", 0, ttl, Logger(t))
+	if err != nil {
+		t.Fatalf("не удалось подключиться к Redis: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// Kafka запускает одноузловую Kafka в режиме KRaft и возвращает адреса брокеров.
+// Топики создаются автоматически при первой записи.
+func Kafka(t testing.TB) []string {
+	t.Helper()
+	ctx := startContext(t)
+
+	ctr, err := kafka.Run(ctx, kafkaImage,
+		kafka.WithClusterID("Z21ldGVvLXRlc3R1dGlsLWthZmth"),
+		testcontainers.WithLogger(tclog.TestLogger(t)),
+	)
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatalf("не удалось запустить Kafka: %v", err)
+	}
+	brokers, err := ctr.Brokers(ctx)
+	if err != nil {
+		t.Fatalf("не удалось получить адреса Kafka: %v", err)
+	}
+	return brokers
+}
+
+// startContext пропускает тест без Docker и ограничивает запуск контейнера startTimeout
+func startContext(t testing.TB) context.Context {
+	t.Helper()
+	requireDocker(t)
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func requireDocker(t testing.TB) {
+	t.Helper()
+	// Клиент Docker паникует, если не находит ни сокета, ни DOCKER_HOST
+	defer func() {
+		if rec := recover(); rec != nil {
+			t.Skipf("docker недоступен, интеграционный тест пропущен: %v", rec)
+		}
+	}()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err == nil {
+		defer provider.Close()
+		err = provider.Health(context.Background())
+	}
+	if err != nil {
+		t.Skipf("docker недоступен, интеграционный тест пропущен: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gometeo/app/internal/aggregator (interfaces: Store)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../testutil/mocks/aggregator.go -package=mocks -mock_names=Store=MockAggregatorStore . Store
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/gometeo/app/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAggregatorStore is a mock of Store interface.
+type MockAggregatorStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAggregatorStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockAggregatorStoreMockRecorder is the mock recorder for MockAggregatorStore.
+type MockAggregatorStoreMockRecorder struct {
+	mock *MockAggregatorStore
+}
+
+// NewMockAggregatorStore creates a new mock instance.
+func NewMockAggregatorStore(ctrl *gomock.Controller) *MockAggregatorStore {
+	mock := &MockAggregatorStore{ctrl: ctrl}
+	mock.recorder = &MockAggregatorStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAggregatorStore) EXPECT() *MockAggregatorStoreMockRecorder {
+	return m.recorder
+}
+
+// GetHistory mocks base method.
+func (m *MockAggregatorStore) GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, city, from, to)
+	ret0, _ := ret[0].([]model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockAggregatorStoreMockRecorder) GetHistory(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockAggregatorStore)(nil).GetHistory), ctx, city, from, to)
+}
+
+// HasProviderForecast mocks base method.
+func (m *MockAggregatorStore) HasProviderForecast(ctx context.Context, city string, after time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasProviderForecast", ctx, city, after)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasProviderForecast indicates an expected call of HasProviderForecast.
+func (mr *MockAggregatorStoreMockRecorder) HasProviderForecast(ctx, city, after any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasProviderForecast", reflect.TypeOf((*MockAggregatorStore)(nil).HasProviderForecast), ctx, city, after)
+}
+
+// ListAlertRulesForCity mocks base method.
+func (m *MockAggregatorStore) ListAlertRulesForCity(ctx context.Context, city string) ([]model.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlertRulesForCity", ctx, city)
+	ret0, _ := ret[0].([]model.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlertRulesForCity indicates an expected call of ListAlertRulesForCity.
+func (mr *MockAggregatorStoreMockRecorder) ListAlertRulesForCity(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlertRulesForCity", reflect.TypeOf((*MockAggregatorStore)(nil).ListAlertRulesForCity), ctx, city)
+}
+
+// ListWebhooksForCity mocks base method.
+func (m *MockAggregatorStore) ListWebhooksForCity(ctx context.Context, city string) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooksForCity", ctx, city)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooksForCity indicates an expected call of ListWebhooksForCity.
+func (mr *MockAggregatorStoreMockRecorder) ListWebhooksForCity(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooksForCity", reflect.TypeOf((*MockAggregatorStore)(nil).ListWebhooksForCity), ctx, city)
+}
+
+// ReplaceForecasts mocks base method.
+func (m *MockAggregatorStore) ReplaceForecasts(ctx context.Context, city, provider string, forecasts []model.Forecast) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceForecasts", ctx, city, provider, forecasts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceForecasts indicates an expected call of ReplaceForecasts.
+func (mr *MockAggregatorStoreMockRecorder) ReplaceForecasts(ctx, city, provider, forecasts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceForecasts", reflect.TypeOf((*MockAggregatorStore)(nil).ReplaceForecasts), ctx, city, provider, forecasts)
+}
+
+// SaveAirQuality mocks base method.
+func (m *MockAggregatorStore) SaveAirQuality(ctx context.Context, data model.AirQuality) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAirQuality", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAirQuality indicates an expected call of SaveAirQuality.
+func (mr *MockAggregatorStoreMockRecorder) SaveAirQuality(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAirQuality", reflect.TypeOf((*MockAggregatorStore)(nil).SaveAirQuality), ctx, data)
+}
+
+// SaveAlertEvent mocks base method.
+func (m *MockAggregatorStore) SaveAlertEvent(ctx context.Context, event model.AlertEvent) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAlertEvent", ctx, event)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveAlertEvent indicates an expected call of SaveAlertEvent.
+func (mr *MockAggregatorStoreMockRecorder) SaveAlertEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAlertEvent", reflect.TypeOf((*MockAggregatorStore)(nil).SaveAlertEvent), ctx, event)
+}
+
+// SaveQualityScore mocks base method.
+func (m *MockAggregatorStore) SaveQualityScore(ctx context.Context, score model.QualityScore) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveQualityScore", ctx, score)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveQualityScore indicates an expected call of SaveQualityScore.
+func (mr *MockAggregatorStoreMockRecorder) SaveQualityScore(ctx, score any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveQualityScore", reflect.TypeOf((*MockAggregatorStore)(nil).SaveQualityScore), ctx, score)
+}
+
+// SaveWithHistory mocks base method.
+func (m *MockAggregatorStore) SaveWithHistory(ctx context.Context, data model.WeatherData) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWithHistory", ctx, data)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWithHistory indicates an expected call of SaveWithHistory.
+func (mr *MockAggregatorStoreMockRecorder) SaveWithHistory(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWithHistory", reflect.TypeOf((*MockAggregatorStore)(nil).SaveWithHistory), ctx, data)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gometeo/app/internal/alerts (interfaces: Store)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../testutil/mocks/alerts.go -package=mocks -mock_names=Store=MockAlertsStore . Store
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/gometeo/app/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAlertsStore is a mock of Store interface.
+type MockAlertsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertsStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockAlertsStoreMockRecorder is the mock recorder for MockAlertsStore.
+type MockAlertsStoreMockRecorder struct {
+	mock *MockAlertsStore
+}
+
+// NewMockAlertsStore creates a new mock instance.
+func NewMockAlertsStore(ctrl *gomock.Controller) *MockAlertsStore {
+	mock := &MockAlertsStore{ctrl: ctrl}
+	mock.recorder = &MockAlertsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertsStore) EXPECT() *MockAlertsStoreMockRecorder {
+	return m.recorder
+}
+
+// ListAlertRulesForCity mocks base method.
+func (m *MockAlertsStore) ListAlertRulesForCity(ctx context.Context, city string) ([]model.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlertRulesForCity", ctx, city)
+	ret0, _ := ret[0].([]model.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlertRulesForCity indicates an expected call of ListAlertRulesForCity.
+func (mr *MockAlertsStoreMockRecorder) ListAlertRulesForCity(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlertRulesForCity", reflect.TypeOf((*MockAlertsStore)(nil).ListAlertRulesForCity), ctx, city)
+}
+
+// SaveAlertEvent mocks base method.
+func (m *MockAlertsStore) SaveAlertEvent(ctx context.Context, event model.AlertEvent) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAlertEvent", ctx, event)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveAlertEvent indicates an expected call of SaveAlertEvent.
+func (mr *MockAlertsStoreMockRecorder) SaveAlertEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAlertEvent", reflect.TypeOf((*MockAlertsStore)(nil).SaveAlertEvent), ctx, event)
+}
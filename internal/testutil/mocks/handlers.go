@@ -0,0 +1,872 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gometeo/app/internal/api/handlers (interfaces: Store,Cache)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../../testutil/mocks/handlers.go -package=mocks -mock_names=Store=MockHandlerStore,Cache=MockHandlerCache . Store,Cache
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	cache "github.com/gometeo/app/internal/cache"
+	model "github.com/gometeo/app/internal/model"
+	storage "github.com/gometeo/app/internal/storage"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHandlerStore is a mock of Store interface.
+type MockHandlerStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockHandlerStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockHandlerStoreMockRecorder is the mock recorder for MockHandlerStore.
+type MockHandlerStoreMockRecorder struct {
+	mock *MockHandlerStore
+}
+
+// NewMockHandlerStore creates a new mock instance.
+func NewMockHandlerStore(ctrl *gomock.Controller) *MockHandlerStore {
+	mock := &MockHandlerStore{ctrl: ctrl}
+	mock.recorder = &MockHandlerStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHandlerStore) EXPECT() *MockHandlerStoreMockRecorder {
+	return m.recorder
+}
+
+// AddFavorite mocks base method.
+func (m *MockHandlerStore) AddFavorite(ctx context.Context, accountID int64, city string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFavorite", ctx, accountID, city)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFavorite indicates an expected call of AddFavorite.
+func (mr *MockHandlerStoreMockRecorder) AddFavorite(ctx, accountID, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFavorite", reflect.TypeOf((*MockHandlerStore)(nil).AddFavorite), ctx, accountID, city)
+}
+
+// CreateAccount mocks base method.
+func (m *MockHandlerStore) CreateAccount(ctx context.Context, account model.Account, keyHash string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, account, keyHash)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockHandlerStoreMockRecorder) CreateAccount(ctx, account, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockHandlerStore)(nil).CreateAccount), ctx, account, keyHash)
+}
+
+// CreateAlertRule mocks base method.
+func (m *MockHandlerStore) CreateAlertRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlertRule", ctx, rule)
+	ret0, _ := ret[0].(model.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlertRule indicates an expected call of CreateAlertRule.
+func (mr *MockHandlerStoreMockRecorder) CreateAlertRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlertRule", reflect.TypeOf((*MockHandlerStore)(nil).CreateAlertRule), ctx, rule)
+}
+
+// CreateCity mocks base method.
+func (m *MockHandlerStore) CreateCity(ctx context.Context, city model.City) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCity", ctx, city)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCity indicates an expected call of CreateCity.
+func (mr *MockHandlerStoreMockRecorder) CreateCity(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCity", reflect.TypeOf((*MockHandlerStore)(nil).CreateCity), ctx, city)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockHandlerStore) CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, hook)
+	ret0, _ := ret[0].(model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockHandlerStoreMockRecorder) CreateWebhook(ctx, hook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockHandlerStore)(nil).CreateWebhook), ctx, hook)
+}
+
+// Delete mocks base method.
+func (m *MockHandlerStore) Delete(ctx context.Context, city string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, city)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockHandlerStoreMockRecorder) Delete(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHandlerStore)(nil).Delete), ctx, city)
+}
+
+// DeleteAlertRule mocks base method.
+func (m *MockHandlerStore) DeleteAlertRule(ctx context.Context, accountID, id int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlertRule", ctx, accountID, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAlertRule indicates an expected call of DeleteAlertRule.
+func (mr *MockHandlerStoreMockRecorder) DeleteAlertRule(ctx, accountID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlertRule", reflect.TypeOf((*MockHandlerStore)(nil).DeleteAlertRule), ctx, accountID, id)
+}
+
+// DeleteCity mocks base method.
+func (m *MockHandlerStore) DeleteCity(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCity", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCity indicates an expected call of DeleteCity.
+func (mr *MockHandlerStoreMockRecorder) DeleteCity(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCity", reflect.TypeOf((*MockHandlerStore)(nil).DeleteCity), ctx, name)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockHandlerStore) DeleteWebhook(ctx context.Context, accountID, id int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, accountID, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockHandlerStoreMockRecorder) DeleteWebhook(ctx, accountID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockHandlerStore)(nil).DeleteWebhook), ctx, accountID, id)
+}
+
+// GetAirQuality mocks base method.
+func (m *MockHandlerStore) GetAirQuality(ctx context.Context, city string) (*model.AirQuality, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAirQuality", ctx, city)
+	ret0, _ := ret[0].(*model.AirQuality)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAirQuality indicates an expected call of GetAirQuality.
+func (mr *MockHandlerStoreMockRecorder) GetAirQuality(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAirQuality", reflect.TypeOf((*MockHandlerStore)(nil).GetAirQuality), ctx, city)
+}
+
+// GetAirQualityHistory mocks base method.
+func (m *MockHandlerStore) GetAirQualityHistory(ctx context.Context, city string, from, to time.Time) ([]model.AirQuality, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAirQualityHistory", ctx, city, from, to)
+	ret0, _ := ret[0].([]model.AirQuality)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAirQualityHistory indicates an expected call of GetAirQualityHistory.
+func (mr *MockHandlerStoreMockRecorder) GetAirQualityHistory(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAirQualityHistory", reflect.TypeOf((*MockHandlerStore)(nil).GetAirQualityHistory), ctx, city, from, to)
+}
+
+// GetAllCities mocks base method.
+func (m *MockHandlerStore) GetAllCities(ctx context.Context, opts storage.CityListOptions) ([]string, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllCities", ctx, opts)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAllCities indicates an expected call of GetAllCities.
+func (mr *MockHandlerStoreMockRecorder) GetAllCities(ctx, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCities", reflect.TypeOf((*MockHandlerStore)(nil).GetAllCities), ctx, opts)
+}
+
+// GetByCities mocks base method.
+func (m *MockHandlerStore) GetByCities(ctx context.Context, cities []string) ([]model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCities", ctx, cities)
+	ret0, _ := ret[0].([]model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCities indicates an expected call of GetByCities.
+func (mr *MockHandlerStoreMockRecorder) GetByCities(ctx, cities any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCities", reflect.TypeOf((*MockHandlerStore)(nil).GetByCities), ctx, cities)
+}
+
+// GetByCity mocks base method.
+func (m *MockHandlerStore) GetByCity(ctx context.Context, city string) (*model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCity", ctx, city)
+	ret0, _ := ret[0].(*model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCity indicates an expected call of GetByCity.
+func (mr *MockHandlerStoreMockRecorder) GetByCity(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCity", reflect.TypeOf((*MockHandlerStore)(nil).GetByCity), ctx, city)
+}
+
+// GetCity mocks base method.
+func (m *MockHandlerStore) GetCity(ctx context.Context, name string) (*model.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCity", ctx, name)
+	ret0, _ := ret[0].(*model.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCity indicates an expected call of GetCity.
+func (mr *MockHandlerStoreMockRecorder) GetCity(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCity", reflect.TypeOf((*MockHandlerStore)(nil).GetCity), ctx, name)
+}
+
+// GetForecasts mocks base method.
+func (m *MockHandlerStore) GetForecasts(ctx context.Context, city string, from, to time.Time) ([]model.Forecast, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForecasts", ctx, city, from, to)
+	ret0, _ := ret[0].([]model.Forecast)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForecasts indicates an expected call of GetForecasts.
+func (mr *MockHandlerStoreMockRecorder) GetForecasts(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecasts", reflect.TypeOf((*MockHandlerStore)(nil).GetForecasts), ctx, city, from, to)
+}
+
+// GetHistory mocks base method.
+func (m *MockHandlerStore) GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, city, from, to)
+	ret0, _ := ret[0].([]model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockHandlerStoreMockRecorder) GetHistory(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockHandlerStore)(nil).GetHistory), ctx, city, from, to)
+}
+
+// GetHistoryAround mocks base method.
+func (m *MockHandlerStore) GetHistoryAround(ctx context.Context, city string, at time.Time) (*model.WeatherData, *model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoryAround", ctx, city, at)
+	ret0, _ := ret[0].(*model.WeatherData)
+	ret1, _ := ret[1].(*model.WeatherData)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetHistoryAround indicates an expected call of GetHistoryAround.
+func (mr *MockHandlerStoreMockRecorder) GetHistoryAround(ctx, city, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryAround", reflect.TypeOf((*MockHandlerStore)(nil).GetHistoryAround), ctx, city, at)
+}
+
+// GetHistoryStats mocks base method.
+func (m *MockHandlerStore) GetHistoryStats(ctx context.Context, city string, from, to time.Time) (model.WeatherStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoryStats", ctx, city, from, to)
+	ret0, _ := ret[0].(model.WeatherStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoryStats indicates an expected call of GetHistoryStats.
+func (mr *MockHandlerStoreMockRecorder) GetHistoryStats(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryStats", reflect.TypeOf((*MockHandlerStore)(nil).GetHistoryStats), ctx, city, from, to)
+}
+
+// GetPreferences mocks base method.
+func (m *MockHandlerStore) GetPreferences(ctx context.Context, accountID int64) (model.Preferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, accountID)
+	ret0, _ := ret[0].(model.Preferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockHandlerStoreMockRecorder) GetPreferences(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockHandlerStore)(nil).GetPreferences), ctx, accountID)
+}
+
+// GetProviderReadings mocks base method.
+func (m *MockHandlerStore) GetProviderReadings(ctx context.Context, city string) ([]model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProviderReadings", ctx, city)
+	ret0, _ := ret[0].([]model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProviderReadings indicates an expected call of GetProviderReadings.
+func (mr *MockHandlerStoreMockRecorder) GetProviderReadings(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderReadings", reflect.TypeOf((*MockHandlerStore)(nil).GetProviderReadings), ctx, city)
+}
+
+// GetUserByName mocks base method.
+func (m *MockHandlerStore) GetUserByName(ctx context.Context, username string) (*model.User, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByName", ctx, username)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserByName indicates an expected call of GetUserByName.
+func (mr *MockHandlerStoreMockRecorder) GetUserByName(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByName", reflect.TypeOf((*MockHandlerStore)(nil).GetUserByName), ctx, username)
+}
+
+// Health mocks base method.
+func (m *MockHandlerStore) Health(ctx context.Context) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health", ctx)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockHandlerStoreMockRecorder) Health(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockHandlerStore)(nil).Health), ctx)
+}
+
+// ListAlertEvents mocks base method.
+func (m *MockHandlerStore) ListAlertEvents(ctx context.Context, accountID int64, limit int) ([]model.AlertEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlertEvents", ctx, accountID, limit)
+	ret0, _ := ret[0].([]model.AlertEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlertEvents indicates an expected call of ListAlertEvents.
+func (mr *MockHandlerStoreMockRecorder) ListAlertEvents(ctx, accountID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlertEvents", reflect.TypeOf((*MockHandlerStore)(nil).ListAlertEvents), ctx, accountID, limit)
+}
+
+// ListAlertRules mocks base method.
+func (m *MockHandlerStore) ListAlertRules(ctx context.Context, accountID int64) ([]model.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlertRules", ctx, accountID)
+	ret0, _ := ret[0].([]model.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlertRules indicates an expected call of ListAlertRules.
+func (mr *MockHandlerStoreMockRecorder) ListAlertRules(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlertRules", reflect.TypeOf((*MockHandlerStore)(nil).ListAlertRules), ctx, accountID)
+}
+
+// ListChanges mocks base method.
+func (m *MockHandlerStore) ListChanges(ctx context.Context, afterRevision int64, updatedAfter time.Time, limit int) ([]model.WeatherData, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanges", ctx, afterRevision, updatedAfter, limit)
+	ret0, _ := ret[0].([]model.WeatherData)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListChanges indicates an expected call of ListChanges.
+func (mr *MockHandlerStoreMockRecorder) ListChanges(ctx, afterRevision, updatedAfter, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockHandlerStore)(nil).ListChanges), ctx, afterRevision, updatedAfter, limit)
+}
+
+// ListCities mocks base method.
+func (m *MockHandlerStore) ListCities(ctx context.Context) ([]model.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCities", ctx)
+	ret0, _ := ret[0].([]model.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCities indicates an expected call of ListCities.
+func (mr *MockHandlerStoreMockRecorder) ListCities(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCities", reflect.TypeOf((*MockHandlerStore)(nil).ListCities), ctx)
+}
+
+// ListCityEntries mocks base method.
+func (m *MockHandlerStore) ListCityEntries(ctx context.Context) ([]model.CityEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCityEntries", ctx)
+	ret0, _ := ret[0].([]model.CityEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCityEntries indicates an expected call of ListCityEntries.
+func (mr *MockHandlerStoreMockRecorder) ListCityEntries(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCityEntries", reflect.TypeOf((*MockHandlerStore)(nil).ListCityEntries), ctx)
+}
+
+// ListFavorites mocks base method.
+func (m *MockHandlerStore) ListFavorites(ctx context.Context, accountID int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFavorites", ctx, accountID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFavorites indicates an expected call of ListFavorites.
+func (mr *MockHandlerStoreMockRecorder) ListFavorites(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFavorites", reflect.TypeOf((*MockHandlerStore)(nil).ListFavorites), ctx, accountID)
+}
+
+// ListQualityScores mocks base method.
+func (m *MockHandlerStore) ListQualityScores(ctx context.Context, city string, from, to time.Time) ([]model.QualityScore, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQualityScores", ctx, city, from, to)
+	ret0, _ := ret[0].([]model.QualityScore)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQualityScores indicates an expected call of ListQualityScores.
+func (mr *MockHandlerStoreMockRecorder) ListQualityScores(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQualityScores", reflect.TypeOf((*MockHandlerStore)(nil).ListQualityScores), ctx, city, from, to)
+}
+
+// ListUpdatedAt mocks base method.
+func (m *MockHandlerStore) ListUpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUpdatedAt", ctx)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUpdatedAt indicates an expected call of ListUpdatedAt.
+func (mr *MockHandlerStoreMockRecorder) ListUpdatedAt(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUpdatedAt", reflect.TypeOf((*MockHandlerStore)(nil).ListUpdatedAt), ctx)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockHandlerStore) ListWebhooks(ctx context.Context, accountID int64) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, accountID)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockHandlerStoreMockRecorder) ListWebhooks(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockHandlerStore)(nil).ListWebhooks), ctx, accountID)
+}
+
+// Ping mocks base method.
+func (m *MockHandlerStore) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockHandlerStoreMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockHandlerStore)(nil).Ping), ctx)
+}
+
+// RemoveFavorite mocks base method.
+func (m *MockHandlerStore) RemoveFavorite(ctx context.Context, accountID int64, city string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFavorite", ctx, accountID, city)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveFavorite indicates an expected call of RemoveFavorite.
+func (mr *MockHandlerStoreMockRecorder) RemoveFavorite(ctx, accountID, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFavorite", reflect.TypeOf((*MockHandlerStore)(nil).RemoveFavorite), ctx, accountID, city)
+}
+
+// Save mocks base method.
+func (m *MockHandlerStore) Save(ctx context.Context, data model.WeatherData) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, data)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockHandlerStoreMockRecorder) Save(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockHandlerStore)(nil).Save), ctx, data)
+}
+
+// SaveBatch mocks base method.
+func (m *MockHandlerStore) SaveBatch(ctx context.Context, batch []model.WeatherData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, batch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockHandlerStoreMockRecorder) SaveBatch(ctx, batch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockHandlerStore)(nil).SaveBatch), ctx, batch)
+}
+
+// SavePreferences mocks base method.
+func (m *MockHandlerStore) SavePreferences(ctx context.Context, accountID int64, prefs model.Preferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreferences", ctx, accountID, prefs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreferences indicates an expected call of SavePreferences.
+func (mr *MockHandlerStoreMockRecorder) SavePreferences(ctx, accountID, prefs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferences", reflect.TypeOf((*MockHandlerStore)(nil).SavePreferences), ctx, accountID, prefs)
+}
+
+// SearchCities mocks base method.
+func (m *MockHandlerStore) SearchCities(ctx context.Context, q string, limit int) ([]model.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchCities", ctx, q, limit)
+	ret0, _ := ret[0].([]model.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchCities indicates an expected call of SearchCities.
+func (mr *MockHandlerStoreMockRecorder) SearchCities(ctx, q, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCities", reflect.TypeOf((*MockHandlerStore)(nil).SearchCities), ctx, q, limit)
+}
+
+// UpdateCity mocks base method.
+func (m *MockHandlerStore) UpdateCity(ctx context.Context, name string, city model.City) (*model.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCity", ctx, name, city)
+	ret0, _ := ret[0].(*model.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCity indicates an expected call of UpdateCity.
+func (mr *MockHandlerStoreMockRecorder) UpdateCity(ctx, name, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCity", reflect.TypeOf((*MockHandlerStore)(nil).UpdateCity), ctx, name, city)
+}
+
+// MockHandlerCache is a mock of Cache interface.
+type MockHandlerCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockHandlerCacheMockRecorder
+	isgomock struct{}
+}
+
+// MockHandlerCacheMockRecorder is the mock recorder for MockHandlerCache.
+type MockHandlerCacheMockRecorder struct {
+	mock *MockHandlerCache
+}
+
+// NewMockHandlerCache creates a new mock instance.
+func NewMockHandlerCache(ctrl *gomock.Controller) *MockHandlerCache {
+	mock := &MockHandlerCache{ctrl: ctrl}
+	mock.recorder = &MockHandlerCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHandlerCache) EXPECT() *MockHandlerCacheMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockHandlerCache) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockHandlerCacheMockRecorder) Delete(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHandlerCache)(nil).Delete), ctx, key)
+}
+
+// Get mocks base method.
+func (m *MockHandlerCache) Get(ctx context.Context, key string) (*model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(*model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockHandlerCacheMockRecorder) Get(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockHandlerCache)(nil).Get), ctx, key)
+}
+
+// GetAirQuality mocks base method.
+func (m *MockHandlerCache) GetAirQuality(ctx context.Context, key string) (*model.AirQuality, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAirQuality", ctx, key)
+	ret0, _ := ret[0].(*model.AirQuality)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAirQuality indicates an expected call of GetAirQuality.
+func (mr *MockHandlerCacheMockRecorder) GetAirQuality(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAirQuality", reflect.TypeOf((*MockHandlerCache)(nil).GetAirQuality), ctx, key)
+}
+
+// GetBytes mocks base method.
+func (m *MockHandlerCache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBytes", ctx, key)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBytes indicates an expected call of GetBytes.
+func (mr *MockHandlerCacheMockRecorder) GetBytes(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBytes", reflect.TypeOf((*MockHandlerCache)(nil).GetBytes), ctx, key)
+}
+
+// GetCityList mocks base method.
+func (m *MockHandlerCache) GetCityList(ctx context.Context, key string) ([]model.CityEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCityList", ctx, key)
+	ret0, _ := ret[0].([]model.CityEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCityList indicates an expected call of GetCityList.
+func (mr *MockHandlerCacheMockRecorder) GetCityList(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCityList", reflect.TypeOf((*MockHandlerCache)(nil).GetCityList), ctx, key)
+}
+
+// GetMaintenance mocks base method.
+func (m *MockHandlerCache) GetMaintenance(ctx context.Context) (*model.Maintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaintenance", ctx)
+	ret0, _ := ret[0].(*model.Maintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaintenance indicates an expected call of GetMaintenance.
+func (mr *MockHandlerCacheMockRecorder) GetMaintenance(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenance", reflect.TypeOf((*MockHandlerCache)(nil).GetMaintenance), ctx)
+}
+
+// GetMany mocks base method.
+func (m *MockHandlerCache) GetMany(ctx context.Context, keys []string) ([]*model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMany", ctx, keys)
+	ret0, _ := ret[0].([]*model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMany indicates an expected call of GetMany.
+func (mr *MockHandlerCacheMockRecorder) GetMany(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMany", reflect.TypeOf((*MockHandlerCache)(nil).GetMany), ctx, keys)
+}
+
+// IncrCounter mocks base method.
+func (m *MockHandlerCache) IncrCounter(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrCounter", ctx, key, ttl)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrCounter indicates an expected call of IncrCounter.
+func (mr *MockHandlerCacheMockRecorder) IncrCounter(ctx, key, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrCounter", reflect.TypeOf((*MockHandlerCache)(nil).IncrCounter), ctx, key, ttl)
+}
+
+// Set mocks base method.
+func (m *MockHandlerCache) Set(ctx context.Context, key string, data model.WeatherData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockHandlerCacheMockRecorder) Set(ctx, key, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockHandlerCache)(nil).Set), ctx, key, data)
+}
+
+// SetAirQuality mocks base method.
+func (m *MockHandlerCache) SetAirQuality(ctx context.Context, key string, data model.AirQuality, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAirQuality", ctx, key, data, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAirQuality indicates an expected call of SetAirQuality.
+func (mr *MockHandlerCacheMockRecorder) SetAirQuality(ctx, key, data, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAirQuality", reflect.TypeOf((*MockHandlerCache)(nil).SetAirQuality), ctx, key, data, ttl)
+}
+
+// SetBytes mocks base method.
+func (m *MockHandlerCache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBytes", ctx, key, data, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBytes indicates an expected call of SetBytes.
+func (mr *MockHandlerCacheMockRecorder) SetBytes(ctx, key, data, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBytes", reflect.TypeOf((*MockHandlerCache)(nil).SetBytes), ctx, key, data, ttl)
+}
+
+// SetCityList mocks base method.
+func (m *MockHandlerCache) SetCityList(ctx context.Context, key string, cities []model.CityEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCityList", ctx, key, cities)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCityList indicates an expected call of SetCityList.
+func (mr *MockHandlerCacheMockRecorder) SetCityList(ctx, key, cities any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCityList", reflect.TypeOf((*MockHandlerCache)(nil).SetCityList), ctx, key, cities)
+}
+
+// SetMaintenance mocks base method.
+func (m_2 *MockHandlerCache) SetMaintenance(ctx context.Context, m model.Maintenance, ttl time.Duration) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SetMaintenance", ctx, m, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMaintenance indicates an expected call of SetMaintenance.
+func (mr *MockHandlerCacheMockRecorder) SetMaintenance(ctx, m, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaintenance", reflect.TypeOf((*MockHandlerCache)(nil).SetMaintenance), ctx, m, ttl)
+}
+
+// TTL mocks base method.
+func (m *MockHandlerCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TTL", ctx, key)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TTL indicates an expected call of TTL.
+func (mr *MockHandlerCacheMockRecorder) TTL(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TTL", reflect.TypeOf((*MockHandlerCache)(nil).TTL), ctx, key)
+}
+
+// TakeToken mocks base method.
+func (m *MockHandlerCache) TakeToken(ctx context.Context, key string, rate float64, burst int64, now time.Time) (cache.Bucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeToken", ctx, key, rate, burst, now)
+	ret0, _ := ret[0].(cache.Bucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeToken indicates an expected call of TakeToken.
+func (mr *MockHandlerCacheMockRecorder) TakeToken(ctx, key, rate, burst, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeToken", reflect.TypeOf((*MockHandlerCache)(nil).TakeToken), ctx, key, rate, burst, now)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gometeo/app/internal/nowcast (interfaces: Store)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../testutil/mocks/nowcast.go -package=mocks -mock_names=Store=MockNowcastStore . Store
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/gometeo/app/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNowcastStore is a mock of Store interface.
+type MockNowcastStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockNowcastStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockNowcastStoreMockRecorder is the mock recorder for MockNowcastStore.
+type MockNowcastStoreMockRecorder struct {
+	mock *MockNowcastStore
+}
+
+// NewMockNowcastStore creates a new mock instance.
+func NewMockNowcastStore(ctrl *gomock.Controller) *MockNowcastStore {
+	mock := &MockNowcastStore{ctrl: ctrl}
+	mock.recorder = &MockNowcastStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNowcastStore) EXPECT() *MockNowcastStoreMockRecorder {
+	return m.recorder
+}
+
+// GetHistory mocks base method.
+func (m *MockNowcastStore) GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, city, from, to)
+	ret0, _ := ret[0].([]model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockNowcastStoreMockRecorder) GetHistory(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockNowcastStore)(nil).GetHistory), ctx, city, from, to)
+}
+
+// HasProviderForecast mocks base method.
+func (m *MockNowcastStore) HasProviderForecast(ctx context.Context, city string, after time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasProviderForecast", ctx, city, after)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasProviderForecast indicates an expected call of HasProviderForecast.
+func (mr *MockNowcastStoreMockRecorder) HasProviderForecast(ctx, city, after any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasProviderForecast", reflect.TypeOf((*MockNowcastStore)(nil).HasProviderForecast), ctx, city, after)
+}
+
+// ReplaceForecasts mocks base method.
+func (m *MockNowcastStore) ReplaceForecasts(ctx context.Context, city, provider string, forecasts []model.Forecast) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceForecasts", ctx, city, provider, forecasts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceForecasts indicates an expected call of ReplaceForecasts.
+func (mr *MockNowcastStoreMockRecorder) ReplaceForecasts(ctx, city, provider, forecasts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceForecasts", reflect.TypeOf((*MockNowcastStore)(nil).ReplaceForecasts), ctx, city, provider, forecasts)
+}
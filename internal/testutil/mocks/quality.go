@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gometeo/app/internal/quality (interfaces: Store)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../testutil/mocks/quality.go -package=mocks -mock_names=Store=MockQualityStore . Store
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/gometeo/app/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockQualityStore is a mock of Store interface.
+type MockQualityStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockQualityStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockQualityStoreMockRecorder is the mock recorder for MockQualityStore.
+type MockQualityStoreMockRecorder struct {
+	mock *MockQualityStore
+}
+
+// NewMockQualityStore creates a new mock instance.
+func NewMockQualityStore(ctrl *gomock.Controller) *MockQualityStore {
+	mock := &MockQualityStore{ctrl: ctrl}
+	mock.recorder = &MockQualityStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQualityStore) EXPECT() *MockQualityStoreMockRecorder {
+	return m.recorder
+}
+
+// GetHistory mocks base method.
+func (m *MockQualityStore) GetHistory(ctx context.Context, city string, from, to time.Time) ([]model.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, city, from, to)
+	ret0, _ := ret[0].([]model.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockQualityStoreMockRecorder) GetHistory(ctx, city, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockQualityStore)(nil).GetHistory), ctx, city, from, to)
+}
+
+// SaveQualityScore mocks base method.
+func (m *MockQualityStore) SaveQualityScore(ctx context.Context, score model.QualityScore) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveQualityScore", ctx, score)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveQualityScore indicates an expected call of SaveQualityScore.
+func (mr *MockQualityStoreMockRecorder) SaveQualityScore(ctx, score any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveQualityScore", reflect.TypeOf((*MockQualityStore)(nil).SaveQualityScore), ctx, score)
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/gometeo/app/internal/webhook (interfaces: Store)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../testutil/mocks/webhook.go -package=mocks -mock_names=Store=MockWebhookStore . Store
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/gometeo/app/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWebhookStore is a mock of Store interface.
+type MockWebhookStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookStoreMockRecorder is the mock recorder for MockWebhookStore.
+type MockWebhookStoreMockRecorder struct {
+	mock *MockWebhookStore
+}
+
+// NewMockWebhookStore creates a new mock instance.
+func NewMockWebhookStore(ctrl *gomock.Controller) *MockWebhookStore {
+	mock := &MockWebhookStore{ctrl: ctrl}
+	mock.recorder = &MockWebhookStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookStore) EXPECT() *MockWebhookStoreMockRecorder {
+	return m.recorder
+}
+
+// ListWebhooksForCity mocks base method.
+func (m *MockWebhookStore) ListWebhooksForCity(ctx context.Context, city string) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooksForCity", ctx, city)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooksForCity indicates an expected call of ListWebhooksForCity.
+func (mr *MockWebhookStoreMockRecorder) ListWebhooksForCity(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooksForCity", reflect.TypeOf((*MockWebhookStore)(nil).ListWebhooksForCity), ctx, city)
+}
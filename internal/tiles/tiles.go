@@ -0,0 +1,135 @@
+// Package tiles загружает тайлы радара и облачности у внешнего провайдера.
+// Ключ провайдера подставляется только здесь и не попадает к клиентам.
+package tiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+)
+
+// maxTileSize — ограничение на размер тайла от провайдера
+const maxTileSize = 1 << 20
+
+var (
+	// ErrUnknownLayer — слой не входит в разрешенный список
+	ErrUnknownLayer = errors.New("неизвестный слой")
+	// ErrInvalidTile — координаты вне сетки тайлов или зум больше допустимого
+	ErrInvalidTile = errors.New("неверные координаты тайла")
+	// ErrNotFound — у провайдера нет такого тайла
+	ErrNotFound = errors.New("тайл не найден")
+)
+
+// Tile — адрес тайла в сетке XYZ
+type Tile struct {
+	Layer   string
+	Z, X, Y int
+}
+
+// String возвращает адрес в виде layer/z/x/y
+func (t Tile) String() string {
+	return t.Layer + "/" + strconv.Itoa(t.Z) + "/" + strconv.Itoa(t.X) + "/" + strconv.Itoa(t.Y)
+}
+
+// Upstream загружает тайлы по шаблону URL провайдера
+type Upstream struct {
+	template string
+	apiKey   string
+	layers   map[string]bool
+	maxZoom  int
+	client   *http.Client
+}
+
+// New создает загрузчик. template содержит {layer}, {z}, {x}, {y} и, при необходимости, {key}.
+func New(template, apiKey string, layers []string, maxZoom int) *Upstream {
+	allowed := make(map[string]bool, len(layers))
+	for _, l := range layers {
+		if l = strings.TrimSpace(l); l != "" {
+			allowed[l] = true
+		}
+	}
+	return &Upstream{
+		template: template,
+		apiKey:   apiKey,
+		layers:   allowed,
+		maxZoom:  maxZoom,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// FromConfig создает загрузчик по настройкам, nil если прокси выключен
+func FromConfig(cfg *config.Config) *Upstream {
+	if cfg.TilesUpstreamURL == "" {
+		return nil
+	}
+	return New(cfg.TilesUpstreamURL, cfg.TilesAPIKey, cfg.TilesLayers, cfg.TilesMaxZoom)
+}
+
+// Validate проверяет слой и координаты до обращения к кэшу и провайдеру
+func (u *Upstream) Validate(t Tile) error {
+	if !u.layers[t.Layer] {
+		return fmt.Errorf("%w: %s", ErrUnknownLayer, t.Layer)
+	}
+	if t.Z < 0 || t.Z > u.maxZoom {
+		return fmt.Errorf("%w: зум %d вне диапазона [0, %d]", ErrInvalidTile, t.Z, u.maxZoom)
+	}
+	size := 1 << t.Z
+	if t.X < 0 || t.X >= size || t.Y < 0 || t.Y >= size {
+		return fmt.Errorf("%w: %s", ErrInvalidTile, t)
+	}
+	return nil
+}
+
+// Fetch загружает PNG тайла у провайдера
+func (u *Upstream) Fetch(ctx context.Context, t Tile) ([]byte, error) {
+	if err := u.Validate(t); err != nil {
+		return nil, err
+	}
+
+	target := strings.NewReplacer(
+		"{layer}", t.Layer,
+		"{z}", strconv.Itoa(t.Z),
+		"{x}", strconv.Itoa(t.X),
+		"{y}", strconv.Itoa(t.Y),
+		"{key}", u.apiKey,
+	).Replace(u.template)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		// Ошибка клиента содержит URL вместе с ключом провайдера
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("ошибка запроса тайла %s: %w", t, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, t)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("провайдер тайлов вернул %d для %s", resp.StatusCode, t)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения тайла %s: %w", t, err)
+	}
+	if len(body) > maxTileSize {
+		return nil, fmt.Errorf("тайл %s больше %d байт", t, maxTileSize)
+	}
+	return body, nil
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+
+	"github.com/gometeo/app/internal/logging"
+)
+
+// producerCarrier позволяет записывать контекст трассировки в заголовки сообщения
+type producerCarrier struct {
+	msg *sarama.ProducerMessage
+}
+
+func (c producerCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c producerCarrier) Set(key, value string) {
+	for i, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			c.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.msg.Headers = append(c.msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (c producerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+	for _, h := range c.msg.Headers {
+		keys = append(keys, string(h.Key))
+	}
+	return keys
+}
+
+// consumerCarrier читает контекст трассировки из заголовков полученного сообщения
+type consumerCarrier struct {
+	msg *sarama.ConsumerMessage
+}
+
+func (c consumerCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c consumerCarrier) Set(string, string) {}
+
+func (c consumerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+	for _, h := range c.msg.Headers {
+		if h != nil {
+			keys = append(keys, string(h.Key))
+		}
+	}
+	return keys
+}
+
+// InjectKafka записывает traceparent текущего спана и идентификатор запроса
+// в заголовки сообщения
+func InjectKafka(ctx context.Context, msg *sarama.ProducerMessage) {
+	carrier := producerCarrier{msg: msg}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if id := logging.RequestID(ctx); id != "" {
+		carrier.Set(logging.HeaderRequestID, id)
+	}
+}
+
+// ExtractKafka восстанавливает контекст продюсера из заголовков сообщения:
+// трассу и идентификатор запроса, с которым замер попадет в логи потребителя
+func ExtractKafka(ctx context.Context, msg *sarama.ConsumerMessage) context.Context {
+	carrier := consumerCarrier{msg: msg}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	if id := carrier.Get(logging.HeaderRequestID); logging.ValidRequestID(id) {
+		ctx = logging.WithRequestID(ctx, id)
+	}
+	return ctx
+}
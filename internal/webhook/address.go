@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/gometeo/app/internal/model"
+)
+
+// lookupTimeout — сколько CheckURL ждет разрешения имени хоста
+const lookupTimeout = 5 * time.Second
+
+var (
+	// ErrPrivateAddress — адрес webhook ведет во внутреннюю сеть
+	ErrPrivateAddress = errors.New("адрес во внутренней сети")
+	// ErrUnresolvable — имя хоста webhook не разрешается
+	ErrUnresolvable = errors.New("не удалось разрешить имя хоста")
+)
+
+// CheckURL не пускает webhooks во внутреннюю сеть: агрегатор не должен ходить
+// на свои сервисы и метаданные облака по адресу пользователя. Адрес должен
+// пройти model.Webhook.Validate. При отправке адрес проверяется еще раз:
+// DNS мог измениться после сохранения.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !model.PublicIP(ip) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return ErrUnresolvable
+	}
+	for _, addr := range addrs {
+		if !model.PublicIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// newClient создает клиент, который соединяется только с публичными адресами.
+// Адрес проверяется после разрешения имени, поэтому подмена DNS после
+// сохранения webhook не помогает. Переадресации не выполняются: ответ 3xx
+// считается отказом получателя.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !model.PublicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Прокси из окружения соединялся бы с адресом в обход проверки
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
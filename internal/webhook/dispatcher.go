@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/metrics"
+	"github.com/gometeo/app/internal/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+//go:generate go tool mockgen -destination=../testutil/mocks/webhook.go -package=mocks -mock_names=Store=MockWebhookStore . Store
+
+// Store — операции с БД, нужные рассылке; реализуется storage.WeatherStorage
+type Store interface {
+	ListWebhooksForCity(ctx context.Context, city string) ([]model.Webhook, error)
+}
+
+// delivery — один замер для одного webhook
+type delivery struct {
+	id   string
+	hook model.Webhook
+	body []byte
+}
+
+// Dispatcher отправляет замеры на webhooks в фоне: обработка сообщения Kafka
+// не ждет медленных получателей. Нулевой указатель безопасен и ничего не делает.
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	logger      *slog.Logger
+	deliveries  metric.Int64Counter
+
+	queue  chan delivery
+	ctx    context.Context // отменяется при Close, прерывая паузы между попытками
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher запускает рассылку по конфигурации, nil если webhooks выключены
+func NewDispatcher(cfg *config.Config, store Store, logger *slog.Logger) *Dispatcher {
+	if !cfg.WebhooksEnabled {
+		return nil
+	}
+
+	deliveries, _ := metrics.Meter("github.com/gometeo/app/internal/webhook").Int64Counter("webhook.deliveries",
+		metric.WithDescription("Количество доставок webhook по результату"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		store:       store,
+		client:      newClient(cfg.WebhookTimeout),
+		maxAttempts: max(cfg.WebhookMaxAttempts, 1),
+		backoff:     cfg.WebhookRetryBackoff,
+		logger:      logger,
+		deliveries:  deliveries,
+		queue:       make(chan delivery, max(cfg.WebhookQueueSize, 1)),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for range max(cfg.WebhookWorkers, 1) {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Observe ставит сохраненный замер в очередь для всех подходящих webhooks
+func (d *Dispatcher) Observe(ctx context.Context, data model.WeatherData) error {
+	if d == nil {
+		return nil
+	}
+
+	hooks, err := d.store.ListWebhooksForCity(ctx, data.City)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	for _, hook := range hooks {
+		if !hook.Matches(data.City) {
+			continue
+		}
+		id, err := newDeliveryID()
+		if err != nil {
+			return err
+		}
+		select {
+		case d.queue <- delivery{id: id, hook: hook, body: body}:
+		default:
+			d.deliveries.Add(ctx, 1, metric.WithAttributes(attribute.String("result", "dropped")))
+			d.logger.WarnContext(ctx, "Очередь webhooks переполнена, доставка отброшена",
+				"webhook_id", hook.ID, "city", data.City)
+		}
+	}
+	return nil
+}
+
+// Close ждет отправки очереди до отмены ctx; после этого паузы между попытками
+// прерываются и оставшиеся доставки теряются. Вызывается после остановки
+// чтения Kafka: Observe после Close недопустим.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	close(d.queue)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return fmt.Errorf("не все доставки webhooks завершены: %w", ctx.Err())
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for job := range d.queue {
+		attempts, err := d.deliver(job)
+		result := "delivered"
+		if err != nil {
+			result = "failed"
+			d.logger.Warn("Webhook не доставлен",
+				"webhook_id", job.hook.ID, "delivery", job.id, "attempts", attempts, "error", err)
+		} else {
+			d.logger.Debug("Webhook доставлен", "webhook_id", job.hook.ID, "delivery", job.id, "attempts", attempts)
+		}
+		d.deliveries.Add(d.ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+	}
+}
+
+// deliver повторяет отправку с экспоненциальной паузой, пока ошибка временная.
+// Возвращает число попыток.
+func (d *Dispatcher) deliver(job delivery) (int, error) {
+	pause := d.backoff
+	for attempt := 1; ; attempt++ {
+		err := d.post(job)
+		if err == nil {
+			return attempt, nil
+		}
+		var perm permanentError
+		if errors.As(err, &perm) || attempt == d.maxAttempts {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(pause)
+		select {
+		case <-timer.C:
+		case <-d.ctx.Done():
+			timer.Stop()
+			return attempt, fmt.Errorf("доставка прервана: %w", err)
+		}
+		pause *= 2
+	}
+}
+
+// permanentError — ошибка, после которой повтор бесполезен: неверный адрес
+// или отказ получателя с кодом 4xx
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// post отправляет одну попытку. Подпись считается заново: время в ней
+// должно быть свежим. Адрес не попадает в ошибку: в нем может быть токен.
+func (d *Dispatcher) post(job delivery) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, job.hook.URL, bytes.NewReader(job.body))
+	if err != nil {
+		return permanentError{errors.New("неверный адрес webhook")}
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoMeteo-Webhook/1")
+	req.Header.Set(HeaderEvent, model.EventWeatherUpdated)
+	req.Header.Set(HeaderDelivery, job.id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(job.hook.Secret, now, job.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		if errors.Is(err, ErrPrivateAddress) {
+			return permanentError{err}
+		}
+		return fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("получатель ответил HTTP %d", resp.StatusCode)
+	default:
+		return permanentError{fmt.Errorf("получатель отклонил доставку: HTTP %d", resp.StatusCode)}
+	}
+}
@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/gometeo/app/internal/config"
+	"github.com/gometeo/app/internal/model"
+	"github.com/gometeo/app/internal/testutil"
+	"github.com/gometeo/app/internal/testutil/mocks"
+)
+
+func newDispatcher(t *testing.T, backoff time.Duration, hook model.Webhook) *Dispatcher {
+	cfg := config.Load()
+	cfg.WebhooksEnabled = true
+	cfg.WebhookMaxAttempts = 3
+	cfg.WebhookRetryBackoff = backoff
+	cfg.WebhookWorkers = 1
+
+	store := mocks.NewMockWebhookStore(gomock.NewController(t))
+	store.EXPECT().ListWebhooksForCity(gomock.Any(), "Moscow").Return([]model.Webhook{hook}, nil)
+	return NewDispatcher(cfg, store, testutil.Logger(t))
+}
+
+func closeDispatcher(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatcherRetriesSignedDelivery(t *testing.T) {
+	type attempt struct {
+		delivery, timestamp, signature string
+		body                           []byte
+	}
+	var (
+		mu       sync.Mutex
+		attempts []attempt
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, attempt{
+			delivery:  r.Header.Get(HeaderDelivery),
+			timestamp: r.Header.Get(HeaderTimestamp),
+			signature: r.Header.Get(HeaderSignature),
+			body:      body,
+		})
+		// Первая попытка — временный сбой получателя
+		if len(attempts) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	hook := model.Webhook{ID: 1, URL: srv.URL, Secret: "whsec_test"}
+	d := newDispatcher(t, time.Millisecond, hook)
+	// Тестовый сервер слушает loopback, куда настоящий клиент не соединяется
+	d.client = srv.Client()
+
+	if err := d.Observe(context.Background(), model.WeatherData{City: "Moscow", Temp: 5}); err != nil {
+		t.Fatal(err)
+	}
+	closeDispatcher(t, d)
+
+	if len(attempts) != 2 {
+		t.Fatalf("попыток %d, ожидалось 2", len(attempts))
+	}
+	if attempts[0].delivery == "" || attempts[0].delivery != attempts[1].delivery {
+		t.Errorf("ID доставки меняется между попытками: %q и %q", attempts[0].delivery, attempts[1].delivery)
+	}
+	for _, a := range attempts {
+		ts, err := strconv.ParseInt(a.timestamp, 10, 64)
+		if err != nil {
+			t.Fatalf("неверное время подписи %q", a.timestamp)
+		}
+		if want := Sign(hook.Secret, time.Unix(ts, 0), a.body); a.signature != want {
+			t.Errorf("подпись %q, ожидалась %q", a.signature, want)
+		}
+	}
+}
+
+func TestDispatcherRefusesInternalAddress(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	// Пауза между попытками длиннее таймаута Close: отказ не должен повторяться
+	d := newDispatcher(t, time.Hour, model.Webhook{ID: 1, URL: srv.URL, Secret: "whsec_test"})
+	if err := d.Observe(context.Background(), model.WeatherData{City: "Moscow", Temp: 5}); err != nil {
+		t.Fatal(err)
+	}
+	closeDispatcher(t, d)
+
+	if n := hits.Load(); n != 0 {
+		t.Errorf("запросов на loopback: %d, ожидалось 0", n)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://93.184.216.34/hook", nil},
+		{"http://127.0.0.1:8080/hook", ErrPrivateAddress},
+		{"http://[::1]/hook", ErrPrivateAddress},
+		{"http://10.1.2.3/hook", ErrPrivateAddress},
+		{"http://192.168.0.10/hook", ErrPrivateAddress},
+		{"http://169.254.169.254/latest/meta-data", ErrPrivateAddress},
+		{"http://100.100.100.200/hook", ErrPrivateAddress},
+		{"http://0.0.0.0/hook", ErrPrivateAddress},
+		{"http://[::ffff:127.0.0.1]/hook", ErrPrivateAddress},
+		{"http://localhost/hook", ErrPrivateAddress},
+		{"http://gometeo-test.invalid/hook", ErrUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if err := CheckURL(context.Background(), tt.url); !errors.Is(err, tt.want) {
+				t.Errorf("CheckURL(%q) = %v, ожидалось %v", tt.url, err, tt.want)
+			}
+		})
+	}
+}
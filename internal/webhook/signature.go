@@ -0,0 +1,54 @@
+// Package webhook доставляет сохраненные агрегатором замеры на адреса,
+// зарегистрированные пользователями через POST /api/v1/webhooks.
+// Каждая доставка подписана HMAC-SHA256 ключом webhook.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Заголовки доставки
+const (
+	HeaderEvent     = "X-GoMeteo-Event"
+	HeaderDelivery  = "X-GoMeteo-Delivery"
+	HeaderTimestamp = "X-GoMeteo-Timestamp"
+	// HeaderSignature — "sha256=" и HMAC-SHA256 строки "<timestamp>.<тело>" в hex
+	HeaderSignature = "X-GoMeteo-Signature"
+)
+
+// secretPrefix помогает узнать ключ подписи GoMeteo в логах и секретах
+const secretPrefix = "whsec_"
+
+// GenerateSecret создает ключ подписи нового webhook
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
+
+// newDeliveryID — идентификатор доставки для заголовка HeaderDelivery;
+// одинаков во всех попытках, чтобы получатель мог отбросить повтор
+func newDeliveryID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign подписывает тело доставки. Время в подписи не дает повторить
+// перехваченную доставку позже: получатель сверяет его со своими часами.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}